@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrMiss is returned by Get when nothing is cached under the key, or the entry expired
+var ErrMiss = errors.New("cache miss")
+
+// Cache keeps encoded values under a string key for a while. Every instance of the API may have its
+// own cache, so an entry can outlive a change made through another instance until its TTL expires.
+type Cache interface {
+
+	// Get returns the value cached under the key, ErrMiss when there is none
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Set caches the value under the key for the TTL, replacing any existing entry
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Delete evicts the entries of the keys, missing keys are ignored
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// NopCache caches nothing, every Get misses
+type NopCache struct{}
+
+func NewNopCache() NopCache {
+	return NopCache{}
+}
+
+func (NopCache) Get(ctx context.Context, key string) ([]byte, error) {
+	return nil, ErrMiss
+}
+
+func (NopCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return nil
+}
+
+func (NopCache) Delete(ctx context.Context, keys ...string) error {
+	return nil
+}
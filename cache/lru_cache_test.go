@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLRUCache_InvalidCapacity(t *testing.T) {
+	_, err := NewLRUCache(0)
+
+	assert.Error(t, err)
+}
+
+func TestLRUCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	lruCache, err := NewLRUCache(2)
+	assert.NoError(t, err)
+	assert.NoError(t, lruCache.Set(ctx, "a", []byte("1"), time.Minute))
+	assert.NoError(t, lruCache.Set(ctx, "b", []byte("2"), time.Minute))
+
+	// Act
+	// reading a makes b the least recently used
+	_, _ = lruCache.Get(ctx, "a")
+	assert.NoError(t, lruCache.Set(ctx, "c", []byte("3"), time.Minute))
+
+	// Assert
+	_, err = lruCache.Get(ctx, "b")
+	assert.ErrorIs(t, err, ErrMiss)
+	value, err := lruCache.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("1"), value)
+	assert.Equal(t, 2, lruCache.Len())
+}
+
+func TestLRUCache_Expires(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	lruCache, err := NewLRUCache(2)
+	assert.NoError(t, err)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	lruCache.now = func() time.Time { return now }
+	assert.NoError(t, lruCache.Set(ctx, "a", []byte("1"), time.Minute))
+
+	// Act
+	now = now.Add(time.Minute)
+	_, err = lruCache.Get(ctx, "a")
+
+	// Assert
+	assert.ErrorIs(t, err, ErrMiss)
+	assert.Equal(t, 0, lruCache.Len())
+}
+
+func TestLRUCache_SetReplacesAndDelete(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	lruCache, err := NewLRUCache(2)
+	assert.NoError(t, err)
+	assert.NoError(t, lruCache.Set(ctx, "a", []byte("1"), time.Minute))
+
+	// Act
+	assert.NoError(t, lruCache.Set(ctx, "a", []byte("2"), time.Minute))
+	replaced, replacedErr := lruCache.Get(ctx, "a")
+	assert.NoError(t, lruCache.Delete(ctx, "a", "missing"))
+	_, deletedErr := lruCache.Get(ctx, "a")
+
+	// Assert
+	assert.NoError(t, replacedErr)
+	assert.Equal(t, []byte("2"), replaced)
+	assert.ErrorIs(t, deletedErr, ErrMiss)
+}
@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestRedisCache runs against an embedded server speaking the Redis protocol, without retries
+// so the tests of a server down fail fast
+func newTestRedisCache(t *testing.T) (*RedisCache, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	redisCache := NewRedisCache(redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1}), "test:")
+	t.Cleanup(func() { _ = redisCache.Close() })
+	return redisCache, server
+}
+
+func TestRedisCache_SetAndGet(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	redisCache, server := newTestRedisCache(t)
+
+	// Act
+	err := redisCache.Set(ctx, "a", []byte("1"), time.Minute)
+	value, getErr := redisCache.Get(ctx, "a")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, getErr)
+	assert.Equal(t, []byte("1"), value)
+	// the key is prefixed and expires with the TTL
+	assert.True(t, server.Exists("test:a"))
+	assert.Equal(t, time.Minute, server.TTL("test:a"))
+}
+
+func TestRedisCache_Miss(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	redisCache, server := newTestRedisCache(t)
+	assert.NoError(t, redisCache.Set(ctx, "expired", []byte("1"), time.Minute))
+
+	// Act
+	server.FastForward(time.Minute)
+	_, expiredErr := redisCache.Get(ctx, "expired")
+	_, missingErr := redisCache.Get(ctx, "missing")
+
+	// Assert
+	assert.ErrorIs(t, expiredErr, ErrMiss)
+	assert.ErrorIs(t, missingErr, ErrMiss)
+}
+
+func TestRedisCache_Delete(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	redisCache, server := newTestRedisCache(t)
+	assert.NoError(t, redisCache.Set(ctx, "a", []byte("1"), time.Minute))
+	assert.NoError(t, redisCache.Set(ctx, "b", []byte("2"), time.Minute))
+
+	// Act
+	err := redisCache.Delete(ctx, "a", "b", "missing")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, server.Exists("test:a"))
+	assert.False(t, server.Exists("test:b"))
+	assert.NoError(t, redisCache.Delete(ctx))
+}
+
+func TestRedisCache_ServerDown(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	redisCache, server := newTestRedisCache(t)
+	server.Close()
+
+	// Act
+	_, err := redisCache.Get(ctx, "a")
+
+	// Assert
+	// a failing server isn't mistaken for a miss
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrMiss)
+	assert.Error(t, redisCache.Ping(ctx))
+}
@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/satryarangga/amartha-loan-engine/metrics"
+	"github.com/satryarangga/amartha-loan-engine/models"
+)
+
+// The cached responses, they name the lookups in the metrics
+const (
+	BorrowerResponses = "borrower_response"
+	LoanResponses     = "loan_response"
+)
+
+// cacheableLoanIncludes are the includes a cached loan response may expand. A response including
+// the borrower isn't cached, the borrower changes without any event of the loan.
+var cacheableLoanIncludes = []models.LoanInclude{models.LoanIncludePayments, models.LoanIncludeSchedules}
+
+// Responses reads the responses through the cache, a response is cached for the TTL unless an
+// event invalidates it before
+type Responses struct {
+	cache Cache
+	ttl   time.Duration
+}
+
+func NewResponses(cache Cache, ttl time.Duration) *Responses {
+	return &Responses{cache: cache, ttl: ttl}
+}
+
+// BorrowerKey is the key of the response of the borrower
+func BorrowerKey(borrowerID string) string {
+	return BorrowerResponses + ":" + borrowerID
+}
+
+// LoanKey is the key of the response of the loan expanded with the includes, false when such a
+// response isn't cached
+func LoanKey(loanID string, includes []models.LoanInclude) (string, bool) {
+	names := make([]string, 0, len(includes))
+	for _, include := range includes {
+		if !slices.Contains(cacheableLoanIncludes, include) {
+			return "", false
+		}
+		names = append(names, string(include))
+	}
+	slices.Sort(names)
+	return LoanResponses + ":" + loanID + ":" + strings.Join(slices.Compact(names), ","), true
+}
+
+// loanKeys are the keys of the responses of the loan with every combination of the includes
+func loanKeys(loanID string) []string {
+	keys := make([]string, 0, 1<<len(cacheableLoanIncludes))
+	for mask := 0; mask < 1<<len(cacheableLoanIncludes); mask++ {
+		var includes []models.LoanInclude
+		for i, include := range cacheableLoanIncludes {
+			if mask&(1<<i) != 0 {
+				includes = append(includes, include)
+			}
+		}
+		key, _ := LoanKey(loanID, includes)
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// GetOrLoad returns the response cached under the key, or loads and caches it. The cache is best
+// effort, when it fails the response is loaded and the failure only shows in the metrics.
+func GetOrLoad[T any](ctx context.Context, r *Responses, name string, key string, load func() (*T, error)) (*T, error) {
+	cached, err := r.cache.Get(ctx, key)
+	switch {
+	case err == nil:
+		var response T
+		if err := json.Unmarshal(cached, &response); err == nil {
+			metrics.RecordCacheRequest(name, "hit")
+			return &response, nil
+		}
+		// an entry written by another version of the response, it's overwritten below
+		metrics.RecordCacheRequest(name, "error")
+	case errors.Is(err, ErrMiss):
+		metrics.RecordCacheRequest(name, "miss")
+	default:
+		metrics.RecordCacheRequest(name, "error")
+	}
+
+	response, err := load()
+	if err != nil {
+		return nil, err
+	}
+	if encoded, err := json.Marshal(response); err == nil {
+		_ = r.cache.Set(ctx, key, encoded, r.ttl)
+	}
+	return response, nil
+}
+
+// Evict removes the responses of the keys
+func (r *Responses) Evict(ctx context.Context, keys ...string) {
+	metrics.RecordCacheInvalidation(r.cache.Delete(ctx, keys...))
+}
+
+// EvictBorrower removes the response of the borrower
+func (r *Responses) EvictBorrower(ctx context.Context, borrowerID string) {
+	r.Evict(ctx, BorrowerKey(borrowerID))
+}
+
+// Publish removes the responses changed by the domain event. The services call it right after
+// committing the events, and it is also one of the publishers of the outbox relay so an entry
+// cached by a read racing with the commit is evicted again.
+func (r *Responses) Publish(ctx context.Context, event models.OutboxEvent) error {
+	// every event referencing a loan or a borrower names them the same way
+	var ids struct {
+		LoanID     string `json:"loan_id"`
+		BorrowerID string `json:"borrower_id"`
+	}
+	if err := json.Unmarshal(event.Payload, &ids); err != nil {
+		return nil
+	}
+
+	var keys []string
+	switch event.EventType {
+	case models.EventTypeLoanCreated, models.EventTypeLoanPaymentPaid, models.EventTypeLoanScheduleOverdue, models.EventTypeLoanFullyPaid:
+		if ids.LoanID != "" {
+			keys = append(keys, loanKeys(ids.LoanID)...)
+		}
+		if ids.BorrowerID != "" {
+			keys = append(keys, BorrowerKey(ids.BorrowerID))
+		}
+	}
+	if len(keys) > 0 {
+		r.Evict(ctx, keys...)
+	}
+	return nil
+}
+
+// Invalidate removes the responses changed by the events
+func (r *Responses) Invalidate(ctx context.Context, outboxEvents []models.OutboxEvent) {
+	for _, event := range outboxEvents {
+		_ = r.Publish(ctx, event)
+	}
+}
@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/satryarangga/amartha-loan-engine/metrics"
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoanKey(t *testing.T) {
+	key, cacheable := LoanKey("loan-id", []models.LoanInclude{models.LoanIncludeSchedules, models.LoanIncludePayments, models.LoanIncludeSchedules})
+	assert.True(t, cacheable)
+	assert.Equal(t, "loan_response:loan-id:payments,schedules", key)
+
+	key, cacheable = LoanKey("loan-id", nil)
+	assert.True(t, cacheable)
+	assert.Equal(t, "loan_response:loan-id:", key)
+
+	_, cacheable = LoanKey("loan-id", []models.LoanInclude{models.LoanIncludeBorrower})
+	assert.False(t, cacheable)
+}
+
+func TestGetOrLoad(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	lruCache, err := NewLRUCache(10)
+	assert.NoError(t, err)
+	responses := NewResponses(lruCache, time.Minute)
+	loads := 0
+	load := func() (*models.BorrowerResponse, error) {
+		loads++
+		return &models.BorrowerResponse{ID: "borrower-id", FirstName: "John"}, nil
+	}
+	hits := testutil.ToFloat64(metrics.CacheRequests.WithLabelValues(BorrowerResponses, "hit"))
+	misses := testutil.ToFloat64(metrics.CacheRequests.WithLabelValues(BorrowerResponses, "miss"))
+
+	// Act
+	first, firstErr := GetOrLoad(ctx, responses, BorrowerResponses, BorrowerKey("borrower-id"), load)
+	second, secondErr := GetOrLoad(ctx, responses, BorrowerResponses, BorrowerKey("borrower-id"), load)
+
+	// Assert
+	assert.NoError(t, errors.Join(firstErr, secondErr))
+	assert.Equal(t, first, second)
+	assert.Equal(t, 1, loads)
+	assert.Equal(t, hits+1, testutil.ToFloat64(metrics.CacheRequests.WithLabelValues(BorrowerResponses, "hit")))
+	assert.Equal(t, misses+1, testutil.ToFloat64(metrics.CacheRequests.WithLabelValues(BorrowerResponses, "miss")))
+}
+
+func TestGetOrLoad_LoadError(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	lruCache, err := NewLRUCache(10)
+	assert.NoError(t, err)
+	responses := NewResponses(lruCache, time.Minute)
+	loadErr := errors.New("borrower not found")
+
+	// Act
+	result, err := GetOrLoad(ctx, responses, BorrowerResponses, BorrowerKey("borrower-id"), func() (*models.BorrowerResponse, error) {
+		return nil, loadErr
+	})
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, loadErr)
+	// errors aren't cached
+	assert.Equal(t, 0, lruCache.Len())
+}
+
+func TestGetOrLoad_CacheDown(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	redisCache, server := newTestRedisCache(t)
+	server.Close()
+	responses := NewResponses(redisCache, time.Minute)
+	failures := testutil.ToFloat64(metrics.CacheRequests.WithLabelValues(LoanResponses, "error"))
+
+	// Act
+	result, err := GetOrLoad(ctx, responses, LoanResponses, "loan_response:loan-id:", func() (*models.LoanResponse, error) {
+		return &models.LoanResponse{ID: "loan-id"}, nil
+	})
+
+	// Assert
+	// the response is still served, from the database
+	assert.NoError(t, err)
+	assert.Equal(t, "loan-id", result.ID)
+	assert.Equal(t, failures+1, testutil.ToFloat64(metrics.CacheRequests.WithLabelValues(LoanResponses, "error")))
+}
+
+func TestResponses_Publish(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	lruCache, err := NewLRUCache(20)
+	assert.NoError(t, err)
+	responses := NewResponses(lruCache, time.Minute)
+	for _, key := range append(loanKeys("loan-id"), BorrowerKey("borrower-id"), BorrowerKey("other-borrower-id")) {
+		assert.NoError(t, lruCache.Set(ctx, key, []byte("{}"), time.Minute))
+	}
+	event := models.OutboxEvent{
+		EventType: models.EventTypeLoanPaymentPaid,
+		Payload:   []byte(`{"loan_payment_id":"payment-id","loan_id":"loan-id","borrower_id":"borrower-id"}`),
+	}
+
+	// Act
+	err = responses.Publish(ctx, event)
+
+	// Assert
+	assert.NoError(t, err)
+	// every include combination of the loan and its borrower are evicted, the other borrower is kept
+	assert.Equal(t, 1, lruCache.Len())
+	_, err = lruCache.Get(ctx, BorrowerKey("other-borrower-id"))
+	assert.NoError(t, err)
+}
+
+func TestResponses_Publish_Overdue(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	lruCache, err := NewLRUCache(20)
+	assert.NoError(t, err)
+	responses := NewResponses(lruCache, time.Minute)
+	assert.NoError(t, lruCache.Set(ctx, BorrowerKey("borrower-id"), []byte("{}"), time.Minute))
+	payload, err := json.Marshal(models.LoanScheduleOverdueEvent{LoanScheduleID: "schedule-id", LoanID: "loan-id", BorrowerID: "borrower-id"})
+	assert.NoError(t, err)
+
+	// Act
+	err = responses.Publish(ctx, models.OutboxEvent{EventType: models.EventTypeLoanScheduleOverdue, Payload: payload})
+
+	// Assert
+	// the borrower is delinquent from now on, its cached response is stale
+	assert.NoError(t, err)
+	_, err = lruCache.Get(ctx, BorrowerKey("borrower-id"))
+	assert.ErrorIs(t, err, ErrMiss)
+}
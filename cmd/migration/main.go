@@ -1,20 +1,353 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+	"time"
 
+	"github.com/pressly/goose/v3"
+	"github.com/satryarangga/amartha-loan-engine/config"
 	migration "github.com/satryarangga/amartha-loan-engine/database/migration"
 	seeder "github.com/satryarangga/amartha-loan-engine/database/seeder"
 )
 
+const (
+	exitOK      = 0
+	exitFailure = 1
+	// exitUsage is returned for a command line that can't be run, like the flag package does
+	exitUsage = 2
+)
+
+const usage = `Usage: migration <command> [arguments]
+
+Commands:
+  migrate up [--dry-run]                 apply every pending migration
+  migrate up-to <version> [--dry-run]    apply the pending migrations up to and including the version
+  migrate down [--dry-run]               roll back the last applied migration
+  migrate down-to <version> [--dry-run]  roll back the migrations newer than the version, 0 rolls back all of them
+  migrate reset [--dry-run]              roll back every migration
+  migrate status                         list the migrations and when they were applied
+  migrate version                        print the version of the database
+  migrate create <name> [--dir <dir>]    write an empty migration, versioned after the last one
+  seed [--borrowers <n>] [--loans-per <n>] [--random-seed <n>]
+                                         upsert the development data, with --borrowers also generate that
+                                         many synthetic borrowers with loans and payments, refused in prod
+  help                                   print this help
+
+Flags:
+  --dry-run  print the migrations the command would apply or roll back, without running them
+  --dir      directory of the migrations to create, default ` + migration.Dir + `
+
+The migrations are embedded in the binary, the database is the one of app.env and the environment.
+
+Exit codes: 0 on success, 1 when the command fails, 2 when the command line is invalid.
+`
+
 func main() {
-	flag.Parse()
-	args := flag.Args()
+	// cancelled on SIGINT / SIGTERM, the migration running is rolled back by its transaction
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// run runs the command line and returns the exit code
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
 
 	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
 	case "migrate":
-		migration.Migrate(args[1:])
+		command, err := parseMigrateCommand(args[1:])
+		if errors.Is(err, flag.ErrHelp) {
+			fmt.Fprint(stdout, usage)
+			return exitOK
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "migration: %v\n\n%s", err, usage)
+			return exitUsage
+		}
+		if err := command.run(ctx, stdout); err != nil {
+			fmt.Fprintf(stderr, "migration %s: %v\n", command.name, err)
+			return exitFailure
+		}
+		return exitOK
 	case "seed":
-		seeder.Seed()
+		options, err := parseSeedOptions(args[1:])
+		if errors.Is(err, flag.ErrHelp) {
+			fmt.Fprint(stdout, usage)
+			return exitOK
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "migration: %v\n\n%s", err, usage)
+			return exitUsage
+		}
+		if err := seed(ctx, options, stdout); err != nil {
+			fmt.Fprintf(stderr, "migration seed: %v\n", err)
+			return exitFailure
+		}
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "migration: unknown command %q\n\n%s", args[0], usage)
+		return exitUsage
+	}
+}
+
+// migrateCommand is a parsed migrate command line
+type migrateCommand struct {
+	name string
+	// version is the target of up-to and down-to
+	version int64
+	// migrationName is the name of the migration to create
+	migrationName string
+	dir           string
+	dryRun        bool
+}
+
+// parseMigrateCommand parses the arguments following migrate, the flags can come before or after
+// the command and its argument
+func parseMigrateCommand(args []string) (migrateCommand, error) {
+	command := migrateCommand{}
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.BoolVar(&command.dryRun, "dry-run", false, "")
+	flags.StringVar(&command.dir, "dir", migration.Dir, "")
+
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return command, err
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+	if len(positional) == 0 {
+		return command, errors.New("missing migrate command")
+	}
+	command.name, positional = positional[0], positional[1:]
+
+	expectedArgs := 0
+	switch command.name {
+	case "up", "down", "reset", "status", "version":
+	case "up-to", "down-to", "create":
+		expectedArgs = 1
+	default:
+		return command, fmt.Errorf("unknown migrate command %q", command.name)
+	}
+	if len(positional) != expectedArgs {
+		return command, fmt.Errorf("migrate %s expects %d argument(s), got %d", command.name, expectedArgs, len(positional))
+	}
+
+	switch command.name {
+	case "up-to", "down-to":
+		version, err := strconv.ParseInt(positional[0], 10, 64)
+		if err != nil || version < 0 || (command.name == "up-to" && version == 0) {
+			return command, fmt.Errorf("invalid version %q", positional[0])
+		}
+		command.version = version
+	case "create":
+		command.migrationName = positional[0]
+	}
+	if command.dryRun && (command.name == "status" || command.name == "version" || command.name == "create") {
+		return command, fmt.Errorf("migrate %s doesn't run any migration, --dry-run doesn't apply", command.name)
+	}
+	return command, nil
+}
+
+func (c migrateCommand) run(ctx context.Context, stdout io.Writer) error {
+	if c.name == "create" {
+		path, err := migration.Create(c.dir, c.migrationName)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "created %s\n", path)
+		return nil
+	}
+
+	conf, err := config.NewConfig()
+	if err != nil {
+		return fmt.Errorf("cannot load config: %w", err)
+	}
+	db, err := config.OpenMigrationDB(conf)
+	if err != nil {
+		return fmt.Errorf("cannot open the database: %w", err)
+	}
+	provider, err := migration.NewProvider(db)
+	if err != nil {
+		_ = db.Close()
+		return err
+	}
+	defer provider.Close()
+
+	switch c.name {
+	case "status":
+		return printStatus(ctx, provider, stdout)
+	case "version":
+		version, err := provider.GetDBVersion(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, version)
+		return nil
+	}
+
+	if c.dryRun {
+		return c.plan(ctx, provider, stdout)
+	}
+
+	var results []*goose.MigrationResult
+	switch c.name {
+	case "up":
+		results, err = provider.Up(ctx)
+	case "up-to":
+		results, err = provider.UpTo(ctx, c.version)
+	case "down":
+		var result *goose.MigrationResult
+		if result, err = provider.Down(ctx); result != nil {
+			results = append(results, result)
+		}
+		if errors.Is(err, goose.ErrNoNextVersion) {
+			err = nil
+		}
+	case "down-to":
+		results, err = provider.DownTo(ctx, c.version)
+	case "reset":
+		results, err = provider.DownTo(ctx, 0)
+	}
+
+	var partialErr *goose.PartialError
+	if errors.As(err, &partialErr) {
+		results = append(partialErr.Applied, partialErr.Failed)
+	}
+	for _, result := range results {
+		fmt.Fprintln(stdout, result)
+	}
+	if err == nil && len(results) == 0 {
+		fmt.Fprintln(stdout, "no migrations to run")
+	}
+	return err
+}
+
+// plan prints the migrations the command would run, from the status of the database
+func (c migrateCommand) plan(ctx context.Context, provider *goose.Provider, stdout io.Writer) error {
+	status, err := provider.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	direction := "up"
+	var sources []*goose.Source
+	switch c.name {
+	case "up":
+		sources, err = migration.PlanUp(status, goose.MaxVersion)
+	case "up-to":
+		sources, err = migration.PlanUp(status, c.version)
+	case "down":
+		direction = "down"
+		if sources = migration.PlanDown(status, 0); len(sources) > 1 {
+			sources = sources[:1]
+		}
+	case "down-to":
+		direction = "down"
+		sources = migration.PlanDown(status, c.version)
+	case "reset":
+		direction = "down"
+		sources = migration.PlanDown(status, 0)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, source := range sources {
+		fmt.Fprintf(stdout, "DRY RUN %-4s %s\n", direction, source.Path)
+	}
+	if len(sources) == 0 {
+		fmt.Fprintln(stdout, "no migrations to run")
+	}
+	return nil
+}
+
+func printStatus(ctx context.Context, provider *goose.Provider, stdout io.Writer) error {
+	status, err := provider.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	table := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "VERSION\tSTATE\tAPPLIED AT\tMIGRATION")
+	for _, migrationStatus := range status {
+		appliedAt := "-"
+		if migrationStatus.State == goose.StateApplied {
+			appliedAt = migrationStatus.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(table, "%d\t%s\t%s\t%s\n", migrationStatus.Source.Version, migrationStatus.State, appliedAt, migrationStatus.Source.Path)
+	}
+	return table.Flush()
+}
+
+// parseSeedOptions parses the arguments following seed
+func parseSeedOptions(args []string) (seeder.GenerateOptions, error) {
+	options := seeder.GenerateOptions{}
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.IntVar(&options.Borrowers, "borrowers", 0, "")
+	flags.IntVar(&options.LoansPerBorrower, "loans-per", 1, "")
+	flags.Int64Var(&options.RandomSeed, "random-seed", 1, "")
+	if err := flags.Parse(args); err != nil {
+		return options, err
+	}
+	if flags.NArg() > 0 {
+		return options, fmt.Errorf("seed expects no argument, got %q", flags.Arg(0))
+	}
+	if options.Borrowers < 0 {
+		return options, fmt.Errorf("invalid number of borrowers %d", options.Borrowers)
+	}
+	if options.LoansPerBorrower < 1 {
+		return options, fmt.Errorf("invalid number of loans per borrower %d", options.LoansPerBorrower)
+	}
+	return options, nil
+}
+
+// seed upserts the fixtures, then generates the synthetic borrowers if any was asked for
+func seed(ctx context.Context, options seeder.GenerateOptions, stdout io.Writer) error {
+	conf, err := config.NewConfig()
+	if err != nil {
+		return fmt.Errorf("cannot load config: %w", err)
+	}
+	if err := seeder.CheckProfile(conf); err != nil {
+		return err
+	}
+	logger := config.NewLogger()
+	if err := logger.SetLevel(conf.LogLevel); err != nil {
+		return err
+	}
+	db, err := config.InitDB(conf, &logger)
+	if err != nil {
+		return fmt.Errorf("cannot open the database: %w", err)
+	}
+
+	s := seeder.NewSeeder(db, stdout)
+	if err := s.SeedFixtures(ctx); err != nil {
+		return err
+	}
+	if options.Borrowers == 0 {
+		return nil
 	}
+	return s.Generate(ctx, options)
 }
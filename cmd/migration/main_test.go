@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	seeder "github.com/satryarangga/amartha-loan-engine/database/seeder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun_Usage(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{name: "no command", args: nil, code: exitUsage},
+		{name: "help", args: []string{"help"}, code: exitOK},
+		{name: "migrate help", args: []string{"migrate", "-h"}, code: exitOK},
+		{name: "unknown command", args: []string{"rollback"}, code: exitUsage},
+		{name: "missing migrate command", args: []string{"migrate"}, code: exitUsage},
+		{name: "unknown migrate command", args: []string{"migrate", "redo"}, code: exitUsage},
+		{name: "missing version", args: []string{"migrate", "up-to"}, code: exitUsage},
+		{name: "invalid version", args: []string{"migrate", "down-to", "latest"}, code: exitUsage},
+		{name: "dry run of status", args: []string{"migrate", "status", "--dry-run"}, code: exitUsage},
+		{name: "unknown flag", args: []string{"migrate", "up", "--force"}, code: exitUsage},
+		{name: "seed argument", args: []string{"seed", "borrowers"}, code: exitUsage},
+		{name: "invalid loans per borrower", args: []string{"seed", "--borrowers", "10", "--loans-per", "0"}, code: exitUsage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var stdout, stderr bytes.Buffer
+
+			// Act
+			code := run(context.Background(), tt.args, &stdout, &stderr)
+
+			// Assert
+			assert.Equal(t, tt.code, code)
+			// the help is printed on stdout when asked for, on stderr along with the error otherwise
+			if code == exitOK {
+				assert.Contains(t, stdout.String(), "Usage: migration")
+			} else {
+				assert.Contains(t, stderr.String(), "Usage: migration")
+			}
+		})
+	}
+}
+
+func TestParseMigrateCommand(t *testing.T) {
+	// Act
+	// the flags can come after the command and its argument
+	command, err := parseMigrateCommand([]string{"up-to", "20240101000012", "--dry-run"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, migrateCommand{name: "up-to", version: 20240101000012, dir: "database/migration/sql", dryRun: true}, command)
+}
+
+func TestParseSeedOptions(t *testing.T) {
+	// Act
+	options, err := parseSeedOptions([]string{"--borrowers", "10000", "--loans-per", "2"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, seeder.GenerateOptions{Borrowers: 10000, LoansPerBorrower: 2, RandomSeed: 1}, options)
+}
+
+func TestRun_Create(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	var stdout, stderr bytes.Buffer
+
+	// Act
+	code := run(context.Background(), []string{"migrate", "create", "--dir", dir, "add_index_to_loans"}, &stdout, &stderr)
+
+	// Assert
+	assert.Equal(t, exitOK, code)
+	path := filepath.Join(dir, "1_add_index_to_loans.sql")
+	assert.Equal(t, "created "+path+"\n", stdout.String())
+	_, err := os.Stat(path)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/satryarangga/amartha-loan-engine/config"
+	"github.com/satryarangga/amartha-loan-engine/helpers"
+	"github.com/satryarangga/amartha-loan-engine/models"
+)
+
+// Issues a HS256 access token signed with JWT_SECRET, for local development only
+func main() {
+	role := flag.String("role", string(models.RoleAdmin), "role of the token (admin, field_officer, finance, borrower, lender, payment_gateway)")
+	borrowerID := flag.String("borrower", "", "borrower ID, required for the borrower role")
+	subject := flag.String("sub", "local-developer", "subject of the token")
+	ttl := flag.Duration("ttl", 24*time.Hour, "lifetime of the token")
+	flag.Parse()
+
+	conf, err := config.NewConfig()
+	if err != nil {
+		log.Fatal("cannot load config:", err)
+	}
+
+	if conf.JWTAlgorithm != helpers.JWTAlgorithmHS256 {
+		log.Fatalf("tokens can only be issued locally with %s, configured algorithm is %s", helpers.JWTAlgorithmHS256, conf.JWTAlgorithm)
+	}
+
+	claims := helpers.AccessTokenClaims{
+		Role:       models.Role(*role),
+		BorrowerID: *borrowerID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   *subject,
+			Issuer:    conf.JWTIssuer,
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(*ttl)),
+		},
+	}
+	if conf.JWTAudience != "" {
+		claims.Audience = jwt.ClaimStrings{conf.JWTAudience}
+	}
+
+	token, err := helpers.SignHS256Token(conf.JWTSecret, claims)
+	if err != nil {
+		log.Fatal("cannot sign token:", err)
+	}
+	fmt.Println(token)
+}
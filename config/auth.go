@@ -0,0 +1,27 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/satryarangga/amartha-loan-engine/helpers"
+)
+
+func NewTokenVerifier(conf ConfigEnv) (*helpers.TokenVerifier, error) {
+	verifierConfig := helpers.TokenVerifierConfig{
+		Algorithm: conf.JWTAlgorithm,
+		Secret:    conf.JWTSecret,
+		Issuer:    conf.JWTIssuer,
+		Audience:  conf.JWTAudience,
+	}
+
+	if conf.JWTAlgorithm == helpers.JWTAlgorithmRS256 {
+		publicKeyPEM, err := os.ReadFile(conf.JWTPublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read JWT public key file: %w", err)
+		}
+		verifierConfig.PublicKeyPEM = publicKeyPEM
+	}
+
+	return helpers.NewTokenVerifier(verifierConfig)
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/satryarangga/amartha-loan-engine/cache"
+)
+
+const (
+	CacheDriverNone   = "none"
+	CacheDriverMemory = "memory"
+	CacheDriverRedis  = "redis"
+)
+
+// NewCache creates the cache of the driver. The memory cache is local to the instance, with several
+// instances only the redis one is evicted everywhere when a response changes.
+func NewCache(ctx context.Context, conf ConfigEnv) (cache.Cache, error) {
+	switch conf.CacheDriver {
+	case CacheDriverNone:
+		return cache.NewNopCache(), nil
+	case CacheDriverMemory:
+		return cache.NewLRUCache(conf.CacheMemoryCapacity)
+	case CacheDriverRedis:
+		redisCache := cache.NewRedisCache(redis.NewClient(&redis.Options{
+			Addr:     conf.CacheRedisAddr,
+			Password: conf.CacheRedisPassword,
+			DB:       conf.CacheRedisDB,
+		}), conf.CacheRedisPrefix)
+		if err := redisCache.Ping(ctx); err != nil {
+			_ = redisCache.Close()
+			return nil, fmt.Errorf("unable to reach redis at %s: %w", conf.CacheRedisAddr, err)
+		}
+		return redisCache, nil
+	default:
+		return nil, fmt.Errorf("unknown cache driver %q", conf.CacheDriver)
+	}
+}
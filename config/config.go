@@ -1,34 +1,315 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/spf13/viper"
 )
 
+const (
+	ProfileDev     = "dev"
+	ProfileStaging = "staging"
+	ProfileProd    = "prod"
+)
+
+// secretFileSuffix points a setting to a file holding its value, e.g. DB_PASSWORD_FILE=/run/secrets/db_password
+const secretFileSuffix = "_FILE"
+
+// ConfigEnv is the whole configuration of the service. The sections are squashed, so every setting
+// keeps its flat environment variable name and is read as conf.DBHost.
 type ConfigEnv struct {
-	DBDriver   string `mapstructure:"DB_DRIVER"`
-	DBHost     string `mapstructure:"DB_HOST"`
-	DBName     string `mapstructure:"DB_NAME"`
-	DBPort     string `mapstructure:"DB_PORT"`
-	DBUser     string `mapstructure:"DB_USER"`
-	DBPassword string `mapstructure:"DB_PASSWORD"`
-	DBSSLMode  string `mapstructure:"DB_SSL_MODE"`
+	// AppEnv is the profile, it picks the app.<profile>.env overrides and the checks of Validate
+	AppEnv string `mapstructure:"APP_ENV"`
+
+	ServerConfig   `mapstructure:",squash"`
+	DatabaseConfig `mapstructure:",squash"`
+	LogConfig      `mapstructure:",squash"`
+	TracingConfig  `mapstructure:",squash"`
+	StorageConfig  `mapstructure:",squash"`
+	CacheConfig    `mapstructure:",squash"`
+	AuthConfig     `mapstructure:",squash"`
+	GatewayConfig  `mapstructure:",squash"`
+	JobsConfig     `mapstructure:",squash"`
+	BusinessConfig `mapstructure:",squash"`
+}
+
+type ServerConfig struct {
+	ServerPort              int           `mapstructure:"SERVER_PORT"`
+	ServerReadHeaderTimeout time.Duration `mapstructure:"SERVER_READ_HEADER_TIMEOUT"`
+	ServerReadTimeout       time.Duration `mapstructure:"SERVER_READ_TIMEOUT"`
+	ServerWriteTimeout      time.Duration `mapstructure:"SERVER_WRITE_TIMEOUT"`
+	ServerIdleTimeout       time.Duration `mapstructure:"SERVER_IDLE_TIMEOUT"`
+	TrustedProxyCIDRs       string        `mapstructure:"TRUSTED_PROXIES"`
+	IdempotencyKeyTTL       time.Duration `mapstructure:"IDEMPOTENCY_KEY_TTL"`
+	ShutdownTimeout         time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"`
+	ShutdownDrainDelay      time.Duration `mapstructure:"SHUTDOWN_DRAIN_DELAY"`
+	// MetricsAddr is the host:port of the internal listener serving /metrics, apart from the public port.
+	// It listens on loopback by default, a scraper on another host needs it widened explicitly.
+	MetricsAddr string `mapstructure:"METRICS_ADDR"`
+}
+
+type DatabaseConfig struct {
+	DBDriver             string        `mapstructure:"DB_DRIVER"`
+	DBHost               string        `mapstructure:"DB_HOST"`
+	DBName               string        `mapstructure:"DB_NAME"`
+	DBPort               int           `mapstructure:"DB_PORT"`
+	DBUser               string        `mapstructure:"DB_USER"`
+	DBPassword           string        `mapstructure:"DB_PASSWORD" secret:"true"`
+	DBSSLMode            string        `mapstructure:"DB_SSL_MODE"`
+	DBSlowQueryThreshold time.Duration `mapstructure:"DB_SLOW_QUERY_THRESHOLD"`
+	DBMaxOpenConns       int           `mapstructure:"DB_MAX_OPEN_CONNS"`
+	DBMaxIdleConns       int           `mapstructure:"DB_MAX_IDLE_CONNS"`
+	DBConnMaxLifetime    time.Duration `mapstructure:"DB_CONN_MAX_LIFETIME"`
+	DBConnMaxIdleTime    time.Duration `mapstructure:"DB_CONN_MAX_IDLE_TIME"`
+	// DBStatementTimeout cancels the queries running longer, 0 disables it
+	DBStatementTimeout time.Duration `mapstructure:"DB_STATEMENT_TIMEOUT"`
+	// DBReplicaHosts are the comma separated host[:port] of the read replicas, using the primary's credentials
+	DBReplicaHosts string `mapstructure:"DB_REPLICA_HOSTS"`
+	// DBMigrateOnBoot applies the pending migrations when the server starts, before it serves any request
+	DBMigrateOnBoot bool `mapstructure:"DB_MIGRATE_ON_BOOT"`
+}
+
+// DBAddress is the address of a database server
+type DBAddress struct {
+	Host string
+	Port int
+}
+
+type LogConfig struct {
+	LogLevel string `mapstructure:"LOG_LEVEL"`
+}
+
+type TracingConfig struct {
+	TracingExporter     string  `mapstructure:"TRACING_EXPORTER"`
+	TracingServiceName  string  `mapstructure:"TRACING_SERVICE_NAME"`
+	TracingSampleRatio  float64 `mapstructure:"TRACING_SAMPLE_RATIO"`
+	TracingOTLPEndpoint string  `mapstructure:"TRACING_OTLP_ENDPOINT"`
+	TracingOTLPInsecure bool    `mapstructure:"TRACING_OTLP_INSECURE"`
+}
+
+type StorageConfig struct {
+	StorageLocalDir string `mapstructure:"STORAGE_LOCAL_DIR"`
+}
+
+// CacheConfig holds the settings of the cache of the hot reads, e.g. the borrower polled by the mobile app
+type CacheConfig struct {
+	CacheDriver string `mapstructure:"CACHE_DRIVER"`
+	// CacheTTL bounds how stale a response can be, e.g. the days past due computed when it was cached
+	CacheTTL            time.Duration `mapstructure:"CACHE_TTL"`
+	CacheMemoryCapacity int           `mapstructure:"CACHE_MEMORY_CAPACITY"`
+	CacheRedisAddr      string        `mapstructure:"CACHE_REDIS_ADDR"`
+	CacheRedisPassword  string        `mapstructure:"CACHE_REDIS_PASSWORD" secret:"true"`
+	CacheRedisDB        int           `mapstructure:"CACHE_REDIS_DB"`
+	CacheRedisPrefix    string        `mapstructure:"CACHE_REDIS_PREFIX"`
+}
+
+type AuthConfig struct {
+	JWTAlgorithm     string `mapstructure:"JWT_ALGORITHM"`
+	JWTSecret        string `mapstructure:"JWT_SECRET" secret:"true"`
+	JWTPublicKeyFile string `mapstructure:"JWT_PUBLIC_KEY_FILE"`
+	JWTIssuer        string `mapstructure:"JWT_ISSUER"`
+	JWTAudience      string `mapstructure:"JWT_AUDIENCE"`
+}
+
+// GatewayConfig holds the settings of the outgoing calls, to the payment gateway and to the partners
+type GatewayConfig struct {
+	PaymentLinkBaseURL string        `mapstructure:"PAYMENT_LINK_BASE_URL"`
+	WebhookTimeout     time.Duration `mapstructure:"WEBHOOK_TIMEOUT"`
+	WebhookMaxAttempts int           `mapstructure:"WEBHOOK_MAX_ATTEMPTS"`
+}
+
+type JobsConfig struct {
+	OutboxPublisher            string        `mapstructure:"OUTBOX_PUBLISHER"`
+	OutboxFilePath             string        `mapstructure:"OUTBOX_FILE_PATH"`
+	OutboxRelayInterval        time.Duration `mapstructure:"OUTBOX_RELAY_INTERVAL"`
+	OutboxBatchSize            int           `mapstructure:"OUTBOX_BATCH_SIZE"`
+	OverdueScanInterval        time.Duration `mapstructure:"OVERDUE_SCAN_INTERVAL"`
+	WebhookDeliveryInterval    time.Duration `mapstructure:"WEBHOOK_DELIVERY_INTERVAL"`
+	IdempotencyCleanupInterval time.Duration `mapstructure:"IDEMPOTENCY_CLEANUP_INTERVAL"`
+	PortfolioMetricsInterval   time.Duration `mapstructure:"PORTFOLIO_METRICS_INTERVAL"`
+}
+
+type BusinessConfig struct {
+	// LoanRepaymentDueWindowDays is how many days ahead a schedule is included in the payment link
+	LoanRepaymentDueWindowDays int `mapstructure:"LOAN_REPAYMENT_DUE_WINDOW_DAYS"`
+	// LoanDelinquencyOverdueSchedules is the number of overdue schedules making a borrower delinquent
+	LoanDelinquencyOverdueSchedules int `mapstructure:"LOAN_DELINQUENCY_OVERDUE_SCHEDULES"`
 }
 
 var Config ConfigEnv
 
-func NewConfig() (config ConfigEnv, err error) {
-	viper.AddConfigPath(".")
-	viper.SetConfigName("app.env")
-	viper.SetConfigType("env")
+var defaults = map[string]interface{}{
+	"APP_ENV":                            ProfileDev,
+	"SERVER_PORT":                        8080,
+	"SERVER_READ_HEADER_TIMEOUT":         "10s",
+	"SERVER_READ_TIMEOUT":                "30s",
+	"SERVER_WRITE_TIMEOUT":               "60s",
+	"SERVER_IDLE_TIMEOUT":                "120s",
+	"IDEMPOTENCY_KEY_TTL":                "24h",
+	"SHUTDOWN_TIMEOUT":                   "30s",
+	"SHUTDOWN_DRAIN_DELAY":               "0s",
+	"METRICS_ADDR":                       "127.0.0.1:9090",
+	"DB_DRIVER":                          "postgres",
+	"DB_PORT":                            5432,
+	"DB_SSL_MODE":                        "disable",
+	"DB_SLOW_QUERY_THRESHOLD":            "200ms",
+	"DB_MAX_OPEN_CONNS":                  25,
+	"DB_MAX_IDLE_CONNS":                  10,
+	"DB_CONN_MAX_LIFETIME":               "30m",
+	"DB_CONN_MAX_IDLE_TIME":              "5m",
+	"DB_STATEMENT_TIMEOUT":               "30s",
+	"DB_MIGRATE_ON_BOOT":                 false,
+	"LOG_LEVEL":                          "info",
+	"TRACING_EXPORTER":                   "none",
+	"TRACING_SERVICE_NAME":               "amartha-loan-engine",
+	"TRACING_SAMPLE_RATIO":               1.0,
+	"TRACING_OTLP_ENDPOINT":              "localhost:4318",
+	"TRACING_OTLP_INSECURE":              true,
+	"STORAGE_LOCAL_DIR":                  "./uploads",
+	"CACHE_DRIVER":                       "memory",
+	"CACHE_TTL":                          "30s",
+	"CACHE_MEMORY_CAPACITY":              10000,
+	"CACHE_REDIS_ADDR":                   "localhost:6379",
+	"CACHE_REDIS_DB":                     0,
+	"CACHE_REDIS_PREFIX":                 "amartha-loan-engine:",
+	"JWT_ALGORITHM":                      "HS256",
+	"PAYMENT_LINK_BASE_URL":              "https://example.com/payment-link",
+	"WEBHOOK_TIMEOUT":                    "10s",
+	"WEBHOOK_MAX_ATTEMPTS":               8,
+	"OUTBOX_PUBLISHER":                   "stdout",
+	"OUTBOX_FILE_PATH":                   "./outbox-events.jsonl",
+	"OUTBOX_RELAY_INTERVAL":              "5s",
+	"OUTBOX_BATCH_SIZE":                  100,
+	"OVERDUE_SCAN_INTERVAL":              "1h",
+	"WEBHOOK_DELIVERY_INTERVAL":          "5s",
+	"IDEMPOTENCY_CLEANUP_INTERVAL":       "1h",
+	"PORTFOLIO_METRICS_INTERVAL":         "1m",
+	"LOAN_REPAYMENT_DUE_WINDOW_DAYS":     3,
+	"LOAN_DELINQUENCY_OVERDUE_SCHEDULES": 2,
+}
+
+// NewConfig loads the configuration from the working directory, see LoadConfig
+func NewConfig() (ConfigEnv, error) {
+	return LoadConfig(".")
+}
+
+// LoadConfig loads and validates the configuration. From the lowest to the highest precedence, a
+// setting is read from the defaults, app.env, app.<APP_ENV>.env, the environment, and the file
+// pointed to by its <KEY>_FILE variable.
+func LoadConfig(dir string) (config ConfigEnv, err error) {
+	v := viper.New()
+	v.SetConfigType("env")
+	for key, value := range defaults {
+		v.SetDefault(key, value)
+	}
+	// AutomaticEnv alone doesn't make Unmarshal see the variables of the keys without a default
+	for _, key := range settingKeys() {
+		if err := v.BindEnv(key); err != nil {
+			return config, err
+		}
+		if err := v.BindEnv(key + secretFileSuffix); err != nil {
+			return config, err
+		}
+	}
+
+	if err := mergeConfigFile(v, filepath.Join(dir, "app.env")); err != nil {
+		return config, err
+	}
+	profile := v.GetString("APP_ENV")
+	if err := mergeConfigFile(v, filepath.Join(dir, "app."+profile+".env")); err != nil {
+		return config, err
+	}
+
+	for _, key := range settingKeys() {
+		path := v.GetString(key + secretFileSuffix)
+		if path == "" {
+			continue
+		}
+		secret, err := os.ReadFile(path)
+		if err != nil {
+			return config, fmt.Errorf("unable to read %s%s: %w", key, secretFileSuffix, err)
+		}
+		v.Set(key, strings.TrimRight(string(secret), "\r\n"))
+	}
+
+	if err := v.Unmarshal(&config); err != nil {
+		return config, fmt.Errorf("unable to decode the configuration: %w", err)
+	}
+	if err := config.Validate(); err != nil {
+		return config, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return config, nil
+}
 
-	viper.AutomaticEnv()
+// mergeConfigFile merges the settings of the file, a missing file is skipped
+func mergeConfigFile(v *viper.Viper, path string) error {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	v.SetConfigFile(path)
+	if err := v.MergeInConfig(); err != nil {
+		return fmt.Errorf("unable to read %s: %w", path, err)
+	}
+	return nil
+}
+
+// settingKeys returns the environment variable names of every setting of ConfigEnv
+func settingKeys() []string {
+	var keys []string
+	walkSettings(reflect.ValueOf(ConfigEnv{}), func(key string, _ reflect.StructField, _ reflect.Value) {
+		keys = append(keys, key)
+	})
+	return keys
+}
 
-	err = viper.ReadInConfig()
-	if err == nil {
-		err = viper.Unmarshal(&config)
-		return
+// walkSettings calls fn with every setting of the struct, going through the squashed sections
+func walkSettings(value reflect.Value, fn func(key string, field reflect.StructField, value reflect.Value)) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		key := field.Tag.Get("mapstructure")
+		if field.Anonymous && strings.HasSuffix(key, ",squash") {
+			walkSettings(value.Field(i), fn)
+			continue
+		}
+		fn(key, field, value.Field(i))
 	}
+}
+
+// DBReplicas returns the read replicas of DB_REPLICA_HOSTS, they default to the port of the primary
+func (c ConfigEnv) DBReplicas() ([]DBAddress, error) {
+	var replicas []DBAddress
+	for _, address := range strings.Split(c.DBReplicaHosts, ",") {
+		if address = strings.TrimSpace(address); address == "" {
+			continue
+		}
+
+		replica := DBAddress{Host: address, Port: c.DBPort}
+		if host, port, err := net.SplitHostPort(address); err == nil {
+			replica.Host = host
+			if replica.Port, err = strconv.Atoi(port); err != nil {
+				return nil, fmt.Errorf("invalid port of replica %q", address)
+			}
+		}
+		replicas = append(replicas, replica)
+	}
+	return replicas, nil
+}
 
-	err = viper.Unmarshal(&config)
-	return
+// TrustedProxies returns the comma separated TRUSTED_PROXIES, nil means no proxy is trusted
+func (c ConfigEnv) TrustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(c.TrustedProxyCIDRs, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
 }
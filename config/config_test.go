@@ -0,0 +1,147 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testAppEnv = `DB_HOST=localhost
+DB_USER=amartha
+DB_PASSWORD=secret
+DB_NAME=amartha
+JWT_SECRET=change-me
+LOG_LEVEL=info
+`
+
+func writeConfigFile(t *testing.T, dir string, name string, content string) string {
+	path := filepath.Join(dir, name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadConfig_Precedence(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	writeConfigFile(t, dir, "app.env", testAppEnv+"APP_ENV=staging\nOUTBOX_BATCH_SIZE=50\n")
+	writeConfigFile(t, dir, "app.staging.env", "LOG_LEVEL=warn\nOUTBOX_BATCH_SIZE=20\n")
+	t.Setenv("OUTBOX_BATCH_SIZE", "10")
+	t.Setenv("DB_PASSWORD_FILE", writeConfigFile(t, dir, "db_password", "from-file\n"))
+
+	// Act
+	conf, err := LoadConfig(dir)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, ProfileStaging, conf.AppEnv)
+	// the profile overrides app.env
+	assert.Equal(t, "warn", conf.LogLevel)
+	// the environment overrides the profile
+	assert.Equal(t, 10, conf.OutboxBatchSize)
+	// the secret file overrides the rest
+	assert.Equal(t, "from-file", conf.DBPassword)
+	// the defaults fill in the rest
+	assert.Equal(t, 8080, conf.ServerPort)
+	assert.Equal(t, 30*time.Second, conf.ShutdownTimeout)
+	assert.Equal(t, "127.0.0.1:9090", conf.MetricsAddr)
+	assert.Equal(t, 2, conf.LoanDelinquencyOverdueSchedules)
+}
+
+func TestLoadConfig_EnvironmentOnly(t *testing.T) {
+	// Arrange
+	// settings without a default are read from the environment even without app.env
+	t.Setenv("DB_HOST", "db.internal")
+	t.Setenv("DB_USER", "amartha")
+	t.Setenv("DB_NAME", "amartha")
+	t.Setenv("JWT_SECRET", "change-me")
+
+	// Act
+	conf, err := LoadConfig(t.TempDir())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "db.internal", conf.DBHost)
+}
+
+func TestLoadConfig_MissingSecretFile(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	writeConfigFile(t, dir, "app.env", testAppEnv)
+	t.Setenv("JWT_SECRET_FILE", filepath.Join(dir, "missing"))
+
+	// Act
+	_, err := LoadConfig(dir)
+
+	// Assert
+	assert.ErrorContains(t, err, "JWT_SECRET_FILE")
+}
+
+func TestLoadConfig_Invalid(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	writeConfigFile(t, dir, "app.env", testAppEnv+"SERVER_PORT=70000\nMETRICS_ADDR=9090\nLOG_LEVEL=verbose\nLOAN_DELINQUENCY_OVERDUE_SCHEDULES=0\nCACHE_DRIVER=memcached\n")
+
+	// Act
+	_, err := LoadConfig(dir)
+
+	// Assert
+	// every invalid setting is reported at once
+	assert.ErrorContains(t, err, "SERVER_PORT")
+	assert.ErrorContains(t, err, "METRICS_ADDR")
+	assert.ErrorContains(t, err, "LOG_LEVEL")
+	assert.ErrorContains(t, err, "LOAN_DELINQUENCY_OVERDUE_SCHEDULES")
+	assert.ErrorContains(t, err, "CACHE_DRIVER")
+}
+
+func TestConfigEnv_Validate_Prod(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	writeConfigFile(t, dir, "app.env", testAppEnv)
+	conf, err := LoadConfig(dir)
+	assert.NoError(t, err)
+	conf.AppEnv = ProfileProd
+
+	// Act
+	err = conf.Validate()
+
+	// Assert
+	assert.ErrorContains(t, err, "DB_SSL_MODE: must not be disable")
+	assert.ErrorContains(t, err, "JWT_SECRET: must be at least 32 bytes")
+}
+
+func TestConfigEnv_Validate_MetricsOnServerPort(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	writeConfigFile(t, dir, "app.env", testAppEnv)
+	conf, err := LoadConfig(dir)
+	assert.NoError(t, err)
+	conf.MetricsAddr = "0.0.0.0:8080"
+
+	// Act
+	err = conf.Validate()
+
+	// Assert
+	assert.ErrorContains(t, err, "METRICS_ADDR: must not use SERVER_PORT")
+}
+
+func TestConfigEnv_Redacted(t *testing.T) {
+	// Arrange
+	conf := ConfigEnv{
+		ServerConfig:   ServerConfig{ServerPort: 8080, ShutdownTimeout: 30 * time.Second},
+		DatabaseConfig: DatabaseConfig{DBHost: "localhost", DBPassword: "secret"},
+	}
+
+	// Act
+	settings := conf.Redacted()
+
+	// Assert
+	assert.Equal(t, 8080, settings["SERVER_PORT"])
+	assert.Equal(t, "30s", settings["SHUTDOWN_TIMEOUT"])
+	assert.Equal(t, "localhost", settings["DB_HOST"])
+	assert.Equal(t, "[redacted]", settings["DB_PASSWORD"])
+	// an unset secret shows it is missing
+	assert.Equal(t, "", settings["JWT_SECRET"])
+}
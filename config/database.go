@@ -1,31 +1,117 @@
 package config
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
-	"log"
 
+	"github.com/satryarangga/amartha-loan-engine/helpers"
+	"github.com/satryarangga/amartha-loan-engine/metrics"
+	"github.com/satryarangga/amartha-loan-engine/tracing"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
 )
 
-func InitDB() (*gorm.DB, error) {
-	config, err := NewConfig()
+func InitDB(config ConfigEnv, logger *AmarthaLogger) (*gorm.DB, error) {
+	// TranslateError turns unique violations into gorm.ErrDuplicatedKey, rendered as 409
+	db, err := gorm.Open(postgres.Open(databaseDSN(config, config.DBHost, config.DBPort)), &gorm.Config{
+		TranslateError: true,
+		Logger:         NewGormLogger(logger, config.DBSlowQueryThreshold),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
 	if err != nil {
-		log.Fatal("cannot load config:", err)
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(config.DBMaxOpenConns)
+	sqlDB.SetMaxIdleConns(config.DBMaxIdleConns)
+	sqlDB.SetConnMaxLifetime(config.DBConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(config.DBConnMaxIdleTime)
+
+	if err := registerReplicas(db, config); err != nil {
+		return nil, err
 	}
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
-		config.DBHost,
+
+	// query timings and connection pool stats are exported on /metrics
+	if err := metrics.RegisterDB(db); err != nil {
+		return nil, err
+	}
+
+	// every query is a span, a child of the request or job that ran it
+	if err := tracing.RegisterDB(db); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// OpenMigrationDB opens a connection to the primary for the migrations. It has no statement timeout,
+// building an index or backfilling a column can take longer than any query of the API.
+func OpenMigrationDB(config ConfigEnv) (*sql.DB, error) {
+	config.DBStatementTimeout = 0
+	return sql.Open("pgx", databaseDSN(config, config.DBHost, config.DBPort))
+}
+
+// databaseDSN returns the DSN of one of the servers, they all share the credentials and settings
+func databaseDSN(config ConfigEnv, host string, port int) string {
+	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s",
+		host,
 		config.DBUser,
 		config.DBPassword,
 		config.DBName,
-		config.DBPort,
+		port,
 		config.DBSSLMode,
 	)
+	// sent as a session parameter, so Postgres cancels any query running longer on the connection
+	if config.DBStatementTimeout > 0 {
+		dsn += fmt.Sprintf(" statement_timeout=%d", config.DBStatementTimeout.Milliseconds())
+	}
+	return dsn
+}
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
-	if err != nil {
-		return nil, err
+// registerReplicas sends the reads made outside of a transaction to the replicas of DB_REPLICA_HOSTS,
+// the writes, the locking reads and the transactions stay on the primary. A record read to be updated
+// must be read locked in the transaction of the update, a replica may still hold an older version of it.
+func registerReplicas(db *gorm.DB, config ConfigEnv) error {
+	replicas, err := config.DBReplicas()
+	if err != nil || len(replicas) == 0 {
+		return err
 	}
 
-	return db, nil
+	dialectors := make([]gorm.Dialector, 0, len(replicas))
+	for _, replica := range replicas {
+		dialectors = append(dialectors, postgres.Open(databaseDSN(config, replica.Host, replica.Port)))
+	}
+	resolver := dbresolver.Register(dbresolver.Config{
+		Replicas: dialectors,
+		Policy:   dbresolver.RandomPolicy{},
+	}).
+		SetMaxOpenConns(config.DBMaxOpenConns).
+		SetMaxIdleConns(config.DBMaxIdleConns).
+		SetConnMaxLifetime(config.DBConnMaxLifetime).
+		SetConnMaxIdleTime(config.DBConnMaxIdleTime)
+	if err := db.Use(resolver); err != nil {
+		return err
+	}
+
+	// registered after the resolver, so it runs first and marks the statement before the connection is picked
+	callbacks := db.Callback()
+	return errors.Join(
+		callbacks.Query().Before("*").Register("primary_reads:query", routeReadToPrimary),
+		callbacks.Row().Before("*").Register("primary_reads:row", routeReadToPrimary),
+		callbacks.Raw().Before("*").Register("primary_reads:raw", routeReadToPrimary),
+	)
+}
+
+// routeReadToPrimary keeps the reads of a context made with helpers.WithPrimaryReads on the primary
+func routeReadToPrimary(db *gorm.DB) {
+	if db.Statement.Context != nil && helpers.ReadsFromPrimary(db.Statement.Context) {
+		dbresolver.Write.ModifyStatement(db.Statement)
+	}
 }
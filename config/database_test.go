@@ -0,0 +1,68 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/satryarangga/amartha-loan-engine/helpers"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestDatabaseDSN_StatementTimeout(t *testing.T) {
+	// Arrange
+	conf := ConfigEnv{DatabaseConfig: DatabaseConfig{DBUser: "amartha", DBName: "amartha", DBSSLMode: "disable", DBStatementTimeout: 5 * time.Second}}
+
+	// Act
+	dsn := databaseDSN(conf, "replica-1", 5433)
+
+	// Assert
+	assert.Equal(t, "host=replica-1 user=amartha password= dbname=amartha port=5433 sslmode=disable statement_timeout=5000", dsn)
+}
+
+func TestConfigEnv_DBReplicas(t *testing.T) {
+	// Arrange
+	conf := ConfigEnv{DatabaseConfig: DatabaseConfig{DBPort: 5432, DBReplicaHosts: "replica-1, replica-2:5433,"}}
+
+	// Act
+	replicas, err := conf.DBReplicas()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []DBAddress{{Host: "replica-1", Port: 5432}, {Host: "replica-2", Port: 5433}}, replicas)
+}
+
+func TestRegisterReplicas_RoutesReads(t *testing.T) {
+	// Arrange
+	// dry run, the statements are built and routed but nothing is sent to a server
+	db, err := gorm.Open(postgres.Open("host=localhost user=amartha dbname=amartha"), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	assert.NoError(t, err)
+	primary, err := db.DB()
+	assert.NoError(t, err)
+	conf := ConfigEnv{DatabaseConfig: DatabaseConfig{
+		DBHost:            "localhost",
+		DBPort:            5432,
+		DBReplicaHosts:    "localhost",
+		DBMaxOpenConns:    2,
+		DBConnMaxLifetime: time.Minute,
+		DBConnMaxIdleTime: time.Minute,
+	}}
+	assert.NoError(t, registerReplicas(db, conf))
+
+	var fromPrimary []bool
+	assert.NoError(t, db.Callback().Query().After("gorm:query").Register("test:connection", func(db *gorm.DB) {
+		connection, _ := db.Statement.ConnPool.(*sql.DB)
+		fromPrimary = append(fromPrimary, connection == primary)
+	}))
+	var rows []map[string]interface{}
+
+	// Act
+	db.WithContext(context.Background()).Table("loans").Find(&rows)
+	db.WithContext(helpers.WithPrimaryReads(context.Background())).Table("loans").Find(&rows)
+
+	// Assert
+	assert.Equal(t, []bool{false, true}, fromPrimary)
+}
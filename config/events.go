@@ -0,0 +1,30 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/satryarangga/amartha-loan-engine/events"
+)
+
+const (
+	OutboxPublisherMemory = "memory"
+	OutboxPublisherStdout = "stdout"
+	OutboxPublisherFile   = "file"
+)
+
+func NewEventPublisher(conf ConfigEnv) (events.Publisher, error) {
+	switch conf.OutboxPublisher {
+	case OutboxPublisherMemory:
+		return events.NewMemoryPublisher(), nil
+	case OutboxPublisherStdout:
+		return events.NewStdoutPublisher(), nil
+	case OutboxPublisherFile:
+		publisher, err := events.NewFilePublisher(conf.OutboxFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to open outbox file: %w", err)
+		}
+		return publisher, nil
+	default:
+		return nil, fmt.Errorf("unknown outbox publisher %q", conf.OutboxPublisher)
+	}
+}
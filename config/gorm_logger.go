@@ -0,0 +1,74 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// GormLogger sends the GORM logs to the application logger, so the queries carry the request ID of
+// the request running them. Every query is logged at debug level with its duration, slow queries
+// at warn level and failed queries at error level.
+type GormLogger struct {
+	logger             *AmarthaLogger
+	slowQueryThreshold time.Duration
+	level              gormlogger.LogLevel
+}
+
+func NewGormLogger(logger *AmarthaLogger, slowQueryThreshold time.Duration) *GormLogger {
+	return &GormLogger{
+		logger:             logger,
+		slowQueryThreshold: slowQueryThreshold,
+		level:              gormlogger.Info,
+	}
+}
+
+func (l *GormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	copied := *l
+	copied.level = level
+	return &copied
+}
+
+func (l *GormLogger) Info(ctx context.Context, format string, v ...interface{}) {
+	if l.level >= gormlogger.Info {
+		l.logger.Infof(ctx, format, v...)
+	}
+}
+
+func (l *GormLogger) Warn(ctx context.Context, format string, v ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		l.logger.Warnf(ctx, format, v...)
+	}
+}
+
+func (l *GormLogger) Error(ctx context.Context, format string, v ...interface{}) {
+	if l.level >= gormlogger.Error {
+		l.logger.Errorf(ctx, format, v...)
+	}
+}
+
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	duration := time.Since(begin)
+	logger := l.logger.ForContext(ctx)
+	switch {
+	// a missing row is an expected outcome, the caller decides whether it is an error
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= gormlogger.Error:
+		sql, rows := fc()
+		logger.Error().Err(err).Str("sql", sql).Int64("rows", rows).Dur("duration_ms", duration).Msg("database query failed")
+	case l.slowQueryThreshold > 0 && duration > l.slowQueryThreshold && l.level >= gormlogger.Warn:
+		sql, rows := fc()
+		logger.Warn().Str("sql", sql).Int64("rows", rows).Dur("duration_ms", duration).Msg("slow database query")
+	case logger.GetLevel() <= zerolog.DebugLevel && l.level >= gormlogger.Info:
+		// the SQL is only built when debug logs are enabled
+		sql, rows := fc()
+		logger.Debug().Str("sql", sql).Int64("rows", rows).Dur("duration_ms", duration).Msg("database query")
+	}
+}
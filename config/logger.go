@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/rs/zerolog"
+	"github.com/satryarangga/amartha-loan-engine/helpers"
 )
 
 var Logger AmarthaLogger
@@ -14,20 +15,63 @@ type AmarthaLogger struct {
 }
 
 func NewLogger() AmarthaLogger {
-	l := zerolog.New(os.Stdout)
+	l := zerolog.New(os.Stdout).With().Timestamp().Logger()
 	return AmarthaLogger{
 		Logger: l,
 	}
 }
 
+// SetLevel changes the minimum level of the logger, e.g. debug to see the database queries
+func (l *AmarthaLogger) SetLevel(level string) error {
+	parsed, err := zerolog.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	l.Logger = l.Logger.Level(parsed)
+	return nil
+}
+
+// ForContext returns the request-scoped logger put in the context by the request logger middleware,
+// or the logger itself outside of a request, along with the request ID and the user of the context
+func (l *AmarthaLogger) ForContext(ctx context.Context) *zerolog.Logger {
+	logger := l.Logger
+	ctxLogger := zerolog.Ctx(ctx)
+	isRequestScoped := ctxLogger.GetLevel() != zerolog.Disabled
+	if isRequestScoped {
+		logger = *ctxLogger
+	}
+
+	fields := logger.With()
+	// the request-scoped logger already has the request ID
+	if requestID := helpers.RequestIDFromContext(ctx); requestID != "" && !isRequestScoped {
+		fields = fields.Str("request_id", requestID)
+	}
+	// the user is only known once the request is authenticated, after the logger was created
+	if principal, ok := helpers.PrincipalFromContext(ctx); ok {
+		fields = fields.Str("user", principal.Subject).Str("role", string(principal.Role))
+	}
+
+	logger = fields.Logger()
+	return &logger
+}
+
 func (l *AmarthaLogger) Errorf(ctx context.Context, format string, v ...interface{}) {
-	l.Error().Msgf(format, v...)
+	l.ForContext(ctx).Error().Msgf(format, v...)
 }
 
 func (l *AmarthaLogger) Infof(ctx context.Context, format string, v ...interface{}) {
-	l.Info().Msgf(format, v...)
+	l.ForContext(ctx).Info().Msgf(format, v...)
 }
 
 func (l *AmarthaLogger) Warnf(ctx context.Context, format string, v ...interface{}) {
-	l.Warn().Msgf(format, v...)
+	l.ForContext(ctx).Warn().Msgf(format, v...)
+}
+
+func (l *AmarthaLogger) Debugf(ctx context.Context, format string, v ...interface{}) {
+	l.ForContext(ctx).Debug().Msgf(format, v...)
+}
+
+// Fatalf logs the message then exits, for errors preventing the application from starting
+func (l *AmarthaLogger) Fatalf(ctx context.Context, format string, v ...interface{}) {
+	l.ForContext(ctx).Fatal().Msgf(format, v...)
 }
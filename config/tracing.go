@@ -0,0 +1,77 @@
+package config
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+const (
+	TracingExporterNone   = "none"
+	TracingExporterStdout = "stdout"
+	TracingExporterOTLP   = "otlp"
+)
+
+// ShutdownFunc flushes the spans still buffered and stops the exporter
+type ShutdownFunc func(ctx context.Context) error
+
+// NewTracerProvider sets up the global tracer provider and the W3C trace context propagation. With
+// the none exporter spans are still created, so trace IDs are logged, but they are not exported.
+func NewTracerProvider(ctx context.Context, conf ConfigEnv) (ShutdownFunc, error) {
+	var exporter sdktrace.SpanExporter
+	switch conf.TracingExporter {
+	case TracingExporterNone:
+	case TracingExporterStdout:
+		stdoutExporter, err := stdouttrace.New()
+		if err != nil {
+			return nil, fmt.Errorf("unable to create stdout exporter: %w", err)
+		}
+		exporter = stdoutExporter
+	case TracingExporterOTLP:
+		options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(conf.TracingOTLPEndpoint)}
+		if conf.TracingOTLPInsecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		otlpExporter, err := otlptracehttp.New(ctx, options...)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create OTLP exporter: %w", err)
+		}
+		exporter = otlpExporter
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", conf.TracingExporter)
+	}
+
+	if conf.TracingSampleRatio < 0 || conf.TracingSampleRatio > 1 {
+		return nil, fmt.Errorf("tracing sample ratio must be between 0 and 1, got %v", conf.TracingSampleRatio)
+	}
+
+	serviceResource, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(conf.TracingServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("unable to describe the service: %w", err)
+	}
+
+	options := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(serviceResource),
+		// the sampling decision of the caller is kept, so a trace is never half exported
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(conf.TracingSampleRatio))),
+	}
+	if exporter != nil {
+		options = append(options, sdktrace.WithBatcher(exporter))
+	}
+	provider := sdktrace.NewTracerProvider(options...)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider.Shutdown, nil
+}
@@ -0,0 +1,215 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/satryarangga/amartha-loan-engine/helpers"
+)
+
+// minProdJWTSecretLength is the HS256 secret length required in production, 256 bits
+const minProdJWTSecretLength = 32
+
+const redactedValue = "[redacted]"
+
+var dbSSLModes = map[string]bool{
+	"disable": true, "allow": true, "prefer": true, "require": true, "verify-ca": true, "verify-full": true,
+}
+
+// Validate checks every setting and returns all the invalid ones at once, so a broken deployment
+// is fixed in one go
+func (c ConfigEnv) Validate() error {
+	var errs []error
+	invalid := func(key string, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+	positive := func(key string, duration time.Duration) {
+		if duration <= 0 {
+			invalid(key, "must be a positive duration, got %s", duration)
+		}
+	}
+
+	switch c.AppEnv {
+	case ProfileDev, ProfileStaging, ProfileProd:
+	default:
+		invalid("APP_ENV", "must be one of %s, %s or %s, got %q", ProfileDev, ProfileStaging, ProfileProd, c.AppEnv)
+	}
+
+	// Server
+	if c.ServerPort < 1 || c.ServerPort > 65535 {
+		invalid("SERVER_PORT", "must be between 1 and 65535, got %d", c.ServerPort)
+	}
+	positive("SERVER_READ_HEADER_TIMEOUT", c.ServerReadHeaderTimeout)
+	positive("SERVER_READ_TIMEOUT", c.ServerReadTimeout)
+	positive("SERVER_WRITE_TIMEOUT", c.ServerWriteTimeout)
+	positive("SERVER_IDLE_TIMEOUT", c.ServerIdleTimeout)
+	for _, proxy := range c.TrustedProxies() {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			invalid("TRUSTED_PROXIES", "%q is neither an IP nor a CIDR", proxy)
+		}
+	}
+	positive("IDEMPOTENCY_KEY_TTL", c.IdempotencyKeyTTL)
+	positive("SHUTDOWN_TIMEOUT", c.ShutdownTimeout)
+	if c.ShutdownDrainDelay < 0 || c.ShutdownDrainDelay >= c.ShutdownTimeout {
+		invalid("SHUTDOWN_DRAIN_DELAY", "must be at least 0 and shorter than SHUTDOWN_TIMEOUT, got %s", c.ShutdownDrainDelay)
+	}
+	if _, port, err := net.SplitHostPort(c.MetricsAddr); err != nil {
+		invalid("METRICS_ADDR", "must be a host:port, got %q", c.MetricsAddr)
+	} else if metricsPort, err := strconv.Atoi(port); err != nil || metricsPort < 1 || metricsPort > 65535 {
+		invalid("METRICS_ADDR", "must have a port between 1 and 65535, got %q", c.MetricsAddr)
+	} else if metricsPort == c.ServerPort {
+		invalid("METRICS_ADDR", "must not use SERVER_PORT, the metrics must not be served on the public port")
+	}
+
+	// Database
+	if c.DBHost == "" {
+		invalid("DB_HOST", "is required")
+	}
+	if c.DBName == "" {
+		invalid("DB_NAME", "is required")
+	}
+	if c.DBUser == "" {
+		invalid("DB_USER", "is required")
+	}
+	if c.DBPort < 1 || c.DBPort > 65535 {
+		invalid("DB_PORT", "must be between 1 and 65535, got %d", c.DBPort)
+	}
+	if !dbSSLModes[c.DBSSLMode] {
+		invalid("DB_SSL_MODE", "unknown mode %q", c.DBSSLMode)
+	}
+	positive("DB_SLOW_QUERY_THRESHOLD", c.DBSlowQueryThreshold)
+	if c.DBMaxOpenConns < 1 {
+		invalid("DB_MAX_OPEN_CONNS", "must be at least 1, got %d", c.DBMaxOpenConns)
+	}
+	if c.DBMaxIdleConns < 0 || c.DBMaxIdleConns > c.DBMaxOpenConns {
+		invalid("DB_MAX_IDLE_CONNS", "must be between 0 and DB_MAX_OPEN_CONNS, got %d", c.DBMaxIdleConns)
+	}
+	positive("DB_CONN_MAX_LIFETIME", c.DBConnMaxLifetime)
+	positive("DB_CONN_MAX_IDLE_TIME", c.DBConnMaxIdleTime)
+	if c.DBStatementTimeout < 0 {
+		invalid("DB_STATEMENT_TIMEOUT", "must be at least 0, got %s", c.DBStatementTimeout)
+	}
+	if _, err := c.DBReplicas(); err != nil {
+		invalid("DB_REPLICA_HOSTS", "%v", err)
+	}
+
+	// Logging and tracing
+	if _, err := zerolog.ParseLevel(c.LogLevel); err != nil {
+		invalid("LOG_LEVEL", "unknown level %q", c.LogLevel)
+	}
+	switch c.TracingExporter {
+	case TracingExporterNone, TracingExporterStdout, TracingExporterOTLP:
+	default:
+		invalid("TRACING_EXPORTER", "unknown exporter %q", c.TracingExporter)
+	}
+	if c.TracingSampleRatio < 0 || c.TracingSampleRatio > 1 {
+		invalid("TRACING_SAMPLE_RATIO", "must be between 0 and 1, got %v", c.TracingSampleRatio)
+	}
+
+	// Cache
+	switch c.CacheDriver {
+	case CacheDriverNone:
+	case CacheDriverMemory:
+		if c.CacheMemoryCapacity < 1 {
+			invalid("CACHE_MEMORY_CAPACITY", "must be at least 1, got %d", c.CacheMemoryCapacity)
+		}
+	case CacheDriverRedis:
+		if c.CacheRedisAddr == "" {
+			invalid("CACHE_REDIS_ADDR", "is required with %s", CacheDriverRedis)
+		}
+		if c.CacheRedisDB < 0 {
+			invalid("CACHE_REDIS_DB", "must be at least 0, got %d", c.CacheRedisDB)
+		}
+	default:
+		invalid("CACHE_DRIVER", "must be one of %s, %s or %s, got %q", CacheDriverNone, CacheDriverMemory, CacheDriverRedis, c.CacheDriver)
+	}
+	positive("CACHE_TTL", c.CacheTTL)
+
+	// Auth
+	switch c.JWTAlgorithm {
+	case helpers.JWTAlgorithmHS256:
+		if c.JWTSecret == "" {
+			invalid("JWT_SECRET", "is required with %s", helpers.JWTAlgorithmHS256)
+		}
+	case helpers.JWTAlgorithmRS256:
+		if c.JWTPublicKeyFile == "" {
+			invalid("JWT_PUBLIC_KEY_FILE", "is required with %s", helpers.JWTAlgorithmRS256)
+		}
+	default:
+		invalid("JWT_ALGORITHM", "must be %s or %s, got %q", helpers.JWTAlgorithmHS256, helpers.JWTAlgorithmRS256, c.JWTAlgorithm)
+	}
+
+	// Gateways
+	if link, err := url.Parse(c.PaymentLinkBaseURL); err != nil || !link.IsAbs() {
+		invalid("PAYMENT_LINK_BASE_URL", "must be an absolute URL, got %q", c.PaymentLinkBaseURL)
+	}
+	positive("WEBHOOK_TIMEOUT", c.WebhookTimeout)
+	if c.WebhookMaxAttempts < 1 {
+		invalid("WEBHOOK_MAX_ATTEMPTS", "must be at least 1, got %d", c.WebhookMaxAttempts)
+	}
+
+	// Jobs
+	switch c.OutboxPublisher {
+	case OutboxPublisherMemory, OutboxPublisherStdout, OutboxPublisherFile:
+	default:
+		invalid("OUTBOX_PUBLISHER", "unknown publisher %q", c.OutboxPublisher)
+	}
+	if c.OutboxBatchSize < 1 {
+		invalid("OUTBOX_BATCH_SIZE", "must be at least 1, got %d", c.OutboxBatchSize)
+	}
+	positive("OUTBOX_RELAY_INTERVAL", c.OutboxRelayInterval)
+	positive("OVERDUE_SCAN_INTERVAL", c.OverdueScanInterval)
+	positive("WEBHOOK_DELIVERY_INTERVAL", c.WebhookDeliveryInterval)
+	positive("IDEMPOTENCY_CLEANUP_INTERVAL", c.IdempotencyCleanupInterval)
+	positive("PORTFOLIO_METRICS_INTERVAL", c.PortfolioMetricsInterval)
+
+	// Business rules
+	if c.LoanRepaymentDueWindowDays < 0 {
+		invalid("LOAN_REPAYMENT_DUE_WINDOW_DAYS", "must be at least 0, got %d", c.LoanRepaymentDueWindowDays)
+	}
+	if c.LoanDelinquencyOverdueSchedules < 1 {
+		invalid("LOAN_DELINQUENCY_OVERDUE_SCHEDULES", "must be at least 1, got %d", c.LoanDelinquencyOverdueSchedules)
+	}
+
+	// production can't run with the settings only meant for a laptop
+	if c.AppEnv == ProfileProd {
+		if c.DBSSLMode == "disable" {
+			invalid("DB_SSL_MODE", "must not be disable in %s", ProfileProd)
+		}
+		if c.OutboxPublisher == OutboxPublisherMemory {
+			invalid("OUTBOX_PUBLISHER", "must not be %s in %s, the events would be lost", OutboxPublisherMemory, ProfileProd)
+		}
+		if c.JWTAlgorithm == helpers.JWTAlgorithmHS256 && c.JWTSecret != "" && len(c.JWTSecret) < minProdJWTSecretLength {
+			invalid("JWT_SECRET", "must be at least %d bytes long in %s", minProdJWTSecretLength, ProfileProd)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Redacted returns every setting by its environment variable name, with the secrets masked, so the
+// effective configuration of an instance can be looked at
+func (c ConfigEnv) Redacted() map[string]interface{} {
+	settings := map[string]interface{}{}
+	walkSettings(reflect.ValueOf(c), func(key string, field reflect.StructField, value reflect.Value) {
+		switch {
+		case field.Tag.Get("secret") == "true":
+			if !value.IsZero() {
+				settings[key] = redactedValue
+			} else {
+				settings[key] = ""
+			}
+		case field.Type == reflect.TypeOf(time.Duration(0)):
+			settings[key] = value.Interface().(time.Duration).String()
+		default:
+			settings[key] = value.Interface()
+		}
+	})
+	return settings
+}
@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/satryarangga/amartha-loan-engine/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminController struct {
+	conf config.ConfigEnv
+}
+
+func NewAdminController(conf config.ConfigEnv) *AdminController {
+	return &AdminController{
+		conf: conf,
+	}
+}
+
+// GetConfig godoc
+// @Summary Get the effective configuration
+// @Description Get every setting the instance runs with by its environment variable name, the secrets are redacted
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Success"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/config [get]
+func (c *AdminController) GetConfig(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, c.conf.Redacted())
+}
@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/satryarangga/amartha-loan-engine/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyController struct {
+	apiKeyService *services.APIKeyServiceImpl
+}
+
+func NewAPIKeyController(apiKeyService *services.APIKeyServiceImpl) *APIKeyController {
+	return &APIKeyController{
+		apiKeyService: apiKeyService,
+	}
+}
+
+// ListAPIKeys godoc
+// @Summary List API keys
+// @Description List the API keys of server-to-server clients, without the keys themselves
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.APIKey "Success"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api-keys [get]
+func (c *APIKeyController) ListAPIKeys(ctx *gin.Context) {
+	apiKeys, err := c.apiKeyService.ListAPIKeys(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data": apiKeys,
+	})
+}
+
+// CreateAPIKey godoc
+// @Summary Create API key
+// @Description Create an API key scoped to permissions and optionally to IP ranges. The key is only returned once. The Idempotency-Key header is ignored, the response holding the key is never stored.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param apiKey body models.APIKeyRequest true "API key"
+// @Success 201 {object} models.APIKeySecretResponse "Created"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api-keys [post]
+func (c *APIKeyController) CreateAPIKey(ctx *gin.Context) {
+	var request models.APIKeyRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		_ = ctx.Error(invalidRequestBody(err))
+		return
+	}
+
+	apiKey, err := c.apiKeyService.CreateAPIKey(ctx, request)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"data":    apiKey,
+		"message": "API key created successfully, store the key now as it can't be retrieved again",
+	})
+}
+
+// RotateAPIKey godoc
+// @Summary Rotate API key
+// @Description Replace the key of an API key, the previous key stops working right away. The new key is only returned once. The Idempotency-Key header is ignored, the response holding the key is never stored.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} models.APIKeySecretResponse "Success"
+// @Failure 404 {object} models.ErrorResponse "Not Found"
+// @Failure 422 {object} models.ErrorResponse "Unprocessable Entity"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api-keys/{id}/rotate [post]
+func (c *APIKeyController) RotateAPIKey(ctx *gin.Context) {
+	apiKey, err := c.apiKeyService.RotateAPIKey(ctx, ctx.Param("id"))
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":    apiKey,
+		"message": "API key rotated successfully, store the key now as it can't be retrieved again",
+	})
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke API key
+// @Description Revoke an API key, it can't be used nor rotated anymore
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} models.APIKey "Success"
+// @Failure 404 {object} models.ErrorResponse "Not Found"
+// @Failure 422 {object} models.ErrorResponse "Unprocessable Entity"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api-keys/{id} [delete]
+func (c *APIKeyController) RevokeAPIKey(ctx *gin.Context) {
+	apiKey, err := c.apiKeyService.RevokeAPIKey(ctx, ctx.Param("id"))
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":    apiKey,
+		"message": "API key revoked successfully",
+	})
+}
@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/satryarangga/amartha-loan-engine/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditLogController struct {
+	auditLogService *services.AuditLogServiceImpl
+}
+
+func NewAuditLogController(auditLogService *services.AuditLogServiceImpl) *AuditLogController {
+	return &AuditLogController{
+		auditLogService: auditLogService,
+	}
+}
+
+// ListAuditLogs godoc
+// @Summary List audit logs
+// @Description List the audit trail of every change, newest first, with filters and pagination
+// @Tags audit-logs
+// @Accept json
+// @Produce json
+// @Param entity_type query string false "Filter by entity type (table name, e.g. loans)"
+// @Param entity_id query string false "Filter by entity ID"
+// @Param actor query string false "Filter by actor"
+// @Param action query string false "Filter by action (create, update, delete)"
+// @Param request_id query string false "Filter by request ID"
+// @Param from query string false "Changes made from this date (YYYY-MM-DD)"
+// @Param to query string false "Changes made until this date (YYYY-MM-DD)"
+// @Param page query int false "Page number, starts from 1"
+// @Param limit query int false "Number of entries per page (max 100)"
+// @Success 200 {object} models.AuditLogListResponse "Success"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /audit-logs [get]
+func (c *AuditLogController) ListAuditLogs(ctx *gin.Context) {
+	var request models.AuditLogListRequest
+	if err := ctx.ShouldBindQuery(&request); err != nil {
+		_ = ctx.Error(invalidQueryParameters(err))
+		return
+	}
+
+	auditLogs, err := c.auditLogService.ListAuditLogs(ctx, request)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, auditLogs)
+}
@@ -29,22 +29,20 @@ func NewBorrowerController(borrowerService *services.BorrowerServiceImpl) *Borro
 // @Success 200 {object} models.Borrower "Success"
 // @Failure 400 {object} models.ErrorResponse "Bad Request"
 // @Failure 404 {object} models.ErrorResponse "Not Found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /borrowers/{id} [get]
 func (c *BorrowerController) GetBorrowerByID(ctx *gin.Context) {
 	id := ctx.Param("id")
 	if id == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": "Borrower ID is required",
-		})
+		_ = ctx.Error(services.NewValidationError("borrower_id_required", "Borrower ID is required"))
 		return
 	}
 
 	borrower, err := c.borrowerService.GetBorrowerByID(ctx, id)
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{
-			"error":   "Borrower not found",
-			"details": err.Error(),
-		})
+		_ = ctx.Error(err)
 		return
 	}
 
@@ -60,24 +58,23 @@ func (c *BorrowerController) GetBorrowerByID(ctx *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param borrower body models.BorrowerRequest true "Borrower object"
+// @Param Idempotency-Key header string false "Unique key making retries safe, the response of the first request is replayed to the retries"
 // @Success 201 {object} models.Borrower "Created"
 // @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 409 {object} models.ErrorResponse "Conflict"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /borrowers [post]
 func (c *BorrowerController) CreateBorrower(ctx *gin.Context) {
 	var borrower models.Borrower
 	if err := ctx.ShouldBindJSON(&borrower); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request body",
-			"details": err.Error(),
-		})
+		_ = ctx.Error(invalidRequestBody(err))
 		return
 	}
 
 	if err := c.borrowerService.CreateBorrower(ctx, &borrower); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Failed to create borrower",
-			"details": err.Error(),
-		})
+		_ = ctx.Error(err)
 		return
 	}
 
@@ -86,3 +83,99 @@ func (c *BorrowerController) CreateBorrower(ctx *gin.Context) {
 		"message": "Borrower created successfully",
 	})
 }
+
+// ListBorrowers godoc
+// @Summary List borrowers
+// @Description List borrowers with keyword search, filters, sorting and pagination
+// @Tags borrowers
+// @Accept json
+// @Produce json
+// @Param search query string false "Keyword searched over first name, last name and phone number"
+// @Param search_mode query string false "How the keyword is matched (contains or fulltext), defaults to contains"
+// @Param kyc_status query string false "Filter by KYC status (unverified, pending, verified, rejected)"
+// @Param sort_by query string false "Sort field (first_name, last_name, phone_number, created_at)"
+// @Param sort_direction query string false "Sort direction (asc or desc)"
+// @Param page query int false "Page number, starts from 1"
+// @Param limit query int false "Number of items per page (max 100)"
+// @Success 200 {object} models.BorrowerListResponse "Success"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /borrowers [get]
+func (c *BorrowerController) ListBorrowers(ctx *gin.Context) {
+	var request models.BorrowerListRequest
+	if err := ctx.ShouldBindQuery(&request); err != nil {
+		_ = ctx.Error(invalidQueryParameters(err))
+		return
+	}
+
+	borrowers, err := c.borrowerService.ListBorrowers(ctx, request)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, borrowers)
+}
+
+// UpdateBorrower godoc
+// @Summary Update a borrower
+// @Description Partially update the information of a borrower
+// @Tags borrowers
+// @Accept json
+// @Produce json
+// @Param id path string true "Borrower ID"
+// @Param borrower body models.BorrowerUpdateRequest true "Fields to update"
+// @Success 200 {object} models.Borrower "Success"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 404 {object} models.ErrorResponse "Not Found"
+// @Failure 409 {object} models.ErrorResponse "Conflict"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /borrowers/{id} [patch]
+func (c *BorrowerController) UpdateBorrower(ctx *gin.Context) {
+	var request models.BorrowerUpdateRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		_ = ctx.Error(invalidRequestBody(err))
+		return
+	}
+
+	borrower, err := c.borrowerService.UpdateBorrower(ctx, ctx.Param("id"), request)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":    borrower,
+		"message": "Borrower updated successfully",
+	})
+}
+
+// DeleteBorrower godoc
+// @Summary Delete a borrower
+// @Description Soft delete a borrower, not allowed while the borrower still has an active loan
+// @Tags borrowers
+// @Accept json
+// @Produce json
+// @Param id path string true "Borrower ID"
+// @Success 200 {object} map[string]interface{} "Success"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 404 {object} models.ErrorResponse "Not Found"
+// @Failure 409 {object} models.ErrorResponse "Conflict"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /borrowers/{id} [delete]
+func (c *BorrowerController) DeleteBorrower(ctx *gin.Context) {
+	if err := c.borrowerService.DeleteBorrower(ctx, ctx.Param("id")); err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Borrower deleted successfully",
+	})
+}
@@ -0,0 +1,13 @@
+package controllers
+
+import "github.com/satryarangga/amartha-loan-engine/services"
+
+// the binding messages only name the request fields and the broken rules, so they are safe to show
+
+func invalidRequestBody(err error) error {
+	return services.NewValidationError("invalid_request_body", "Invalid request body: %v", err)
+}
+
+func invalidQueryParameters(err error) error {
+	return services.NewValidationError("invalid_query_parameters", "Invalid query parameters: %v", err)
+}
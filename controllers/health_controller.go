@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/satryarangga/amartha-loan-engine/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthController struct {
+	healthService *services.HealthServiceImpl
+}
+
+func NewHealthController(healthService *services.HealthServiceImpl) *HealthController {
+	return &HealthController{
+		healthService: healthService,
+	}
+}
+
+// Liveness answers as long as the process serves HTTP, it doesn't check the dependencies so an
+// outage of the database doesn't get every instance restarted
+func (c *HealthController) Liveness(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, models.HealthResponse{Status: models.HealthStatusUp})
+}
+
+// Readiness answers 503 while the instance can't serve requests, see HealthService.CheckReadiness
+func (c *HealthController) Readiness(ctx *gin.Context) {
+	health := c.healthService.CheckReadiness(ctx)
+	if health.Status != models.HealthStatusUp {
+		ctx.JSON(http.StatusServiceUnavailable, health)
+		return
+	}
+	ctx.JSON(http.StatusOK, health)
+}
@@ -0,0 +1,203 @@
+package controllers
+
+import (
+	"mime"
+	"net/http"
+	"slices"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/satryarangga/amartha-loan-engine/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type KYCController struct {
+	kycService *services.KYCServiceImpl
+}
+
+func NewKYCController(kycService *services.KYCServiceImpl) *KYCController {
+	return &KYCController{
+		kycService: kycService,
+	}
+}
+
+// GetKYC godoc
+// @Summary Get borrower KYC
+// @Description Retrieve the KYC status, profile and uploaded documents of a borrower
+// @Tags kyc
+// @Accept json
+// @Produce json
+// @Param id path string true "Borrower ID"
+// @Success 200 {object} models.KYCResponse "Success"
+// @Failure 404 {object} models.ErrorResponse "Not Found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /borrowers/{id}/kyc [get]
+func (c *KYCController) GetKYC(ctx *gin.Context) {
+	kyc, err := c.kycService.GetKYC(ctx, ctx.Param("id"))
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data": kyc,
+	})
+}
+
+// SubmitKYCProfile godoc
+// @Summary Submit borrower KYC profile
+// @Description Create or replace the KYC profile of a borrower and put the KYC in pending review
+// @Tags kyc
+// @Accept json
+// @Produce json
+// @Param id path string true "Borrower ID"
+// @Param profile body models.KYCProfileRequest true "KYC profile"
+// @Success 200 {object} models.BorrowerKYCProfile "Success"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 404 {object} models.ErrorResponse "Not Found"
+// @Failure 422 {object} models.ErrorResponse "Unprocessable Entity"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /borrowers/{id}/kyc [put]
+func (c *KYCController) SubmitKYCProfile(ctx *gin.Context) {
+	var request models.KYCProfileRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		_ = ctx.Error(invalidRequestBody(err))
+		return
+	}
+
+	profile, err := c.kycService.SubmitKYCProfile(ctx, ctx.Param("id"), request)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":    profile,
+		"message": "KYC profile submitted successfully",
+	})
+}
+
+// UploadDocument godoc
+// @Summary Upload borrower KYC document
+// @Description Upload an ID photo, selfie or business photo of a borrower, the file must be a JPEG, PNG or PDF detected from its content
+// @Tags kyc
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Borrower ID"
+// @Param document_type formData string true "Document type (id_photo, selfie, business_photo)"
+// @Param file formData file true "Document file"
+// @Param Idempotency-Key header string false "Unique key making retries safe, the response of the first request is replayed to the retries"
+// @Success 201 {object} models.BorrowerDocument "Created"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 404 {object} models.ErrorResponse "Not Found"
+// @Failure 422 {object} models.ErrorResponse "Unprocessable Entity"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Failure 502 {object} models.ErrorResponse "Bad Gateway"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /borrowers/{id}/kyc/documents [post]
+func (c *KYCController) UploadDocument(ctx *gin.Context) {
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		_ = ctx.Error(services.NewValidationError("document_file_required", "Document file is required"))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		_ = ctx.Error(services.NewValidationError("invalid_document_file", "Unable to read document file"))
+		return
+	}
+	defer file.Close()
+
+	document, err := c.kycService.UploadDocument(
+		ctx,
+		ctx.Param("id"),
+		models.DocumentType(ctx.PostForm("document_type")),
+		fileHeader.Filename,
+		fileHeader.Size,
+		file,
+	)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"data":    document,
+		"message": "Document uploaded successfully",
+	})
+}
+
+// DownloadDocument godoc
+// @Summary Download borrower KYC document
+// @Description Stream the content of an uploaded KYC document as an attachment, the browser is told not to sniff its type
+// @Tags kyc
+// @Produce octet-stream
+// @Param id path string true "Borrower ID"
+// @Param documentId path string true "Document ID"
+// @Success 200 {file} file "Document content"
+// @Failure 404 {object} models.ErrorResponse "Not Found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Failure 502 {object} models.ErrorResponse "Bad Gateway"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /borrowers/{id}/kyc/documents/{documentId} [get]
+func (c *KYCController) DownloadDocument(ctx *gin.Context) {
+	document, content, err := c.kycService.GetDocumentContent(ctx, ctx.Param("id"), ctx.Param("documentId"))
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	defer content.Close()
+
+	// the documents uploaded before their type was detected may have any declared type, so only the
+	// accepted types are served as such and the browser is told not to guess another one
+	contentType := document.ContentType
+	if !slices.Contains(models.KYCDocumentContentTypes, contentType) {
+		contentType = "application/octet-stream"
+	}
+	ctx.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": document.FileName}))
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	ctx.DataFromReader(http.StatusOK, document.SizeBytes, contentType, content, nil)
+}
+
+// ReviewKYC godoc
+// @Summary Review borrower KYC
+// @Description Verify or reject a borrower KYC that is pending review
+// @Tags kyc
+// @Accept json
+// @Produce json
+// @Param id path string true "Borrower ID"
+// @Param review body models.KYCReviewRequest true "Review result"
+// @Param Idempotency-Key header string false "Unique key making retries safe, the response of the first request is replayed to the retries"
+// @Success 200 {object} models.KYCResponse "Success"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 404 {object} models.ErrorResponse "Not Found"
+// @Failure 422 {object} models.ErrorResponse "Unprocessable Entity"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /borrowers/{id}/kyc/review [post]
+func (c *KYCController) ReviewKYC(ctx *gin.Context) {
+	var request models.KYCReviewRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		_ = ctx.Error(invalidRequestBody(err))
+		return
+	}
+
+	kyc, err := c.kycService.ReviewKYC(ctx, ctx.Param("id"), request)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":    kyc,
+		"message": "KYC reviewed successfully",
+	})
+}
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/satryarangga/amartha-loan-engine/models"
 	"github.com/satryarangga/amartha-loan-engine/services"
@@ -26,24 +27,24 @@ func NewLoanController(loanService *services.LoanServiceImpl) *LoanController {
 // @Accept json
 // @Produce json
 // @Param loan body models.LoanRequest true "Loan object"
+// @Param Idempotency-Key header string false "Unique key making retries safe, the response of the first request is replayed to the retries"
 // @Success 201 {object} models.Loan "Created"
 // @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 404 {object} models.ErrorResponse "Not Found"
+// @Failure 422 {object} models.ErrorResponse "Unprocessable Entity"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /loans [post]
 func (c *LoanController) CreateLoan(ctx *gin.Context) {
 	var loan models.LoanRequest
 	if err := ctx.ShouldBindJSON(&loan); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request body",
-			"details": err.Error(),
-		})
+		_ = ctx.Error(invalidRequestBody(err))
 		return
 	}
 
 	if err := c.loanService.CreateLoan(ctx, &loan); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Failed to create loan",
-			"details": err.Error(),
-		})
+		_ = ctx.Error(err)
 		return
 	}
 
@@ -55,30 +56,36 @@ func (c *LoanController) CreateLoan(ctx *gin.Context) {
 
 // GetLoanByID godoc
 // @Summary Get loan by ID
-// @Description Retrieve a specific loan by its ID
+// @Description Retrieve a specific loan by its ID, optionally expanded with its schedules, payments and borrower
 // @Tags loans
 // @Accept json
 // @Produce json
 // @Param id path string true "Loan ID"
-// @Success 200 {object} models.Loan "Success"
+// @Param include query string false "Comma separated relations to expand (schedules, payments, borrower)"
+// @Success 200 {object} models.LoanResponse "Success"
 // @Failure 400 {object} models.ErrorResponse "Bad Request"
 // @Failure 404 {object} models.ErrorResponse "Not Found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /loans/{id} [get]
 func (c *LoanController) GetLoanByID(ctx *gin.Context) {
 	id := ctx.Param("id")
 	if id == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": "Loan ID is required",
-		})
+		_ = ctx.Error(services.NewValidationError("loan_id_required", "Loan ID is required"))
 		return
 	}
 
-	loan, err := c.loanService.GetLoanByID(ctx, id)
+	includes := []models.LoanInclude{}
+	for _, include := range strings.Split(ctx.Query("include"), ",") {
+		if include = strings.TrimSpace(include); include != "" {
+			includes = append(includes, models.LoanInclude(include))
+		}
+	}
+
+	loan, err := c.loanService.GetLoanByID(ctx, id, includes)
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{
-			"error":   "Loan not found",
-			"details": err.Error(),
-		})
+		_ = ctx.Error(err)
 		return
 	}
 
@@ -86,3 +93,44 @@ func (c *LoanController) GetLoanByID(ctx *gin.Context) {
 		"data": loan,
 	})
 }
+
+// ListLoans godoc
+// @Summary List loans
+// @Description List loans with filters, sorting and cursor pagination
+// @Tags loans
+// @Accept json
+// @Produce json
+// @Param status query string false "Filter by loan status (active, paid)"
+// @Param borrower_id query string false "Filter by borrower ID"
+// @Param product_code query string false "Filter by loan product code"
+// @Param disbursed_from query string false "Disbursed on or after this date (YYYY-MM-DD)"
+// @Param disbursed_to query string false "Disbursed on or before this date (YYYY-MM-DD)"
+// @Param dpd_bucket query string false "Days past due bucket (current, 1-30, 31-60, 61-90, 90+)"
+// @Param min_outstanding query number false "Minimum outstanding amount"
+// @Param max_outstanding query number false "Maximum outstanding amount"
+// @Param sort_by query string false "Sort field (created_at, disbursed_at, amount)"
+// @Param sort_direction query string false "Sort direction (asc or desc)"
+// @Param cursor query string false "Cursor of the page to fetch, taken from next_cursor of the previous page"
+// @Param limit query int false "Number of items per page (max 100)"
+// @Success 200 {object} models.LoanListResponse "Success"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /loans [get]
+func (c *LoanController) ListLoans(ctx *gin.Context) {
+	var request models.LoanListRequest
+	if err := ctx.ShouldBindQuery(&request); err != nil {
+		_ = ctx.Error(invalidQueryParameters(err))
+		return
+	}
+
+	loans, err := c.loanService.ListLoans(ctx, request)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, loans)
+}
@@ -26,16 +26,19 @@ func NewPaymentController(paymentService *services.PaymentServiceImpl) *PaymentC
 // @Accept json
 // @Produce json
 // @Param paymentLinkRequest body models.PaymentLinkRequest true "Payment link request"
+// @Param Idempotency-Key header string false "Unique key making retries safe, the response of the first request is replayed to the retries"
 // @Success 200 {object} map[string]interface{} "Success"
-// @Failure 400 {object} map[string]interface{} "Bad Request"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 404 {object} models.ErrorResponse "Not Found"
+// @Failure 422 {object} models.ErrorResponse "Unprocessable Entity"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /payments/link [post]
 func (c *PaymentController) GeneratePaymentLink(ctx *gin.Context) {
 	var paymentLinkRequest models.PaymentLinkRequest
 	if err := ctx.ShouldBindJSON(&paymentLinkRequest); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid payment link request",
-			"details": err.Error(),
-		})
+		_ = ctx.Error(invalidRequestBody(err))
 		return
 	}
 
@@ -44,10 +47,7 @@ func (c *PaymentController) GeneratePaymentLink(ctx *gin.Context) {
 		PaymentMethod: paymentLinkRequest.PaymentMethod,
 	})
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Failed to generate payment link",
-			"details": err.Error(),
-		})
+		_ = ctx.Error(err)
 		return
 	}
 
@@ -59,29 +59,28 @@ func (c *PaymentController) GeneratePaymentLink(ctx *gin.Context) {
 
 // HandlePaymentWebhook godoc
 // @Summary Handle payment webhook
-// @Description Process payment webhook from payment gateway
+// @Description Process payment webhook from payment gateway, called with a payment_gateway token or an API key holding payments:webhook
 // @Tags payments
 // @Accept json
 // @Produce json
 // @Param paymentData body models.PaymentWebhookRequest true "Payment webhook data"
+// @Param Idempotency-Key header string false "Unique key making retries safe, the response of the first request is replayed to the retries"
 // @Success 200 {object} map[string]interface{} "Success"
-// @Failure 400 {object} map[string]interface{} "Bad Request"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 404 {object} models.ErrorResponse "Not Found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /payments/webhook [post]
 func (c *PaymentController) HandlePaymentWebhook(ctx *gin.Context) {
 	var paymentData models.PaymentWebhookRequest
 	if err := ctx.ShouldBindJSON(&paymentData); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid webhook payload",
-			"details": err.Error(),
-		})
+		_ = ctx.Error(invalidRequestBody(err))
 		return
 	}
 
 	if err := c.paymentService.HandlePaymentWebhook(ctx, paymentData); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Failed to process payment webhook",
-			"details": err.Error(),
-		})
+		_ = ctx.Error(err)
 		return
 	}
 
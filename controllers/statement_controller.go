@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/satryarangga/amartha-loan-engine/helpers"
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/satryarangga/amartha-loan-engine/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StatementController struct {
+	statementService *services.StatementServiceImpl
+}
+
+func NewStatementController(statementService *services.StatementServiceImpl) *StatementController {
+	return &StatementController{
+		statementService: statementService,
+	}
+}
+
+// GetBorrowerStatement godoc
+// @Summary Get borrower statement of account
+// @Description Retrieve the disbursements, interest, installments and payments of every loan of a borrower within a period, as JSON, CSV or PDF
+// @Tags borrowers
+// @Produce json
+// @Produce text/csv
+// @Produce application/pdf
+// @Param id path string true "Borrower ID"
+// @Param from query string false "Start of the period (YYYY-MM-DD), defaults to the first disbursement"
+// @Param to query string false "End of the period (YYYY-MM-DD), defaults to today"
+// @Param format query string false "Export format (json, csv, pdf)"
+// @Success 200 {object} models.StatementResponse "Success"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 404 {object} models.ErrorResponse "Not Found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /borrowers/{id}/statement [get]
+func (c *StatementController) GetBorrowerStatement(ctx *gin.Context) {
+	var request models.StatementRequest
+	if err := ctx.ShouldBindQuery(&request); err != nil {
+		_ = ctx.Error(invalidQueryParameters(err))
+		return
+	}
+
+	statement, err := c.statementService.GetBorrowerStatement(ctx, ctx.Param("id"), request)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	var (
+		content     []byte
+		contentType string
+	)
+	switch request.Format {
+	case models.StatementFormatCSV:
+		content, err = helpers.RenderStatementCSV(*statement)
+		contentType = "text/csv"
+	case models.StatementFormatPDF:
+		content, err = helpers.RenderStatementPDF(*statement)
+		contentType = "application/pdf"
+	default:
+		ctx.JSON(http.StatusOK, gin.H{
+			"data": statement,
+		})
+		return
+	}
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	fileName := fmt.Sprintf("statement-%s-%s.%s", statement.Borrower.ID, statement.To.Format("20060102"), request.Format)
+	ctx.Header("Content-Disposition", "attachment; filename=\""+fileName+"\"")
+	ctx.Data(http.StatusOK, contentType, content)
+}
@@ -0,0 +1,213 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/satryarangga/amartha-loan-engine/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookController struct {
+	webhookService *services.WebhookServiceImpl
+}
+
+func NewWebhookController(webhookService *services.WebhookServiceImpl) *WebhookController {
+	return &WebhookController{
+		webhookService: webhookService,
+	}
+}
+
+// ListSubscriptions godoc
+// @Summary List webhook subscriptions
+// @Description List the partner endpoints receiving domain events, without their secrets
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.WebhookSubscription "Success"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /webhook-subscriptions [get]
+func (c *WebhookController) ListSubscriptions(ctx *gin.Context) {
+	subscriptions, err := c.webhookService.ListSubscriptions(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data": subscriptions,
+	})
+}
+
+// CreateSubscription godoc
+// @Summary Create webhook subscription
+// @Description Register a partner endpoint for a list of event types, it only receives the events of the borrowers granted to the partner. Deliveries are signed with HMAC-SHA256 of the secret in the X-Webhook-Signature header.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param subscription body models.WebhookSubscriptionRequest true "Webhook subscription"
+// @Param Idempotency-Key header string false "Unique key making retries safe, the response of the first request is replayed to the retries"
+// @Success 201 {object} models.WebhookSubscription "Created"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /webhook-subscriptions [post]
+func (c *WebhookController) CreateSubscription(ctx *gin.Context) {
+	var request models.WebhookSubscriptionRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		_ = ctx.Error(invalidRequestBody(err))
+		return
+	}
+
+	subscription, err := c.webhookService.CreateSubscription(ctx, request)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"data":    subscription,
+		"message": "Webhook subscription created successfully",
+	})
+}
+
+// DeleteSubscription godoc
+// @Summary Delete webhook subscription
+// @Description Delete a webhook subscription, its pending deliveries are not sent anymore
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook subscription ID"
+// @Success 200 {object} map[string]interface{} "Success"
+// @Failure 404 {object} models.ErrorResponse "Not Found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /webhook-subscriptions/{id} [delete]
+func (c *WebhookController) DeleteSubscription(ctx *gin.Context) {
+	if err := c.webhookService.DeleteSubscription(ctx, ctx.Param("id")); err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Webhook subscription deleted successfully",
+	})
+}
+
+// GrantBorrower godoc
+// @Summary Grant borrower to partner
+// @Description Let the webhook subscriptions of the partner receive the events of the borrower, granting it again changes nothing
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param partner_id path string true "Partner ID"
+// @Param borrower_id path string true "Borrower ID"
+// @Success 200 {object} models.PartnerBorrower "Success"
+// @Failure 404 {object} models.ErrorResponse "Not Found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /webhook-partners/{partner_id}/borrowers/{borrower_id} [put]
+func (c *WebhookController) GrantBorrower(ctx *gin.Context) {
+	grant, err := c.webhookService.GrantBorrower(ctx, ctx.Param("partner_id"), ctx.Param("borrower_id"))
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":    grant,
+		"message": "Borrower granted to the partner successfully",
+	})
+}
+
+// RevokeBorrower godoc
+// @Summary Revoke borrower from partner
+// @Description Stop sending the events of the borrower to the webhook subscriptions of the partner, the deliveries already enqueued are still sent
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param partner_id path string true "Partner ID"
+// @Param borrower_id path string true "Borrower ID"
+// @Success 200 {object} map[string]interface{} "Success"
+// @Failure 404 {object} models.ErrorResponse "Not Found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /webhook-partners/{partner_id}/borrowers/{borrower_id} [delete]
+func (c *WebhookController) RevokeBorrower(ctx *gin.Context) {
+	if err := c.webhookService.RevokeBorrower(ctx, ctx.Param("partner_id"), ctx.Param("borrower_id")); err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Borrower revoked from the partner successfully",
+	})
+}
+
+// ListDeliveries godoc
+// @Summary List webhook deliveries
+// @Description List the delivery log of a webhook subscription, newest first, with the outcome of the last attempt
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook subscription ID"
+// @Param status query string false "Filter by status (pending, succeeded, failed)"
+// @Param page query int false "Page number, starts from 1"
+// @Param limit query int false "Number of deliveries per page (max 100)"
+// @Success 200 {object} models.WebhookDeliveryListResponse "Success"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 404 {object} models.ErrorResponse "Not Found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /webhook-subscriptions/{id}/deliveries [get]
+func (c *WebhookController) ListDeliveries(ctx *gin.Context) {
+	var request models.WebhookDeliveryListRequest
+	if err := ctx.ShouldBindQuery(&request); err != nil {
+		_ = ctx.Error(invalidQueryParameters(err))
+		return
+	}
+
+	deliveries, err := c.webhookService.ListDeliveries(ctx, ctx.Param("id"), request)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, deliveries)
+}
+
+// RedeliverDelivery godoc
+// @Summary Redeliver webhook
+// @Description Send the payload of a delivery again, as a new delivery
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook delivery ID"
+// @Param Idempotency-Key header string false "Unique key making retries safe, the response of the first request is replayed to the retries"
+// @Success 202 {object} models.WebhookDelivery "Accepted"
+// @Failure 404 {object} models.ErrorResponse "Not Found"
+// @Failure 422 {object} models.ErrorResponse "Unprocessable Entity"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /webhook-deliveries/{id}/redeliver [post]
+func (c *WebhookController) RedeliverDelivery(ctx *gin.Context) {
+	delivery, err := c.webhookService.RedeliverDelivery(ctx, ctx.Param("id"))
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{
+		"data":    delivery,
+		"message": "Webhook redelivery scheduled",
+	})
+}
@@ -0,0 +1,56 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// template is the skeleton of a new migration, every statement runs between StatementBegin and
+// StatementEnd so the functions and DO blocks aren't split on their semicolons
+const template = `-- +goose Up
+-- +goose StatementBegin
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+-- +goose StatementEnd
+`
+
+var nonWordCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Create writes an empty migration in the directory and returns its path. It is versioned right after
+// the last migration of the directory, following the sequence of the existing ones, and named after
+// the name in snake case, e.g. "Add index to loans" is 20240101000017_add_index_to_loans.sql.
+func Create(dir, name string) (string, error) {
+	name = strings.Trim(nonWordCharacters.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return "", errors.New("the name of the migration must contain a letter or a digit")
+	}
+
+	// a missing directory would otherwise be read as one without any migration
+	if _, err := os.Stat(dir); err != nil {
+		return "", fmt.Errorf("unable to read the migrations of %s: %w", dir, err)
+	}
+	latest, err := latestVersion(os.DirFS(dir))
+	if err != nil {
+		return "", fmt.Errorf("unable to read the migrations of %s: %w", dir, err)
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("%d_%s.sql", latest+1, name))
+	// O_EXCL, a migration is never overwritten
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return "", err
+	}
+	if _, err := file.WriteString(template); err != nil {
+		_ = file.Close()
+		return "", err
+	}
+	return path, file.Close()
+}
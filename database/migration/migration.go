@@ -0,0 +1,107 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+)
+
+// Dir holds the SQL migrations, relative to the root of the repository. They are embedded in the
+// binaries, the directory is only read to create a new migration.
+const Dir = "database/migration/sql"
+
+//go:embed sql/*.sql
+var embedded embed.FS
+
+// FS returns the SQL migrations embedded in the build, so they run from any working directory
+func FS() fs.FS {
+	migrations, err := fs.Sub(embedded, "sql")
+	if err != nil {
+		// the directory is embedded, it can't be missing
+		panic(err)
+	}
+	return migrations
+}
+
+// NewProvider runs the embedded migrations against the database. It holds a Postgres advisory lock
+// while migrating, so instances migrating on boot at the same time apply each migration only once.
+func NewProvider(db *sql.DB) (*goose.Provider, error) {
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return nil, err
+	}
+	return goose.NewProvider(goose.DialectPostgres, db, FS(), goose.WithSessionLocker(locker))
+}
+
+// Up applies every pending migration, it is what the server runs on boot with DB_MIGRATE_ON_BOOT
+func Up(ctx context.Context, db *sql.DB) ([]*goose.MigrationResult, error) {
+	provider, err := NewProvider(db)
+	if err != nil {
+		return nil, err
+	}
+	return provider.Up(ctx)
+}
+
+// LatestVersion returns the version of the last migration, the schema version once every migration is applied
+func LatestVersion() (int64, error) {
+	return latestVersion(FS())
+}
+
+// latestVersion returns the highest version of the SQL migrations of the directory, 0 when there is none
+func latestVersion(fsys fs.FS) (int64, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return 0, err
+	}
+	var latest int64
+	for _, name := range names {
+		version, err := goose.NumericComponent(name)
+		if err != nil {
+			return 0, fmt.Errorf("invalid migration %s: %w", name, err)
+		}
+		latest = max(latest, version)
+	}
+	return latest, nil
+}
+
+// PlanUp returns the pending migrations up to and including the target version, in the order the
+// provider would apply them. Like the provider, it fails when a pending migration is older than one
+// already applied, as they aren't applied out of order.
+func PlanUp(status []*goose.MigrationStatus, target int64) ([]*goose.Source, error) {
+	var current int64
+	for _, migration := range status {
+		if migration.State == goose.StateApplied {
+			current = max(current, migration.Source.Version)
+		}
+	}
+
+	var plan []*goose.Source
+	for _, migration := range status {
+		if migration.State != goose.StatePending || migration.Source.Version > target {
+			continue
+		}
+		if migration.Source.Version < current {
+			return nil, fmt.Errorf("migration %s is pending but older than the database version %d", path.Base(migration.Source.Path), current)
+		}
+		plan = append(plan, migration.Source)
+	}
+	return plan, nil
+}
+
+// PlanDown returns the applied migrations newer than the target version, in the order the provider
+// would roll them back, the latest first
+func PlanDown(status []*goose.MigrationStatus, target int64) []*goose.Source {
+	var plan []*goose.Source
+	for i := len(status) - 1; i >= 0; i-- {
+		if status[i].State == goose.StateApplied && status[i].Source.Version > target {
+			plan = append(plan, status[i].Source)
+		}
+	}
+	return plan
+}
@@ -0,0 +1,149 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatestVersion(t *testing.T) {
+	// Arrange
+	// the embedded migrations are the ones of the repository
+	files, err := filepath.Glob(filepath.Join("sql", "*.sql"))
+	assert.NoError(t, err)
+	var expected int64
+	for _, file := range files {
+		version, err := goose.NumericComponent(file)
+		assert.NoError(t, err)
+		expected = max(expected, version)
+	}
+
+	// Act
+	version, err := LatestVersion()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotZero(t, version)
+	assert.Equal(t, expected, version)
+}
+
+func TestNewProvider_EmbedsMigrations(t *testing.T) {
+	// Arrange
+	// the connection is only opened once a command runs
+	db, err := sql.Open("pgx", "host=localhost")
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	files, err := filepath.Glob(filepath.Join("sql", "*.sql"))
+	assert.NoError(t, err)
+
+	// Act
+	provider, err := NewProvider(db)
+
+	// Assert
+	assert.NoError(t, err)
+	sources := provider.ListSources()
+	assert.Len(t, sources, len(files))
+	assert.Equal(t, filepath.Base(files[0]), sources[0].Path)
+}
+
+func TestLatestVersion_InvalidName(t *testing.T) {
+	_, err := latestVersion(fstest.MapFS{"add_index.sql": {}})
+
+	assert.Error(t, err)
+}
+
+func newTestStatus(applied ...bool) []*goose.MigrationStatus {
+	status := make([]*goose.MigrationStatus, 0, len(applied))
+	for i, isApplied := range applied {
+		state := goose.StatePending
+		if isApplied {
+			state = goose.StateApplied
+		}
+		version := int64(i + 1)
+		status = append(status, &goose.MigrationStatus{
+			Source: &goose.Source{Type: goose.TypeSQL, Path: fmt.Sprintf("%d_migration.sql", version), Version: version},
+			State:  state,
+		})
+	}
+	return status
+}
+
+func sourceVersions(sources []*goose.Source) []int64 {
+	versions := make([]int64, 0, len(sources))
+	for _, source := range sources {
+		versions = append(versions, source.Version)
+	}
+	return versions
+}
+
+func TestPlanUp(t *testing.T) {
+	// Arrange
+	status := newTestStatus(true, true, false, false, false)
+
+	// Act
+	all, allErr := PlanUp(status, goose.MaxVersion)
+	upTo, upToErr := PlanUp(status, 4)
+
+	// Assert
+	assert.NoError(t, allErr)
+	assert.NoError(t, upToErr)
+	assert.Equal(t, []int64{3, 4, 5}, sourceVersions(all))
+	assert.Equal(t, []int64{3, 4}, sourceVersions(upTo))
+}
+
+func TestPlanUp_OutOfOrder(t *testing.T) {
+	// Arrange
+	// 2 was added after 3 was applied
+	status := newTestStatus(true, false, true)
+
+	// Act
+	_, err := PlanUp(status, goose.MaxVersion)
+
+	// Assert
+	assert.ErrorContains(t, err, "older than the database version 3")
+}
+
+func TestPlanDown(t *testing.T) {
+	// Arrange
+	status := newTestStatus(true, true, true, false)
+
+	// Act
+	all := PlanDown(status, 0)
+	downTo := PlanDown(status, 2)
+
+	// Assert
+	// the latest is rolled back first, the pending ones are left alone
+	assert.Equal(t, []int64{3, 2, 1}, sourceVersions(all))
+	assert.Equal(t, []int64{3}, sourceVersions(downTo))
+}
+
+func TestCreate(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "20240101000016_add_borrowers_search_index.sql"), nil, 0o644))
+
+	// Act
+	path, err := Create(dir, "Add index to loans!")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "20240101000017_add_index_to_loans.sql"), path)
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, template, string(content))
+}
+
+func TestCreate_InvalidNameOrDirectory(t *testing.T) {
+	_, invalidNameErr := Create(t.TempDir(), " -- ")
+	_, missingDirErr := Create(filepath.Join(t.TempDir(), "missing"), "add_index")
+
+	assert.Error(t, invalidNameErr)
+	assert.Error(t, missingDirErr)
+}
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE borrowers ADD COLUMN kyc_status VARCHAR(50) NOT NULL DEFAULT 'unverified';
+
+CREATE INDEX idx_borrowers_kyc_status ON borrowers(kyc_status);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_borrowers_kyc_status;
+ALTER TABLE borrowers DROP COLUMN IF EXISTS kyc_status;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE borrower_kyc_profiles (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    borrower_id UUID NOT NULL UNIQUE REFERENCES borrowers(id) ON DELETE CASCADE,
+    nik VARCHAR(16) NOT NULL UNIQUE,
+    date_of_birth DATE NOT NULL,
+    address TEXT NOT NULL,
+    business_type VARCHAR(100) NOT NULL,
+    monthly_income DECIMAL(15,2) NOT NULL,
+    rejection_reason TEXT,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS borrower_kyc_profiles;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE borrower_documents (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    borrower_id UUID NOT NULL REFERENCES borrowers(id) ON DELETE CASCADE,
+    document_type VARCHAR(50) NOT NULL,
+    storage_key VARCHAR(500) NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_borrower_documents_borrower_id ON borrower_documents(borrower_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS borrower_documents;
+-- +goose StatementEnd
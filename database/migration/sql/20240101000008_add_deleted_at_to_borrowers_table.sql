@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE borrowers ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_borrowers_deleted_at ON borrowers(deleted_at);
+
+-- a soft deleted borrower must not block the phone number from being registered again
+ALTER TABLE borrowers DROP CONSTRAINT IF EXISTS borrowers_phone_number_key;
+CREATE UNIQUE INDEX idx_borrowers_phone_number ON borrowers(phone_number) WHERE deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_borrowers_phone_number;
+ALTER TABLE borrowers ADD CONSTRAINT borrowers_phone_number_key UNIQUE (phone_number);
+DROP INDEX IF EXISTS idx_borrowers_deleted_at;
+ALTER TABLE borrowers DROP COLUMN IF EXISTS deleted_at;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE loans ADD COLUMN product_code VARCHAR(50) NOT NULL DEFAULT 'standard';
+ALTER TABLE loans ADD COLUMN disbursed_at TIMESTAMP WITH TIME ZONE;
+
+-- loans are disbursed as soon as they are created, so existing rows take their creation time
+UPDATE loans SET disbursed_at = created_at;
+ALTER TABLE loans ALTER COLUMN disbursed_at SET NOT NULL;
+ALTER TABLE loans ALTER COLUMN disbursed_at SET DEFAULT CURRENT_TIMESTAMP;
+
+CREATE INDEX idx_loans_product_code ON loans(product_code);
+CREATE INDEX idx_loans_disbursed_at_id ON loans(disbursed_at, id);
+CREATE INDEX idx_loans_created_at_id ON loans(created_at, id);
+CREATE INDEX idx_loans_amount_id ON loans(amount, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_loans_amount_id;
+DROP INDEX IF EXISTS idx_loans_created_at_id;
+DROP INDEX IF EXISTS idx_loans_disbursed_at_id;
+DROP INDEX IF EXISTS idx_loans_product_code;
+ALTER TABLE loans DROP COLUMN IF EXISTS disbursed_at;
+ALTER TABLE loans DROP COLUMN IF EXISTS product_code;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE outbox (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_type VARCHAR(100) NOT NULL,
+    aggregate_type VARCHAR(100) NOT NULL,
+    aggregate_id VARCHAR(255) NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    available_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    published_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- the relay only ever looks for unpublished events
+CREATE INDEX idx_outbox_unpublished ON outbox(available_at, created_at) WHERE published_at IS NULL;
+CREATE INDEX idx_outbox_aggregate ON outbox(aggregate_type, aggregate_id);
+
+ALTER TABLE loan_schedules ADD COLUMN overdue_at TIMESTAMP WITH TIME ZONE;
+CREATE INDEX idx_loan_schedules_overdue_scan ON loan_schedules(due_date) WHERE status = 'pending' AND overdue_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_loan_schedules_overdue_scan;
+ALTER TABLE loan_schedules DROP COLUMN IF EXISTS overdue_at;
+DROP TABLE IF EXISTS outbox;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the version is bumped by every update, an update made from a stale read is rejected instead of
+-- overwriting the change it didn't see
+ALTER TABLE loans ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE loan_schedules ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE loan_payments ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE loan_payments DROP COLUMN IF EXISTS version;
+ALTER TABLE loan_schedules DROP COLUMN IF EXISTS version;
+ALTER TABLE loans DROP COLUMN IF EXISTS version;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- serves the full text search of the borrower listing, the expression has to stay the one the
+-- repository builds over first_name, last_name and phone_number in that order
+CREATE INDEX IF NOT EXISTS idx_borrowers_search ON borrowers USING GIN (
+    to_tsvector('simple', coalesce(first_name, '') || ' ' || coalesce(last_name, '') || ' ' || coalesce(phone_number, ''))
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_borrowers_search;
+-- +goose StatementEnd
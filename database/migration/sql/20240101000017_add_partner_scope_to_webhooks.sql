@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- a subscription belongs to a partner and only receives the events of the borrowers granted to it,
+-- the existing subscriptions have no partner so they receive nothing until they are recreated
+ALTER TABLE webhook_subscriptions ADD COLUMN partner_id VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE webhook_subscriptions ALTER COLUMN partner_id DROP DEFAULT;
+
+CREATE TABLE partner_borrowers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    partner_id VARCHAR(100) NOT NULL,
+    borrower_id UUID NOT NULL REFERENCES borrowers(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_partner_borrowers_partner ON partner_borrowers(partner_id, borrower_id);
+CREATE INDEX idx_partner_borrowers_borrower ON partner_borrowers(borrower_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS partner_borrowers;
+ALTER TABLE webhook_subscriptions DROP COLUMN IF EXISTS partner_id;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the time the payment gateway confirmed the payment, the payments already paid were last updated by
+-- the webhook so their update time is the best estimate
+ALTER TABLE loan_payments ADD COLUMN paid_at TIMESTAMP WITH TIME ZONE;
+UPDATE loan_payments SET paid_at = updated_at WHERE status = 'paid';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE loan_payments DROP COLUMN IF EXISTS paid_at;
+-- +goose StatementEnd
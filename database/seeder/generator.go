@@ -0,0 +1,301 @@
+package seeder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/satryarangga/amartha-loan-engine/cache"
+	"github.com/satryarangga/amartha-loan-engine/helpers"
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/satryarangga/amartha-loan-engine/repositories"
+	"github.com/satryarangga/amartha-loan-engine/services"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// borrowerBatchSize is the number of borrowers upserted per statement
+	borrowerBatchSize = 500
+	// overdueBatchSize is the number of schedules flagged overdue per transaction
+	overdueBatchSize = 500
+	// maxInstallmentsPerPayment bounds how many installments a borrower pays at once
+	maxInstallmentsPerPayment = 3
+	// syntheticPhonePrefix tells the synthetic borrowers apart, their phone number is the prefix and their index
+	syntheticPhonePrefix = "0899"
+)
+
+var (
+	syntheticFirstNames = []string{"Siti", "Dewi", "Sri", "Nur", "Rina", "Wati", "Ani", "Yuli", "Ratna", "Lestari", "Fitri", "Ayu"}
+	syntheticLastNames  = []string{"Rahayu", "Susanti", "Wulandari", "Handayani", "Kurniasih", "Purwanti", "Hidayati", "Setiawati", "Maryani", "Suryani"}
+	// the repetitions divide the amounts and the interests, so the installments sum up exactly to the loan
+	syntheticRepetitions   = []int{10, 25, 50}
+	syntheticCadenceDays   = []int{7, 14, 30}
+	syntheticInterests     = []float64{5, 10, 15}
+	syntheticPaymentMethod = models.PaymentMethods
+	// borrowerPhoneConflict skips the borrowers of a previous run, the phone numbers are only unique among the borrowers not deleted
+	borrowerPhoneConflict = clause.OnConflict{
+		Columns:     []clause.Column{{Name: "phone_number"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "deleted_at IS NULL"}}},
+		DoNothing:   true,
+	}
+)
+
+// GenerateOptions sizes the synthetic data
+type GenerateOptions struct {
+	Borrowers        int
+	LoansPerBorrower int
+	// RandomSeed makes the data reproducible, the same seed generates the same borrowers and loans
+	RandomSeed int64
+}
+
+// loanPlan is the synthetic history of a loan
+type loanPlan struct {
+	request models.LoanRequest
+	// backdateDays moves the loan back in time, so its first installments are already due
+	backdateDays int
+	// payments are the number of installments of each payment, oldest first
+	payments []int
+}
+
+// newLoanPlan draws a loan and its history. Most borrowers pay every installment due, some are a
+// few installments late and some stopped paying halfway, so every DPD bucket is populated.
+func newLoanPlan(random *rand.Rand, borrowerID string) loanPlan {
+	repetition := syntheticRepetitions[random.Intn(len(syntheticRepetitions))]
+	cadenceDays := syntheticCadenceDays[random.Intn(len(syntheticCadenceDays))]
+	request := models.LoanRequest{
+		BorrowerID:           borrowerID,
+		Amount:               float64(2+random.Intn(39)) * 500000,
+		RepaymentCadenceDays: cadenceDays,
+		RepaymentRepetition:  repetition,
+		InterestPercentage:   syntheticInterests[random.Intn(len(syntheticInterests))],
+	}
+
+	// the installments 1 to elapsed are due, the next one isn't yet
+	elapsed := random.Intn(repetition + 1)
+	paid := elapsed
+	switch behaviour := random.Float64(); {
+	case behaviour < 0.1:
+		paid = elapsed / 2
+	case behaviour < 0.3:
+		paid = max(0, elapsed-1-random.Intn(3))
+	}
+
+	var payments []int
+	for remaining := paid; remaining > 0; {
+		installments := min(remaining, 1+random.Intn(maxInstallmentsPerPayment))
+		payments = append(payments, installments)
+		remaining -= installments
+	}
+
+	return loanPlan{
+		request:      request,
+		backdateDays: elapsed*cadenceDays + 1 + random.Intn(cadenceDays-1),
+		payments:     payments,
+	}
+}
+
+// newSyntheticBorrower returns the borrower of the index, the same one every run
+func newSyntheticBorrower(index int, random *rand.Rand) models.Borrower {
+	return models.Borrower{
+		FirstName:   syntheticFirstNames[random.Intn(len(syntheticFirstNames))],
+		LastName:    syntheticLastNames[random.Intn(len(syntheticLastNames))],
+		PhoneNumber: fmt.Sprintf("%s%08d", syntheticPhonePrefix, index),
+		KYCStatus:   models.KYCStatusVerified,
+	}
+}
+
+// generator creates the loans and pays them through the services, like the API and the payment
+// gateway would, so the schedules, the payments, the audit trail and the events are the real ones
+type generator struct {
+	db              *gorm.DB
+	loanService     *services.LoanServiceImpl
+	paymentService  *services.PaymentServiceImpl
+	loanPaymentRepo repositories.LoanPaymentRepository
+}
+
+// Generate creates synthetic borrowers with loans and partially paid histories, for load testing.
+// The borrowers are keyed by their phone number and only get the loans they are missing, so running
+// it again with the same options adds nothing and an interrupted run can be resumed.
+func (s *Seeder) Generate(ctx context.Context, options GenerateOptions) error {
+	if options.Borrowers < 0 || options.Borrowers > 1e8 {
+		return fmt.Errorf("invalid number of borrowers %d", options.Borrowers)
+	}
+	if options.LoansPerBorrower < 1 {
+		return fmt.Errorf("invalid number of loans per borrower %d", options.LoansPerBorrower)
+	}
+
+	// every row is read right after it's written, a replica may not have it yet
+	ctx = helpers.WithPrimaryReads(ctx)
+
+	borrowerRepo := repositories.NewBorrowerRepository(s.db)
+	loanRepo := repositories.NewLoanRepository(s.db)
+	loanScheduleRepo := repositories.NewLoanScheduleRepository(s.db)
+	loanPaymentRepo := repositories.NewLoanPaymentRepository(s.db)
+	outboxRepo := repositories.NewOutboxRepository(s.db)
+	// the server's cache expires on its own, the responses of the synthetic borrowers weren't read yet
+	responses := cache.NewResponses(cache.NewNopCache(), 0)
+	g := generator{
+		db:              s.db,
+		loanService:     services.NewLoanService(loanRepo, loanScheduleRepo, borrowerRepo, outboxRepo, responses),
+		paymentService:  services.NewPaymentService(loanRepo, loanPaymentRepo, loanScheduleRepo, borrowerRepo, outboxRepo, responses, ""),
+		loanPaymentRepo: loanPaymentRepo,
+	}
+
+	for start := 0; start < options.Borrowers; start += borrowerBatchSize {
+		end := min(start+borrowerBatchSize, options.Borrowers)
+		if err := g.generateBatch(ctx, options, start, end); err != nil {
+			return err
+		}
+		fmt.Fprintf(s.out, "generated %d/%d borrowers\n", end, options.Borrowers)
+	}
+
+	// the installments left unpaid are flagged like the overdue scanner does
+	var overdue int
+	for {
+		marked, err := g.loanService.MarkOverdueSchedules(ctx, time.Now(), overdueBatchSize)
+		if err != nil {
+			return err
+		}
+		overdue += marked
+		if marked < overdueBatchSize {
+			break
+		}
+	}
+	fmt.Fprintf(s.out, "marked %d overdue schedules\n", overdue)
+	return nil
+}
+
+// generateBatch upserts the borrowers of the indexes start to end and creates their missing loans
+func (g generator) generateBatch(ctx context.Context, options GenerateOptions, start, end int) error {
+	randoms := make([]*rand.Rand, 0, end-start)
+	borrowers := make([]models.Borrower, 0, end-start)
+	phoneNumbers := make([]string, 0, end-start)
+	for index := start; index < end; index++ {
+		// one source per borrower, its loans are the same whichever borrowers were generated before
+		random := rand.New(rand.NewSource(options.RandomSeed + int64(index)))
+		borrower := newSyntheticBorrower(index, random)
+		randoms = append(randoms, random)
+		borrowers = append(borrowers, borrower)
+		phoneNumbers = append(phoneNumbers, borrower.PhoneNumber)
+	}
+
+	err := g.db.WithContext(ctx).Clauses(borrowerPhoneConflict).Create(&borrowers).Error
+	if err != nil {
+		return err
+	}
+	var stored []models.Borrower
+	if err := g.db.WithContext(ctx).Where("phone_number IN ?", phoneNumbers).Find(&stored).Error; err != nil {
+		return err
+	}
+	storedByPhone := make(map[string]models.Borrower, len(stored))
+	for _, borrower := range stored {
+		storedByPhone[borrower.PhoneNumber] = borrower
+	}
+
+	for i, phoneNumber := range phoneNumbers {
+		borrower, ok := storedByPhone[phoneNumber]
+		if !ok {
+			// deleted while the batch was generated, a borrower deleted before is registered again
+			continue
+		}
+		if err := g.generateLoans(ctx, borrower, options.LoansPerBorrower, randoms[i]); err != nil {
+			return fmt.Errorf("unable to generate the loans of borrower %s: %w", borrower.ID, err)
+		}
+	}
+	return nil
+}
+
+func (g generator) generateLoans(ctx context.Context, borrower models.Borrower, loansPerBorrower int, random *rand.Rand) error {
+	var existing int64
+	if err := g.db.WithContext(ctx).Model(&models.Loan{}).Where("borrower_id = ?", borrower.ID).Count(&existing).Error; err != nil {
+		return err
+	}
+
+	for i := int(existing); i < loansPerBorrower; i++ {
+		plan := newLoanPlan(random, borrower.ID)
+		if err := g.loanService.CreateLoan(ctx, &plan.request); err != nil {
+			return err
+		}
+		loan, err := g.backdateLatestLoan(ctx, borrower.ID, plan.backdateDays)
+		if err != nil {
+			return err
+		}
+		if err := g.payInstallments(ctx, loan, plan.payments, random); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backdateLatestLoan moves the loan just created back in time and returns it with its schedules
+func (g generator) backdateLatestLoan(ctx context.Context, borrowerID string, days int) (*models.Loan, error) {
+	var loan models.Loan
+	err := g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("borrower_id = ?", borrowerID).Order("created_at desc").First(&loan).Error
+		if err != nil {
+			return err
+		}
+
+		shift := gorm.Expr("make_interval(days => ?::int)", days)
+		err = tx.Exec("UPDATE loans SET disbursed_at = disbursed_at - ?, created_at = created_at - ?, updated_at = updated_at - ? WHERE id = ?",
+			shift, shift, shift, loan.ID).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Exec("UPDATE loan_schedules SET due_date = due_date - ?, created_at = created_at - ?, updated_at = updated_at - ? WHERE loan_id = ?",
+			shift, shift, shift, loan.ID).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Preload("LoanSchedules", func(db *gorm.DB) *gorm.DB {
+			return db.Order("due_date asc")
+		}).First(&loan, "id = ?", loan.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &loan, nil
+}
+
+// payInstallments pays the installments of the loan in order, each payment is created like a
+// payment link is and confirmed by the webhook of the payment gateway, on the due date of its last
+// installment
+func (g generator) payInstallments(ctx context.Context, loan *models.Loan, payments []int, random *rand.Rand) error {
+	next := 0
+	for _, installments := range payments {
+		if next+installments > len(loan.LoanSchedules) {
+			return errors.New("more installments paid than scheduled")
+		}
+		loanSchedules := loan.LoanSchedules[next : next+installments]
+		next += installments
+
+		loanPayment := models.LoanPayment{
+			LoanID:        loan.ID,
+			PaymentMethod: syntheticPaymentMethod[random.Intn(len(syntheticPaymentMethod))],
+		}
+		for _, loanSchedule := range loanSchedules {
+			loanPayment.LoanScheduleIDs = append(loanPayment.LoanScheduleIDs, loanSchedule.ID)
+			loanPayment.TotalPayment += loanSchedule.TotalPayment
+		}
+		loanPaymentID, err := g.loanPaymentRepo.Insert(ctx, &loanPayment)
+		if err != nil {
+			return err
+		}
+
+		webhook := models.PaymentWebhookRequest{ExternalID: loanPaymentID, PaymentStatus: string(models.LoanPaymentStatusPaid)}
+		if err := g.paymentService.HandlePaymentWebhook(ctx, webhook); err != nil {
+			return err
+		}
+
+		paidAt := loanSchedules[len(loanSchedules)-1].DueDate
+		err = g.db.WithContext(ctx).Exec("UPDATE loan_payments SET paid_at = ?, created_at = ?, updated_at = ? WHERE id = ?", paidAt, paidAt, paidAt, loanPaymentID).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
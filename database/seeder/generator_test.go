@@ -0,0 +1,68 @@
+package seeder
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestNewLoanPlan(t *testing.T) {
+	for seed := int64(0); seed < 500; seed++ {
+		// Act
+		plan := newLoanPlan(rand.New(rand.NewSource(seed)), "borrower-id")
+
+		// Assert
+		request := plan.request
+		assert.Equal(t, "borrower-id", request.BorrowerID)
+		// the installments are whole amounts, so paying all of them pays off the loan exactly
+		installment := (request.Amount + request.Amount*request.InterestPercentage/100) / float64(request.RepaymentRepetition)
+		assert.Equal(t, math.Trunc(installment), installment)
+
+		// the installments paid are due, the next one isn't
+		elapsed := plan.backdateDays / request.RepaymentCadenceDays
+		assert.LessOrEqual(t, elapsed, request.RepaymentRepetition)
+		assert.NotZero(t, plan.backdateDays%request.RepaymentCadenceDays)
+		var paid int
+		for _, installments := range plan.payments {
+			assert.True(t, installments >= 1 && installments <= maxInstallmentsPerPayment)
+			paid += installments
+		}
+		assert.LessOrEqual(t, paid, elapsed)
+	}
+}
+
+func TestNewLoanPlan_Reproducible(t *testing.T) {
+	first := newLoanPlan(rand.New(rand.NewSource(42)), "borrower-id")
+	second := newLoanPlan(rand.New(rand.NewSource(42)), "borrower-id")
+
+	assert.Equal(t, first, second)
+}
+
+func TestNewSyntheticBorrower(t *testing.T) {
+	borrower := newSyntheticBorrower(42, rand.New(rand.NewSource(1)))
+
+	assert.Equal(t, "089900000042", borrower.PhoneNumber)
+	assert.NotEmpty(t, borrower.FirstName)
+	assert.NotEmpty(t, borrower.LastName)
+	assert.Equal(t, "verified", string(borrower.KYCStatus))
+}
+
+func TestGenerateBatch_BorrowerUpsertTargetsPhoneIndex(t *testing.T) {
+	// Arrange
+	// dry run, the statement is built but nothing is sent to a server
+	db, err := gorm.Open(postgres.Open("host=localhost"), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	assert.NoError(t, err)
+	borrowers := []models.Borrower{newSyntheticBorrower(1, rand.New(rand.NewSource(1)))}
+
+	// Act
+	statement := db.Clauses(borrowerPhoneConflict).Create(&borrowers).Statement
+
+	// Assert
+	// postgres only infers the partial unique index idx_borrowers_phone_number when the conflict repeats its predicate
+	assert.Regexp(t, `ON CONFLICT \("phone_number"\)\s+WHERE deleted_at IS NULL DO NOTHING`, statement.SQL.String())
+}
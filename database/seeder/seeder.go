@@ -0,0 +1,61 @@
+package seeder
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"github.com/satryarangga/amartha-loan-engine/config"
+	"gorm.io/gorm"
+)
+
+// fixtures are the development data, upserts keyed by fixed IDs so seeding again updates the same rows
+//
+//go:embed sql/*.sql
+var fixtures embed.FS
+
+// CheckProfile refuses to seed the production database, the fixtures and the synthetic data would
+// mix with the real borrowers
+func CheckProfile(conf config.ConfigEnv) error {
+	if conf.AppEnv == config.ProfileProd {
+		return fmt.Errorf("seeding is disabled in %s", config.ProfileProd)
+	}
+	return nil
+}
+
+// Seeder inserts the development and load testing data, it reports its progress to out
+type Seeder struct {
+	db  *gorm.DB
+	out io.Writer
+}
+
+func NewSeeder(db *gorm.DB, out io.Writer) *Seeder {
+	return &Seeder{db: db, out: out}
+}
+
+// SeedFixtures runs the SQL files of the fixtures in the order of their names, each in its own
+// transaction. A file is run whole, it either succeeds or is rolled back and stops the seeding.
+func (s *Seeder) SeedFixtures(ctx context.Context) error {
+	files, err := fs.Glob(fixtures, "sql/*.sql")
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		content, err := fixtures.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		// without arguments the file is sent as a single simple query, statements and all
+		err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return tx.Exec(string(content)).Error
+		})
+		if err != nil {
+			return fmt.Errorf("unable to seed %s: %w", file, err)
+		}
+		fmt.Fprintf(s.out, "seeded %s\n", file)
+	}
+	return nil
+}
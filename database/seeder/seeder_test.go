@@ -0,0 +1,34 @@
+package seeder
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/satryarangga/amartha-loan-engine/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckProfile(t *testing.T) {
+	assert.NoError(t, CheckProfile(config.ConfigEnv{AppEnv: config.ProfileDev}))
+	assert.NoError(t, CheckProfile(config.ConfigEnv{AppEnv: config.ProfileStaging}))
+	assert.Error(t, CheckProfile(config.ConfigEnv{AppEnv: config.ProfileProd}))
+}
+
+func TestFixtures_AreUpserts(t *testing.T) {
+	// Arrange
+	files, err := fs.Glob(fixtures, "sql/*.sql")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, files)
+
+	for _, file := range files {
+		// Act
+		content, err := fixtures.ReadFile(file)
+
+		// Assert
+		// every insert updates the row seeded by a previous run instead of failing on its ID
+		assert.NoError(t, err)
+		statement := strings.ToLower(string(content))
+		assert.Equal(t, strings.Count(statement, "insert into"), strings.Count(statement, "on conflict (id) do update"), file)
+	}
+}
@@ -1,2 +1,9 @@
-insert into borrowers (id, first_name, last_name, phone_number) 
-values ('3e9cb9ee-684a-48b9-b532-1c7b822f8ae0','John', 'Doe', '081234567890');
\ No newline at end of file
+insert into borrowers (id, first_name, last_name, phone_number, kyc_status, deleted_at)
+values ('3e9cb9ee-684a-48b9-b532-1c7b822f8ae0', 'John', 'Doe', '081234567890', 'verified', null)
+on conflict (id) do update set
+    first_name = excluded.first_name,
+    last_name = excluded.last_name,
+    phone_number = excluded.phone_number,
+    kyc_status = excluded.kyc_status,
+    deleted_at = excluded.deleted_at,
+    updated_at = current_timestamp;
@@ -1,2 +1,13 @@
-insert into loans (id, borrower_id, amount, repayment_cadence_days, repayment_repetition, interest_percentage, interest_amount, status) 
-values ('3e9cb9ee-684a-48b9-b532-1c7b822f8ae1', '3e9cb9ee-684a-48b9-b532-1c7b822f8ae0', 5000000, 7, 50, 10, 500000, 'active');
\ No newline at end of file
+insert into loans (id, borrower_id, amount, repayment_cadence_days, repayment_repetition, interest_percentage, interest_amount, status, disbursed_at)
+values ('3e9cb9ee-684a-48b9-b532-1c7b822f8ae1', '3e9cb9ee-684a-48b9-b532-1c7b822f8ae0', 5000000, 7, 50, 10, 500000, 'active', '2024-12-25')
+on conflict (id) do update set
+    borrower_id = excluded.borrower_id,
+    amount = excluded.amount,
+    repayment_cadence_days = excluded.repayment_cadence_days,
+    repayment_repetition = excluded.repayment_repetition,
+    interest_percentage = excluded.interest_percentage,
+    interest_amount = excluded.interest_amount,
+    status = excluded.status,
+    disbursed_at = excluded.disbursed_at,
+    version = loans.version + 1,
+    updated_at = current_timestamp;
@@ -1,3 +1,11 @@
-insert into loan_payments (loan_id, loan_schedule_ids, total_payment, status, payment_method)
-values 
-('3e9cb9ee-684a-48b9-b532-1c7b822f8ae1', '{550e8400-e29b-41d4-a716-446655440001}', 110000, 'paid', 'bank_transfer');
\ No newline at end of file
+insert into loan_payments (id, loan_id, loan_schedule_ids, total_payment, status, payment_method, paid_at)
+values ('3e9cb9ee-684a-48b9-b532-1c7b822f8ae2', '3e9cb9ee-684a-48b9-b532-1c7b822f8ae1', '{550e8400-e29b-41d4-a716-446655440001}', 110000, 'paid', 'bank_transfer', current_timestamp)
+on conflict (id) do update set
+    loan_id = excluded.loan_id,
+    loan_schedule_ids = excluded.loan_schedule_ids,
+    total_payment = excluded.total_payment,
+    status = excluded.status,
+    payment_method = excluded.payment_method,
+    paid_at = coalesce(loan_payments.paid_at, excluded.paid_at),
+    version = loan_payments.version + 1,
+    updated_at = current_timestamp;
@@ -1,5 +1,5 @@
-insert into loan_schedules 
-(id, loan_id, due_date, basic_amount, interest_amount, total_payment, status) 
+insert into loan_schedules
+(id, loan_id, due_date, basic_amount, interest_amount, total_payment, status)
 values
 ('550e8400-e29b-41d4-a716-446655440001', '3e9cb9ee-684a-48b9-b532-1c7b822f8ae1', '2025-01-01', 100000, 10000, 110000, 'paid'),
 ('550e8400-e29b-41d4-a716-446655440002', '3e9cb9ee-684a-48b9-b532-1c7b822f8ae1', '2025-01-08', 100000, 10000, 110000, 'pending'),
@@ -50,4 +50,14 @@ values
 ('550e8400-e29b-41d4-a716-446655440047', '3e9cb9ee-684a-48b9-b532-1c7b822f8ae1', '2025-11-19', 100000, 10000, 110000, 'pending'),
 ('550e8400-e29b-41d4-a716-446655440048', '3e9cb9ee-684a-48b9-b532-1c7b822f8ae1', '2025-11-26', 100000, 10000, 110000, 'pending'),
 ('550e8400-e29b-41d4-a716-446655440049', '3e9cb9ee-684a-48b9-b532-1c7b822f8ae1', '2025-12-03', 100000, 10000, 110000, 'pending'),
-('550e8400-e29b-41d4-a716-446655440050', '3e9cb9ee-684a-48b9-b532-1c7b822f8ae1', '2025-12-10', 100000, 10000, 110000, 'pending');
\ No newline at end of file
+('550e8400-e29b-41d4-a716-446655440050', '3e9cb9ee-684a-48b9-b532-1c7b822f8ae1', '2025-12-10', 100000, 10000, 110000, 'pending')
+on conflict (id) do update set
+    loan_id = excluded.loan_id,
+    due_date = excluded.due_date,
+    basic_amount = excluded.basic_amount,
+    interest_amount = excluded.interest_amount,
+    total_payment = excluded.total_payment,
+    status = excluded.status,
+    overdue_at = null,
+    version = loan_schedules.version + 1,
+    updated_at = current_timestamp;
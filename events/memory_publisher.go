@@ -0,0 +1,34 @@
+package events
+
+import (
+	"context"
+	"sync"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+)
+
+// MemoryPublisher keeps the published events in memory, for tests and local runs
+type MemoryPublisher struct {
+	mu     sync.Mutex
+	events []models.OutboxEvent
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (p *MemoryPublisher) Publish(ctx context.Context, event models.OutboxEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.events = append(p.events, event)
+	return nil
+}
+
+// Events returns a copy of the events published so far
+func (p *MemoryPublisher) Events() []models.OutboxEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]models.OutboxEvent{}, p.events...)
+}
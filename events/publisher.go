@@ -0,0 +1,58 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+)
+
+// Publisher delivers outbox events to the systems interested in them. An event may be published
+// more than once (e.g. when the relay stops before marking it published), so consumers should
+// deduplicate on the event ID.
+type Publisher interface {
+	Publish(ctx context.Context, event models.OutboxEvent) error
+}
+
+// NewOutboxEvent wraps the payload of a domain event so it can be appended to the outbox
+func NewOutboxEvent(eventType models.EventType, aggregateType string, aggregateID string, payload interface{}) (models.OutboxEvent, error) {
+	rawPayload, err := json.Marshal(payload)
+	if err != nil {
+		return models.OutboxEvent{}, err
+	}
+
+	return models.OutboxEvent{
+		EventType:     eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       rawPayload,
+		AvailableAt:   time.Now(),
+	}, nil
+}
+
+// PublisherFunc lets an ordinary function be used as a Publisher
+type PublisherFunc func(ctx context.Context, event models.OutboxEvent) error
+
+func (f PublisherFunc) Publish(ctx context.Context, event models.OutboxEvent) error {
+	return f(ctx, event)
+}
+
+// MultiPublisher publishes every event to all the publishers and stops at the first error, the
+// relay then retries the event on all of them so every publisher has to tolerate duplicates
+type MultiPublisher struct {
+	publishers []Publisher
+}
+
+func NewMultiPublisher(publishers ...Publisher) *MultiPublisher {
+	return &MultiPublisher{publishers: publishers}
+}
+
+func (p *MultiPublisher) Publish(ctx context.Context, event models.OutboxEvent) error {
+	for _, publisher := range p.publishers {
+		if err := publisher.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
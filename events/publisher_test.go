@@ -0,0 +1,113 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewOutboxEvent(t *testing.T) {
+	// Act
+	event, err := NewOutboxEvent(models.EventTypeLoanFullyPaid, "loan", "loan-id", models.LoanFullyPaidEvent{LoanID: "loan-id", BorrowerID: "borrower-id"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, models.EventTypeLoanFullyPaid, event.EventType)
+	assert.Equal(t, "loan", event.AggregateType)
+	assert.Equal(t, "loan-id", event.AggregateID)
+	assert.JSONEq(t, `{"loan_id":"loan-id","borrower_id":"borrower-id","paid_at":"0001-01-01T00:00:00Z"}`, string(event.Payload))
+	assert.False(t, event.AvailableAt.IsZero())
+}
+
+func TestMemoryPublisher(t *testing.T) {
+	// Arrange
+	publisher := NewMemoryPublisher()
+
+	// Act
+	err := publisher.Publish(context.Background(), models.OutboxEvent{ID: "event-1"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []models.OutboxEvent{{ID: "event-1"}}, publisher.Events())
+}
+
+func TestWriterPublisher(t *testing.T) {
+	// Arrange
+	var buffer bytes.Buffer
+	publisher := NewWriterPublisher(&buffer)
+
+	// Act
+	err := publisher.Publish(context.Background(), models.OutboxEvent{ID: "event-1", EventType: models.EventTypeLoanCreated, Payload: json.RawMessage(`{"loan_id":"loan-id"}`)})
+	otherErr := publisher.Publish(context.Background(), models.OutboxEvent{ID: "event-2", EventType: models.EventTypeLoanCreated, Payload: json.RawMessage(`{}`)})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, otherErr)
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	assert.Len(t, lines, 2)
+
+	var published models.OutboxEvent
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &published))
+	assert.Equal(t, "event-1", published.ID)
+	assert.JSONEq(t, `{"loan_id":"loan-id"}`, string(published.Payload))
+}
+
+func TestFilePublisher(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	publisher, err := NewFilePublisher(path)
+	assert.NoError(t, err)
+
+	// Act
+	publishErr := publisher.Publish(context.Background(), models.OutboxEvent{ID: "event-1", Payload: json.RawMessage(`{}`)})
+	closeErr := publisher.Close()
+	closedErr := publisher.Publish(context.Background(), models.OutboxEvent{ID: "event-2", Payload: json.RawMessage(`{}`)})
+
+	// Assert
+	assert.NoError(t, publishErr)
+	assert.NoError(t, closeErr)
+	// not marked as published, the relay of the next start publishes it
+	assert.Error(t, closedErr)
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), `"id":"event-1"`)
+}
+
+func TestMultiPublisher(t *testing.T) {
+	// Arrange
+	first := NewMemoryPublisher()
+	second := NewMemoryPublisher()
+	publisher := NewMultiPublisher(first, second)
+
+	// Act
+	err := publisher.Publish(context.Background(), models.OutboxEvent{ID: "event-1"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, first.Events(), 1)
+	assert.Len(t, second.Events(), 1)
+}
+
+func TestMultiPublisher_StopsAtFirstError(t *testing.T) {
+	// Arrange
+	expectedError := errors.New("unable to publish")
+	last := NewMemoryPublisher()
+	publisher := NewMultiPublisher(PublisherFunc(func(ctx context.Context, event models.OutboxEvent) error {
+		return expectedError
+	}), last)
+
+	// Act
+	err := publisher.Publish(context.Background(), models.OutboxEvent{ID: "event-1"})
+
+	// Assert
+	assert.Equal(t, expectedError, err)
+	assert.Empty(t, last.Events())
+}
@@ -0,0 +1,62 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+)
+
+// WriterPublisher writes every event as one JSON line, to stdout or to a file for local use
+type WriterPublisher struct {
+	mu     sync.Mutex
+	writer io.Writer
+	file   *os.File
+}
+
+func NewWriterPublisher(writer io.Writer) *WriterPublisher {
+	return &WriterPublisher{writer: writer}
+}
+
+func NewStdoutPublisher() *WriterPublisher {
+	return NewWriterPublisher(os.Stdout)
+}
+
+// NewFilePublisher appends the events to the file, it is closed by Close
+func NewFilePublisher(path string) (*WriterPublisher, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &WriterPublisher{writer: file, file: file}, nil
+}
+
+func (p *WriterPublisher) Publish(ctx context.Context, event models.OutboxEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, err = p.writer.Write(append(line, '\n'))
+	return err
+}
+
+// Close flushes the file opened by NewFilePublisher to the disk and closes it, the other writers are
+// left to their owner. The events published afterwards fail, so the relay leaves them in the outbox.
+func (p *WriterPublisher) Close() error {
+	if p.file == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// the relay marked the events written as published, they must not be lost in the page cache
+	return errors.Join(p.file.Sync(), p.file.Close())
+}
@@ -0,0 +1,32 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateAPIKey(t *testing.T) {
+	// Act
+	key, prefix, err := GenerateAPIKey()
+	otherKey, otherPrefix, otherErr := GenerateAPIKey()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, otherErr)
+	assert.NotEqual(t, key, otherKey)
+	assert.NotEqual(t, prefix, otherPrefix)
+
+	parsedPrefix, ok := ParseAPIKeyPrefix(key)
+	assert.True(t, ok)
+	assert.Equal(t, prefix, parsedPrefix)
+	assert.Len(t, HashAPIKey(key), 64)
+	assert.NotEqual(t, HashAPIKey(key), HashAPIKey(otherKey))
+}
+
+func TestParseAPIKeyPrefix_Invalid(t *testing.T) {
+	for _, key := range []string{"", "random", "ak_prefix", "xx_prefix_secret", "ak__secret", "ak_prefix_"} {
+		_, ok := ParseAPIKeyPrefix(key)
+		assert.False(t, ok, key)
+	}
+}
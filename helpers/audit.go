@@ -0,0 +1,66 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+)
+
+const auditRedactedValue = "[redacted]"
+
+// columns holding secrets or personal data, the audit only tells that they changed as its entries
+// can never be removed
+var auditRedactedColumns = map[string]bool{
+	"key_hash":       true,
+	"secret":         true,
+	"nik":            true,
+	"date_of_birth":  true,
+	"address":        true,
+	"monthly_income": true,
+	"phone_number":   true,
+}
+
+// AuditDiff compares two column snapshots and returns the changed columns.
+// A nil snapshot stands for a row that doesn't exist, before a create or after a delete.
+func AuditDiff(before map[string]interface{}, after map[string]interface{}) (map[string]models.AuditChange, error) {
+	columns := map[string]bool{}
+	for column := range before {
+		columns[column] = true
+	}
+	for column := range after {
+		columns[column] = true
+	}
+
+	changes := map[string]models.AuditChange{}
+	for column := range columns {
+		beforeValue, afterValue := before[column], after[column]
+
+		// values are compared the way they end up in the audit entry
+		beforeJSON, err := json.Marshal(beforeValue)
+		if err != nil {
+			return nil, err
+		}
+		afterJSON, err := json.Marshal(afterValue)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Equal(beforeJSON, afterJSON) {
+			continue
+		}
+
+		if auditRedactedColumns[column] {
+			beforeValue, afterValue = redactAuditValue(beforeValue), redactAuditValue(afterValue)
+		}
+		changes[column] = models.AuditChange{Before: beforeValue, After: afterValue}
+	}
+
+	return changes, nil
+}
+
+func redactAuditValue(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	return auditRedactedValue
+}
@@ -0,0 +1,47 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditDiff_Update(t *testing.T) {
+	// Arrange
+	before := map[string]interface{}{"id": "loan-id", "status": "active", "amount": 1000000.0, "key_hash": "old-hash", "secret": "old-secret"}
+	after := map[string]interface{}{"id": "loan-id", "status": "paid", "amount": 1000000.0, "key_hash": "new-hash", "secret": "new-secret"}
+
+	// Act
+	changes, err := AuditDiff(before, after)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]models.AuditChange{
+		"status":   {Before: "active", After: "paid"},
+		"key_hash": {Before: "[redacted]", After: "[redacted]"},
+		"secret":   {Before: "[redacted]", After: "[redacted]"},
+	}, changes)
+}
+
+func TestAuditDiff_Create(t *testing.T) {
+	// Act
+	changes, err := AuditDiff(nil, map[string]interface{}{"id": "borrower-id", "first_name": "John", "phone_number": "+6281234567890", "deleted_at": nil})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]models.AuditChange{
+		"id":           {Before: nil, After: "borrower-id"},
+		"first_name":   {Before: nil, After: "John"},
+		"phone_number": {Before: nil, After: "[redacted]"},
+	}, changes)
+}
+
+func TestAuditDiff_NoChange(t *testing.T) {
+	// Act
+	changes, err := AuditDiff(map[string]interface{}{"status": "active"}, map[string]interface{}{"status": "active"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+}
@@ -0,0 +1,63 @@
+package helpers
+
+import (
+	"context"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+)
+
+type contextKey string
+
+const (
+	principalContextKey contextKey = "principal"
+	requestIDContextKey contextKey = "request_id"
+	primaryContextKey   contextKey = "primary_reads"
+)
+
+func WithPrincipal(ctx context.Context, principal models.Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey, principal)
+}
+
+func PrincipalFromContext(ctx context.Context) (models.Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey).(models.Principal)
+	return principal, ok
+}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey, requestID)
+}
+
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey).(string)
+	return requestID
+}
+
+// WithPrimaryReads sends the reads made with the context to the primary database instead of a
+// replica, for reads which must see a write made just before
+func WithPrimaryReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryContextKey, true)
+}
+
+func ReadsFromPrimary(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryContextKey).(bool)
+	return primary
+}
+
+// CanAccessBorrower tells whether the caller may see the data of the borrower.
+// A context without principal comes from an internal caller (seeder, jobs) and is always allowed.
+func CanAccessBorrower(ctx context.Context, borrowerID string) bool {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok || !principal.IsBorrowerScoped() {
+		return true
+	}
+	return principal.BorrowerID != "" && principal.BorrowerID == borrowerID
+}
+
+// ScopedBorrowerID returns the borrower the caller is limited to, if any
+func ScopedBorrowerID(ctx context.Context) (string, bool) {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok || !principal.IsBorrowerScoped() {
+		return "", false
+	}
+	return principal.BorrowerID, true
+}
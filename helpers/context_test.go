@@ -0,0 +1,19 @@
+package helpers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanAccessBorrower(t *testing.T) {
+	borrowerCtx := WithPrincipal(context.Background(), models.Principal{Role: models.RoleBorrower, BorrowerID: "borrower-id"})
+	adminCtx := WithPrincipal(context.Background(), models.Principal{Role: models.RoleAdmin})
+
+	assert.True(t, CanAccessBorrower(borrowerCtx, "borrower-id"))
+	assert.False(t, CanAccessBorrower(borrowerCtx, "other-borrower-id"))
+	assert.True(t, CanAccessBorrower(adminCtx, "other-borrower-id"))
+	assert.True(t, CanAccessBorrower(context.Background(), "other-borrower-id"))
+}
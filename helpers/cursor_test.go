@@ -0,0 +1,40 @@
+package helpers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeDecodeCursor_RoundTrip(t *testing.T) {
+	// Arrange
+	cursor := models.Cursor{
+		SortField: "amount",
+		Direction: models.SortDirectDescending,
+		Value:     json.RawMessage(`5000000`),
+		ID:        "loan-id",
+	}
+
+	// Act
+	token, err := EncodeCursor(cursor)
+	assert.NoError(t, err)
+	result, err := DecodeCursor(token)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, cursor, result)
+}
+
+func TestDecodeCursor_InvalidToken(t *testing.T) {
+	// Act
+	_, notBase64Err := DecodeCursor("not a cursor!")
+	_, notJSONErr := DecodeCursor("bm90LWpzb24")
+	_, missingIDErr := DecodeCursor("e30")
+
+	// Assert
+	assert.Equal(t, ErrInvalidCursor, notBase64Err)
+	assert.Equal(t, ErrInvalidCursor, notJSONErr)
+	assert.Equal(t, ErrInvalidCursor, missingIDErr)
+}
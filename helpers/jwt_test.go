@@ -0,0 +1,116 @@
+package helpers
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenVerifier_HS256(t *testing.T) {
+	// Arrange
+	verifier, err := NewTokenVerifier(TokenVerifierConfig{Algorithm: JWTAlgorithmHS256, Secret: "secret", Issuer: "amartha"})
+	assert.NoError(t, err)
+	token, err := SignHS256Token("secret", AccessTokenClaims{
+		Role:       models.RoleBorrower,
+		BorrowerID: "borrower-id",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "user-1",
+			Issuer:    "amartha",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	})
+	assert.NoError(t, err)
+
+	// Act
+	principal, err := verifier.Verify(token)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "user-1", principal.Subject)
+	assert.Equal(t, "borrower-id", principal.BorrowerID)
+	assert.True(t, principal.IsBorrowerScoped())
+	assert.Equal(t, models.RolePermissions[models.RoleBorrower], principal.Permissions)
+}
+
+func TestTokenVerifier_RejectsInvalidTokens(t *testing.T) {
+	verifier, err := NewTokenVerifier(TokenVerifierConfig{Algorithm: JWTAlgorithmHS256, Secret: "secret", Issuer: "amartha"})
+	assert.NoError(t, err)
+
+	expiresAt := jwt.NewNumericDate(time.Now().Add(time.Hour))
+	testCases := []struct {
+		name   string
+		secret string
+		claims AccessTokenClaims
+	}{
+		{"wrong secret", "other", AccessTokenClaims{Role: models.RoleAdmin, RegisteredClaims: jwt.RegisteredClaims{Subject: "user-1", Issuer: "amartha", ExpiresAt: expiresAt}}},
+		{"expired", "secret", AccessTokenClaims{Role: models.RoleAdmin, RegisteredClaims: jwt.RegisteredClaims{Subject: "user-1", Issuer: "amartha", ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute))}}},
+		{"without expiry", "secret", AccessTokenClaims{Role: models.RoleAdmin, RegisteredClaims: jwt.RegisteredClaims{Subject: "user-1", Issuer: "amartha"}}},
+		{"wrong issuer", "secret", AccessTokenClaims{Role: models.RoleAdmin, RegisteredClaims: jwt.RegisteredClaims{Subject: "user-1", Issuer: "other", ExpiresAt: expiresAt}}},
+		{"unknown role", "secret", AccessTokenClaims{Role: "superuser", RegisteredClaims: jwt.RegisteredClaims{Subject: "user-1", Issuer: "amartha", ExpiresAt: expiresAt}}},
+		{"borrower without borrower id", "secret", AccessTokenClaims{Role: models.RoleBorrower, RegisteredClaims: jwt.RegisteredClaims{Subject: "user-1", Issuer: "amartha", ExpiresAt: expiresAt}}},
+		{"without subject", "secret", AccessTokenClaims{Role: models.RoleAdmin, RegisteredClaims: jwt.RegisteredClaims{Issuer: "amartha", ExpiresAt: expiresAt}}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Arrange
+			token, err := SignHS256Token(testCase.secret, testCase.claims)
+			assert.NoError(t, err)
+
+			// Act
+			_, err = verifier.Verify(token)
+
+			// Assert
+			assert.True(t, errors.Is(err, ErrInvalidToken))
+		})
+	}
+}
+
+func TestTokenVerifier_RS256(t *testing.T) {
+	// Arrange
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	publicKeyDER, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	assert.NoError(t, err)
+	publicKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyDER})
+
+	verifier, err := NewTokenVerifier(TokenVerifierConfig{Algorithm: JWTAlgorithmRS256, PublicKeyPEM: publicKeyPEM})
+	assert.NoError(t, err)
+
+	claims := AccessTokenClaims{
+		Role:             models.RoleFinance,
+		RegisteredClaims: jwt.RegisteredClaims{Subject: "user-1", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(privateKey)
+	assert.NoError(t, err)
+	// a HS256 token signed with the public key must not be accepted
+	confusedToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(publicKeyPEM)
+	assert.NoError(t, err)
+
+	// Act
+	principal, err := verifier.Verify(token)
+	_, confusedErr := verifier.Verify(confusedToken)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, models.RoleFinance, principal.Role)
+	assert.True(t, errors.Is(confusedErr, ErrInvalidToken))
+}
+
+func TestNewTokenVerifier_InvalidConfig(t *testing.T) {
+	_, missingSecretErr := NewTokenVerifier(TokenVerifierConfig{Algorithm: JWTAlgorithmHS256})
+	_, invalidKeyErr := NewTokenVerifier(TokenVerifierConfig{Algorithm: JWTAlgorithmRS256, PublicKeyPEM: []byte("not a key")})
+	_, unknownAlgorithmErr := NewTokenVerifier(TokenVerifierConfig{Algorithm: "none"})
+
+	assert.Error(t, missingSecretErr)
+	assert.Error(t, invalidKeyErr)
+	assert.Error(t, unknownAlgorithmErr)
+}
@@ -21,6 +21,27 @@ func GetTotalRepaymentAmount(loan *models.Loan) float64 {
 	return loan.Amount + loan.InterestAmount
 }
 
+// LoanRules are the business rules applied to the loans
+type LoanRules struct {
+	// RepaymentDueWindowDays is how many days ahead a schedule is included in the payment link
+	RepaymentDueWindowDays int
+	// DelinquencyOverdueSchedules is the number of overdue schedules making a borrower delinquent
+	DelinquencyOverdueSchedules int
+}
+
+var DefaultLoanRules = LoanRules{
+	RepaymentDueWindowDays:      3,
+	DelinquencyOverdueSchedules: 2,
+}
+
+// ActiveLoanRules are the rules in force, replaced at startup by the configured ones
+var ActiveLoanRules = DefaultLoanRules
+
+// RepaymentDueBefore returns the date up to which the pending schedules are due for repayment
+func (r LoanRules) RepaymentDueBefore(now time.Time) time.Time {
+	return now.AddDate(0, 0, r.RepaymentDueWindowDays)
+}
+
 func IsBorrowerDelinquent(loanSchedules []models.LoanSchedule) bool {
 	if len(loanSchedules) == 0 {
 		return false
@@ -28,10 +49,9 @@ func IsBorrowerDelinquent(loanSchedules []models.LoanSchedule) bool {
 
 	now := time.Now()
 	overdueCount := 0
-	const maxOverdueThreshold = 2
 
 	for _, schedule := range loanSchedules {
-		if overdueCount >= maxOverdueThreshold {
+		if overdueCount >= ActiveLoanRules.DelinquencyOverdueSchedules {
 			return true
 		}
 
@@ -40,5 +60,84 @@ func IsBorrowerDelinquent(loanSchedules []models.LoanSchedule) bool {
 		}
 	}
 
-	return overdueCount >= maxOverdueThreshold
+	return overdueCount >= ActiveLoanRules.DelinquencyOverdueSchedules
+}
+
+// CalculateDPD returns the days past due of the oldest pending schedule that is already overdue
+func CalculateDPD(loanSchedules []models.LoanSchedule, now time.Time) int {
+	var oldestOverdue *time.Time
+	for i, schedule := range loanSchedules {
+		if schedule.Status != models.LoanScheduleStatusPending || !schedule.DueDate.Before(now) {
+			continue
+		}
+		if oldestOverdue == nil || schedule.DueDate.Before(*oldestOverdue) {
+			oldestOverdue = &loanSchedules[i].DueDate
+		}
+	}
+
+	if oldestOverdue == nil {
+		return 0
+	}
+	return int(now.Sub(*oldestOverdue).Hours() / 24)
+}
+
+// DPDBucket is an inclusive range of days past due, MaxDays is -1 when the bucket is open ended
+type DPDBucket struct {
+	MinDays int
+	MaxDays int
+}
+
+var dpdBuckets = map[string]DPDBucket{
+	"current": {MinDays: 0, MaxDays: 0},
+	"1-30":    {MinDays: 1, MaxDays: 30},
+	"31-60":   {MinDays: 31, MaxDays: 60},
+	"61-90":   {MinDays: 61, MaxDays: 90},
+	"90+":     {MinDays: 91, MaxDays: -1},
+}
+
+func GetDPDBucket(name string) (DPDBucket, bool) {
+	bucket, ok := dpdBuckets[name]
+	return bucket, ok
+}
+
+// GetNextDue returns the due date of the next upcoming pending schedule and the amount to be paid by
+// then, which includes every overdue schedule. When every pending schedule is already overdue the
+// oldest overdue due date is returned instead, and nil when there is nothing left to pay.
+func GetNextDue(loanSchedules []models.LoanSchedule, now time.Time) (*time.Time, float64) {
+	var nextDueDate, oldestOverdue *time.Time
+	for i, schedule := range loanSchedules {
+		if schedule.Status != models.LoanScheduleStatusPending {
+			continue
+		}
+		dueDate := &loanSchedules[i].DueDate
+		if dueDate.Before(now) {
+			if oldestOverdue == nil || dueDate.Before(*oldestOverdue) {
+				oldestOverdue = dueDate
+			}
+			continue
+		}
+		if nextDueDate == nil || dueDate.Before(*nextDueDate) {
+			nextDueDate = dueDate
+		}
+	}
+
+	cutoff := now
+	if nextDueDate != nil {
+		cutoff = *nextDueDate
+	} else {
+		nextDueDate = oldestOverdue
+	}
+	if nextDueDate == nil {
+		return nil, 0
+	}
+
+	var amount float64
+	for _, schedule := range loanSchedules {
+		if schedule.Status == models.LoanScheduleStatusPending && !schedule.DueDate.After(cutoff) {
+			amount += schedule.TotalPayment
+		}
+	}
+
+	dueDate := *nextDueDate
+	return &dueDate, amount
 }
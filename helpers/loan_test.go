@@ -372,3 +372,94 @@ func TestIsBorrowerDelinquent_WithMixedStatuses(t *testing.T) {
 	// Assert
 	assert.True(t, result, "Borrower should be delinquent with 2 overdue payments (2 pending + 1 paid)")
 }
+
+func TestCalculateDPD_UsesOldestOverduePendingSchedule(t *testing.T) {
+	// Arrange
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	loanSchedules := []models.LoanSchedule{
+		{Status: models.LoanScheduleStatusPaid, DueDate: now.AddDate(0, 0, -30)},
+		{Status: models.LoanScheduleStatusPending, DueDate: now.AddDate(0, 0, -7)},
+		{Status: models.LoanScheduleStatusPending, DueDate: now.AddDate(0, 0, -14)},
+		{Status: models.LoanScheduleStatusPending, DueDate: now.AddDate(0, 0, 7)},
+	}
+
+	// Act
+	result := CalculateDPD(loanSchedules, now)
+
+	// Assert
+	assert.Equal(t, 14, result)
+}
+
+func TestCalculateDPD_NoOverdueSchedules(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	loanSchedules := []models.LoanSchedule{
+		{Status: models.LoanScheduleStatusPaid, DueDate: now.AddDate(0, 0, -7)},
+		{Status: models.LoanScheduleStatusPending, DueDate: now.AddDate(0, 0, 7)},
+	}
+
+	// Act
+	result := CalculateDPD(loanSchedules, now)
+
+	// Assert
+	assert.Equal(t, 0, result)
+}
+
+func TestGetDPDBucket(t *testing.T) {
+	// Act
+	bucket, ok := GetDPDBucket("31-60")
+	_, unknownOk := GetDPDBucket("1-7")
+
+	// Assert
+	assert.True(t, ok)
+	assert.Equal(t, DPDBucket{MinDays: 31, MaxDays: 60}, bucket)
+	assert.False(t, unknownOk)
+}
+
+func TestGetNextDue_IncludesOverdueSchedules(t *testing.T) {
+	// Arrange
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	loanSchedules := []models.LoanSchedule{
+		{TotalPayment: 110000, Status: models.LoanScheduleStatusPaid, DueDate: now.AddDate(0, 0, -14)},
+		{TotalPayment: 110000, Status: models.LoanScheduleStatusPending, DueDate: now.AddDate(0, 0, -7)},
+		{TotalPayment: 110000, Status: models.LoanScheduleStatusPending, DueDate: now.AddDate(0, 0, 14)},
+		{TotalPayment: 110000, Status: models.LoanScheduleStatusPending, DueDate: now.AddDate(0, 0, 7)},
+	}
+
+	// Act
+	dueDate, amount := GetNextDue(loanSchedules, now)
+
+	// Assert
+	assert.Equal(t, now.AddDate(0, 0, 7), *dueDate)
+	assert.Equal(t, 220000.0, amount)
+}
+
+func TestGetNextDue_OnlyOverdueSchedules(t *testing.T) {
+	// Arrange
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	loanSchedules := []models.LoanSchedule{
+		{TotalPayment: 110000, Status: models.LoanScheduleStatusPending, DueDate: now.AddDate(0, 0, -7)},
+		{TotalPayment: 110000, Status: models.LoanScheduleStatusPending, DueDate: now.AddDate(0, 0, -14)},
+	}
+
+	// Act
+	dueDate, amount := GetNextDue(loanSchedules, now)
+
+	// Assert
+	assert.Equal(t, now.AddDate(0, 0, -14), *dueDate)
+	assert.Equal(t, 220000.0, amount)
+}
+
+func TestGetNextDue_FullyPaid(t *testing.T) {
+	// Arrange
+	loanSchedules := []models.LoanSchedule{
+		{TotalPayment: 110000, Status: models.LoanScheduleStatusPaid, DueDate: time.Now()},
+	}
+
+	// Act
+	dueDate, amount := GetNextDue(loanSchedules, time.Now())
+
+	// Assert
+	assert.Nil(t, dueDate)
+	assert.Equal(t, 0.0, amount)
+}
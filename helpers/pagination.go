@@ -0,0 +1,35 @@
+package helpers
+
+import "github.com/satryarangga/amartha-loan-engine/models"
+
+const (
+	DefaultPageLimit = 10
+	MaxPageLimit     = 100
+)
+
+// NormalizePage fills in the defaults of a requested page and caps the limit
+func NormalizePage(page int, limit int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = DefaultPageLimit
+	}
+	if limit > MaxPageLimit {
+		limit = MaxPageLimit
+	}
+	return page, limit
+}
+
+func NewPagination(page int, limit int, totalItems int64) models.Pagination {
+	totalPages := 0
+	if limit > 0 {
+		totalPages = int((totalItems + int64(limit) - 1) / int64(limit))
+	}
+	return models.Pagination{
+		Page:       page,
+		Limit:      limit,
+		TotalItems: totalItems,
+		TotalPages: totalPages,
+	}
+}
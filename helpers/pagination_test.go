@@ -0,0 +1,44 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizePage_Defaults(t *testing.T) {
+	// Act
+	page, limit := NormalizePage(0, 0)
+
+	// Assert
+	assert.Equal(t, 1, page)
+	assert.Equal(t, DefaultPageLimit, limit)
+}
+
+func TestNormalizePage_CapsLimit(t *testing.T) {
+	// Act
+	page, limit := NormalizePage(3, 1000)
+
+	// Assert
+	assert.Equal(t, 3, page)
+	assert.Equal(t, MaxPageLimit, limit)
+}
+
+func TestNewPagination_RoundsTotalPagesUp(t *testing.T) {
+	// Act
+	result := NewPagination(2, 10, 21)
+
+	// Assert
+	assert.Equal(t, 2, result.Page)
+	assert.Equal(t, 10, result.Limit)
+	assert.Equal(t, int64(21), result.TotalItems)
+	assert.Equal(t, 3, result.TotalPages)
+}
+
+func TestNewPagination_NoItems(t *testing.T) {
+	// Act
+	result := NewPagination(1, 10, 0)
+
+	// Assert
+	assert.Equal(t, 0, result.TotalPages)
+}
@@ -0,0 +1,23 @@
+package helpers
+
+import "time"
+
+// ExponentialBackoff returns the delay before the given attempt (starting at 1), doubling from
+// base on every attempt up to max
+func ExponentialBackoff(base time.Duration, max time.Duration, attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	delay := base
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	if delay > max {
+		return max
+	}
+	return delay
+}
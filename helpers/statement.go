@@ -0,0 +1,248 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/satryarangga/amartha-loan-engine/models"
+)
+
+// entries happening at the same time are ordered the way they happen in real life
+var statementEntryOrder = map[models.StatementEntryType]int{
+	models.StatementEntryTypeDisbursement: 0,
+	models.StatementEntryTypeInterest:     1,
+	models.StatementEntryTypePayment:      2,
+	models.StatementEntryTypeInstallment:  3,
+}
+
+// BuildLoanStatement builds the ledger of a loan and keeps the entries happening within [from, to).
+// A nil from starts the period at the disbursement. The returned bool is false when the loan has
+// nothing to show for the period, i.e. it was disbursed after it or fully repaid before it.
+// There are no penalty entries: late installments aren't charged any penalty by the loan engine,
+// overdue schedules are only flagged, so the ledger has nothing to report until penalties are modelled.
+func BuildLoanStatement(loan models.Loan, from *time.Time, to time.Time) (models.LoanStatementResponse, bool) {
+	entries := []models.StatementEntryResponse{
+		{
+			Date:        loan.DisbursedAt,
+			Type:        models.StatementEntryTypeDisbursement,
+			Reference:   loan.ID,
+			Description: "Loan disbursement",
+			Debit:       loan.Amount,
+		},
+		{
+			Date:        loan.DisbursedAt,
+			Type:        models.StatementEntryTypeInterest,
+			Reference:   loan.ID,
+			Description: fmt.Sprintf("Interest %s%%", strconv.FormatFloat(loan.InterestPercentage, 'f', -1, 64)),
+			Debit:       loan.InterestAmount,
+		},
+	}
+
+	loanSchedules := append([]models.LoanSchedule{}, loan.LoanSchedules...)
+	sort.SliceStable(loanSchedules, func(i, j int) bool {
+		return loanSchedules[i].DueDate.Before(loanSchedules[j].DueDate)
+	})
+	for i, schedule := range loanSchedules {
+		entries = append(entries, models.StatementEntryResponse{
+			Date:        schedule.DueDate,
+			Type:        models.StatementEntryTypeInstallment,
+			Reference:   schedule.ID,
+			Description: fmt.Sprintf("Installment %d of %d (%s)", i+1, len(loanSchedules), schedule.Status),
+			DueAmount:   schedule.TotalPayment,
+		})
+	}
+
+	for _, payment := range loan.LoanPayments {
+		if payment.Status != models.LoanPaymentStatusPaid {
+			continue
+		}
+		paidAt := payment.UpdatedAt
+		if payment.PaidAt != nil {
+			paidAt = *payment.PaidAt
+		}
+		entries = append(entries, models.StatementEntryResponse{
+			Date:        paidAt,
+			Type:        models.StatementEntryTypePayment,
+			Reference:   payment.ID,
+			Description: "Payment via " + payment.PaymentMethod,
+			Credit:      payment.TotalPayment,
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].Date.Equal(entries[j].Date) {
+			return entries[i].Date.Before(entries[j].Date)
+		}
+		return statementEntryOrder[entries[i].Type] < statementEntryOrder[entries[j].Type]
+	})
+
+	statement := models.LoanStatementResponse{
+		LoanID:      loan.ID,
+		ProductCode: loan.ProductCode,
+		DisbursedAt: loan.DisbursedAt,
+		Status:      loan.Status,
+		Entries:     []models.StatementEntryResponse{},
+	}
+
+	var balance float64
+	for _, entry := range entries {
+		balance += entry.Debit - entry.Credit
+		entry.Balance = balance
+
+		if !entry.Date.Before(to) {
+			break
+		}
+		if from != nil && entry.Date.Before(*from) {
+			statement.OpeningBalance = balance
+			continue
+		}
+
+		statement.TotalDebit += entry.Debit
+		statement.TotalCredit += entry.Credit
+		statement.Entries = append(statement.Entries, entry)
+	}
+	statement.ClosingBalance = statement.OpeningBalance + statement.TotalDebit - statement.TotalCredit
+
+	hasActivity := len(statement.Entries) > 0 || statement.OpeningBalance != 0
+	return statement, hasActivity
+}
+
+// RenderStatementCSV renders one row per entry, surrounded by the opening and closing balance of each loan
+func RenderStatementCSV(statement models.StatementResponse) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+
+	rows := [][]string{{"loan_id", "date", "type", "reference", "description", "due_amount", "debit", "credit", "balance"}}
+	for _, loan := range statement.Loans {
+		rows = append(rows, []string{loan.LoanID, formatStatementDate(statementPeriodStart(statement, loan)), "opening_balance", "", "Opening balance", "", "", "", formatAmount(loan.OpeningBalance)})
+		for _, entry := range loan.Entries {
+			rows = append(rows, []string{
+				loan.LoanID,
+				formatStatementDate(entry.Date),
+				string(entry.Type),
+				escapeCSVFormula(entry.Reference),
+				escapeCSVFormula(entry.Description),
+				formatOptionalAmount(entry.DueAmount),
+				formatOptionalAmount(entry.Debit),
+				formatOptionalAmount(entry.Credit),
+				formatAmount(entry.Balance),
+			})
+		}
+		rows = append(rows, []string{loan.LoanID, formatStatementDate(statement.To), "closing_balance", "", "Closing balance", "", formatAmount(loan.TotalDebit), formatAmount(loan.TotalCredit), formatAmount(loan.ClosingBalance)})
+	}
+
+	if err := writer.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// escapeCSVFormula prefixes the text starting like a formula with a quote, so a spreadsheet opening
+// the statement shows it instead of evaluating it. The payment method in the descriptions comes from the client.
+func escapeCSVFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// RenderStatementPDF renders the statement with the core PDF fonts only, so no font files nor external services are needed
+func RenderStatementPDF(statement models.StatementResponse) ([]byte, error) {
+	pdf := fpdf.New("L", "mm", "A4", "")
+	pdf.SetTitle("Statement of Account", false)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AddPage()
+
+	borrowerName := statement.Borrower.FirstName + " " + statement.Borrower.LastName
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 10, "Statement of Account", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(0, 6, fmt.Sprintf("Borrower: %s (%s)", borrowerName, statement.Borrower.ID), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 6, fmt.Sprintf("Phone number: %s", statement.Borrower.PhoneNumber), "", 1, "L", false, 0, "")
+	periodStart := "first disbursement"
+	if statement.From != nil {
+		periodStart = formatStatementDate(*statement.From)
+	}
+	pdf.CellFormat(0, 6, fmt.Sprintf("Period: %s until %s", periodStart, formatStatementDate(statement.To)), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 6, fmt.Sprintf("Generated at: %s", statement.GeneratedAt.Format(time.RFC3339)), "", 1, "L", false, 0, "")
+
+	if len(statement.Loans) == 0 {
+		pdf.Ln(4)
+		pdf.CellFormat(0, 6, "No loan activity in this period.", "", 1, "L", false, 0, "")
+	}
+
+	headers := []string{"Date", "Type", "Description", "Due Amount", "Debit", "Credit", "Balance"}
+	widths := []float64{25, 28, 92, 32, 32, 32, 36}
+	for _, loan := range statement.Loans {
+		pdf.Ln(4)
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.CellFormat(0, 7, fmt.Sprintf("Loan %s - %s (%s), disbursed %s", loan.LoanID, loan.ProductCode, loan.Status, formatStatementDate(loan.DisbursedAt)), "", 1, "L", false, 0, "")
+
+		pdf.SetFont("Helvetica", "B", 9)
+		for i, header := range headers {
+			pdf.CellFormat(widths[i], 7, header, "1", 0, "C", false, 0, "")
+		}
+		pdf.Ln(-1)
+
+		pdf.SetFont("Helvetica", "", 9)
+		writeStatementPDFRow(pdf, widths, []string{formatStatementDate(statementPeriodStart(statement, loan)), "", "Opening balance", "", "", "", formatAmount(loan.OpeningBalance)})
+		for _, entry := range loan.Entries {
+			writeStatementPDFRow(pdf, widths, []string{
+				formatStatementDate(entry.Date),
+				string(entry.Type),
+				entry.Description,
+				formatOptionalAmount(entry.DueAmount),
+				formatOptionalAmount(entry.Debit),
+				formatOptionalAmount(entry.Credit),
+				formatAmount(entry.Balance),
+			})
+		}
+		pdf.SetFont("Helvetica", "B", 9)
+		writeStatementPDFRow(pdf, widths, []string{formatStatementDate(statement.To), "", "Closing balance", "", formatAmount(loan.TotalDebit), formatAmount(loan.TotalCredit), formatAmount(loan.ClosingBalance)})
+	}
+
+	var buffer bytes.Buffer
+	if err := pdf.Output(&buffer); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func writeStatementPDFRow(pdf *fpdf.Fpdf, widths []float64, values []string) {
+	for i, value := range values {
+		align := "R"
+		if i < 3 {
+			align = "L"
+		}
+		pdf.CellFormat(widths[i], 6, value, "1", 0, align, false, 0, "")
+	}
+	pdf.Ln(-1)
+}
+
+func statementPeriodStart(statement models.StatementResponse, loan models.LoanStatementResponse) time.Time {
+	if statement.From != nil {
+		return *statement.From
+	}
+	return loan.DisbursedAt
+}
+
+func formatStatementDate(date time.Time) string {
+	return date.Format(time.DateOnly)
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+func formatOptionalAmount(amount float64) string {
+	if amount == 0 {
+		return ""
+	}
+	return formatAmount(amount)
+}
@@ -0,0 +1,196 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func newStatementTestLoan() models.Loan {
+	disbursedAt := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	paidAt := disbursedAt.AddDate(0, 0, 6)
+	return models.Loan{
+		ID:                 "loan-id",
+		Amount:             200000,
+		InterestPercentage: 10,
+		InterestAmount:     20000,
+		ProductCode:        "standard",
+		Status:             models.LoanStatusActive,
+		DisbursedAt:        disbursedAt,
+		LoanSchedules: []models.LoanSchedule{
+			{ID: "schedule-2", DueDate: disbursedAt.AddDate(0, 0, 14), TotalPayment: 110000, Status: models.LoanScheduleStatusPending},
+			{ID: "schedule-1", DueDate: disbursedAt.AddDate(0, 0, 7), TotalPayment: 110000, Status: models.LoanScheduleStatusPaid},
+		},
+		LoanPayments: []models.LoanPayment{
+			{ID: "payment-1", TotalPayment: 110000, PaymentMethod: "bank_transfer", Status: models.LoanPaymentStatusPaid, PaidAt: &paidAt, UpdatedAt: disbursedAt.AddDate(0, 0, 20)},
+			{ID: "payment-2", TotalPayment: 110000, PaymentMethod: "bank_transfer", Status: models.LoanPaymentStatusPending, UpdatedAt: disbursedAt.AddDate(0, 0, 13)},
+		},
+	}
+}
+
+func TestBuildLoanStatement_WholeHistory(t *testing.T) {
+	// Arrange
+	loan := newStatementTestLoan()
+	to := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	// Act
+	statement, hasActivity := BuildLoanStatement(loan, nil, to)
+
+	// Assert
+	assert.True(t, hasActivity)
+	assert.Equal(t, 0.0, statement.OpeningBalance)
+	assert.Equal(t, 220000.0, statement.TotalDebit)
+	assert.Equal(t, 110000.0, statement.TotalCredit)
+	assert.Equal(t, 110000.0, statement.ClosingBalance)
+
+	types := []models.StatementEntryType{}
+	references := []string{}
+	for _, entry := range statement.Entries {
+		types = append(types, entry.Type)
+		references = append(references, entry.Reference)
+	}
+	assert.Equal(t, []models.StatementEntryType{
+		models.StatementEntryTypeDisbursement,
+		models.StatementEntryTypeInterest,
+		models.StatementEntryTypePayment,
+		models.StatementEntryTypeInstallment,
+		models.StatementEntryTypeInstallment,
+	}, types)
+	assert.Equal(t, []string{"loan-id", "loan-id", "payment-1", "schedule-1", "schedule-2"}, references)
+	assert.Equal(t, 110000.0, statement.Entries[2].Balance)
+	assert.Equal(t, "Installment 1 of 2 (paid)", statement.Entries[3].Description)
+	assert.Equal(t, 110000.0, statement.Entries[3].DueAmount)
+}
+
+func TestBuildLoanStatement_WithinPeriod(t *testing.T) {
+	// Arrange
+	loan := newStatementTestLoan()
+	from := time.Date(2025, 1, 7, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 9, 0, 0, 0, 0, time.UTC)
+
+	// Act
+	statement, hasActivity := BuildLoanStatement(loan, &from, to)
+
+	// Assert
+	assert.True(t, hasActivity)
+	assert.Equal(t, 220000.0, statement.OpeningBalance)
+	assert.Len(t, statement.Entries, 2)
+	assert.Equal(t, models.StatementEntryTypePayment, statement.Entries[0].Type)
+	assert.Equal(t, models.StatementEntryTypeInstallment, statement.Entries[1].Type)
+	assert.Equal(t, 0.0, statement.TotalDebit)
+	assert.Equal(t, 110000.0, statement.TotalCredit)
+	assert.Equal(t, 110000.0, statement.ClosingBalance)
+}
+
+func TestBuildLoanStatement_DisbursedAfterPeriod(t *testing.T) {
+	// Arrange
+	loan := newStatementTestLoan()
+	to := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	// Act
+	statement, hasActivity := BuildLoanStatement(loan, nil, to)
+
+	// Assert
+	assert.False(t, hasActivity)
+	assert.Empty(t, statement.Entries)
+	assert.Equal(t, 0.0, statement.ClosingBalance)
+}
+
+func TestBuildLoanStatement_RepaidBeforePeriod(t *testing.T) {
+	// Arrange
+	loan := models.Loan{
+		ID:          "loan-id",
+		Amount:      100000,
+		Status:      models.LoanStatusPaid,
+		DisbursedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		LoanPayments: []models.LoanPayment{
+			{ID: "payment-1", TotalPayment: 100000, Status: models.LoanPaymentStatusPaid, UpdatedAt: time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	// Act
+	_, hasActivity := BuildLoanStatement(loan, &from, to)
+
+	// Assert
+	assert.False(t, hasActivity)
+}
+
+func TestRenderStatementCSV(t *testing.T) {
+	// Arrange
+	loan := newStatementTestLoan()
+	to := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	loanStatement, _ := BuildLoanStatement(loan, nil, to.AddDate(0, 0, 1))
+	statement := models.StatementResponse{
+		Borrower: models.BorrowerResponse{ID: "borrower-id"},
+		To:       to,
+		Loans:    []models.LoanStatementResponse{loanStatement},
+	}
+
+	// Act
+	content, err := RenderStatementCSV(statement)
+
+	// Assert
+	assert.NoError(t, err)
+	rows, err := csv.NewReader(bytes.NewReader(content)).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, rows, 8) // header + opening + 5 entries + closing
+	assert.Equal(t, []string{"loan_id", "date", "type", "reference", "description", "due_amount", "debit", "credit", "balance"}, rows[0])
+	assert.Equal(t, []string{"loan-id", "2025-01-01", "opening_balance", "", "Opening balance", "", "", "", "0.00"}, rows[1])
+	assert.Equal(t, []string{"loan-id", "2025-01-01", "disbursement", "loan-id", "Loan disbursement", "", "200000.00", "", "200000.00"}, rows[2])
+	assert.Equal(t, []string{"loan-id", "2025-01-31", "closing_balance", "", "Closing balance", "", "220000.00", "110000.00", "110000.00"}, rows[7])
+}
+
+func TestRenderStatementCSV_EscapesFormulas(t *testing.T) {
+	// Arrange
+	loan := newStatementTestLoan()
+	loan.LoanPayments[0].PaymentMethod = "=HYPERLINK(\"https://attacker.example.com\")"
+	to := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	loanStatement, _ := BuildLoanStatement(loan, nil, to)
+	loanStatement.Entries[0].Description = "@SUM(1+1)"
+	statement := models.StatementResponse{To: to, Loans: []models.LoanStatementResponse{loanStatement}}
+
+	// Act
+	content, err := RenderStatementCSV(statement)
+
+	// Assert
+	assert.NoError(t, err)
+	rows, err := csv.NewReader(bytes.NewReader(content)).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, "'@SUM(1+1)", rows[2][4])
+	// the formula has to start the cell to be evaluated
+	assert.Equal(t, "Payment via =HYPERLINK(\"https://attacker.example.com\")", rows[4][4])
+}
+
+func TestEscapeCSVFormula(t *testing.T) {
+	for _, value := range []string{"=1+1", "+1", "-1", "@SUM(A1)", "\t=1", "\r=1"} {
+		assert.Equal(t, "'"+value, escapeCSVFormula(value))
+	}
+	assert.Equal(t, "Payment via bank_transfer", escapeCSVFormula("Payment via bank_transfer"))
+	assert.Equal(t, "", escapeCSVFormula(""))
+}
+
+func TestRenderStatementPDF(t *testing.T) {
+	// Arrange
+	loan := newStatementTestLoan()
+	to := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	loanStatement, _ := BuildLoanStatement(loan, nil, to.AddDate(0, 0, 1))
+	statement := models.StatementResponse{
+		Borrower:    models.BorrowerResponse{ID: "borrower-id", FirstName: "John", LastName: "Doe"},
+		To:          to,
+		GeneratedAt: to,
+		Loans:       []models.LoanStatementResponse{loanStatement},
+	}
+
+	// Act
+	content, err := RenderStatementPDF(statement)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(content, []byte("%PDF-")))
+}
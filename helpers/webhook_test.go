@@ -0,0 +1,82 @@
+package helpers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignWebhookPayload(t *testing.T) {
+	// Arrange
+	timestamp := time.Unix(1700000000, 0)
+	body := []byte(`{"id":"event-id"}`)
+	mac := hmac.New(sha256.New, []byte("partner-secret"))
+	mac.Write([]byte(`1700000000.{"id":"event-id"}`))
+
+	// Act
+	signature := SignWebhookPayload("partner-secret", timestamp, body)
+
+	// Assert
+	assert.Equal(t, "t=1700000000,v1="+hex.EncodeToString(mac.Sum(nil)), signature)
+	assert.NotEqual(t, signature, SignWebhookPayload("other-secret", timestamp, body))
+	assert.NotEqual(t, signature, SignWebhookPayload("partner-secret", timestamp.Add(time.Second), body))
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	assert.Equal(t, time.Second, ExponentialBackoff(time.Second, time.Minute, 0))
+	assert.Equal(t, time.Second, ExponentialBackoff(time.Second, time.Minute, 1))
+	assert.Equal(t, 8*time.Second, ExponentialBackoff(time.Second, time.Minute, 4))
+	assert.Equal(t, time.Minute, ExponentialBackoff(time.Second, time.Minute, 7))
+	assert.Equal(t, time.Minute, ExponentialBackoff(time.Second, time.Minute, 1000))
+}
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		ip     string
+		public bool
+	}{
+		{ip: "93.184.216.34", public: true},
+		{ip: "2606:2800:220:1:248:1893:25c8:1946", public: true},
+		{ip: "127.0.0.1", public: false},
+		{ip: "::1", public: false},
+		{ip: "10.1.2.3", public: false},
+		{ip: "172.16.0.1", public: false},
+		{ip: "192.168.1.1", public: false},
+		{ip: "169.254.169.254", public: false},
+		{ip: "fe80::1", public: false},
+		{ip: "fd00::1", public: false},
+		{ip: "100.64.0.1", public: false},
+		{ip: "0.0.0.0", public: false},
+		{ip: "224.0.0.1", public: false},
+		{ip: "::ffff:127.0.0.1", public: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			assert.Equal(t, tt.public, IsPublicIP(net.ParseIP(tt.ip)))
+		})
+	}
+}
+
+func TestNewWebhookHTTPClient_RefusesLoopback(t *testing.T) {
+	// Arrange
+	var called bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	// Act
+	_, err := NewWebhookHTTPClient(time.Second).Get(server.URL)
+
+	// Assert
+	assert.ErrorContains(t, err, "webhook address 127.0.0.1 is not public")
+	assert.False(t, called)
+}
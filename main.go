@@ -2,15 +2,31 @@ package main
 
 import (
 	"context"
-	"log"
+	"errors"
+	"io"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/satryarangga/amartha-loan-engine/cache"
 	"github.com/satryarangga/amartha-loan-engine/config"
 	"github.com/satryarangga/amartha-loan-engine/controllers"
+	migration "github.com/satryarangga/amartha-loan-engine/database/migration"
+	"github.com/satryarangga/amartha-loan-engine/events"
+	"github.com/satryarangga/amartha-loan-engine/helpers"
+	"github.com/satryarangga/amartha-loan-engine/middlewares"
+	"github.com/satryarangga/amartha-loan-engine/models"
 	"github.com/satryarangga/amartha-loan-engine/repositories"
 	"github.com/satryarangga/amartha-loan-engine/services"
+	"github.com/satryarangga/amartha-loan-engine/storage"
+	"github.com/satryarangga/amartha-loan-engine/workers"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 
 	"github.com/gin-gonic/gin"
 	_ "github.com/satryarangga/amartha-loan-engine/docs"
@@ -28,22 +44,60 @@ import (
 // @name Authorization
 // @description Type "Bearer" followed by a space and JWT token.
 
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description API key of a server-to-server client.
+
 func main() {
 
 	// Initialize logger
 	logger := config.NewLogger()
-	ctx := context.Background()
+	// cancelled on SIGINT / SIGTERM, which starts the graceful shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	conf, err := config.NewConfig()
 	if err != nil {
-		logger.Errorf(ctx, "Unable to initialize config. Error: %v", err)
+		logger.Fatalf(ctx, "Unable to initialize config. Error: %v", err)
 	}
 	config.Config = conf
+	if err := logger.SetLevel(conf.LogLevel); err != nil {
+		logger.Fatalf(ctx, "Invalid log level: %v", err)
+	}
+	config.Logger = logger
+	helpers.ActiveLoanRules = helpers.LoanRules{
+		RepaymentDueWindowDays:      conf.LoanRepaymentDueWindowDays,
+		DelinquencyOverdueSchedules: conf.LoanDelinquencyOverdueSchedules,
+	}
+
+	// Initialize tracing, the spans still buffered are flushed when the server stops
+	shutdownTracing, err := config.NewTracerProvider(ctx, conf)
+	if err != nil {
+		logger.Fatalf(ctx, "Failed to initialize tracing: %v", err)
+	}
+
+	// Apply the pending migrations before the database is used, the instances booting at the same time
+	// take turns on the migration lock
+	if conf.DBMigrateOnBoot {
+		migrationDB, err := config.OpenMigrationDB(conf)
+		if err != nil {
+			logger.Fatalf(ctx, "Failed to connect to database: %v", err)
+		}
+		results, err := migration.Up(ctx, migrationDB)
+		_ = migrationDB.Close()
+		if err != nil {
+			logger.Fatalf(ctx, "Failed to migrate the database: %v", err)
+		}
+		for _, result := range results {
+			logger.Infof(ctx, "Applied migration %s in %s", result.Source.Path, result.Duration)
+		}
+	}
 
 	// Initialize database
-	db, err := config.InitDB()
+	db, err := config.InitDB(conf, &logger)
 	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+		logger.Fatalf(ctx, "Failed to connect to database: %v", err)
 	}
 
 	// Initialize repositories
@@ -51,19 +105,102 @@ func main() {
 	loanRepo := repositories.NewLoanRepository(db)
 	loanScheduleRepo := repositories.NewLoanScheduleRepository(db)
 	loanPaymentRepo := repositories.NewLoanPaymentRepository(db)
+	kycProfileRepo := repositories.NewBorrowerKYCProfileRepository(db)
+	documentRepo := repositories.NewBorrowerDocumentRepository(db)
+	apiKeyRepo := repositories.NewAPIKeyRepository(db)
+	auditLogRepo := repositories.NewAuditLogRepository(db)
+	outboxRepo := repositories.NewOutboxRepository(db)
+	webhookSubscriptionRepo := repositories.NewWebhookSubscriptionRepository(db)
+	webhookDeliveryRepo := repositories.NewWebhookDeliveryRepository(db)
+	idempotencyKeyRepo := repositories.NewIdempotencyKeyRepository(db)
+	healthRepo := repositories.NewHealthRepository(db)
+
+	// Initialize blob storage
+	blobStorage, err := storage.NewLocalBlobStorage(conf.StorageLocalDir)
+	if err != nil {
+		logger.Fatalf(ctx, "Failed to initialize blob storage: %v", err)
+	}
+
+	// Initialize access token verifier
+	tokenVerifier, err := config.NewTokenVerifier(conf)
+	if err != nil {
+		logger.Fatalf(ctx, "Failed to initialize access token verifier: %v", err)
+	}
+
+	// Initialize domain events publisher
+	eventPublisher, err := config.NewEventPublisher(conf)
+	if err != nil {
+		logger.Fatalf(ctx, "Failed to initialize event publisher: %v", err)
+	}
+
+	// Initialize the cache of the hot reads
+	responseCache, err := config.NewCache(ctx, conf)
+	if err != nil {
+		logger.Fatalf(ctx, "Failed to initialize cache: %v", err)
+	}
+	responses := cache.NewResponses(responseCache, conf.CacheTTL)
+
+	// Readiness fails until the database is migrated up to the last migration of this build
+	schemaVersion, err := migration.LatestVersion()
+	if err != nil {
+		logger.Fatalf(ctx, "Failed to read the migrations: %v", err)
+	}
 
 	// Initialize services
-	borrowerService := services.NewBorrowerService(borrowerRepo, loanRepo)
-	loanService := services.NewLoanService(loanRepo, loanScheduleRepo, borrowerRepo)
-	paymentService := services.NewPaymentService(loanRepo, loanPaymentRepo, loanScheduleRepo, borrowerRepo)
+	borrowerService := services.NewBorrowerService(borrowerRepo, loanRepo, outboxRepo, responses)
+	loanService := services.NewLoanService(loanRepo, loanScheduleRepo, borrowerRepo, outboxRepo, responses)
+	paymentService := services.NewPaymentService(loanRepo, loanPaymentRepo, loanScheduleRepo, borrowerRepo, outboxRepo, responses, conf.PaymentLinkBaseURL)
+	kycService := services.NewKYCService(borrowerRepo, kycProfileRepo, documentRepo, blobStorage, responses)
+	statementService := services.NewStatementService(borrowerRepo, loanRepo)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo)
+	auditLogService := services.NewAuditLogService(auditLogRepo)
+	webhookService := services.NewWebhookService(webhookSubscriptionRepo, webhookDeliveryRepo, borrowerRepo, helpers.NewWebhookHTTPClient(conf.WebhookTimeout), conf.WebhookMaxAttempts)
+	healthService := services.NewHealthService(healthRepo, schemaVersion, &logger)
+
+	// Start background workers, the relay also fans the events out to the webhook subscriptions and
+	// evicts the cached responses again, in case a read raced with the commit of the event
+	relayPublisher := events.NewMultiPublisher(eventPublisher, events.PublisherFunc(webhookService.EnqueueDeliveries), responses)
+	// they stop on the shutdown signal, a batch cut off midway is rolled back and picked up again
+	backgroundWorkers := workers.NewGroup()
+	backgroundWorkers.Start(ctx, workers.NewOutboxRelay(outboxRepo, relayPublisher, conf.OutboxBatchSize, conf.OutboxRelayInterval, &logger))
+	backgroundWorkers.Start(ctx, workers.NewOverdueScanner(loanService, conf.OutboxBatchSize, conf.OverdueScanInterval, &logger))
+	backgroundWorkers.Start(ctx, workers.NewWebhookDispatcher(webhookService, conf.OutboxBatchSize, conf.WebhookDeliveryInterval, &logger))
+	backgroundWorkers.Start(ctx, workers.NewIdempotencyKeyCleaner(idempotencyKeyRepo, conf.IdempotencyCleanupInterval, &logger))
+	backgroundWorkers.Start(ctx, workers.NewPortfolioMetricsRefresher(loanService, conf.PortfolioMetricsInterval, &logger))
 
 	// Initialize controllers
 	borrowerController := controllers.NewBorrowerController(borrowerService)
 	loanController := controllers.NewLoanController(loanService)
 	paymentController := controllers.NewPaymentController(paymentService)
+	kycController := controllers.NewKYCController(kycService)
+	statementController := controllers.NewStatementController(statementService)
+	apiKeyController := controllers.NewAPIKeyController(apiKeyService)
+	auditLogController := controllers.NewAuditLogController(auditLogService)
+	webhookController := controllers.NewWebhookController(webhookService)
+	healthController := controllers.NewHealthController(healthService)
+	adminController := controllers.NewAdminController(conf)
 
 	// Setup router
-	r := gin.Default()
+	r := gin.New()
+	r.Use(gin.Recovery())
+	// lets services read the principal set by the auth middleware from the gin context
+	r.ContextWithFallback = true
+	// the client IP is used to restrict API keys, so X-Forwarded-For is only trusted from our own proxies
+	if err := r.SetTrustedProxies(conf.TrustedProxies()); err != nil {
+		logger.Fatalf(ctx, "Failed to set trusted proxies: %v", err)
+	}
+	// Probes of the orchestrator, registered before the middlewares so they are neither logged nor traced
+	r.GET("/healthz", healthController.Liveness)
+	r.GET("/readyz", healthController.Readiness)
+
+	r.Use(middlewares.RequestID())
+	// one span per request, the services and repositories add their spans as children through the context
+	r.Use(otelgin.Middleware(conf.TracingServiceName))
+	// one structured log line per request, the handlers and queries log with the same request ID
+	r.Use(middlewares.RequestLogger(&logger))
+	r.Use(middlewares.Metrics())
+	// handlers attach their errors with ctx.Error, they are rendered as models.ErrorResponse
+	r.Use(middlewares.ErrorHandler(&logger))
 
 	// Debug route to check if docs are accessible
 	r.GET("/docs.json", func(c *gin.Context) {
@@ -73,32 +210,139 @@ func main() {
 	// Swagger documentation route
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// POST routes replay their response to retries sent with the same Idempotency-Key. The API key
+	// routes are left out on purpose, their response holds the key itself which is only stored hashed.
+	idempotent := middlewares.Idempotency(idempotencyKeyRepo, conf.IdempotencyKeyTTL)
+
 	// API routes
 	api := r.Group("/api/v1")
+	authorized := api.Group("", middlewares.Authenticate(tokenVerifier, apiKeyService))
 	{
 		// Borrower routes
-		api.GET("/borrowers/:id", borrowerController.GetBorrowerByID)
-		api.POST("/borrowers", borrowerController.CreateBorrower)
+		authorized.GET("/borrowers", middlewares.RequirePermission(models.PermissionBorrowerList), borrowerController.ListBorrowers)
+		authorized.GET("/borrowers/:id", middlewares.RequirePermission(models.PermissionBorrowerRead), borrowerController.GetBorrowerByID)
+		authorized.POST("/borrowers", middlewares.RequirePermission(models.PermissionBorrowerWrite), idempotent, borrowerController.CreateBorrower)
+		authorized.PATCH("/borrowers/:id", middlewares.RequirePermission(models.PermissionBorrowerWrite), borrowerController.UpdateBorrower)
+		authorized.DELETE("/borrowers/:id", middlewares.RequirePermission(models.PermissionBorrowerWrite), borrowerController.DeleteBorrower)
+		authorized.GET("/borrowers/:id/statement", middlewares.RequirePermission(models.PermissionBorrowerRead), statementController.GetBorrowerStatement)
+
+		// Borrower KYC routes
+		authorized.GET("/borrowers/:id/kyc", middlewares.RequirePermission(models.PermissionKYCRead), kycController.GetKYC)
+		authorized.PUT("/borrowers/:id/kyc", middlewares.RequirePermission(models.PermissionKYCWrite), kycController.SubmitKYCProfile)
+		authorized.POST("/borrowers/:id/kyc/documents", middlewares.RequirePermission(models.PermissionKYCWrite), idempotent, kycController.UploadDocument)
+		authorized.GET("/borrowers/:id/kyc/documents/:documentId", middlewares.RequirePermission(models.PermissionKYCRead), kycController.DownloadDocument)
+		authorized.POST("/borrowers/:id/kyc/review", middlewares.RequirePermission(models.PermissionKYCReview), idempotent, kycController.ReviewKYC)
 
 		// Loan routes
-		api.POST("/loans", loanController.CreateLoan)
-		api.GET("/loans/:id", loanController.GetLoanByID)
+		authorized.GET("/loans", middlewares.RequirePermission(models.PermissionLoanRead), loanController.ListLoans)
+		authorized.POST("/loans", middlewares.RequirePermission(models.PermissionLoanWrite), idempotent, loanController.CreateLoan)
+		authorized.GET("/loans/:id", middlewares.RequirePermission(models.PermissionLoanRead), loanController.GetLoanByID)
 
 		// Payment routes
-		api.POST("/payments/link", paymentController.GeneratePaymentLink)
-		api.POST("/payments/webhook", paymentController.HandlePaymentWebhook)
+		authorized.POST("/payments/link", middlewares.RequirePermission(models.PermissionPaymentLink), idempotent, paymentController.GeneratePaymentLink)
+		authorized.POST("/payments/webhook", middlewares.RequirePermission(models.PermissionPaymentWebhook), idempotent, paymentController.HandlePaymentWebhook)
+
+		// API key routes
+		authorized.GET("/api-keys", middlewares.RequirePermission(models.PermissionAPIKeyManage), apiKeyController.ListAPIKeys)
+		authorized.POST("/api-keys", middlewares.RequirePermission(models.PermissionAPIKeyManage), apiKeyController.CreateAPIKey)
+		authorized.POST("/api-keys/:id/rotate", middlewares.RequirePermission(models.PermissionAPIKeyManage), apiKeyController.RotateAPIKey)
+		authorized.DELETE("/api-keys/:id", middlewares.RequirePermission(models.PermissionAPIKeyManage), apiKeyController.RevokeAPIKey)
+
+		// Audit log routes
+		authorized.GET("/audit-logs", middlewares.RequirePermission(models.PermissionAuditLogRead), auditLogController.ListAuditLogs)
+
+		// Webhook routes
+		authorized.GET("/webhook-subscriptions", middlewares.RequirePermission(models.PermissionWebhookManage), webhookController.ListSubscriptions)
+		authorized.POST("/webhook-subscriptions", middlewares.RequirePermission(models.PermissionWebhookManage), idempotent, webhookController.CreateSubscription)
+		authorized.DELETE("/webhook-subscriptions/:id", middlewares.RequirePermission(models.PermissionWebhookManage), webhookController.DeleteSubscription)
+		authorized.PUT("/webhook-partners/:partner_id/borrowers/:borrower_id", middlewares.RequirePermission(models.PermissionWebhookManage), webhookController.GrantBorrower)
+		authorized.DELETE("/webhook-partners/:partner_id/borrowers/:borrower_id", middlewares.RequirePermission(models.PermissionWebhookManage), webhookController.RevokeBorrower)
+		authorized.GET("/webhook-subscriptions/:id/deliveries", middlewares.RequirePermission(models.PermissionWebhookManage), webhookController.ListDeliveries)
+		authorized.POST("/webhook-deliveries/:id/redeliver", middlewares.RequirePermission(models.PermissionWebhookManage), idempotent, webhookController.RedeliverDelivery)
+
+		// Admin routes
+		authorized.GET("/admin/config", middlewares.RequirePermission(models.PermissionConfigRead), adminController.GetConfig)
 	}
 
-	// Get port from environment
-	port := os.Getenv("SERVER_PORT")
-	if port == "" {
-		port = "8080"
+	port := strconv.Itoa(conf.ServerPort)
+	server := &http.Server{
+		Addr:              ":" + port,
+		Handler:           r,
+		ReadHeaderTimeout: conf.ServerReadHeaderTimeout,
+		ReadTimeout:       conf.ServerReadTimeout,
+		WriteTimeout:      conf.ServerWriteTimeout,
+		IdleTimeout:       conf.ServerIdleTimeout,
 	}
+	// Prometheus metrics are served on their own listener, it isn't authenticated so its address
+	// must only be reachable from inside the network
+	metricsMux := http.NewServeMux()
+	metricsMux.Handle("/metrics", promhttp.Handler())
+	metricsServer := &http.Server{
+		Addr:              conf.MetricsAddr,
+		Handler:           metricsMux,
+		ReadHeaderTimeout: conf.ServerReadHeaderTimeout,
+	}
+	serverErrors := make(chan error, 2)
+	go func() {
+		logger.Infof(ctx, "Server starting on port %s", port)
+		logger.Infof(ctx, "Swagger documentation available at http://localhost:%s/swagger/index.html", port)
+		logger.Infof(ctx, "Direct docs.json available at http://localhost:%s/doc.json", port)
+		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			serverErrors <- err
+		}
+	}()
+	go func() {
+		logger.Infof(ctx, "Metrics available at %s/metrics", conf.MetricsAddr)
+		if err := metricsServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			serverErrors <- err
+		}
+	}()
+
+	select {
+	case err := <-serverErrors:
+		logger.Fatalf(ctx, "Failed to start server: %v", err)
+	case <-ctx.Done():
+	}
+	// a second signal kills the process right away
+	stop()
 
-	log.Printf("Server starting on port %s", port)
-	log.Printf("Swagger documentation available at http://localhost:%s/swagger/index.html", port)
-	log.Printf("Direct docs.json available at http://localhost:%s/doc.json", port)
-	if err := r.Run(":" + port); err != nil {
-		log.Fatal("Failed to start server:", err)
+	// the context of the signal is cancelled, the shutdown has its own deadline
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), conf.ShutdownTimeout)
+	defer cancel()
+
+	logger.Infof(shutdownCtx, "Shutting down, draining the in-flight requests")
+	healthService.StartDraining()
+	// leaves the load balancer the time to see the failing readiness before new connections are refused
+	time.Sleep(conf.ShutdownDrainDelay)
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Errorf(shutdownCtx, "Failed to drain the in-flight requests: %v", err)
+	}
+	// stopped after the API so the requests drained above are still counted by a last scrape
+	if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+		logger.Errorf(shutdownCtx, "Failed to stop the metrics server: %v", err)
+	}
+	if err := backgroundWorkers.Wait(shutdownCtx); err != nil {
+		logger.Errorf(shutdownCtx, "Failed to stop the background workers: %v", err)
+	}
+	// closed once the relay stopped, so the events it marked as published are flushed
+	if closer, ok := eventPublisher.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			logger.Errorf(shutdownCtx, "Failed to close the event publisher: %v", err)
+		}
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logger.Errorf(shutdownCtx, "Failed to flush traces: %v", err)
+	}
+	if closer, ok := responseCache.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			logger.Errorf(shutdownCtx, "Failed to close the cache connections: %v", err)
+		}
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		if err := sqlDB.Close(); err != nil {
+			logger.Errorf(shutdownCtx, "Failed to close the database connections: %v", err)
+		}
 	}
+	logger.Infof(shutdownCtx, "Server stopped")
 }
@@ -0,0 +1,119 @@
+// Package metrics holds the Prometheus collectors of the service, they are registered on the default
+// registry served on /metrics
+package metrics
+
+import (
+	"slices"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/satryarangga/amartha-loan-engine/models"
+)
+
+const namespace = "amartha"
+
+// otherLabelValue replaces the label values that aren't known, the values coming from the clients
+// would otherwise create a new series each
+const otherLabelValue = "other"
+
+var (
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Duration of the HTTP requests by method, route and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	DBQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "Duration of the database queries by operation, table and outcome.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table", "status"})
+
+	LoansCreated = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "loans_created_total",
+		Help:      "Number of loans created by product.",
+	}, []string{"product_code"})
+
+	AmountDisbursed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "amount_disbursed_total",
+		Help:      "Principal amount disbursed by product.",
+	}, []string{"product_code"})
+
+	PaymentsProcessed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "payments_processed_total",
+		Help:      "Number of loan payments by payment method and status, pending when the payment link is generated and paid once the payment gateway confirms it.",
+	}, []string{"payment_method", "status"})
+
+	OutstandingPortfolio = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "outstanding_portfolio_amount",
+		Help:      "Amount still to be repaid over every pending loan schedule.",
+	})
+
+	DelinquentBorrowers = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "delinquent_borrowers",
+		Help:      "Number of borrowers with a loan having enough overdue schedules to be delinquent.",
+	})
+
+	CacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "requests_total",
+		Help:      "Number of cache lookups by cached response and result, hit, miss or error when the cache failed and the response was loaded from the database.",
+	}, []string{"cache", "result"})
+
+	CacheInvalidations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "invalidations_total",
+		Help:      "Number of cache invalidations by result, a failed invalidation leaves the entries until their TTL expires.",
+	}, []string{"result"})
+)
+
+// RecordLoanCreated counts a loan once it is committed
+func RecordLoanCreated(productCode string, amount float64) {
+	productCode = knownLabelValue(productCode, models.LoanProductCodes)
+	LoansCreated.WithLabelValues(productCode).Inc()
+	AmountDisbursed.WithLabelValues(productCode).Add(amount)
+}
+
+// RecordPaymentProcessed counts a loan payment reaching the status
+func RecordPaymentProcessed(paymentMethod string, status models.LoanPaymentStatus) {
+	paymentMethod = knownLabelValue(paymentMethod, models.PaymentMethods)
+	PaymentsProcessed.WithLabelValues(paymentMethod, string(status)).Inc()
+}
+
+// RecordCacheRequest counts a cache lookup of the response
+func RecordCacheRequest(cache string, result string) {
+	CacheRequests.WithLabelValues(cache, result).Inc()
+}
+
+// RecordCacheInvalidation counts an invalidation, failed when err isn't nil
+func RecordCacheInvalidation(err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	CacheInvalidations.WithLabelValues(result).Inc()
+}
+
+// SetPortfolioStats refreshes the portfolio gauges, they are computed from the database
+func SetPortfolioStats(stats models.PortfolioStats) {
+	OutstandingPortfolio.Set(stats.OutstandingAmount)
+	DelinquentBorrowers.Set(float64(stats.DelinquentBorrowers))
+}
+
+func knownLabelValue(value string, known []string) string {
+	if slices.Contains(known, value) {
+		return value
+	}
+	return otherLabelValue
+}
@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordLoanCreated_UnknownProductCode(t *testing.T) {
+	// Arrange
+	LoansCreated.Reset()
+	AmountDisbursed.Reset()
+
+	// Act
+	RecordLoanCreated("standard", 1000)
+	RecordLoanCreated("made-up-1", 2000)
+	RecordLoanCreated("made-up-2", 3000)
+
+	// Assert
+	// the unknown product codes share a single series
+	assert.Equal(t, 2, testutil.CollectAndCount(LoansCreated))
+	assert.Equal(t, float64(1), testutil.ToFloat64(LoansCreated.WithLabelValues("standard")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(LoansCreated.WithLabelValues(otherLabelValue)))
+	assert.Equal(t, float64(5000), testutil.ToFloat64(AmountDisbursed.WithLabelValues(otherLabelValue)))
+}
+
+func TestRecordPaymentProcessed_UnknownPaymentMethod(t *testing.T) {
+	// Arrange
+	PaymentsProcessed.Reset()
+
+	// Act
+	RecordPaymentProcessed(models.PaymentMethodBankTransfer, models.LoanPaymentStatusPending)
+	RecordPaymentProcessed("<script>", models.LoanPaymentStatusPending)
+	RecordPaymentProcessed("crypto", models.LoanPaymentStatusPending)
+
+	// Assert
+	assert.Equal(t, 2, testutil.CollectAndCount(PaymentsProcessed))
+	assert.Equal(t, float64(1), testutil.ToFloat64(PaymentsProcessed.WithLabelValues("bank_transfer", "pending")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(PaymentsProcessed.WithLabelValues(otherLabelValue, "pending")))
+}
@@ -0,0 +1,73 @@
+package middlewares
+
+import (
+	"context"
+	"strings"
+
+	"github.com/satryarangga/amartha-loan-engine/helpers"
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/satryarangga/amartha-loan-engine/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errMissingCredentials = services.NewUnauthorizedError("missing_credentials", "Missing bearer token or API key")
+
+// APIKeyAuthenticator resolves the principal of a server-to-server client from its API key
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string, clientIP string) (models.Principal, error)
+}
+
+// Authenticate verifies the X-API-Key header, or the bearer token when there is no API key,
+// and puts the principal in the request context
+func Authenticate(verifier *helpers.TokenVerifier, apiKeyAuthenticator APIKeyAuthenticator) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if apiKey := ctx.GetHeader("X-API-Key"); apiKey != "" {
+			principal, err := apiKeyAuthenticator.AuthenticateAPIKey(ctx.Request.Context(), apiKey, ctx.ClientIP())
+			if err != nil {
+				abortWithError(ctx, err)
+				return
+			}
+
+			ctx.Request = ctx.Request.WithContext(helpers.WithPrincipal(ctx.Request.Context(), principal))
+			ctx.Next()
+			return
+		}
+
+		authorization := ctx.GetHeader("Authorization")
+		scheme, token, found := strings.Cut(authorization, " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+			abortWithError(ctx, errMissingCredentials)
+			return
+		}
+
+		principal, err := verifier.Verify(strings.TrimSpace(token))
+		if err != nil {
+			invalidTokenErr := services.NewUnauthorizedError("invalid_token", "Invalid bearer token")
+			invalidTokenErr.Err = err
+			abortWithError(ctx, invalidTokenErr)
+			return
+		}
+
+		ctx.Request = ctx.Request.WithContext(helpers.WithPrincipal(ctx.Request.Context(), principal))
+		ctx.Next()
+	}
+}
+
+// RequirePermission rejects the request unless the authenticated principal has the permission
+func RequirePermission(permission models.Permission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal, ok := helpers.PrincipalFromContext(ctx.Request.Context())
+		if !ok {
+			abortWithError(ctx, errMissingCredentials)
+			return
+		}
+
+		if !principal.HasPermission(permission) {
+			abortWithError(ctx, services.NewForbiddenError("missing_permission", "Permission %s is required", permission))
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,185 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/satryarangga/amartha-loan-engine/helpers"
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/satryarangga/amartha-loan-engine/services"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	testJWTSecret = "test-secret"
+	testAPIKey    = "ak_prefix_secret"
+	// the authenticator fails as if the database was down
+	testBrokenAPIKey = "ak_prefix_broken"
+)
+
+type fakeAPIKeyAuthenticator struct{}
+
+func (fakeAPIKeyAuthenticator) AuthenticateAPIKey(ctx context.Context, key string, clientIP string) (models.Principal, error) {
+	switch key {
+	case testAPIKey:
+		return models.Principal{Subject: "api_key:key-id", Role: models.RoleService, Permissions: []models.Permission{models.PermissionLoanWrite}}, nil
+	case testBrokenAPIKey:
+		return models.Principal{}, errors.New("connection refused")
+	default:
+		return models.Principal{}, services.ErrInvalidAPIKey
+	}
+}
+
+func newAuthTestRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	verifier, err := helpers.NewTokenVerifier(helpers.TokenVerifierConfig{
+		Algorithm: helpers.JWTAlgorithmHS256,
+		Secret:    testJWTSecret,
+	})
+	assert.NoError(t, err)
+
+	router := gin.New()
+	router.GET("/loans", Authenticate(verifier, fakeAPIKeyAuthenticator{}), RequirePermission(models.PermissionLoanWrite), func(ctx *gin.Context) {
+		principal, _ := helpers.PrincipalFromContext(ctx.Request.Context())
+		ctx.String(http.StatusOK, principal.Subject)
+	})
+	return router
+}
+
+func signTestToken(t *testing.T, role models.Role) string {
+	token, err := helpers.SignHS256Token(testJWTSecret, helpers.AccessTokenClaims{
+		Role: role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "user-1",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	})
+	assert.NoError(t, err)
+	return token
+}
+
+func TestAuthenticate_MissingToken(t *testing.T) {
+	// Arrange
+	router := newAuthTestRouter(t)
+	request := httptest.NewRequest(http.MethodGet, "/loans", nil)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(recorder, request)
+
+	// Assert
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestAuthenticate_InvalidToken(t *testing.T) {
+	// Arrange
+	router := newAuthTestRouter(t)
+	request := httptest.NewRequest(http.MethodGet, "/loans", nil)
+	request.Header.Set("Authorization", "Bearer not-a-jwt")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(recorder, request)
+
+	// Assert
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestRequirePermission_Forbidden(t *testing.T) {
+	// Arrange
+	router := newAuthTestRouter(t)
+	request := httptest.NewRequest(http.MethodGet, "/loans", nil)
+	request.Header.Set("Authorization", "Bearer "+signTestToken(t, models.RoleLender))
+	recorder := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(recorder, request)
+
+	// Assert
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+}
+
+func TestRequirePermission_PaymentGatewayForbidden(t *testing.T) {
+	// Arrange
+	router := newAuthTestRouter(t)
+	request := httptest.NewRequest(http.MethodGet, "/loans", nil)
+	request.Header.Set("Authorization", "Bearer "+signTestToken(t, models.RolePaymentGateway))
+	recorder := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(recorder, request)
+
+	// Assert
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	assert.Equal(t, []models.Permission{models.PermissionPaymentWebhook}, models.RolePermissions[models.RolePaymentGateway])
+}
+
+func TestRequirePermission_Allowed(t *testing.T) {
+	// Arrange
+	router := newAuthTestRouter(t)
+	request := httptest.NewRequest(http.MethodGet, "/loans", nil)
+	request.Header.Set("Authorization", "Bearer "+signTestToken(t, models.RoleFieldOfficer))
+	recorder := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(recorder, request)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "user-1", recorder.Body.String())
+}
+
+func TestAuthenticate_APIKey(t *testing.T) {
+	// Arrange
+	router := newAuthTestRouter(t)
+	request := httptest.NewRequest(http.MethodGet, "/loans", nil)
+	request.Header.Set("X-API-Key", testAPIKey)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(recorder, request)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "api_key:key-id", recorder.Body.String())
+}
+
+func TestAuthenticate_InvalidAPIKey(t *testing.T) {
+	// Arrange
+	router := newAuthTestRouter(t)
+	request := httptest.NewRequest(http.MethodGet, "/loans", nil)
+	request.Header.Set("X-API-Key", "ak_prefix_wrong")
+	// a valid bearer token doesn't rescue an invalid API key
+	request.Header.Set("Authorization", "Bearer "+signTestToken(t, models.RoleAdmin))
+	recorder := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(recorder, request)
+
+	// Assert
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestAuthenticate_APIKeyAuthenticatorFailure(t *testing.T) {
+	// Arrange
+	router := newAuthTestRouter(t)
+	request := httptest.NewRequest(http.MethodGet, "/loans", nil)
+	request.Header.Set("X-API-Key", testBrokenAPIKey)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(recorder, request)
+
+	// Assert
+	// an outage is not the caller's fault, and its details aren't shown
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.JSONEq(t, `{"code":"internal_error","message":"Something went wrong while processing your request."}`, recorder.Body.String())
+}
@@ -0,0 +1,134 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/satryarangga/amartha-loan-engine/config"
+	"github.com/satryarangga/amartha-loan-engine/helpers"
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/satryarangga/amartha-loan-engine/repositories"
+	"github.com/satryarangga/amartha-loan-engine/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// pgInvalidTextRepresentation is raised by Postgres for a value it can't parse into the column type,
+// e.g. a malformed UUID in a path or the external ID of a payment webhook
+const pgInvalidTextRepresentation = "22P02"
+
+const (
+	ErrorCodeNotFound        = "not_found"
+	ErrorCodeDuplicate       = "duplicate_resource"
+	ErrorCodeVersionConflict = "version_conflict"
+	ErrorCodeTimeout         = "timeout"
+	ErrorCodeInvalidValue    = "invalid_value"
+	ErrorCodeInternalError   = "internal_error"
+)
+
+var errorKindStatusCodes = map[services.ErrorKind]int{
+	services.ErrorKindNotFound:        http.StatusNotFound,
+	services.ErrorKindValidation:      http.StatusBadRequest,
+	services.ErrorKindConflict:        http.StatusConflict,
+	services.ErrorKindStateTransition: http.StatusUnprocessableEntity,
+	services.ErrorKindUnauthorized:    http.StatusUnauthorized,
+	services.ErrorKindForbidden:       http.StatusForbidden,
+	services.ErrorKindUpstream:        http.StatusBadGateway,
+}
+
+// ErrorHandler renders the last error attached by a handler with ctx.Error as a models.ErrorResponse.
+// Errors that aren't domain errors are logged and hidden behind a generic message, so database
+// errors never reach the caller.
+func ErrorHandler(logger *config.AmarthaLogger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		if len(ctx.Errors) == 0 {
+			return
+		}
+
+		err := ctx.Errors.Last().Err
+		response := NewErrorResponse(ctx.Request.Context(), err)
+		if response.HTTPStatusCode >= http.StatusInternalServerError {
+			logger.Errorf(ctx.Request.Context(), "request failed: %v", err)
+		}
+
+		// middlewares aborting the request already rendered their error
+		writeError(ctx)
+	}
+}
+
+// writeError renders the last attached error unless a response is already written, for
+// middlewares needing the final response before ErrorHandler runs
+func writeError(ctx *gin.Context) {
+	if len(ctx.Errors) == 0 || ctx.Writer.Written() {
+		return
+	}
+
+	response := NewErrorResponse(ctx.Request.Context(), ctx.Errors.Last().Err)
+	ctx.JSON(response.HTTPStatusCode, response)
+}
+
+// NewErrorResponse maps an error to the response shown to the caller
+func NewErrorResponse(ctx context.Context, err error) models.ErrorResponse {
+	response := models.ErrorResponse{
+		Err:       err,
+		RequestID: helpers.RequestIDFromContext(ctx),
+	}
+
+	var domainErr *services.Error
+	var conflictErr *repositories.VersionConflictError
+	var queryErr *repositories.QueryValidationError
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.As(err, &domainErr):
+		response.HTTPStatusCode = http.StatusInternalServerError
+		if statusCode, ok := errorKindStatusCodes[domainErr.Kind]; ok {
+			response.HTTPStatusCode = statusCode
+		}
+		response.Code = domainErr.Code
+		response.Message = domainErr.Message
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		response.HTTPStatusCode = http.StatusNotFound
+		response.Code = ErrorCodeNotFound
+		response.Message = "Resource not found"
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		response.HTTPStatusCode = http.StatusConflict
+		response.Code = ErrorCodeDuplicate
+		response.Message = "Resource already exists"
+	case errors.As(err, &queryErr):
+		response.HTTPStatusCode = http.StatusBadRequest
+		response.Code = queryErr.Code
+		response.Message = queryErr.Message
+	case errors.As(err, &pgErr) && pgErr.Code == pgInvalidTextRepresentation:
+		// the client sent the malformed value, a 500 would only be retried by the payment gateway
+		response.HTTPStatusCode = http.StatusBadRequest
+		response.Code = ErrorCodeInvalidValue
+		response.Message = "Invalid identifier or value format"
+	case errors.As(err, &conflictErr):
+		response.HTTPStatusCode = http.StatusConflict
+		response.Code = ErrorCodeVersionConflict
+		response.Message = "Resource was changed by another request, please retry"
+	case errors.Is(err, context.DeadlineExceeded):
+		response.HTTPStatusCode = http.StatusGatewayTimeout
+		response.Code = ErrorCodeTimeout
+		response.Message = "The request took too long to process"
+	default:
+		response.HTTPStatusCode = http.StatusInternalServerError
+		response.Code = ErrorCodeInternalError
+		response.Message = "Something went wrong while processing your request."
+	}
+
+	return response
+}
+
+// abortWithError renders the error right away, for middlewares rejecting a request before the handler.
+// The error is still attached so ErrorHandler logs it.
+func abortWithError(ctx *gin.Context, err error) {
+	_ = ctx.Error(err)
+	response := NewErrorResponse(ctx.Request.Context(), err)
+	ctx.AbortWithStatusJSON(response.HTTPStatusCode, response)
+}
@@ -0,0 +1,211 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/satryarangga/amartha-loan-engine/config"
+	"github.com/satryarangga/amartha-loan-engine/helpers"
+	"github.com/satryarangga/amartha-loan-engine/repositories"
+	"github.com/satryarangga/amartha-loan-engine/services"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestNewErrorResponse(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		wantStatusCode int
+		wantCode       string
+		wantMessage    string
+	}{
+		{
+			name:           "not found",
+			err:            services.NewNotFoundError("borrower_not_found", "borrower not found"),
+			wantStatusCode: http.StatusNotFound,
+			wantCode:       "borrower_not_found",
+			wantMessage:    "borrower not found",
+		},
+		{
+			name:           "validation",
+			err:            services.NewValidationError("invalid_date", "from must use YYYY-MM-DD format"),
+			wantStatusCode: http.StatusBadRequest,
+			wantCode:       "invalid_date",
+			wantMessage:    "from must use YYYY-MM-DD format",
+		},
+		{
+			name:           "conflict",
+			err:            services.NewConflictError("borrower_has_active_loan", "borrower still has an active loan"),
+			wantStatusCode: http.StatusConflict,
+			wantCode:       "borrower_has_active_loan",
+			wantMessage:    "borrower still has an active loan",
+		},
+		{
+			name:           "state transition",
+			err:            services.NewStateTransitionError("kyc_not_verified", "borrower KYC is not verified"),
+			wantStatusCode: http.StatusUnprocessableEntity,
+			wantCode:       "kyc_not_verified",
+			wantMessage:    "borrower KYC is not verified",
+		},
+		{
+			name:           "upstream failure hides its cause",
+			err:            services.NewUpstreamError("document_storage_unavailable", errors.New("disk full"), "unable to store the document"),
+			wantStatusCode: http.StatusBadGateway,
+			wantCode:       "document_storage_unavailable",
+			wantMessage:    "unable to store the document",
+		},
+		{
+			name:           "wrapped domain error",
+			err:            fmt.Errorf("%w: key is revoked", services.ErrInvalidAPIKey),
+			wantStatusCode: http.StatusUnauthorized,
+			wantCode:       "invalid_api_key",
+			wantMessage:    "invalid API key",
+		},
+		{
+			name:           "record not found",
+			err:            gorm.ErrRecordNotFound,
+			wantStatusCode: http.StatusNotFound,
+			wantCode:       ErrorCodeNotFound,
+			wantMessage:    "Resource not found",
+		},
+		{
+			name:           "duplicated key",
+			err:            gorm.ErrDuplicatedKey,
+			wantStatusCode: http.StatusConflict,
+			wantCode:       ErrorCodeDuplicate,
+			wantMessage:    "Resource already exists",
+		},
+		{
+			name:           "version conflict",
+			err:            fmt.Errorf("update loan: %w", &repositories.VersionConflictError{Table: "loans", ID: "loan-id", Version: 3}),
+			wantStatusCode: http.StatusConflict,
+			wantCode:       ErrorCodeVersionConflict,
+			wantMessage:    "Resource was changed by another request, please retry",
+		},
+		{
+			name:           "query validation",
+			err:            &repositories.QueryValidationError{Code: repositories.QueryErrorCodeInvalidSortField, Message: `unable to sort loans by "interest_amount"`},
+			wantStatusCode: http.StatusBadRequest,
+			wantCode:       repositories.QueryErrorCodeInvalidSortField,
+			wantMessage:    `unable to sort loans by "interest_amount"`,
+		},
+		{
+			name:           "invalid id",
+			err:            fmt.Errorf("find loan: %w", &pgconn.PgError{Code: "22P02", Message: `invalid input syntax for type uuid: "not-a-uuid"`}),
+			wantStatusCode: http.StatusBadRequest,
+			wantCode:       ErrorCodeInvalidValue,
+			wantMessage:    "Invalid identifier or value format",
+		},
+		{
+			name:           "other database error is not a validation error",
+			err:            &pgconn.PgError{Code: "42P01", Message: `relation "loans" does not exist`},
+			wantStatusCode: http.StatusInternalServerError,
+			wantCode:       ErrorCodeInternalError,
+			wantMessage:    "Something went wrong while processing your request.",
+		},
+		{
+			name:           "timeout",
+			err:            fmt.Errorf("query loans: %w", context.DeadlineExceeded),
+			wantStatusCode: http.StatusGatewayTimeout,
+			wantCode:       ErrorCodeTimeout,
+			wantMessage:    "The request took too long to process",
+		},
+		{
+			name:           "unexpected error is not leaked",
+			err:            errors.New(`pq: relation "loans" does not exist`),
+			wantStatusCode: http.StatusInternalServerError,
+			wantCode:       ErrorCodeInternalError,
+			wantMessage:    "Something went wrong while processing your request.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := helpers.WithRequestID(context.Background(), "request-1")
+
+			// Act
+			response := NewErrorResponse(ctx, tt.err)
+
+			// Assert
+			assert.Equal(t, tt.wantStatusCode, response.HTTPStatusCode)
+			assert.Equal(t, tt.wantCode, response.Code)
+			assert.Equal(t, tt.wantMessage, response.Message)
+			assert.Equal(t, "request-1", response.RequestID)
+			assert.Equal(t, tt.err, response.Err)
+		})
+	}
+}
+
+func TestErrorHandler_RendersAttachedError(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	logger := config.NewLogger()
+	router := gin.New()
+	router.Use(ErrorHandler(&logger))
+	router.GET("/loans/:id", func(ctx *gin.Context) {
+		_ = ctx.Error(services.NewNotFoundError("loan_not_found", "loan not found"))
+	})
+	recorder := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/loans/loan-1", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.JSONEq(t, `{"code":"loan_not_found","message":"loan not found"}`, recorder.Body.String())
+}
+
+func TestErrorHandler_KeepsWrittenResponse(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	logger := config.NewLogger()
+	router := gin.New()
+	router.Use(ErrorHandler(&logger))
+	router.GET("/loans", func(ctx *gin.Context) {
+		_ = ctx.Error(errors.New("rendered already"))
+		ctx.String(http.StatusAccepted, "accepted")
+	})
+	recorder := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/loans", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusAccepted, recorder.Code)
+	assert.Equal(t, "accepted", recorder.Body.String())
+}
+
+func TestIdempotency_StoresRenderedError(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	logger := config.NewLogger()
+	var calls int
+	router := gin.New()
+	router.Use(ErrorHandler(&logger))
+	router.POST("/loans", Idempotency(newMemoryIdempotencyStore(), time.Hour), func(ctx *gin.Context) {
+		calls++
+		_ = ctx.Error(services.NewStateTransitionError("kyc_not_verified", "borrower KYC is not verified"))
+	})
+
+	// Act
+	first := sendIdempotentRequest(router, http.MethodPost, "key-1", "", `{"amount":1000}`)
+	retry := sendIdempotentRequest(router, http.MethodPost, "key-1", "", `{"amount":1000}`)
+
+	// Assert
+	// the error is rendered before the idempotency middleware stores the response
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusUnprocessableEntity, first.Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, retry.Code)
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Contains(t, retry.Body.String(), `"code":"kyc_not_verified"`)
+}
@@ -0,0 +1,162 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/satryarangga/amartha-loan-engine/helpers"
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/satryarangga/amartha-loan-engine/services"
+	"gorm.io/gorm"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	idempotencyAnonymousScope = "anonymous"
+	// a request holding a key longer than this is considered gone, e.g. the server restarted
+	idempotencyLockDuration = time.Minute
+)
+
+var errIdempotentRequestInProgress = services.NewConflictError("idempotent_request_in_progress", "A request with this Idempotency-Key is still in progress, retry later")
+
+// IdempotencyStore keeps the idempotency keys and the responses of their requests
+type IdempotencyStore interface {
+	Reserve(ctx context.Context, idempotencyKey *models.IdempotencyKey, now time.Time) (bool, error)
+	FindByKey(ctx context.Context, scope string, key string) (*models.IdempotencyKey, error)
+	Complete(ctx context.Context, idempotencyKey *models.IdempotencyKey) error
+	Release(ctx context.Context, idempotencyKey *models.IdempotencyKey) error
+}
+
+// Idempotency honours the Idempotency-Key header of POST requests. The response of the first request
+// is stored for the TTL and replayed to the retries of the same request, while reusing the key for
+// another request is rejected with 409. Keys are scoped to the authenticated caller, so the
+// middleware goes after Authenticate. Server errors aren't stored, the request can be retried.
+func Idempotency(store IdempotencyStore, ttl time.Duration) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(IdempotencyKeyHeader)
+		if ctx.Request.Method != http.MethodPost || key == "" {
+			ctx.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			abortWithError(ctx, services.NewValidationError("invalid_idempotency_key", "Idempotency-Key must be at most %d characters", maxIdempotencyKeyLength))
+			return
+		}
+
+		body, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			abortWithError(ctx, services.NewValidationError("invalid_request_body", "Unable to read request body"))
+			return
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		now := time.Now()
+		idempotencyKey := &models.IdempotencyKey{
+			Scope:       idempotencyScope(ctx.Request.Context()),
+			Key:         key,
+			RequestHash: hashIdempotentRequest(ctx.Request, body),
+			LockedUntil: now.Add(idempotencyLockDuration),
+			ExpiresAt:   now.Add(ttl),
+		}
+
+		reserved, err := store.Reserve(ctx.Request.Context(), idempotencyKey, now)
+		if err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+		if !reserved {
+			replayIdempotentResponse(ctx, store, idempotencyKey)
+			return
+		}
+
+		writer := &idempotencyResponseWriter{ResponseWriter: ctx.Writer}
+		ctx.Writer = writer
+		ctx.Next()
+		// the response must be complete before it is stored
+		writeError(ctx)
+
+		// the key is stored with the caller context, a cancelled request still has to free or complete it
+		storeCtx := context.WithoutCancel(ctx.Request.Context())
+		if writer.Status() >= http.StatusInternalServerError {
+			_ = store.Release(storeCtx, idempotencyKey)
+			return
+		}
+
+		completedAt := time.Now()
+		idempotencyKey.StatusCode = writer.Status()
+		idempotencyKey.ContentType = writer.Header().Get("Content-Type")
+		idempotencyKey.ResponseBody = writer.body.Bytes()
+		idempotencyKey.CompletedAt = &completedAt
+		_ = store.Complete(storeCtx, idempotencyKey)
+	}
+}
+
+func replayIdempotentResponse(ctx *gin.Context, store IdempotencyStore, idempotencyKey *models.IdempotencyKey) {
+	stored, err := store.FindByKey(ctx.Request.Context(), idempotencyKey.Scope, idempotencyKey.Key)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// released by a failed request in the meantime
+		abortWithError(ctx, errIdempotentRequestInProgress)
+		return
+	}
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	if stored.RequestHash != idempotencyKey.RequestHash {
+		abortWithError(ctx, services.NewConflictError("idempotency_key_reused", "Idempotency-Key is already used for a different request"))
+		return
+	}
+	if stored.CompletedAt == nil {
+		abortWithError(ctx, errIdempotentRequestInProgress)
+		return
+	}
+
+	ctx.Header(IdempotentReplayedHeader, "true")
+	ctx.Data(stored.StatusCode, stored.ContentType, stored.ResponseBody)
+	ctx.Abort()
+}
+
+// idempotencyScope is the role and subject of the caller, the role tells the token subjects apart from
+// the API keys (role service) so a token can't replay the response of an API key with the same subject
+func idempotencyScope(ctx context.Context) string {
+	if principal, ok := helpers.PrincipalFromContext(ctx); ok {
+		return string(principal.Role) + ":" + principal.Subject
+	}
+	return idempotencyAnonymousScope
+}
+
+// hashIdempotentRequest identifies a request by its method, path and body
+func hashIdempotentRequest(request *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(request.Method + " " + request.URL.Path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// idempotencyResponseWriter keeps a copy of the response body to store it
+type idempotencyResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyResponseWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *idempotencyResponseWriter) WriteString(data string) (int, error) {
+	w.body.WriteString(data)
+	return w.ResponseWriter.WriteString(data)
+}
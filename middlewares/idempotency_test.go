@@ -0,0 +1,244 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/satryarangga/amartha-loan-engine/helpers"
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"github.com/gin-gonic/gin"
+)
+
+// memoryIdempotencyStore mimics the repository, a key can be taken over once it expired or its lock did
+type memoryIdempotencyStore struct {
+	mu   sync.Mutex
+	keys map[string]models.IdempotencyKey
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{keys: map[string]models.IdempotencyKey{}}
+}
+
+func (s *memoryIdempotencyStore) Reserve(ctx context.Context, idempotencyKey *models.IdempotencyKey, now time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.keys[idempotencyKey.Scope+"/"+idempotencyKey.Key]
+	if ok && existing.ExpiresAt.After(now) && (existing.CompletedAt != nil || existing.LockedUntil.After(now)) {
+		return false, nil
+	}
+	idempotencyKey.ID = idempotencyKey.Scope + "/" + idempotencyKey.Key
+	s.keys[idempotencyKey.ID] = *idempotencyKey
+	return true, nil
+}
+
+func (s *memoryIdempotencyStore) FindByKey(ctx context.Context, scope string, key string) (*models.IdempotencyKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idempotencyKey, ok := s.keys[scope+"/"+key]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &idempotencyKey, nil
+}
+
+func (s *memoryIdempotencyStore) Complete(ctx context.Context, idempotencyKey *models.IdempotencyKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[idempotencyKey.ID] = *idempotencyKey
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Release(ctx context.Context, idempotencyKey *models.IdempotencyKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.keys, idempotencyKey.ID)
+	return nil
+}
+
+// newIdempotencyTestRouter counts the requests reaching the handler, the handler answers with the
+// given status and the request body
+func newIdempotencyTestRouter(store IdempotencyStore, status int, calls *int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(func(ctx *gin.Context) {
+		if subject := ctx.GetHeader("X-Test-Subject"); subject != "" {
+			principal := models.Principal{Subject: subject, Role: models.Role(ctx.GetHeader("X-Test-Role"))}
+			ctx.Request = ctx.Request.WithContext(helpers.WithPrincipal(ctx.Request.Context(), principal))
+		}
+	})
+	router.Use(Idempotency(store, time.Hour))
+	handler := func(ctx *gin.Context) {
+		*calls++
+		var body map[string]interface{}
+		_ = ctx.ShouldBindJSON(&body)
+		ctx.JSON(status, gin.H{"data": body, "call": *calls})
+	}
+	router.POST("/loans", handler)
+	router.GET("/loans", handler)
+	return router
+}
+
+func sendIdempotentRequest(router *gin.Engine, method string, key string, subject string, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, "/loans", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		request.Header.Set(IdempotencyKeyHeader, key)
+	}
+	if subject != "" {
+		request.Header.Set("X-Test-Subject", subject)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestIdempotency_ReplaysStoredResponse(t *testing.T) {
+	// Arrange
+	var calls int
+	router := newIdempotencyTestRouter(newMemoryIdempotencyStore(), http.StatusCreated, &calls)
+
+	// Act
+	first := sendIdempotentRequest(router, http.MethodPost, "key-1", "user-1", `{"amount":1000}`)
+	retry := sendIdempotentRequest(router, http.MethodPost, "key-1", "user-1", `{"amount":1000}`)
+
+	// Assert
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, first.Header().Get("Content-Type"), retry.Header().Get("Content-Type"))
+	assert.Empty(t, first.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, "true", retry.Header().Get(IdempotentReplayedHeader))
+}
+
+func TestIdempotency_KeyReusedWithDifferentBody(t *testing.T) {
+	// Arrange
+	var calls int
+	router := newIdempotencyTestRouter(newMemoryIdempotencyStore(), http.StatusCreated, &calls)
+
+	// Act
+	sendIdempotentRequest(router, http.MethodPost, "key-1", "user-1", `{"amount":1000}`)
+	reused := sendIdempotentRequest(router, http.MethodPost, "key-1", "user-1", `{"amount":2000}`)
+
+	// Assert
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusConflict, reused.Code)
+	assert.Contains(t, reused.Body.String(), "Idempotency-Key is already used for a different request")
+}
+
+func TestIdempotency_RequestInProgress(t *testing.T) {
+	// Arrange
+	var calls int
+	store := newMemoryIdempotencyStore()
+	router := newIdempotencyTestRouter(store, http.StatusCreated, &calls)
+	_, _ = store.Reserve(context.Background(), &models.IdempotencyKey{
+		Scope:       idempotencyScope(helpers.WithPrincipal(context.Background(), models.Principal{Subject: "user-1"})),
+		Key:         "key-1",
+		RequestHash: hashIdempotentRequest(httptest.NewRequest(http.MethodPost, "/loans", nil), []byte(`{"amount":1000}`)),
+		LockedUntil: time.Now().Add(time.Minute),
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}, time.Now())
+
+	// Act
+	response := sendIdempotentRequest(router, http.MethodPost, "key-1", "user-1", `{"amount":1000}`)
+
+	// Assert
+	assert.Equal(t, 0, calls)
+	assert.Equal(t, http.StatusConflict, response.Code)
+	assert.Contains(t, response.Body.String(), "still in progress")
+}
+
+func TestIdempotency_ServerErrorIsNotStored(t *testing.T) {
+	// Arrange
+	var calls int
+	router := newIdempotencyTestRouter(newMemoryIdempotencyStore(), http.StatusInternalServerError, &calls)
+
+	// Act
+	first := sendIdempotentRequest(router, http.MethodPost, "key-1", "user-1", `{"amount":1000}`)
+	retry := sendIdempotentRequest(router, http.MethodPost, "key-1", "user-1", `{"amount":1000}`)
+
+	// Assert
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, http.StatusInternalServerError, first.Code)
+	assert.Empty(t, retry.Header().Get(IdempotentReplayedHeader))
+}
+
+func TestIdempotency_KeysAreScopedToTheCaller(t *testing.T) {
+	// Arrange
+	var calls int
+	router := newIdempotencyTestRouter(newMemoryIdempotencyStore(), http.StatusCreated, &calls)
+
+	// Act
+	sendIdempotentRequest(router, http.MethodPost, "key-1", "user-1", `{"amount":1000}`)
+	other := sendIdempotentRequest(router, http.MethodPost, "key-1", "user-2", `{"amount":1000}`)
+
+	// Assert
+	assert.Equal(t, 2, calls)
+	assert.Empty(t, other.Header().Get(IdempotentReplayedHeader))
+}
+
+func TestIdempotency_KeysAreScopedToTheCallerRole(t *testing.T) {
+	// Arrange
+	var calls int
+	router := newIdempotencyTestRouter(newMemoryIdempotencyStore(), http.StatusCreated, &calls)
+	send := func(role models.Role) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/loans", strings.NewReader(`{"amount":1000}`))
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set(IdempotencyKeyHeader, "key-1")
+		request.Header.Set("X-Test-Subject", "api_key:key-id")
+		request.Header.Set("X-Test-Role", string(role))
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	// Act
+	// a token whose subject looks like the one of an API key
+	send(models.RoleService)
+	token := send(models.RoleAdmin)
+
+	// Assert
+	assert.Equal(t, 2, calls)
+	assert.Empty(t, token.Header().Get(IdempotentReplayedHeader))
+}
+
+func TestIdempotency_IgnoredWithoutKeyOrForOtherMethods(t *testing.T) {
+	// Arrange
+	var calls int
+	router := newIdempotencyTestRouter(newMemoryIdempotencyStore(), http.StatusOK, &calls)
+
+	// Act
+	sendIdempotentRequest(router, http.MethodPost, "", "user-1", `{"amount":1000}`)
+	sendIdempotentRequest(router, http.MethodPost, "", "user-1", `{"amount":1000}`)
+	sendIdempotentRequest(router, http.MethodGet, "key-1", "user-1", "")
+	sendIdempotentRequest(router, http.MethodGet, "key-1", "user-1", "")
+
+	// Assert
+	assert.Equal(t, 4, calls)
+}
+
+func TestIdempotency_KeyTooLong(t *testing.T) {
+	// Arrange
+	var calls int
+	router := newIdempotencyTestRouter(newMemoryIdempotencyStore(), http.StatusCreated, &calls)
+
+	// Act
+	response := sendIdempotentRequest(router, http.MethodPost, strings.Repeat("k", 256), "user-1", `{}`)
+
+	// Assert
+	assert.Equal(t, 0, calls)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
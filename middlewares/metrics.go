@@ -0,0 +1,29 @@
+package middlewares
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/satryarangga/amartha-loan-engine/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute groups the requests not matching any route, their paths would explode the metrics
+const unmatchedRoute = "unmatched"
+
+// Metrics records the duration and the status of every request per route
+func Metrics() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		metrics.HTTPRequestDuration.
+			WithLabelValues(ctx.Request.Method, route, strconv.Itoa(ctx.Writer.Status())).
+			Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,44 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/satryarangga/amartha-loan-engine/metrics"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gin-gonic/gin"
+)
+
+func requestDurationCount(t *testing.T, method string, route string, status string) uint64 {
+	var metric dto.Metric
+	observer := metrics.HTTPRequestDuration.WithLabelValues(method, route, status)
+	assert.NoError(t, observer.(prometheus.Histogram).Write(&metric))
+	return metric.GetHistogram().GetSampleCount()
+}
+
+func TestMetrics_RecordsRequestPerRoute(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Metrics())
+	router.GET("/loans/:id", func(ctx *gin.Context) {
+		ctx.Status(http.StatusNoContent)
+	})
+	metrics.HTTPRequestDuration.Reset()
+
+	// Act
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/loans/loan-1", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/loans/loan-2", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/unknown/3", nil))
+
+	// Assert
+	// the path parameters are kept out of the labels, so there is one series per route and status
+	assert.Equal(t, 2, testutil.CollectAndCount(metrics.HTTPRequestDuration))
+	assert.Equal(t, uint64(2), requestDurationCount(t, http.MethodGet, "/loans/:id", "204"))
+	assert.Equal(t, uint64(1), requestDurationCount(t, http.MethodGet, unmatchedRoute, "404"))
+}
@@ -0,0 +1,36 @@
+package middlewares
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"github.com/satryarangga/amartha-loan-engine/helpers"
+
+	"github.com/gin-gonic/gin"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// an incoming request ID ends up in logs and audit entries, so only short and plain values are kept
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID keeps the X-Request-ID of the caller, or generates one, and echoes it in the response
+func RequestID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = newRequestID()
+		}
+
+		ctx.Header(RequestIDHeader, requestID)
+		ctx.Request = ctx.Request.WithContext(helpers.WithRequestID(ctx.Request.Context(), requestID))
+		ctx.Next()
+	}
+}
+
+func newRequestID() string {
+	requestID := make([]byte, 16)
+	_, _ = rand.Read(requestID)
+	return hex.EncodeToString(requestID)
+}
@@ -0,0 +1,55 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/satryarangga/amartha-loan-engine/helpers"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newRequestIDTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(RequestID())
+	router.GET("/", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, helpers.RequestIDFromContext(ctx.Request.Context()))
+	})
+	return router
+}
+
+func TestRequestID_KeepsIncomingID(t *testing.T) {
+	// Arrange
+	router := newRequestIDTestRouter()
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.Header.Set(RequestIDHeader, "mobile-backend:1234")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(recorder, request)
+
+	// Assert
+	assert.Equal(t, "mobile-backend:1234", recorder.Body.String())
+	assert.Equal(t, "mobile-backend:1234", recorder.Header().Get(RequestIDHeader))
+}
+
+func TestRequestID_GeneratesID(t *testing.T) {
+	for _, incoming := range []string{"", "contains spaces and\nnewlines"} {
+		// Arrange
+		router := newRequestIDTestRouter()
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Set(RequestIDHeader, incoming)
+		recorder := httptest.NewRecorder()
+
+		// Act
+		router.ServeHTTP(recorder, request)
+
+		// Assert
+		assert.Len(t, recorder.Body.String(), 32)
+		assert.Equal(t, recorder.Body.String(), recorder.Header().Get(RequestIDHeader))
+	}
+}
@@ -0,0 +1,56 @@
+package middlewares
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/satryarangga/amartha-loan-engine/config"
+	"github.com/satryarangga/amartha-loan-engine/helpers"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestLogger puts a logger carrying the request ID, the method and the route in the request
+// context, then logs the request once it is handled. It goes after RequestID and the tracing middleware.
+func RequestLogger(logger *config.AmarthaLogger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+
+		// the route keeps the path parameters out, so requests of the same endpoint are grouped
+		route := ctx.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		fields := logger.With().
+			Str("request_id", helpers.RequestIDFromContext(ctx.Request.Context())).
+			Str("method", ctx.Request.Method).
+			Str("route", route)
+		// set when the tracing middleware runs first, so a log line leads to its trace
+		if spanContext := trace.SpanContextFromContext(ctx.Request.Context()); spanContext.HasTraceID() {
+			fields = fields.Str("trace_id", spanContext.TraceID().String())
+		}
+		requestLogger := fields.Logger()
+		ctx.Request = ctx.Request.WithContext(requestLogger.WithContext(ctx.Request.Context()))
+
+		ctx.Next()
+
+		// picked after the request so the authenticated user is logged too
+		handledLogger := logger.ForContext(ctx.Request.Context())
+		status := ctx.Writer.Status()
+		event := handledLogger.Info()
+		switch {
+		case status >= http.StatusInternalServerError:
+			event = handledLogger.Error()
+		case status >= http.StatusBadRequest:
+			event = handledLogger.Warn()
+		}
+		event.
+			Str("path", ctx.Request.URL.Path).
+			Int("status", status).
+			Int("size", ctx.Writer.Size()).
+			Str("client_ip", ctx.ClientIP()).
+			Dur("duration_ms", time.Since(start)).
+			Msg("request handled")
+	}
+}
@@ -0,0 +1,118 @@
+package middlewares
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/satryarangga/amartha-loan-engine/config"
+	"github.com/satryarangga/amartha-loan-engine/helpers"
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func decodeLogLines(t *testing.T, output *bytes.Buffer) []map[string]interface{} {
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(output)
+	for scanner.Scan() {
+		var line map[string]interface{}
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestRequestLogger_PropagatesRequestFields(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	var output bytes.Buffer
+	logger := config.AmarthaLogger{Logger: zerolog.New(&output)}
+
+	router := gin.New()
+	router.Use(RequestID(), RequestLogger(&logger))
+	router.GET("/loans/:id", func(ctx *gin.Context) {
+		// stands for the auth middleware, which runs after the request logger
+		ctx.Request = ctx.Request.WithContext(helpers.WithPrincipal(ctx.Request.Context(), models.Principal{Subject: "user-1", Role: models.RoleAdmin}))
+		logger.Infof(ctx.Request.Context(), "loading loan %s", ctx.Param("id"))
+		ctx.Status(http.StatusNotFound)
+	})
+	request := httptest.NewRequest(http.MethodGet, "/loans/loan-1", nil)
+	request.Header.Set(RequestIDHeader, "request-1")
+
+	// Act
+	router.ServeHTTP(httptest.NewRecorder(), request)
+
+	// Assert
+	lines := decodeLogLines(t, &output)
+	assert.Len(t, lines, 2)
+
+	handlerLine := lines[0]
+	assert.Equal(t, "loading loan loan-1", handlerLine["message"])
+	assert.Equal(t, "request-1", handlerLine["request_id"])
+	assert.Equal(t, "/loans/:id", handlerLine["route"])
+	assert.Equal(t, "GET", handlerLine["method"])
+	assert.Equal(t, "user-1", handlerLine["user"])
+
+	requestLine := lines[1]
+	assert.Equal(t, "request handled", requestLine["message"])
+	assert.Equal(t, "warn", requestLine["level"])
+	assert.Equal(t, "request-1", requestLine["request_id"])
+	assert.Equal(t, "/loans/loan-1", requestLine["path"])
+	assert.Equal(t, float64(http.StatusNotFound), requestLine["status"])
+	assert.Equal(t, "user-1", requestLine["user"])
+	assert.Contains(t, requestLine, "duration_ms")
+}
+
+func TestRequestLogger_OutsideOfRequest(t *testing.T) {
+	// Arrange
+	var output bytes.Buffer
+	logger := config.AmarthaLogger{Logger: zerolog.New(&output)}
+	ctx := helpers.WithRequestID(httptest.NewRequest(http.MethodGet, "/", nil).Context(), "request-1")
+
+	// Act
+	logger.Errorf(ctx, "relay failed")
+
+	// Assert
+	// without a request-scoped logger the request ID is still taken from the context
+	lines := decodeLogLines(t, &output)
+	assert.Len(t, lines, 1)
+	assert.Equal(t, "relay failed", lines[0]["message"])
+	assert.Equal(t, "request-1", lines[0]["request_id"])
+	assert.NotContains(t, lines[0], "route")
+}
+
+func TestRequestLogger_LogsTraceID(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	var output bytes.Buffer
+	logger := config.AmarthaLogger{Logger: zerolog.New(&output)}
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	router := gin.New()
+	router.Use(RequestID(), otelgin.Middleware("test", otelgin.WithTracerProvider(provider)), RequestLogger(&logger))
+	router.GET("/loans/:id", func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	// Act
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/loans/loan-1", nil))
+
+	// Assert
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "/loans/:id", spans[0].Name())
+
+	lines := decodeLogLines(t, &output)
+	assert.Len(t, lines, 1)
+	assert.Equal(t, spans[0].SpanContext().TraceID().String(), lines[0]["trace_id"])
+}
@@ -5,8 +5,6 @@ package mock
 import (
 	context "context"
 
-	gorm "gorm.io/gorm"
-
 	mock "github.com/stretchr/testify/mock"
 
 	models "github.com/satryarangga/amartha-loan-engine/models"
@@ -19,6 +17,98 @@ type CommonRepository[T interface{}] struct {
 	mock.Mock
 }
 
+// BulkInsert provides a mock function with given fields: ctx, records, batchSize
+func (_m *CommonRepository[T]) BulkInsert(ctx context.Context, records []T, batchSize int) error {
+	ret := _m.Called(ctx, records, batchSize)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BulkInsert")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []T, int) error); ok {
+		r0 = rf(ctx, records, batchSize)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Count provides a mock function with given fields: ctx, param
+func (_m *CommonRepository[T]) Count(ctx context.Context, param models.FindAllParam) (int64, error) {
+	ret := _m.Called(ctx, param)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Count")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.FindAllParam) (int64, error)); ok {
+		return rf(ctx, param)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.FindAllParam) int64); ok {
+		r0 = rf(ctx, param)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.FindAllParam) error); ok {
+		r1 = rf(ctx, param)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Delete provides a mock function with given fields: ctx, model
+func (_m *CommonRepository[T]) Delete(ctx context.Context, model *T) error {
+	ret := _m.Called(ctx, model)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *T) error); ok {
+		r0 = rf(ctx, model)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Exists provides a mock function with given fields: ctx, param
+func (_m *CommonRepository[T]) Exists(ctx context.Context, param models.FindAllParam) (bool, error) {
+	ret := _m.Called(ctx, param)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Exists")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.FindAllParam) (bool, error)); ok {
+		return rf(ctx, param)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.FindAllParam) bool); ok {
+		r0 = rf(ctx, param)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.FindAllParam) error); ok {
+		r1 = rf(ctx, param)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindAll provides a mock function with given fields: ctx, param
 func (_m *CommonRepository[T]) FindAll(ctx context.Context, param models.FindAllParam) ([]T, error) {
 	ret := _m.Called(ctx, param)
@@ -49,6 +139,73 @@ func (_m *CommonRepository[T]) FindAll(ctx context.Context, param models.FindAll
 	return r0, r1
 }
 
+// FindAllByCursor provides a mock function with given fields: ctx, param
+func (_m *CommonRepository[T]) FindAllByCursor(ctx context.Context, param models.FindAllParam) ([]T, string, error) {
+	ret := _m.Called(ctx, param)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindAllByCursor")
+	}
+
+	var r0 []T
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.FindAllParam) ([]T, string, error)); ok {
+		return rf(ctx, param)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.FindAllParam) []T); ok {
+		r0 = rf(ctx, param)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]T)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.FindAllParam) string); ok {
+		r1 = rf(ctx, param)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, models.FindAllParam) error); ok {
+		r2 = rf(ctx, param)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// FindAllForUpdate provides a mock function with given fields: ctx, param
+func (_m *CommonRepository[T]) FindAllForUpdate(ctx context.Context, param models.FindAllParam) ([]T, error) {
+	ret := _m.Called(ctx, param)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindAllForUpdate")
+	}
+
+	var r0 []T
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.FindAllParam) ([]T, error)); ok {
+		return rf(ctx, param)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.FindAllParam) []T); ok {
+		r0 = rf(ctx, param)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]T)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.FindAllParam) error); ok {
+		r1 = rf(ctx, param)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindByID provides a mock function with given fields: ctx, id, relations
 func (_m *CommonRepository[T]) FindByID(ctx context.Context, id string, relations []string) (*T, error) {
 	ret := _m.Called(ctx, id, relations)
@@ -79,9 +236,39 @@ func (_m *CommonRepository[T]) FindByID(ctx context.Context, id string, relation
 	return r0, r1
 }
 
-// Insert provides a mock function with given fields: ctx, tx, model
-func (_m *CommonRepository[T]) Insert(ctx context.Context, tx *gorm.DB, model *T) (string, error) {
-	ret := _m.Called(ctx, tx, model)
+// FindByIDForUpdate provides a mock function with given fields: ctx, id, relations
+func (_m *CommonRepository[T]) FindByIDForUpdate(ctx context.Context, id string, relations []string) (*T, error) {
+	ret := _m.Called(ctx, id, relations)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByIDForUpdate")
+	}
+
+	var r0 *T
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) (*T, error)); ok {
+		return rf(ctx, id, relations)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) *T); ok {
+		r0 = rf(ctx, id, relations)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*T)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string) error); ok {
+		r1 = rf(ctx, id, relations)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Insert provides a mock function with given fields: ctx, model
+func (_m *CommonRepository[T]) Insert(ctx context.Context, model *T) (string, error) {
+	ret := _m.Called(ctx, model)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Insert")
@@ -89,17 +276,17 @@ func (_m *CommonRepository[T]) Insert(ctx context.Context, tx *gorm.DB, model *T
 
 	var r0 string
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, *gorm.DB, *T) (string, error)); ok {
-		return rf(ctx, tx, model)
+	if rf, ok := ret.Get(0).(func(context.Context, *T) (string, error)); ok {
+		return rf(ctx, model)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, *gorm.DB, *T) string); ok {
-		r0 = rf(ctx, tx, model)
+	if rf, ok := ret.Get(0).(func(context.Context, *T) string); ok {
+		r0 = rf(ctx, model)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, *gorm.DB, *T) error); ok {
-		r1 = rf(ctx, tx, model)
+	if rf, ok := ret.Get(1).(func(context.Context, *T) error); ok {
+		r1 = rf(ctx, model)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -107,17 +294,35 @@ func (_m *CommonRepository[T]) Insert(ctx context.Context, tx *gorm.DB, model *T
 	return r0, r1
 }
 
-// Update provides a mock function with given fields: ctx, tx, model
-func (_m *CommonRepository[T]) Update(ctx context.Context, tx *gorm.DB, model *T) error {
-	ret := _m.Called(ctx, tx, model)
+// Update provides a mock function with given fields: ctx, model
+func (_m *CommonRepository[T]) Update(ctx context.Context, model *T) error {
+	ret := _m.Called(ctx, model)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Update")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *gorm.DB, *T) error); ok {
-		r0 = rf(ctx, tx, model)
+	if rf, ok := ret.Get(0).(func(context.Context, *T) error); ok {
+		r0 = rf(ctx, model)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateFields provides a mock function with given fields: ctx, id, fields
+func (_m *CommonRepository[T]) UpdateFields(ctx context.Context, id string, fields map[string]interface{}) error {
+	ret := _m.Called(ctx, id, fields)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateFields")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, map[string]interface{}) error); ok {
+		r0 = rf(ctx, id, fields)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -5,8 +5,6 @@ package mock
 import (
 	context "context"
 
-	gorm "gorm.io/gorm"
-
 	mock "github.com/stretchr/testify/mock"
 
 	models "github.com/satryarangga/amartha-loan-engine/models"
@@ -19,6 +17,98 @@ type LoanPaymentRepository struct {
 	mock.Mock
 }
 
+// BulkInsert provides a mock function with given fields: ctx, records, batchSize
+func (_m *LoanPaymentRepository) BulkInsert(ctx context.Context, records []models.LoanPayment, batchSize int) error {
+	ret := _m.Called(ctx, records, batchSize)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BulkInsert")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []models.LoanPayment, int) error); ok {
+		r0 = rf(ctx, records, batchSize)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Count provides a mock function with given fields: ctx, param
+func (_m *LoanPaymentRepository) Count(ctx context.Context, param models.FindAllParam) (int64, error) {
+	ret := _m.Called(ctx, param)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Count")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.FindAllParam) (int64, error)); ok {
+		return rf(ctx, param)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.FindAllParam) int64); ok {
+		r0 = rf(ctx, param)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.FindAllParam) error); ok {
+		r1 = rf(ctx, param)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Delete provides a mock function with given fields: ctx, model
+func (_m *LoanPaymentRepository) Delete(ctx context.Context, model *models.LoanPayment) error {
+	ret := _m.Called(ctx, model)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.LoanPayment) error); ok {
+		r0 = rf(ctx, model)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Exists provides a mock function with given fields: ctx, param
+func (_m *LoanPaymentRepository) Exists(ctx context.Context, param models.FindAllParam) (bool, error) {
+	ret := _m.Called(ctx, param)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Exists")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.FindAllParam) (bool, error)); ok {
+		return rf(ctx, param)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.FindAllParam) bool); ok {
+		r0 = rf(ctx, param)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.FindAllParam) error); ok {
+		r1 = rf(ctx, param)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindAll provides a mock function with given fields: ctx, param
 func (_m *LoanPaymentRepository) FindAll(ctx context.Context, param models.FindAllParam) ([]models.LoanPayment, error) {
 	ret := _m.Called(ctx, param)
@@ -49,6 +139,73 @@ func (_m *LoanPaymentRepository) FindAll(ctx context.Context, param models.FindA
 	return r0, r1
 }
 
+// FindAllByCursor provides a mock function with given fields: ctx, param
+func (_m *LoanPaymentRepository) FindAllByCursor(ctx context.Context, param models.FindAllParam) ([]models.LoanPayment, string, error) {
+	ret := _m.Called(ctx, param)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindAllByCursor")
+	}
+
+	var r0 []models.LoanPayment
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.FindAllParam) ([]models.LoanPayment, string, error)); ok {
+		return rf(ctx, param)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.FindAllParam) []models.LoanPayment); ok {
+		r0 = rf(ctx, param)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.LoanPayment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.FindAllParam) string); ok {
+		r1 = rf(ctx, param)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, models.FindAllParam) error); ok {
+		r2 = rf(ctx, param)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// FindAllForUpdate provides a mock function with given fields: ctx, param
+func (_m *LoanPaymentRepository) FindAllForUpdate(ctx context.Context, param models.FindAllParam) ([]models.LoanPayment, error) {
+	ret := _m.Called(ctx, param)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindAllForUpdate")
+	}
+
+	var r0 []models.LoanPayment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.FindAllParam) ([]models.LoanPayment, error)); ok {
+		return rf(ctx, param)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.FindAllParam) []models.LoanPayment); ok {
+		r0 = rf(ctx, param)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.LoanPayment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.FindAllParam) error); ok {
+		r1 = rf(ctx, param)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindByID provides a mock function with given fields: ctx, id, relations
 func (_m *LoanPaymentRepository) FindByID(ctx context.Context, id string, relations []string) (*models.LoanPayment, error) {
 	ret := _m.Called(ctx, id, relations)
@@ -79,9 +236,39 @@ func (_m *LoanPaymentRepository) FindByID(ctx context.Context, id string, relati
 	return r0, r1
 }
 
-// Insert provides a mock function with given fields: ctx, tx, model
-func (_m *LoanPaymentRepository) Insert(ctx context.Context, tx *gorm.DB, model *models.LoanPayment) (string, error) {
-	ret := _m.Called(ctx, tx, model)
+// FindByIDForUpdate provides a mock function with given fields: ctx, id, relations
+func (_m *LoanPaymentRepository) FindByIDForUpdate(ctx context.Context, id string, relations []string) (*models.LoanPayment, error) {
+	ret := _m.Called(ctx, id, relations)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByIDForUpdate")
+	}
+
+	var r0 *models.LoanPayment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) (*models.LoanPayment, error)); ok {
+		return rf(ctx, id, relations)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) *models.LoanPayment); ok {
+		r0 = rf(ctx, id, relations)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.LoanPayment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string) error); ok {
+		r1 = rf(ctx, id, relations)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Insert provides a mock function with given fields: ctx, model
+func (_m *LoanPaymentRepository) Insert(ctx context.Context, model *models.LoanPayment) (string, error) {
+	ret := _m.Called(ctx, model)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Insert")
@@ -89,17 +276,17 @@ func (_m *LoanPaymentRepository) Insert(ctx context.Context, tx *gorm.DB, model
 
 	var r0 string
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, *gorm.DB, *models.LoanPayment) (string, error)); ok {
-		return rf(ctx, tx, model)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.LoanPayment) (string, error)); ok {
+		return rf(ctx, model)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, *gorm.DB, *models.LoanPayment) string); ok {
-		r0 = rf(ctx, tx, model)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.LoanPayment) string); ok {
+		r0 = rf(ctx, model)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, *gorm.DB, *models.LoanPayment) error); ok {
-		r1 = rf(ctx, tx, model)
+	if rf, ok := ret.Get(1).(func(context.Context, *models.LoanPayment) error); ok {
+		r1 = rf(ctx, model)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -107,17 +294,35 @@ func (_m *LoanPaymentRepository) Insert(ctx context.Context, tx *gorm.DB, model
 	return r0, r1
 }
 
-// Update provides a mock function with given fields: ctx, tx, model
-func (_m *LoanPaymentRepository) Update(ctx context.Context, tx *gorm.DB, model *models.LoanPayment) error {
-	ret := _m.Called(ctx, tx, model)
+// Update provides a mock function with given fields: ctx, model
+func (_m *LoanPaymentRepository) Update(ctx context.Context, model *models.LoanPayment) error {
+	ret := _m.Called(ctx, model)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Update")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *gorm.DB, *models.LoanPayment) error); ok {
-		r0 = rf(ctx, tx, model)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.LoanPayment) error); ok {
+		r0 = rf(ctx, model)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateFields provides a mock function with given fields: ctx, id, fields
+func (_m *LoanPaymentRepository) UpdateFields(ctx context.Context, id string, fields map[string]interface{}) error {
+	ret := _m.Called(ctx, id, fields)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateFields")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, map[string]interface{}) error); ok {
+		r0 = rf(ctx, id, fields)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -5,13 +5,13 @@ package mock
 import (
 	context "context"
 
-	gorm "gorm.io/gorm"
-
 	mock "github.com/stretchr/testify/mock"
 
 	models "github.com/satryarangga/amartha-loan-engine/models"
 
 	repositories "github.com/satryarangga/amartha-loan-engine/repositories"
+
+	time "time"
 )
 
 // LoanRepository is an autogenerated mock type for the LoanRepository type
@@ -19,6 +19,98 @@ type LoanRepository struct {
 	mock.Mock
 }
 
+// BulkInsert provides a mock function with given fields: ctx, records, batchSize
+func (_m *LoanRepository) BulkInsert(ctx context.Context, records []models.Loan, batchSize int) error {
+	ret := _m.Called(ctx, records, batchSize)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BulkInsert")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []models.Loan, int) error); ok {
+		r0 = rf(ctx, records, batchSize)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Count provides a mock function with given fields: ctx, param
+func (_m *LoanRepository) Count(ctx context.Context, param models.FindAllParam) (int64, error) {
+	ret := _m.Called(ctx, param)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Count")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.FindAllParam) (int64, error)); ok {
+		return rf(ctx, param)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.FindAllParam) int64); ok {
+		r0 = rf(ctx, param)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.FindAllParam) error); ok {
+		r1 = rf(ctx, param)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Delete provides a mock function with given fields: ctx, model
+func (_m *LoanRepository) Delete(ctx context.Context, model *models.Loan) error {
+	ret := _m.Called(ctx, model)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Loan) error); ok {
+		r0 = rf(ctx, model)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Exists provides a mock function with given fields: ctx, param
+func (_m *LoanRepository) Exists(ctx context.Context, param models.FindAllParam) (bool, error) {
+	ret := _m.Called(ctx, param)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Exists")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.FindAllParam) (bool, error)); ok {
+		return rf(ctx, param)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.FindAllParam) bool); ok {
+		r0 = rf(ctx, param)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.FindAllParam) error); ok {
+		r1 = rf(ctx, param)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindAll provides a mock function with given fields: ctx, param
 func (_m *LoanRepository) FindAll(ctx context.Context, param models.FindAllParam) ([]models.Loan, error) {
 	ret := _m.Called(ctx, param)
@@ -49,6 +141,110 @@ func (_m *LoanRepository) FindAll(ctx context.Context, param models.FindAllParam
 	return r0, r1
 }
 
+// FindAllByCursor provides a mock function with given fields: ctx, param
+func (_m *LoanRepository) FindAllByCursor(ctx context.Context, param models.FindAllParam) ([]models.Loan, string, error) {
+	ret := _m.Called(ctx, param)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindAllByCursor")
+	}
+
+	var r0 []models.Loan
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.FindAllParam) ([]models.Loan, string, error)); ok {
+		return rf(ctx, param)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.FindAllParam) []models.Loan); ok {
+		r0 = rf(ctx, param)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Loan)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.FindAllParam) string); ok {
+		r1 = rf(ctx, param)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, models.FindAllParam) error); ok {
+		r2 = rf(ctx, param)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// FindAllByFilter provides a mock function with given fields: ctx, filter, param
+func (_m *LoanRepository) FindAllByFilter(ctx context.Context, filter models.LoanFilter, param models.FindAllParam) ([]models.Loan, string, error) {
+	ret := _m.Called(ctx, filter, param)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindAllByFilter")
+	}
+
+	var r0 []models.Loan
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.LoanFilter, models.FindAllParam) ([]models.Loan, string, error)); ok {
+		return rf(ctx, filter, param)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.LoanFilter, models.FindAllParam) []models.Loan); ok {
+		r0 = rf(ctx, filter, param)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Loan)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.LoanFilter, models.FindAllParam) string); ok {
+		r1 = rf(ctx, filter, param)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, models.LoanFilter, models.FindAllParam) error); ok {
+		r2 = rf(ctx, filter, param)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// FindAllForUpdate provides a mock function with given fields: ctx, param
+func (_m *LoanRepository) FindAllForUpdate(ctx context.Context, param models.FindAllParam) ([]models.Loan, error) {
+	ret := _m.Called(ctx, param)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindAllForUpdate")
+	}
+
+	var r0 []models.Loan
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.FindAllParam) ([]models.Loan, error)); ok {
+		return rf(ctx, param)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.FindAllParam) []models.Loan); ok {
+		r0 = rf(ctx, param)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Loan)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.FindAllParam) error); ok {
+		r1 = rf(ctx, param)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindByID provides a mock function with given fields: ctx, id, relations
 func (_m *LoanRepository) FindByID(ctx context.Context, id string, relations []string) (*models.Loan, error) {
 	ret := _m.Called(ctx, id, relations)
@@ -79,6 +275,36 @@ func (_m *LoanRepository) FindByID(ctx context.Context, id string, relations []s
 	return r0, r1
 }
 
+// FindByIDForUpdate provides a mock function with given fields: ctx, id, relations
+func (_m *LoanRepository) FindByIDForUpdate(ctx context.Context, id string, relations []string) (*models.Loan, error) {
+	ret := _m.Called(ctx, id, relations)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByIDForUpdate")
+	}
+
+	var r0 *models.Loan
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) (*models.Loan, error)); ok {
+		return rf(ctx, id, relations)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) *models.Loan); ok {
+		r0 = rf(ctx, id, relations)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Loan)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string) error); ok {
+		r1 = rf(ctx, id, relations)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindOneByBorrowerID provides a mock function with given fields: ctx, borrowerID
 func (_m *LoanRepository) FindOneByBorrowerID(ctx context.Context, borrowerID string) (models.Loan, error) {
 	ret := _m.Called(ctx, borrowerID)
@@ -107,9 +333,37 @@ func (_m *LoanRepository) FindOneByBorrowerID(ctx context.Context, borrowerID st
 	return r0, r1
 }
 
-// Insert provides a mock function with given fields: ctx, tx, model
-func (_m *LoanRepository) Insert(ctx context.Context, tx *gorm.DB, model *models.Loan) (string, error) {
-	ret := _m.Called(ctx, tx, model)
+// GetPortfolioStats provides a mock function with given fields: ctx, now
+func (_m *LoanRepository) GetPortfolioStats(ctx context.Context, now time.Time) (models.PortfolioStats, error) {
+	ret := _m.Called(ctx, now)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPortfolioStats")
+	}
+
+	var r0 models.PortfolioStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (models.PortfolioStats, error)); ok {
+		return rf(ctx, now)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) models.PortfolioStats); ok {
+		r0 = rf(ctx, now)
+	} else {
+		r0 = ret.Get(0).(models.PortfolioStats)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Insert provides a mock function with given fields: ctx, model
+func (_m *LoanRepository) Insert(ctx context.Context, model *models.Loan) (string, error) {
+	ret := _m.Called(ctx, model)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Insert")
@@ -117,17 +371,17 @@ func (_m *LoanRepository) Insert(ctx context.Context, tx *gorm.DB, model *models
 
 	var r0 string
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, *gorm.DB, *models.Loan) (string, error)); ok {
-		return rf(ctx, tx, model)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Loan) (string, error)); ok {
+		return rf(ctx, model)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, *gorm.DB, *models.Loan) string); ok {
-		r0 = rf(ctx, tx, model)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Loan) string); ok {
+		r0 = rf(ctx, model)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, *gorm.DB, *models.Loan) error); ok {
-		r1 = rf(ctx, tx, model)
+	if rf, ok := ret.Get(1).(func(context.Context, *models.Loan) error); ok {
+		r1 = rf(ctx, model)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -135,17 +389,35 @@ func (_m *LoanRepository) Insert(ctx context.Context, tx *gorm.DB, model *models
 	return r0, r1
 }
 
-// Update provides a mock function with given fields: ctx, tx, model
-func (_m *LoanRepository) Update(ctx context.Context, tx *gorm.DB, model *models.Loan) error {
-	ret := _m.Called(ctx, tx, model)
+// Update provides a mock function with given fields: ctx, model
+func (_m *LoanRepository) Update(ctx context.Context, model *models.Loan) error {
+	ret := _m.Called(ctx, model)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Update")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *gorm.DB, *models.Loan) error); ok {
-		r0 = rf(ctx, tx, model)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Loan) error); ok {
+		r0 = rf(ctx, model)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateFields provides a mock function with given fields: ctx, id, fields
+func (_m *LoanRepository) UpdateFields(ctx context.Context, id string, fields map[string]interface{}) error {
+	ret := _m.Called(ctx, id, fields)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateFields")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, map[string]interface{}) error); ok {
+		r0 = rf(ctx, id, fields)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -5,13 +5,13 @@ package mock
 import (
 	context "context"
 
-	gorm "gorm.io/gorm"
-
 	mock "github.com/stretchr/testify/mock"
 
 	models "github.com/satryarangga/amartha-loan-engine/models"
 
 	repositories "github.com/satryarangga/amartha-loan-engine/repositories"
+
+	time "time"
 )
 
 // LoanScheduleRepository is an autogenerated mock type for the LoanScheduleRepository type
@@ -19,6 +19,98 @@ type LoanScheduleRepository struct {
 	mock.Mock
 }
 
+// BulkInsert provides a mock function with given fields: ctx, records, batchSize
+func (_m *LoanScheduleRepository) BulkInsert(ctx context.Context, records []models.LoanSchedule, batchSize int) error {
+	ret := _m.Called(ctx, records, batchSize)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BulkInsert")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []models.LoanSchedule, int) error); ok {
+		r0 = rf(ctx, records, batchSize)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Count provides a mock function with given fields: ctx, param
+func (_m *LoanScheduleRepository) Count(ctx context.Context, param models.FindAllParam) (int64, error) {
+	ret := _m.Called(ctx, param)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Count")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.FindAllParam) (int64, error)); ok {
+		return rf(ctx, param)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.FindAllParam) int64); ok {
+		r0 = rf(ctx, param)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.FindAllParam) error); ok {
+		r1 = rf(ctx, param)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Delete provides a mock function with given fields: ctx, model
+func (_m *LoanScheduleRepository) Delete(ctx context.Context, model *models.LoanSchedule) error {
+	ret := _m.Called(ctx, model)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.LoanSchedule) error); ok {
+		r0 = rf(ctx, model)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Exists provides a mock function with given fields: ctx, param
+func (_m *LoanScheduleRepository) Exists(ctx context.Context, param models.FindAllParam) (bool, error) {
+	ret := _m.Called(ctx, param)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Exists")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.FindAllParam) (bool, error)); ok {
+		return rf(ctx, param)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.FindAllParam) bool); ok {
+		r0 = rf(ctx, param)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.FindAllParam) error); ok {
+		r1 = rf(ctx, param)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindAll provides a mock function with given fields: ctx, param
 func (_m *LoanScheduleRepository) FindAll(ctx context.Context, param models.FindAllParam) ([]models.LoanSchedule, error) {
 	ret := _m.Called(ctx, param)
@@ -49,6 +141,73 @@ func (_m *LoanScheduleRepository) FindAll(ctx context.Context, param models.Find
 	return r0, r1
 }
 
+// FindAllByCursor provides a mock function with given fields: ctx, param
+func (_m *LoanScheduleRepository) FindAllByCursor(ctx context.Context, param models.FindAllParam) ([]models.LoanSchedule, string, error) {
+	ret := _m.Called(ctx, param)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindAllByCursor")
+	}
+
+	var r0 []models.LoanSchedule
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.FindAllParam) ([]models.LoanSchedule, string, error)); ok {
+		return rf(ctx, param)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.FindAllParam) []models.LoanSchedule); ok {
+		r0 = rf(ctx, param)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.LoanSchedule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.FindAllParam) string); ok {
+		r1 = rf(ctx, param)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, models.FindAllParam) error); ok {
+		r2 = rf(ctx, param)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// FindAllForUpdate provides a mock function with given fields: ctx, param
+func (_m *LoanScheduleRepository) FindAllForUpdate(ctx context.Context, param models.FindAllParam) ([]models.LoanSchedule, error) {
+	ret := _m.Called(ctx, param)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindAllForUpdate")
+	}
+
+	var r0 []models.LoanSchedule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.FindAllParam) ([]models.LoanSchedule, error)); ok {
+		return rf(ctx, param)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.FindAllParam) []models.LoanSchedule); ok {
+		r0 = rf(ctx, param)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.LoanSchedule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.FindAllParam) error); ok {
+		r1 = rf(ctx, param)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindByID provides a mock function with given fields: ctx, id, relations
 func (_m *LoanScheduleRepository) FindByID(ctx context.Context, id string, relations []string) (*models.LoanSchedule, error) {
 	ret := _m.Called(ctx, id, relations)
@@ -79,9 +238,39 @@ func (_m *LoanScheduleRepository) FindByID(ctx context.Context, id string, relat
 	return r0, r1
 }
 
-// FindDueRepaymentSchedules provides a mock function with given fields: ctx, loanID
-func (_m *LoanScheduleRepository) FindDueRepaymentSchedules(ctx context.Context, loanID string) ([]models.LoanSchedule, error) {
-	ret := _m.Called(ctx, loanID)
+// FindByIDForUpdate provides a mock function with given fields: ctx, id, relations
+func (_m *LoanScheduleRepository) FindByIDForUpdate(ctx context.Context, id string, relations []string) (*models.LoanSchedule, error) {
+	ret := _m.Called(ctx, id, relations)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByIDForUpdate")
+	}
+
+	var r0 *models.LoanSchedule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) (*models.LoanSchedule, error)); ok {
+		return rf(ctx, id, relations)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) *models.LoanSchedule); ok {
+		r0 = rf(ctx, id, relations)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.LoanSchedule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string) error); ok {
+		r1 = rf(ctx, id, relations)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindDueRepaymentSchedules provides a mock function with given fields: ctx, loanID, dueBefore
+func (_m *LoanScheduleRepository) FindDueRepaymentSchedules(ctx context.Context, loanID string, dueBefore time.Time) ([]models.LoanSchedule, error) {
+	ret := _m.Called(ctx, loanID, dueBefore)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FindDueRepaymentSchedules")
@@ -89,19 +278,49 @@ func (_m *LoanScheduleRepository) FindDueRepaymentSchedules(ctx context.Context,
 
 	var r0 []models.LoanSchedule
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) ([]models.LoanSchedule, error)); ok {
-		return rf(ctx, loanID)
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) ([]models.LoanSchedule, error)); ok {
+		return rf(ctx, loanID, dueBefore)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) []models.LoanSchedule); ok {
+		r0 = rf(ctx, loanID, dueBefore)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.LoanSchedule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = rf(ctx, loanID, dueBefore)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindNewlyOverdueForUpdate provides a mock function with given fields: ctx, now, limit
+func (_m *LoanScheduleRepository) FindNewlyOverdueForUpdate(ctx context.Context, now time.Time, limit int) ([]models.LoanSchedule, error) {
+	ret := _m.Called(ctx, now, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindNewlyOverdueForUpdate")
+	}
+
+	var r0 []models.LoanSchedule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) ([]models.LoanSchedule, error)); ok {
+		return rf(ctx, now, limit)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) []models.LoanSchedule); ok {
-		r0 = rf(ctx, loanID)
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) []models.LoanSchedule); ok {
+		r0 = rf(ctx, now, limit)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]models.LoanSchedule)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, loanID)
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int) error); ok {
+		r1 = rf(ctx, now, limit)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -109,9 +328,9 @@ func (_m *LoanScheduleRepository) FindDueRepaymentSchedules(ctx context.Context,
 	return r0, r1
 }
 
-// Insert provides a mock function with given fields: ctx, tx, model
-func (_m *LoanScheduleRepository) Insert(ctx context.Context, tx *gorm.DB, model *models.LoanSchedule) (string, error) {
-	ret := _m.Called(ctx, tx, model)
+// Insert provides a mock function with given fields: ctx, model
+func (_m *LoanScheduleRepository) Insert(ctx context.Context, model *models.LoanSchedule) (string, error) {
+	ret := _m.Called(ctx, model)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Insert")
@@ -119,17 +338,17 @@ func (_m *LoanScheduleRepository) Insert(ctx context.Context, tx *gorm.DB, model
 
 	var r0 string
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, *gorm.DB, *models.LoanSchedule) (string, error)); ok {
-		return rf(ctx, tx, model)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.LoanSchedule) (string, error)); ok {
+		return rf(ctx, model)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, *gorm.DB, *models.LoanSchedule) string); ok {
-		r0 = rf(ctx, tx, model)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.LoanSchedule) string); ok {
+		r0 = rf(ctx, model)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, *gorm.DB, *models.LoanSchedule) error); ok {
-		r1 = rf(ctx, tx, model)
+	if rf, ok := ret.Get(1).(func(context.Context, *models.LoanSchedule) error); ok {
+		r1 = rf(ctx, model)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -137,17 +356,53 @@ func (_m *LoanScheduleRepository) Insert(ctx context.Context, tx *gorm.DB, model
 	return r0, r1
 }
 
-// Update provides a mock function with given fields: ctx, tx, model
-func (_m *LoanScheduleRepository) Update(ctx context.Context, tx *gorm.DB, model *models.LoanSchedule) error {
-	ret := _m.Called(ctx, tx, model)
+// MarkOverdueByIDs provides a mock function with given fields: ctx, ids, overdueAt
+func (_m *LoanScheduleRepository) MarkOverdueByIDs(ctx context.Context, ids []string, overdueAt time.Time) error {
+	ret := _m.Called(ctx, ids, overdueAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkOverdueByIDs")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string, time.Time) error); ok {
+		r0 = rf(ctx, ids, overdueAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: ctx, model
+func (_m *LoanScheduleRepository) Update(ctx context.Context, model *models.LoanSchedule) error {
+	ret := _m.Called(ctx, model)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Update")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *gorm.DB, *models.LoanSchedule) error); ok {
-		r0 = rf(ctx, tx, model)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.LoanSchedule) error); ok {
+		r0 = rf(ctx, model)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateFields provides a mock function with given fields: ctx, id, fields
+func (_m *LoanScheduleRepository) UpdateFields(ctx context.Context, id string, fields map[string]interface{}) error {
+	ret := _m.Called(ctx, id, fields)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateFields")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, map[string]interface{}) error); ok {
+		r0 = rf(ctx, id, fields)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -155,17 +410,17 @@ func (_m *LoanScheduleRepository) Update(ctx context.Context, tx *gorm.DB, model
 	return r0
 }
 
-// UpdateStatusByIDs provides a mock function with given fields: ctx, tx, ids, status
-func (_m *LoanScheduleRepository) UpdateStatusByIDs(ctx context.Context, tx *gorm.DB, ids []string, status models.LoanScheduleStatus) error {
-	ret := _m.Called(ctx, tx, ids, status)
+// UpdateStatusByIDs provides a mock function with given fields: ctx, ids, status
+func (_m *LoanScheduleRepository) UpdateStatusByIDs(ctx context.Context, ids []string, status models.LoanScheduleStatus) error {
+	ret := _m.Called(ctx, ids, status)
 
 	if len(ret) == 0 {
 		panic("no return value specified for UpdateStatusByIDs")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *gorm.DB, []string, models.LoanScheduleStatus) error); ok {
-		r0 = rf(ctx, tx, ids, status)
+	if rf, ok := ret.Get(0).(func(context.Context, []string, models.LoanScheduleStatus) error); ok {
+		r0 = rf(ctx, ids, status)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -3,8 +3,9 @@
 package mock
 
 import (
+	context "context"
+
 	mock "github.com/stretchr/testify/mock"
-	gorm "gorm.io/gorm"
 )
 
 // TransactionFunc is an autogenerated mock type for the TransactionFunc type
@@ -12,17 +13,17 @@ type TransactionFunc struct {
 	mock.Mock
 }
 
-// Execute provides a mock function with given fields: tx
-func (_m *TransactionFunc) Execute(tx *gorm.DB) error {
-	ret := _m.Called(tx)
+// Execute provides a mock function with given fields: ctx
+func (_m *TransactionFunc) Execute(ctx context.Context) error {
+	ret := _m.Called(ctx)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Execute")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*gorm.DB) error); ok {
-		r0 = rf(tx)
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
 	} else {
 		r0 = ret.Error(0)
 	}
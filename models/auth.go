@@ -0,0 +1,23 @@
+package models
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	Subject     string
+	Role        Role
+	BorrowerID  string
+	Permissions []Permission
+}
+
+func (p Principal) HasPermission(permission Permission) bool {
+	for _, granted := range p.Permissions {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// IsBorrowerScoped tells whether the principal may only see the data of a single borrower
+func (p Principal) IsBorrowerScoped() bool {
+	return p.Role == RoleBorrower
+}
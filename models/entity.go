@@ -1,30 +1,67 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/lib/pq"
+	"gorm.io/gorm"
 )
 
 type Borrower struct {
-	ID          string    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	FirstName   string    `gorm:"not null" json:"first_name"`
-	LastName    string    `gorm:"not null" json:"last_name"`
-	PhoneNumber string    `gorm:"not null;unique" json:"phone_number"`
-	CreatedAt   time.Time `json:"-"`
-	UpdatedAt   time.Time `json:"-"`
+	ID          string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	FirstName   string         `gorm:"not null" json:"first_name" query:"sort,search"`
+	LastName    string         `gorm:"not null" json:"last_name" query:"sort,search"`
+	PhoneNumber string         `gorm:"not null;unique" json:"phone_number" query:"sort,search"`
+	KYCStatus   KYCStatus      `gorm:"not null;default:'unverified'" json:"kyc_status"`
+	CreatedAt   time.Time      `json:"-" query:"sort"`
+	UpdatedAt   time.Time      `json:"-"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+type BorrowerKYCProfile struct {
+	ID              string     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	BorrowerID      string     `gorm:"type:uuid;not null;unique" json:"borrower_id"`
+	NIK             string     `gorm:"not null;unique" json:"nik"`
+	DateOfBirth     time.Time  `gorm:"type:date;not null" json:"date_of_birth"`
+	Address         string     `gorm:"not null" json:"address"`
+	BusinessType    string     `gorm:"not null" json:"business_type"`
+	MonthlyIncome   float64    `gorm:"not null" json:"monthly_income"`
+	RejectionReason string     `json:"rejection_reason,omitempty"`
+	ReviewedAt      *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+
+	Borrower Borrower `gorm:"foreignKey:BorrowerID" json:"-"`
+}
+
+type BorrowerDocument struct {
+	ID           string       `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	BorrowerID   string       `gorm:"type:uuid;not null" json:"borrower_id"`
+	DocumentType DocumentType `gorm:"not null" json:"document_type"`
+	StorageKey   string       `gorm:"not null" json:"-"`
+	FileName     string       `gorm:"not null" json:"file_name"`
+	ContentType  string       `gorm:"not null" json:"content_type"`
+	SizeBytes    int64        `gorm:"not null" json:"size_bytes"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+
+	Borrower Borrower `gorm:"foreignKey:BorrowerID" json:"-"`
 }
 
 type Loan struct {
 	ID                   string     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	BorrowerID           string     `gorm:"type:uuid;not null" json:"borrower_id"`
-	Amount               float64    `gorm:"not null" json:"amount"`
+	Amount               float64    `gorm:"not null" json:"amount" query:"sort"`
 	RepaymentCadenceDays int        `gorm:"not null" json:"repayment_cadence_days"`
 	RepaymentRepetition  int        `gorm:"not null" json:"repayment_repetition"`
 	InterestPercentage   float64    `gorm:"not null" json:"interest_percentage"`
 	InterestAmount       float64    `gorm:"not null" json:"interest_amount"`
 	Status               LoanStatus `gorm:"not null;default:'active'" json:"status"`
-	CreatedAt            time.Time  `json:"created_at"`
+	ProductCode          string     `gorm:"not null;default:'standard'" json:"product_code"`
+	DisbursedAt          time.Time  `gorm:"not null" json:"disbursed_at" query:"sort"`
+	Version              int64      `gorm:"not null;default:1" json:"version"`
+	CreatedAt            time.Time  `json:"created_at" query:"sort"`
 	UpdatedAt            time.Time  `json:"updated_at"`
 
 	Borrower      Borrower       `gorm:"foreignKey:BorrowerID" json:"-"`
@@ -40,6 +77,8 @@ type LoanSchedule struct {
 	InterestAmount float64            `gorm:"not null" json:"interest_amount"`
 	TotalPayment   float64            `gorm:"not null" json:"total_payment"`
 	Status         LoanScheduleStatus `gorm:"not null;default:'pending'" json:"status"`
+	OverdueAt      *time.Time         `json:"overdue_at,omitempty"`
+	Version        int64              `gorm:"not null;default:1" json:"version"`
 	CreatedAt      time.Time          `json:"created_at"`
 	UpdatedAt      time.Time          `json:"updated_at"`
 
@@ -53,8 +92,107 @@ type LoanPayment struct {
 	TotalPayment    float64           `gorm:"not null" json:"total_payment"`
 	PaymentMethod   string            `gorm:"not null" json:"payment_method"`
 	Status          LoanPaymentStatus `gorm:"not null;default:'pending'" json:"status"`
+	PaidAt          *time.Time        `json:"paid_at"`
+	Version         int64             `gorm:"not null;default:1" json:"version"`
 	CreatedAt       time.Time         `json:"created_at"`
 	UpdatedAt       time.Time         `json:"updated_at"`
 
 	Loan Loan `gorm:"foreignKey:LoanID" json:"loan,omitempty"`
 }
+
+// APIKey authenticates server-to-server clients, only the hash of the key is stored
+type APIKey struct {
+	ID           string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Name         string         `gorm:"not null" json:"name"`
+	KeyPrefix    string         `gorm:"not null;unique" json:"key_prefix"`
+	KeyHash      string         `gorm:"not null" json:"-"`
+	Permissions  pq.StringArray `gorm:"type:text[]" json:"permissions" swaggertype:"array,string"`
+	AllowedCIDRs pq.StringArray `gorm:"column:allowed_cidrs;type:text[]" json:"allowed_cidrs" swaggertype:"array,string"`
+	LastUsedAt   *time.Time     `json:"last_used_at"`
+	RotatedAt    *time.Time     `json:"rotated_at"`
+	RevokedAt    *time.Time     `json:"revoked_at"`
+	CreatedAt    time.Time      `json:"created_at" query:"sort"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+}
+
+// WebhookSubscription is a partner endpoint receiving the domain events it subscribed to, only for
+// the borrowers granted to the partner. The secret signs the deliveries so it is never returned.
+type WebhookSubscription struct {
+	ID         string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	PartnerID  string         `gorm:"not null" json:"partner_id"`
+	Name       string         `gorm:"not null" json:"name"`
+	URL        string         `gorm:"column:url;not null" json:"url"`
+	Secret     string         `gorm:"not null" json:"-"`
+	EventTypes pq.StringArray `gorm:"type:text[]" json:"event_types" swaggertype:"array,string"`
+	IsActive   bool           `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt  time.Time      `json:"created_at" query:"sort"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// PartnerBorrower grants a partner the events of a borrower
+type PartnerBorrower struct {
+	ID         string    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	PartnerID  string    `gorm:"not null" json:"partner_id"`
+	BorrowerID string    `gorm:"type:uuid;not null" json:"borrower_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// WebhookDelivery is the delivery of one event to one subscription, it keeps the outcome of the
+// last attempt. A manual redelivery is a new delivery pointing to the original one.
+type WebhookDelivery struct {
+	ID                 string                `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	SubscriptionID     string                `gorm:"type:uuid;not null" json:"subscription_id"`
+	EventID            string                `gorm:"type:uuid;not null" json:"event_id"`
+	EventType          EventType             `gorm:"not null" json:"event_type"`
+	Payload            json.RawMessage       `gorm:"type:jsonb;not null" json:"payload" swaggertype:"object"`
+	Status             WebhookDeliveryStatus `gorm:"not null;default:'pending'" json:"status"`
+	Attempts           int                   `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt      *time.Time            `json:"next_attempt_at"`
+	LastResponseStatus *int                  `json:"last_response_status"`
+	LastResponseBody   string                `json:"last_response_body"`
+	LastError          string                `json:"last_error"`
+	DeliveredAt        *time.Time            `json:"delivered_at"`
+	RedeliveryOf       *string               `gorm:"type:uuid" json:"redelivery_of"`
+	CreatedAt          time.Time             `json:"created_at" query:"sort"`
+	UpdatedAt          time.Time             `json:"updated_at"`
+
+	Subscription WebhookSubscription `gorm:"foreignKey:SubscriptionID" json:"-"`
+}
+
+// IdempotencyKey remembers the response of a request sent with an Idempotency-Key header, so a
+// retry of the same request gets the same response instead of being processed again. The key is
+// scoped to the caller, and LockedUntil lets another request take over a key left in progress.
+type IdempotencyKey struct {
+	ID           string     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Scope        string     `gorm:"not null" json:"scope"`
+	Key          string     `gorm:"not null" json:"key"`
+	RequestHash  string     `gorm:"not null" json:"request_hash"`
+	StatusCode   int        `json:"status_code"`
+	ContentType  string     `json:"content_type"`
+	ResponseBody []byte     `json:"-"`
+	LockedUntil  time.Time  `gorm:"not null" json:"locked_until"`
+	CompletedAt  *time.Time `json:"completed_at"`
+	ExpiresAt    time.Time  `gorm:"not null" json:"expires_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// AuditLog is an append-only record of a change made to an entity, Changes maps every changed
+// column to its value before and after the change
+type AuditLog struct {
+	ID         string          `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Actor      string          `gorm:"not null" json:"actor"`
+	ActorRole  Role            `json:"actor_role"`
+	Action     AuditAction     `gorm:"not null" json:"action"`
+	EntityType string          `gorm:"not null" json:"entity_type"`
+	EntityID   string          `gorm:"not null" json:"entity_id"`
+	Changes    json.RawMessage `gorm:"type:jsonb;not null" json:"changes" swaggertype:"object"`
+	RequestID  string          `json:"request_id"`
+	CreatedAt  time.Time       `json:"created_at" query:"sort"`
+}
+
+// AuditChange is the value of a column before and after a change, nil when the row didn't exist (anymore)
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
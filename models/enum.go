@@ -20,3 +20,156 @@ const (
 	LoanPaymentStatusPending LoanPaymentStatus = "pending"
 	LoanPaymentStatusPaid    LoanPaymentStatus = "paid"
 )
+
+// the payment methods offered by the payment gateway
+const (
+	PaymentMethodBankTransfer   = "bank_transfer"
+	PaymentMethodVirtualAccount = "virtual_account"
+	PaymentMethodRetailOutlet   = "retail_outlet"
+)
+
+var PaymentMethods = []string{PaymentMethodBankTransfer, PaymentMethodVirtualAccount, PaymentMethodRetailOutlet}
+
+// LoanProductCodes are the loan products known to the reporting, the loans of another product code
+// are still created but counted as "other" in the metrics
+var LoanProductCodes = []string{"standard"}
+
+type KYCStatus string
+
+const (
+	KYCStatusUnverified KYCStatus = "unverified"
+	KYCStatusPending    KYCStatus = "pending"
+	KYCStatusVerified   KYCStatus = "verified"
+	KYCStatusRejected   KYCStatus = "rejected"
+)
+
+type DocumentType string
+
+const (
+	DocumentTypeIDPhoto       DocumentType = "id_photo"
+	DocumentTypeSelfie        DocumentType = "selfie"
+	DocumentTypeBusinessPhoto DocumentType = "business_photo"
+)
+
+// RequiredKYCDocumentTypes lists the documents a borrower must upload before the KYC can be verified
+var RequiredKYCDocumentTypes = []DocumentType{
+	DocumentTypeIDPhoto,
+	DocumentTypeSelfie,
+	DocumentTypeBusinessPhoto,
+}
+
+// KYCDocumentContentTypes are the content types accepted for the KYC documents, detected from the
+// content itself as the type declared by the client can't be trusted
+var KYCDocumentContentTypes = []string{"image/jpeg", "image/png", "application/pdf"}
+
+type LoanInclude string
+
+const (
+	LoanIncludeSchedules LoanInclude = "schedules"
+	LoanIncludePayments  LoanInclude = "payments"
+	LoanIncludeBorrower  LoanInclude = "borrower"
+)
+
+type StatementEntryType string
+
+const (
+	StatementEntryTypeDisbursement StatementEntryType = "disbursement"
+	StatementEntryTypeInterest     StatementEntryType = "interest"
+	StatementEntryTypeInstallment  StatementEntryType = "installment"
+	StatementEntryTypePayment      StatementEntryType = "payment"
+)
+
+type StatementFormat string
+
+const (
+	StatementFormatJSON StatementFormat = "json"
+	StatementFormatCSV  StatementFormat = "csv"
+	StatementFormatPDF  StatementFormat = "pdf"
+)
+
+type Role string
+
+const (
+	RoleAdmin        Role = "admin"
+	RoleFieldOfficer Role = "field_officer"
+	RoleFinance      Role = "finance"
+	RoleBorrower     Role = "borrower"
+	RoleLender       Role = "lender"
+	// RolePaymentGateway is given to the payment gateway, it can only confirm payments
+	RolePaymentGateway Role = "payment_gateway"
+	// RoleService is given to API key clients, their permissions come from the key itself
+	RoleService Role = "service"
+)
+
+type Permission string
+
+const (
+	PermissionBorrowerRead  Permission = "borrowers:read"
+	PermissionBorrowerList  Permission = "borrowers:list"
+	PermissionBorrowerWrite Permission = "borrowers:write"
+	PermissionKYCRead       Permission = "kyc:read"
+	PermissionKYCWrite      Permission = "kyc:write"
+	PermissionKYCReview     Permission = "kyc:review"
+	PermissionLoanRead      Permission = "loans:read"
+	PermissionLoanWrite     Permission = "loans:write"
+	PermissionPaymentLink   Permission = "payments:link"
+	PermissionAPIKeyManage  Permission = "api_keys:manage"
+	PermissionAuditLogRead  Permission = "audit_logs:read"
+	PermissionWebhookManage Permission = "webhooks:manage"
+	PermissionConfigRead    Permission = "config:read"
+	// PermissionPaymentWebhook lets the payment gateway confirm payments
+	PermissionPaymentWebhook Permission = "payments:webhook"
+)
+
+// RolePermissions is what each role is allowed to do. Borrowers are additionally limited to their own data.
+var RolePermissions = map[Role][]Permission{
+	RoleAdmin: {
+		PermissionBorrowerRead, PermissionBorrowerList, PermissionBorrowerWrite,
+		PermissionKYCRead, PermissionKYCWrite, PermissionKYCReview,
+		PermissionLoanRead, PermissionLoanWrite,
+		PermissionPaymentLink,
+		PermissionAPIKeyManage,
+		PermissionAuditLogRead,
+		PermissionWebhookManage,
+		PermissionConfigRead,
+	},
+	RoleFieldOfficer: {
+		PermissionBorrowerRead, PermissionBorrowerList, PermissionBorrowerWrite,
+		PermissionKYCRead, PermissionKYCWrite,
+		PermissionLoanRead, PermissionLoanWrite,
+		PermissionPaymentLink,
+	},
+	RoleFinance: {
+		PermissionBorrowerRead, PermissionBorrowerList,
+		PermissionLoanRead,
+		PermissionPaymentLink,
+		PermissionAuditLogRead,
+	},
+	RoleBorrower: {
+		PermissionBorrowerRead,
+		PermissionLoanRead,
+		PermissionPaymentLink,
+	},
+	RoleLender: {
+		PermissionLoanRead,
+	},
+	RolePaymentGateway: {
+		PermissionPaymentWebhook,
+	},
+}
+
+type AuditAction string
+
+const (
+	AuditActionCreate AuditAction = "create"
+	AuditActionUpdate AuditAction = "update"
+	AuditActionDelete AuditAction = "delete"
+)
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryStatusSucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryStatusFailed    WebhookDeliveryStatus = "failed"
+)
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventTypeBorrowerCreated     EventType = "borrower.created"
+	EventTypeLoanCreated         EventType = "loan.created"
+	EventTypeLoanPaymentPaid     EventType = "loan_payment.paid"
+	EventTypeLoanScheduleOverdue EventType = "loan_schedule.overdue"
+	EventTypeLoanFullyPaid       EventType = "loan.fully_paid"
+)
+
+// EventTypes lists every domain event, partners can subscribe to any of them
+var EventTypes = []EventType{
+	EventTypeBorrowerCreated,
+	EventTypeLoanCreated,
+	EventTypeLoanPaymentPaid,
+	EventTypeLoanScheduleOverdue,
+	EventTypeLoanFullyPaid,
+}
+
+// OutboxEvent is a domain event stored in the same transaction as the change it describes,
+// the outbox relay publishes it afterwards
+type OutboxEvent struct {
+	ID            string          `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	EventType     EventType       `gorm:"not null" json:"event_type"`
+	AggregateType string          `gorm:"not null" json:"aggregate_type"`
+	AggregateID   string          `gorm:"not null" json:"aggregate_id"`
+	Payload       json.RawMessage `gorm:"type:jsonb;not null" json:"payload" swaggertype:"object"`
+	Attempts      int             `gorm:"not null;default:0" json:"-"`
+	LastError     string          `json:"-"`
+	AvailableAt   time.Time       `gorm:"not null" json:"-"`
+	PublishedAt   *time.Time      `json:"-"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+func (OutboxEvent) TableName() string {
+	return "outbox"
+}
+
+type BorrowerCreatedEvent struct {
+	BorrowerID  string `json:"borrower_id"`
+	FirstName   string `json:"first_name"`
+	LastName    string `json:"last_name"`
+	PhoneNumber string `json:"phone_number"`
+}
+
+type LoanCreatedEvent struct {
+	LoanID               string    `json:"loan_id"`
+	BorrowerID           string    `json:"borrower_id"`
+	ProductCode          string    `json:"product_code"`
+	Amount               float64   `json:"amount"`
+	InterestAmount       float64   `json:"interest_amount"`
+	RepaymentCadenceDays int       `json:"repayment_cadence_days"`
+	RepaymentRepetition  int       `json:"repayment_repetition"`
+	DisbursedAt          time.Time `json:"disbursed_at"`
+}
+
+type LoanPaymentPaidEvent struct {
+	LoanPaymentID   string    `json:"loan_payment_id"`
+	LoanID          string    `json:"loan_id"`
+	BorrowerID      string    `json:"borrower_id"`
+	LoanScheduleIDs []string  `json:"loan_schedule_ids"`
+	TotalPayment    float64   `json:"total_payment"`
+	PaymentMethod   string    `json:"payment_method"`
+	PaidAt          time.Time `json:"paid_at"`
+}
+
+type LoanScheduleOverdueEvent struct {
+	LoanScheduleID string    `json:"loan_schedule_id"`
+	LoanID         string    `json:"loan_id"`
+	BorrowerID     string    `json:"borrower_id"`
+	DueDate        time.Time `json:"due_date"`
+	TotalPayment   float64   `json:"total_payment"`
+}
+
+type LoanFullyPaidEvent struct {
+	LoanID     string    `json:"loan_id"`
+	BorrowerID string    `json:"borrower_id"`
+	PaidAt     time.Time `json:"paid_at"`
+}
+
+// WebhookEvent is the body sent to the webhook subscriptions, partners deduplicate on the ID
+type WebhookEvent struct {
+	ID            string          `json:"id"`
+	Type          EventType       `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	CreatedAt     time.Time       `json:"created_at"`
+	Data          json.RawMessage `json:"data" swaggertype:"object"`
+}
@@ -1,5 +1,10 @@
 package models
 
+import (
+	"encoding/json"
+	"time"
+)
+
 type SortDirection string
 
 const (
@@ -7,17 +12,66 @@ const (
 	SortDirectDescending SortDirection = "desc"
 )
 
+// SearchMode tells how the search keyword is matched against the searched fields
+type SearchMode string
+
+const (
+	// SearchModeContains matches the fields containing the keyword, case insensitively
+	SearchModeContains SearchMode = "contains"
+	// SearchModeFullText matches the words of the keyword with the Postgres full text search
+	SearchModeFullText SearchMode = "fulltext"
+)
+
 type FindAllParam struct {
 	Limit          int
 	Offset         int
+	Cursor         string
 	SearchKeyword  string
 	FieldsToSearch []string
+	SearchMode     SearchMode
+	Filters        map[string]interface{}
+	Specs          []Spec
+	Conditions     []Condition
 	PreloadTables  []string
 	JoinTables     []string
 	SortBy         SortBy
 }
 
+// Condition is a raw where clause for filters that can't be expressed as column equality (e.g. subqueries)
+type Condition struct {
+	Query string
+	Args  []interface{}
+}
+
+// Cursor points to the last row of a page for keyset pagination
+type Cursor struct {
+	SortField string          `json:"f"`
+	Direction SortDirection   `json:"d"`
+	Value     json.RawMessage `json:"v"`
+	ID        string          `json:"id"`
+}
+
 type SortBy struct {
 	FieldName string
 	Direction SortDirection
 }
+
+// LoanFilter narrows down the loans returned by the loan listing
+type LoanFilter struct {
+	Status         LoanStatus
+	BorrowerID     string
+	ProductCode    string
+	DisbursedFrom  *time.Time
+	DisbursedTo    *time.Time
+	MinDPD         *int
+	MaxDPD         *int
+	MinOutstanding *float64
+	MaxOutstanding *float64
+}
+
+// PortfolioStats sums up the loan book, delinquent borrowers have enough overdue schedules to be
+// considered delinquent
+type PortfolioStats struct {
+	OutstandingAmount   float64
+	DelinquentBorrowers int64
+}
@@ -6,20 +6,103 @@ type BorrowerRequest struct {
 	PhoneNumber string `json:"phone_number" binding:"required"`
 }
 
+type BorrowerUpdateRequest struct {
+	FirstName   *string `json:"first_name" binding:"omitempty,min=1" description:"First name"`
+	LastName    *string `json:"last_name" binding:"omitempty,min=1" description:"Last name"`
+	PhoneNumber *string `json:"phone_number" binding:"omitempty,min=1" description:"Phone number"`
+}
+
+type BorrowerListRequest struct {
+	Search        string        `form:"search" description:"Keyword searched over first name, last name and phone number"`
+	SearchMode    SearchMode    `form:"search_mode" binding:"omitempty,oneof=contains fulltext" description:"How the keyword is matched (contains or fulltext), defaults to contains"`
+	KYCStatus     KYCStatus     `form:"kyc_status" binding:"omitempty,oneof=unverified pending verified rejected" description:"Filter by KYC status"`
+	SortBy        string        `form:"sort_by" description:"Sort field (first_name, last_name, phone_number, created_at)"`
+	SortDirection SortDirection `form:"sort_direction" binding:"omitempty,oneof=asc desc" description:"Sort direction (asc or desc)"`
+	Page          int           `form:"page" binding:"omitempty,min=1" description:"Page number, starts from 1"`
+	Limit         int           `form:"limit" binding:"omitempty,min=1,max=100" description:"Number of items per page"`
+}
+
 type LoanRequest struct {
 	BorrowerID           string  `json:"borrower_id" binding:"required" description:"Borrower ID"`
 	Amount               float64 `json:"amount" binding:"required" description:"Loan amount"`
 	RepaymentCadenceDays int     `json:"repayment_cadence_days" binding:"required" description:"Repayment cadence days (If weekly then 7)"`
 	RepaymentRepetition  int     `json:"repayment_repetition" binding:"required" description:"How many times the loan will be repaid"`
 	InterestPercentage   float64 `json:"interest_percentage" binding:"required" description:"Interest percentage"`
+	ProductCode          string  `json:"product_code" description:"Loan product code, defaults to standard"`
+}
+
+type LoanListRequest struct {
+	Status         LoanStatus    `form:"status" binding:"omitempty,oneof=active paid" description:"Filter by loan status"`
+	BorrowerID     string        `form:"borrower_id" description:"Filter by borrower ID"`
+	ProductCode    string        `form:"product_code" description:"Filter by loan product code"`
+	DisbursedFrom  string        `form:"disbursed_from" description:"Disbursed on or after this date (YYYY-MM-DD)"`
+	DisbursedTo    string        `form:"disbursed_to" description:"Disbursed on or before this date (YYYY-MM-DD)"`
+	DPDBucket      string        `form:"dpd_bucket" binding:"omitempty,oneof=current 1-30 31-60 61-90 90+" description:"Days past due bucket (current, 1-30, 31-60, 61-90, 90+)"`
+	MinOutstanding *float64      `form:"min_outstanding" binding:"omitempty,min=0" description:"Minimum outstanding amount"`
+	MaxOutstanding *float64      `form:"max_outstanding" binding:"omitempty,min=0" description:"Maximum outstanding amount"`
+	SortBy         string        `form:"sort_by" description:"Sort field (created_at, disbursed_at, amount)"`
+	SortDirection  SortDirection `form:"sort_direction" binding:"omitempty,oneof=asc desc" description:"Sort direction (asc or desc)"`
+	Cursor         string        `form:"cursor" description:"Cursor of the page to fetch, taken from next_cursor of the previous page"`
+	Limit          int           `form:"limit" binding:"omitempty,min=1,max=100" description:"Number of items per page"`
 }
 
 type PaymentLinkRequest struct {
 	BorrowerID    string `json:"borrower_id" binding:"required" description:"Borrower ID"`
-	PaymentMethod string `json:"payment_method" binding:"required" description:"Payment method"`
+	PaymentMethod string `json:"payment_method" binding:"required,oneof=bank_transfer virtual_account retail_outlet" description:"Payment method (bank_transfer, virtual_account, retail_outlet)"`
 }
 
 type PaymentWebhookRequest struct {
 	ExternalID    string `json:"external_id" binding:"required" description:"External ID of Payment Gateway (Loan Payment ID)"`
 	PaymentStatus string `json:"payment_status" binding:"required" description:"Payment status"`
 }
+
+type KYCProfileRequest struct {
+	NIK           string  `json:"nik" binding:"required,len=16,numeric" description:"National ID number (NIK)"`
+	DateOfBirth   string  `json:"date_of_birth" binding:"required" description:"Date of birth (YYYY-MM-DD)"`
+	Address       string  `json:"address" binding:"required" description:"Home address"`
+	BusinessType  string  `json:"business_type" binding:"required" description:"Type of business run by the borrower"`
+	MonthlyIncome float64 `json:"monthly_income" binding:"required,gt=0" description:"Monthly income"`
+}
+
+type KYCReviewRequest struct {
+	Status          KYCStatus `json:"status" binding:"required,oneof=verified rejected" description:"Review result (verified or rejected)"`
+	RejectionReason string    `json:"rejection_reason" description:"Reason of rejection, required when status is rejected"`
+}
+
+type StatementRequest struct {
+	From   string          `form:"from" description:"Start of the period (YYYY-MM-DD), defaults to the first disbursement"`
+	To     string          `form:"to" description:"End of the period (YYYY-MM-DD), defaults to today"`
+	Format StatementFormat `form:"format" binding:"omitempty,oneof=json csv pdf" description:"Export format (json, csv or pdf)"`
+}
+
+type APIKeyRequest struct {
+	Name         string       `json:"name" binding:"required" description:"Name of the client using the key"`
+	Permissions  []Permission `json:"permissions" binding:"required,min=1" description:"Permissions granted to the key"`
+	AllowedCIDRs []string     `json:"allowed_cidrs" description:"IP ranges allowed to use the key (e.g. 10.0.0.0/8), any IP when empty"`
+}
+
+type AuditLogListRequest struct {
+	EntityType string      `form:"entity_type" description:"Filter by entity type (table name, e.g. loans)"`
+	EntityID   string      `form:"entity_id" description:"Filter by entity ID"`
+	Actor      string      `form:"actor" description:"Filter by actor"`
+	Action     AuditAction `form:"action" binding:"omitempty,oneof=create update delete" description:"Filter by action (create, update, delete)"`
+	RequestID  string      `form:"request_id" description:"Filter by request ID"`
+	From       string      `form:"from" description:"Changes made from this date (YYYY-MM-DD)"`
+	To         string      `form:"to" description:"Changes made until this date (YYYY-MM-DD)"`
+	Page       int         `form:"page" description:"Page number, starts from 1"`
+	Limit      int         `form:"limit" description:"Number of entries per page"`
+}
+
+type WebhookSubscriptionRequest struct {
+	PartnerID  string      `json:"partner_id" binding:"required,max=100" description:"Partner owning the endpoint, it only receives the events of the borrowers granted to it"`
+	Name       string      `json:"name" binding:"required" description:"Name of the partner receiving the webhooks"`
+	URL        string      `json:"url" binding:"required" description:"HTTP(S) endpoint receiving the events"`
+	Secret     string      `json:"secret" binding:"required,min=16" description:"Secret used to sign the deliveries with HMAC-SHA256"`
+	EventTypes []EventType `json:"event_types" binding:"required,min=1" description:"Event types sent to the endpoint"`
+}
+
+type WebhookDeliveryListRequest struct {
+	Status WebhookDeliveryStatus `form:"status" binding:"omitempty,oneof=pending succeeded failed" description:"Filter by status (pending, succeeded, failed)"`
+	Page   int                   `form:"page" description:"Page number, starts from 1"`
+	Limit  int                   `form:"limit" description:"Number of deliveries per page"`
+}
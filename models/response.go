@@ -1,23 +1,65 @@
 package models
 
+import "time"
+
 type ErrorResponse struct {
 	Err            error `json:"-"` // low-level runtime error
 	HTTPStatusCode int   `json:"-"` // http response status code
 
+	Code      string      `json:"code" example:"internal_error"`                                         // Stable machine-readable error code
 	Message   string      `json:"message" example:"Something went wrong while processing your request."` // Error message to be shown for user
 	ErrorText string      `json:"error,omitempty" example:"Nil pointer reference"`                       // Error message for debugging
+	RequestID string      `json:"request_id,omitempty" example:"4f1c2a9e8b7d6c5e4f3a2b1c0d9e8f7a"`       // Request ID to quote when reporting the error
 	Result    interface{} `json:"result,omitempty"`                                                      // Custom data for needed for specific case
 }
 
 type LoanResponse struct {
-	ID                   string  `json:"id"`
-	Amount               float64 `json:"amount"`
-	RepaymentCadenceDays int     `json:"repayment_cadence_days"`
-	RepaymentRepetition  int     `json:"repayment_repetition"`
-	InterestPercentage   float64 `json:"interest_percentage"`
-	InterestAmount       float64 `json:"interest_amount"`
-	Status               string  `json:"status"`
-	TotalOutstanding     float64 `json:"total_outstanding"`
+	ID                   string    `json:"id"`
+	BorrowerID           string    `json:"borrower_id"`
+	ProductCode          string    `json:"product_code"`
+	Amount               float64   `json:"amount"`
+	RepaymentCadenceDays int       `json:"repayment_cadence_days"`
+	RepaymentRepetition  int       `json:"repayment_repetition"`
+	InterestPercentage   float64   `json:"interest_percentage"`
+	InterestAmount       float64   `json:"interest_amount"`
+	Status               string    `json:"status"`
+	DisbursedAt          time.Time `json:"disbursed_at"`
+	TotalOutstanding     float64   `json:"total_outstanding"`
+	DPD                  int       `json:"dpd"`
+
+	NextDueDate   *time.Time `json:"next_due_date"`
+	NextDueAmount float64    `json:"next_due_amount"`
+
+	Schedules []LoanScheduleResponse `json:"schedules,omitempty"`
+	Payments  []LoanPaymentResponse  `json:"payments,omitempty"`
+	Borrower  *BorrowerResponse      `json:"borrower,omitempty"`
+}
+
+type LoanScheduleResponse struct {
+	ID             string             `json:"id"`
+	DueDate        time.Time          `json:"due_date"`
+	BasicAmount    float64            `json:"basic_amount"`
+	InterestAmount float64            `json:"interest_amount"`
+	TotalPayment   float64            `json:"total_payment"`
+	PaidAmount     float64            `json:"paid_amount"`
+	Status         LoanScheduleStatus `json:"status"`
+	IsOverdue      bool               `json:"is_overdue"`
+}
+
+type LoanPaymentResponse struct {
+	ID              string            `json:"id"`
+	LoanScheduleIDs []string          `json:"loan_schedule_ids"`
+	TotalPayment    float64           `json:"total_payment"`
+	PaymentMethod   string            `json:"payment_method"`
+	Status          LoanPaymentStatus `json:"status"`
+	PaidAt          *time.Time        `json:"paid_at,omitempty"`
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+}
+
+type LoanListResponse struct {
+	Data       []LoanResponse `json:"data"`
+	NextCursor string         `json:"next_cursor,omitempty"`
 }
 
 type PaymentLinkResponse struct {
@@ -27,9 +69,95 @@ type PaymentLinkResponse struct {
 }
 
 type BorrowerResponse struct {
-	ID           string `json:"id"`
-	FirstName    string `json:"first_name"`
-	LastName     string `json:"last_name"`
-	PhoneNumber  string `json:"phone_number"`
-	IsDelinquent bool   `json:"is_delinquent"`
+	ID           string    `json:"id"`
+	FirstName    string    `json:"first_name"`
+	LastName     string    `json:"last_name"`
+	PhoneNumber  string    `json:"phone_number"`
+	KYCStatus    KYCStatus `json:"kyc_status"`
+	IsDelinquent bool      `json:"is_delinquent"`
+}
+
+type Pagination struct {
+	Page       int   `json:"page"`
+	Limit      int   `json:"limit"`
+	TotalItems int64 `json:"total_items"`
+	TotalPages int   `json:"total_pages"`
+}
+
+type BorrowerListResponse struct {
+	Data       []BorrowerResponse `json:"data"`
+	Pagination Pagination         `json:"pagination"`
+}
+
+type KYCResponse struct {
+	BorrowerID string              `json:"borrower_id"`
+	Status     KYCStatus           `json:"status"`
+	Profile    *BorrowerKYCProfile `json:"profile"`
+	Documents  []BorrowerDocument  `json:"documents"`
+}
+
+type StatementResponse struct {
+	Borrower    BorrowerResponse        `json:"borrower"`
+	From        *time.Time              `json:"from"`
+	To          time.Time               `json:"to"`
+	GeneratedAt time.Time               `json:"generated_at"`
+	Loans       []LoanStatementResponse `json:"loans"`
+}
+
+type LoanStatementResponse struct {
+	LoanID         string                   `json:"loan_id"`
+	ProductCode    string                   `json:"product_code"`
+	DisbursedAt    time.Time                `json:"disbursed_at"`
+	Status         LoanStatus               `json:"status"`
+	OpeningBalance float64                  `json:"opening_balance"`
+	TotalDebit     float64                  `json:"total_debit"`
+	TotalCredit    float64                  `json:"total_credit"`
+	ClosingBalance float64                  `json:"closing_balance"`
+	Entries        []StatementEntryResponse `json:"entries"`
+}
+
+// StatementEntryResponse is one line of a statement, Balance is the amount still owed by the borrower
+// after the line. Installment lines only inform about a due date, they don't move the balance.
+type StatementEntryResponse struct {
+	Date        time.Time          `json:"date"`
+	Type        StatementEntryType `json:"type"`
+	Reference   string             `json:"reference"`
+	Description string             `json:"description"`
+	DueAmount   float64            `json:"due_amount,omitempty"`
+	Debit       float64            `json:"debit"`
+	Credit      float64            `json:"credit"`
+	Balance     float64            `json:"balance"`
+}
+
+// APIKeySecretResponse is the only response containing the key itself, it can't be retrieved afterwards
+type APIKeySecretResponse struct {
+	APIKey APIKey `json:"api_key"`
+	Key    string `json:"key"`
+}
+
+type AuditLogListResponse struct {
+	Data       []AuditLog `json:"data"`
+	Pagination Pagination `json:"pagination"`
+}
+
+type WebhookDeliveryListResponse struct {
+	Data       []WebhookDelivery `json:"data"`
+	Pagination Pagination        `json:"pagination"`
+}
+
+type HealthStatus string
+
+const (
+	HealthStatusUp   HealthStatus = "up"
+	HealthStatusDown HealthStatus = "down"
+)
+
+type HealthResponse struct {
+	Status HealthStatus                   `json:"status" example:"up"`
+	Checks map[string]HealthCheckResponse `json:"checks,omitempty"`
+}
+
+type HealthCheckResponse struct {
+	Status HealthStatus `json:"status" example:"up"`
+	Error  string       `json:"error,omitempty"`
 }
@@ -0,0 +1,55 @@
+package models
+
+// SpecOperator is the comparison a Spec makes
+type SpecOperator string
+
+const (
+	SpecOperatorEq        SpecOperator = "eq"
+	SpecOperatorNotEq     SpecOperator = "not_eq"
+	SpecOperatorIn        SpecOperator = "in"
+	SpecOperatorRange     SpecOperator = "range"
+	SpecOperatorIsNull    SpecOperator = "is_null"
+	SpecOperatorIsNotNull SpecOperator = "is_not_null"
+)
+
+// Spec is a typed condition on a column of the model, unlike Condition the column is checked
+// against the model and the values are always bound
+type Spec struct {
+	Column   string
+	Operator SpecOperator
+	Values   []interface{}
+}
+
+// Eq matches the rows where column = value
+func Eq(column string, value interface{}) Spec {
+	return Spec{Column: column, Operator: SpecOperatorEq, Values: []interface{}{value}}
+}
+
+// NotEq matches the rows where column <> value
+func NotEq(column string, value interface{}) Spec {
+	return Spec{Column: column, Operator: SpecOperatorNotEq, Values: []interface{}{value}}
+}
+
+// In matches the rows where column is one of values, none when values is empty
+func In[V any](column string, values []V) Spec {
+	spec := Spec{Column: column, Operator: SpecOperatorIn, Values: make([]interface{}, 0, len(values))}
+	for _, value := range values {
+		spec.Values = append(spec.Values, value)
+	}
+	return spec
+}
+
+// Range matches the rows where from <= column < to, a nil bound is left open
+func Range(column string, from interface{}, to interface{}) Spec {
+	return Spec{Column: column, Operator: SpecOperatorRange, Values: []interface{}{from, to}}
+}
+
+// IsNull matches the rows where column is NULL
+func IsNull(column string) Spec {
+	return Spec{Column: column, Operator: SpecOperatorIsNull}
+}
+
+// IsNotNull matches the rows where column is not NULL
+func IsNotNull(column string) Spec {
+	return Spec{Column: column, Operator: SpecOperatorIsNotNull}
+}
@@ -0,0 +1,15 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+)
+
+type APIKeyRepository interface {
+	CommonRepository[models.APIKey]
+
+	FindOneByPrefix(ctx context.Context, prefix string) (*models.APIKey, error)
+	UpdateLastUsedAt(ctx context.Context, id string, lastUsedAt time.Time) error
+}
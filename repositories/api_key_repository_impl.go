@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/satryarangga/amartha-loan-engine/helpers"
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"gorm.io/gorm"
+)
+
+type APIKeyRepositoryImpl struct {
+	DB *gorm.DB
+	CommonRepository[models.APIKey]
+}
+
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepositoryImpl {
+	return &APIKeyRepositoryImpl{
+		DB:               db,
+		CommonRepository: NewCommonRepository[models.APIKey](db),
+	}
+}
+
+// FindOneByPrefix reads from the primary, a key revoked or rotated must stop working right away
+func (r *APIKeyRepositoryImpl) FindOneByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	var apiKey models.APIKey
+	err := conn(helpers.WithPrimaryReads(ctx), r.DB).Where("key_prefix = ?", prefix).First(&apiKey).Error
+	if err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+// UpdateLastUsedAt only touches last_used_at, so the usage of a key doesn't look like a change of the key
+func (r *APIKeyRepositoryImpl) UpdateLastUsedAt(ctx context.Context, id string, lastUsedAt time.Time) error {
+	return conn(ctx, r.DB).Model(&models.APIKey{}).Where("id = ?", id).UpdateColumn("last_used_at", lastUsedAt).Error
+}
@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/satryarangga/amartha-loan-engine/helpers"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestAPIKeyRepositoryImpl_FindOneByPrefix_ReadsFromPrimary(t *testing.T) {
+	// Arrange
+	db := newDryRunDB(t)
+	repo := NewAPIKeyRepository(db)
+
+	// the replica routing keeps the reads of a context marked with helpers.WithPrimaryReads on the primary
+	var fromPrimary []bool
+	assert.NoError(t, db.Callback().Query().Before("gorm:query").Register("test:primary_reads", func(db *gorm.DB) {
+		fromPrimary = append(fromPrimary, helpers.ReadsFromPrimary(db.Statement.Context))
+	}))
+
+	// Act
+	// a key revoked on the primary is rejected even when a replica still has it active
+	_, _ = repo.FindOneByPrefix(context.Background(), "revokedpfx")
+
+	// Assert
+	assert.Equal(t, []bool{true}, fromPrimary)
+}
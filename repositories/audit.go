@@ -0,0 +1,148 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/satryarangga/amartha-loan-engine/helpers"
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+const auditSystemActor = "system"
+
+// columns maintained by GORM and the repositories, they would show up in every update
+var auditIgnoredColumns = map[string]bool{
+	"created_at":  true,
+	"updated_at":  true,
+	versionColumn: true,
+}
+
+// withAuditTransaction runs the change and its audit entry in the same transaction,
+// joining the ambient transaction of ctx when there is one
+func withAuditTransaction(ctx context.Context, db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	return runInTransaction(ctx, db, func(ctx context.Context) error {
+		return fn(conn(ctx, db))
+	})
+}
+
+// recordAudit appends an audit entry for the change of a model from before to after,
+// before is nil for a create and after is nil for a delete
+func recordAudit(ctx context.Context, db *gorm.DB, action models.AuditAction, before interface{}, after interface{}) error {
+	auditLog, err := newAuditLog(ctx, db, action, before, after)
+	if err != nil || auditLog == nil {
+		return err
+	}
+	return db.Session(&gorm.Session{NewDB: true}).WithContext(ctx).Create(auditLog).Error
+}
+
+// recordCreateAudits appends the audit entries of records created at once, batchSize per statement
+func recordCreateAudits[T any](ctx context.Context, db *gorm.DB, records []T, batchSize int) error {
+	auditLogs := make([]models.AuditLog, 0, len(records))
+	for i := range records {
+		auditLog, err := newAuditLog(ctx, db, models.AuditActionCreate, nil, &records[i])
+		if err != nil {
+			return err
+		}
+		auditLogs = append(auditLogs, *auditLog)
+	}
+	return db.Session(&gorm.Session{NewDB: true}).WithContext(ctx).CreateInBatches(&auditLogs, batchSize).Error
+}
+
+// newAuditLog builds the audit entry of a change, nil for an update changing nothing
+func newAuditLog(ctx context.Context, db *gorm.DB, action models.AuditAction, before interface{}, after interface{}) (*models.AuditLog, error) {
+	model := after
+	if isNilModel(model) {
+		model = before
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+	if stmt.Schema.PrioritizedPrimaryField == nil {
+		return nil, fmt.Errorf("unable to audit %s without primary key", stmt.Schema.Table)
+	}
+
+	changes, err := helpers.AuditDiff(auditSnapshot(ctx, stmt.Schema, before), auditSnapshot(ctx, stmt.Schema, after))
+	if err != nil {
+		return nil, err
+	}
+	if action == models.AuditActionUpdate && len(changes) == 0 {
+		return nil, nil
+	}
+	rawChanges, err := json.Marshal(changes)
+	if err != nil {
+		return nil, err
+	}
+
+	entityID, _ := stmt.Schema.PrioritizedPrimaryField.ValueOf(ctx, reflect.Indirect(reflect.ValueOf(model)))
+
+	auditLog := models.AuditLog{
+		Actor:      auditSystemActor,
+		Action:     action,
+		EntityType: stmt.Schema.Table,
+		EntityID:   fmt.Sprint(entityID),
+		Changes:    rawChanges,
+		RequestID:  helpers.RequestIDFromContext(ctx),
+	}
+	if principal, ok := helpers.PrincipalFromContext(ctx); ok {
+		auditLog.Actor = principal.Subject
+		auditLog.ActorRole = principal.Role
+	}
+	return &auditLog, nil
+}
+
+func auditSnapshot(ctx context.Context, modelSchema *schema.Schema, model interface{}) map[string]interface{} {
+	if isNilModel(model) {
+		return nil
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(model))
+	snapshot := map[string]interface{}{}
+	for _, field := range modelSchema.Fields {
+		if field.DBName == "" || auditIgnoredColumns[field.DBName] {
+			continue
+		}
+		fieldValue, _ := field.ValueOf(ctx, value)
+		snapshot[field.DBName] = normalizeAuditValue(fieldValue)
+	}
+	return snapshot
+}
+
+// normalizeAuditValue makes in-memory values comparable with the ones read back from the database,
+// which come in another time zone, with microsecond precision and with empty instead of nil arrays
+func normalizeAuditValue(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case time.Time:
+		return typedValue.UTC().Truncate(time.Microsecond)
+	case *time.Time:
+		if typedValue == nil {
+			return nil
+		}
+		return typedValue.UTC().Truncate(time.Microsecond)
+	case gorm.DeletedAt:
+		if !typedValue.Valid {
+			return nil
+		}
+		return typedValue.Time.UTC().Truncate(time.Microsecond)
+	}
+
+	reflectValue := reflect.ValueOf(value)
+	if reflectValue.Kind() == reflect.Slice && reflectValue.Len() == 0 {
+		return []interface{}{}
+	}
+	return value
+}
+
+func isNilModel(model interface{}) bool {
+	if model == nil {
+		return true
+	}
+	value := reflect.ValueOf(model)
+	return value.Kind() == reflect.Ptr && value.IsNil()
+}
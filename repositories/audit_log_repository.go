@@ -0,0 +1,9 @@
+package repositories
+
+import (
+	"github.com/satryarangga/amartha-loan-engine/models"
+)
+
+type AuditLogRepository interface {
+	CommonRepository[models.AuditLog]
+}
@@ -0,0 +1,19 @@
+package repositories
+
+import (
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"gorm.io/gorm"
+)
+
+// AuditLogRepositoryImpl only reads the audit trail, entries are written by recordAudit
+type AuditLogRepositoryImpl struct {
+	DB *gorm.DB
+	CommonRepository[models.AuditLog]
+}
+
+func NewAuditLogRepository(db *gorm.DB) *AuditLogRepositoryImpl {
+	return &AuditLogRepositoryImpl{
+		DB:               db,
+		CommonRepository: NewCommonRepository[models.AuditLog](db),
+	}
+}
@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAuditLog_KYCProfileUpsertRedactsPersonalData(t *testing.T) {
+	// Arrange
+	db := newDryRunDB(t)
+	ctx := context.Background()
+	before := &models.BorrowerKYCProfile{
+		ID:            "profile-id",
+		BorrowerID:    "borrower-id",
+		NIK:           "3171234567890001",
+		DateOfBirth:   time.Date(1990, 1, 2, 0, 0, 0, 0, time.UTC),
+		Address:       "Jl. Sudirman 1, Jakarta",
+		BusinessType:  "grocery",
+		MonthlyIncome: 5000000,
+	}
+	after := *before
+	after.NIK = "3171234567890002"
+	after.DateOfBirth = time.Date(1991, 3, 4, 0, 0, 0, 0, time.UTC)
+	after.Address = "Jl. Thamrin 2, Jakarta"
+	after.BusinessType = "tailor"
+	after.MonthlyIncome = 7500000
+
+	// Act
+	created, createErr := newAuditLog(ctx, db, models.AuditActionCreate, nil, before)
+	updated, updateErr := newAuditLog(ctx, db, models.AuditActionUpdate, before, &after)
+
+	// Assert
+	assert.NoError(t, createErr)
+	assert.NoError(t, updateErr)
+
+	var createdChanges map[string]models.AuditChange
+	assert.NoError(t, json.Unmarshal(created.Changes, &createdChanges))
+	assert.Equal(t, "borrower_kyc_profiles", created.EntityType)
+	assert.Equal(t, "grocery", createdChanges["business_type"].After)
+	for _, column := range []string{"nik", "date_of_birth", "address", "monthly_income"} {
+		assert.Equal(t, models.AuditChange{Before: nil, After: "[redacted]"}, createdChanges[column], column)
+	}
+
+	var updatedChanges map[string]models.AuditChange
+	assert.NoError(t, json.Unmarshal(updated.Changes, &updatedChanges))
+	assert.Equal(t, models.AuditChange{Before: "grocery", After: "tailor"}, updatedChanges["business_type"])
+	for _, column := range []string{"nik", "date_of_birth", "address", "monthly_income"} {
+		assert.Equal(t, models.AuditChange{Before: "[redacted]", After: "[redacted]"}, updatedChanges[column], column)
+	}
+	assert.NotContains(t, string(created.Changes)+string(updated.Changes), "3171234567890")
+	assert.NotContains(t, string(created.Changes)+string(updated.Changes), "Jakarta")
+}
@@ -0,0 +1,13 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+)
+
+type BorrowerDocumentRepository interface {
+	CommonRepository[models.BorrowerDocument]
+
+	FindByBorrowerID(ctx context.Context, borrowerID string) ([]models.BorrowerDocument, error)
+}
@@ -0,0 +1,26 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"gorm.io/gorm"
+)
+
+type BorrowerDocumentRepositoryImpl struct {
+	DB *gorm.DB
+	CommonRepository[models.BorrowerDocument]
+}
+
+func NewBorrowerDocumentRepository(db *gorm.DB) *BorrowerDocumentRepositoryImpl {
+	return &BorrowerDocumentRepositoryImpl{
+		DB:               db,
+		CommonRepository: NewCommonRepository[models.BorrowerDocument](db),
+	}
+}
+
+func (r *BorrowerDocumentRepositoryImpl) FindByBorrowerID(ctx context.Context, borrowerID string) ([]models.BorrowerDocument, error) {
+	var documents []models.BorrowerDocument
+	err := conn(ctx, r.DB).Where("borrower_id = ?", borrowerID).Order("created_at asc").Find(&documents).Error
+	return documents, err
+}
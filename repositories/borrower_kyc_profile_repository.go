@@ -0,0 +1,13 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+)
+
+type BorrowerKYCProfileRepository interface {
+	CommonRepository[models.BorrowerKYCProfile]
+
+	FindOneByBorrowerID(ctx context.Context, borrowerID string) (models.BorrowerKYCProfile, error)
+}
@@ -0,0 +1,26 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"gorm.io/gorm"
+)
+
+type BorrowerKYCProfileRepositoryImpl struct {
+	DB *gorm.DB
+	CommonRepository[models.BorrowerKYCProfile]
+}
+
+func NewBorrowerKYCProfileRepository(db *gorm.DB) *BorrowerKYCProfileRepositoryImpl {
+	return &BorrowerKYCProfileRepositoryImpl{
+		DB:               db,
+		CommonRepository: NewCommonRepository[models.BorrowerKYCProfile](db),
+	}
+}
+
+func (r *BorrowerKYCProfileRepositoryImpl) FindOneByBorrowerID(ctx context.Context, borrowerID string) (models.BorrowerKYCProfile, error) {
+	var profile models.BorrowerKYCProfile
+	err := conn(ctx, r.DB).Where("borrower_id = ?", borrowerID).First(&profile).Error
+	return profile, err
+}
@@ -21,6 +21,6 @@ func NewBorrowerRepository(db *gorm.DB) *BorrowerRepositoryImpl {
 
 func (r *BorrowerRepositoryImpl) FindOneByPhoneNumber(ctx context.Context, phoneNumber string) (models.Borrower, error) {
 	var borrower models.Borrower
-	err := r.DB.WithContext(ctx).Where("phone_number = ?", phoneNumber).First(&borrower).Error
+	err := conn(ctx, r.DB).Where("phone_number = ?", phoneNumber).First(&borrower).Error
 	return borrower, err
 }
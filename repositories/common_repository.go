@@ -4,26 +4,48 @@ import (
 	"context"
 
 	"github.com/satryarangga/amartha-loan-engine/models"
-	"gorm.io/gorm"
 )
 
-type TransactionFunc func(tx *gorm.DB) error
-
 // CommonRepository interface with generic type parameter
 type CommonRepository[T any] interface {
 
 	// Insert inserts a new record into the repository
-	Insert(ctx context.Context, tx *gorm.DB, model *T) (string, error)
+	Insert(ctx context.Context, model *T) (string, error)
+
+	// BulkInsert inserts several records, batchSize rows per statement
+	BulkInsert(ctx context.Context, records []T, batchSize int) error
+
+	// Update updates an existing record in the repository, a versioned record is only updated when it
+	// wasn't changed since it was read and a *VersionConflictError is returned otherwise
+	Update(ctx context.Context, model *T) error
 
-	// Update updates an existing record in the repository
-	Update(ctx context.Context, tx *gorm.DB, model *T) error
+	// UpdateFields updates only the given columns of a record, by column or Go field name
+	UpdateFields(ctx context.Context, id string, fields map[string]interface{}) error
 
 	// FindByID finds a record by its ID
 	FindByID(ctx context.Context, id string, relations []string) (*T, error)
 
+	// FindByIDForUpdate finds a record by its ID and locks it until the end of the transaction
+	FindByIDForUpdate(ctx context.Context, id string, relations []string) (*T, error)
+
+	// Delete deletes an existing record, models having a gorm.DeletedAt field are soft deleted
+	Delete(ctx context.Context, model *T) error
+
 	// FindAll finds all records matching the provided parameters
 	FindAll(ctx context.Context, param models.FindAllParam) ([]T, error)
 
-	// Wrapper transaction
+	// FindAllForUpdate finds all records matching the provided parameters and locks them until the end of the transaction
+	FindAllForUpdate(ctx context.Context, param models.FindAllParam) ([]T, error)
+
+	// FindAllByCursor finds a page of records using keyset pagination and returns the cursor of the next page
+	FindAllByCursor(ctx context.Context, param models.FindAllParam) ([]T, string, error)
+
+	// Count counts all records matching the search and filters of the provided parameters
+	Count(ctx context.Context, param models.FindAllParam) (int64, error)
+
+	// Exists tells whether a record matches the search and filters of the provided parameters
+	Exists(ctx context.Context, param models.FindAllParam) (bool, error)
+
+	// WithTransaction runs fn in a transaction carried by its context, which every repository method joins
 	WithTransaction(ctx context.Context, fn TransactionFunc) error
 }
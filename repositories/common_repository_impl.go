@@ -2,12 +2,18 @@ package repositories
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
-	"strings"
 
+	"github.com/satryarangga/amartha-loan-engine/helpers"
 	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/satryarangga/amartha-loan-engine/tracing"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
 )
 
 type CommonRepositoryImpl[T any] struct {
@@ -18,9 +24,77 @@ func NewCommonRepository[T any](db *gorm.DB) *CommonRepositoryImpl[T] {
 	return &CommonRepositoryImpl[T]{db: db}
 }
 
+// startSpan starts the span of a repository method, named after the model, e.g. LoanRepository.FindAll
+func (r *CommonRepositoryImpl[T]) startSpan(ctx context.Context, method string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, reflect.TypeOf(new(T)).Elem().Name()+"Repository."+method)
+}
+
+// schema returns the parsed schema of the model
+func (r *CommonRepositoryImpl[T]) schema() (*schema.Schema, error) {
+	stmt := &gorm.Statement{DB: r.db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, err
+	}
+	return stmt.Schema, nil
+}
+
+// buildQueryConditions applies only the conditions narrowing down the result set,
+// so it can be shared between FindAll, Count and Exists
+func (r *CommonRepositoryImpl[T]) buildQueryConditions(param models.FindAllParam, query *gorm.DB) *gorm.DB {
+	if len(param.Filters) > 0 {
+		query = query.Where(param.Filters)
+	}
+
+	if len(param.Specs) > 0 {
+		modelSchema, err := r.schema()
+		if err != nil {
+			_ = query.AddError(err)
+			return query
+		}
+		expressions, err := specExpressions(modelSchema, param.Specs)
+		if err != nil {
+			_ = query.AddError(err)
+			return query
+		}
+		if len(expressions) > 0 {
+			query = query.Clauses(clause.Where{Exprs: expressions})
+		}
+	}
+
+	for _, condition := range param.Conditions {
+		query = query.Where(condition.Query, condition.Args...)
+	}
+
+	if param.SearchKeyword != "" && len(param.FieldsToSearch) > 0 {
+		modelSchema, err := r.schema()
+		if err != nil {
+			_ = query.AddError(err)
+			return query
+		}
+		expression, err := searchExpression(modelSchema, param.FieldsToSearch, param.SearchMode, param.SearchKeyword)
+		if err != nil {
+			_ = query.AddError(err)
+			return query
+		}
+		query = query.Clauses(clause.Where{Exprs: []clause.Expression{expression}})
+	}
+
+	return query
+}
+
 func (r *CommonRepositoryImpl[T]) buildQueryFindAll(param models.FindAllParam, query *gorm.DB) *gorm.DB {
 	if param.SortBy.FieldName != "" {
-		query = query.Order(fmt.Sprintf("%s %s", param.SortBy.FieldName, param.SortBy.Direction))
+		modelSchema, err := r.schema()
+		if err != nil {
+			_ = query.AddError(err)
+			return query
+		}
+		field, err := lookUpSortField(modelSchema, param.SortBy)
+		if err != nil {
+			_ = query.AddError(err)
+			return query
+		}
+		query = query.Order(orderByColumn(modelSchema, field, param.SortBy.Direction))
 	}
 
 	if param.Limit > 0 {
@@ -29,18 +103,7 @@ func (r *CommonRepositoryImpl[T]) buildQueryFindAll(param models.FindAllParam, q
 			Offset((param.Offset - 1) * param.Limit)
 	}
 
-	if param.SearchKeyword != "" && len(param.FieldsToSearch) > 0 {
-		conditions := make([]string, 0, len(param.FieldsToSearch))
-		args := make([]interface{}, 0, len(param.FieldsToSearch))
-
-		for _, field := range param.FieldsToSearch {
-			conditions = append(conditions, field+" ILIKE ?")
-			args = append(args, "%"+param.SearchKeyword+"%")
-		}
-
-		whereClause := "(" + strings.Join(conditions, " OR ") + ")"
-		query = query.Where(whereClause, args...)
-	}
+	query = r.buildQueryConditions(param, query)
 
 	for _, relation := range param.PreloadTables {
 		query = query.Preload(relation)
@@ -53,9 +116,28 @@ func (r *CommonRepositoryImpl[T]) buildQueryFindAll(param models.FindAllParam, q
 	return query
 }
 
-func (r *CommonRepositoryImpl[T]) FindAll(ctx context.Context, param models.FindAllParam) ([]T, error) {
+func (r *CommonRepositoryImpl[T]) FindAll(ctx context.Context, param models.FindAllParam) (_ []T, err error) {
+	ctx, span := r.startSpan(ctx, "FindAll")
+	defer func() { tracing.End(span, err) }()
+
+	return r.findAll(conn(ctx, r.db), param)
+}
+
+// FindAllForUpdate locks the rows found with FOR UPDATE until the end of the ambient transaction,
+// the preloaded relations aren't locked
+func (r *CommonRepositoryImpl[T]) FindAllForUpdate(ctx context.Context, param models.FindAllParam) (_ []T, err error) {
+	ctx, span := r.startSpan(ctx, "FindAllForUpdate")
+	defer func() { tracing.End(span, err) }()
+
+	tx, err := lockingConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.findAll(tx.Clauses(clause.Locking{Strength: "UPDATE"}), param)
+}
+
+func (r *CommonRepositoryImpl[T]) findAll(query *gorm.DB, param models.FindAllParam) ([]T, error) {
 	var models []T
-	query := r.db.WithContext(ctx)
 	query = r.buildQueryFindAll(param, query)
 
 	// Execute the query
@@ -66,15 +148,155 @@ func (r *CommonRepositoryImpl[T]) FindAll(ctx context.Context, param models.Find
 	return models, nil
 }
 
-func (r *CommonRepositoryImpl[T]) Insert(ctx context.Context, tx *gorm.DB, model *T) (string, error) {
-	db := r.db
-	if tx != nil {
-		db = tx
+// FindAllByCursor pages through the records with keyset pagination on (sort field, id), unlike offset
+// pagination a page never skips or repeats rows when new records are inserted while paging.
+// It returns the cursor of the next page, which is empty when there is no next page.
+func (r *CommonRepositoryImpl[T]) FindAllByCursor(ctx context.Context, param models.FindAllParam) (_ []T, _ string, err error) {
+	ctx, span := r.startSpan(ctx, "FindAllByCursor")
+	defer func() { tracing.End(span, err) }()
+
+	stmt := &gorm.Statement{DB: r.db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, "", err
+	}
+
+	idField := stmt.Schema.PrioritizedPrimaryField
+	if idField == nil {
+		return nil, "", fmt.Errorf("%s has no primary key to page through", stmt.Schema.Table)
 	}
+	sortField := idField
+	if param.SortBy.FieldName != "" {
+		field, err := lookUpSortField(stmt.Schema, param.SortBy)
+		if err != nil {
+			return nil, "", err
+		}
+		sortField = field
+	}
+
+	direction := models.SortDirectAscending
+	operator := ">"
+	if param.SortBy.Direction == models.SortDirectDescending {
+		direction = models.SortDirectDescending
+		operator = "<"
+	}
+
+	sortColumn := stmt.Schema.Table + "." + sortField.DBName
+	idColumn := stmt.Schema.Table + "." + idField.DBName
+
+	query := conn(ctx, r.db)
+	if param.Cursor != "" {
+		cursor, err := helpers.DecodeCursor(param.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		if cursor.SortField != sortField.DBName || cursor.Direction != direction {
+			return nil, "", errors.New("cursor does not match the requested sort")
+		}
+
+		// decode the value into the field's own type so it's bound with the right SQL type
+		value := reflect.New(sortField.FieldType)
+		if err := json.Unmarshal(cursor.Value, value.Interface()); err != nil {
+			return nil, "", helpers.ErrInvalidCursor
+		}
+
+		if sortField == idField {
+			query = query.Where(fmt.Sprintf("%s %s ?", idColumn, operator), cursor.ID)
+		} else {
+			query = query.Where(fmt.Sprintf("(%s, %s) %s (?, ?)", sortColumn, idColumn, operator), value.Elem().Interface(), cursor.ID)
+		}
+	}
+
+	query = query.Order(orderByColumn(stmt.Schema, sortField, direction))
+	if sortField != idField {
+		query = query.Order(orderByColumn(stmt.Schema, idField, direction))
+	}
+
+	// the cursor replaces both the sorting and the offset of the regular FindAll
+	pageParam := param
+	pageParam.SortBy = models.SortBy{}
+	pageParam.Limit = 0
+	query = r.buildQueryFindAll(pageParam, query)
+
+	limit := param.Limit
+	if limit <= 0 {
+		limit = helpers.DefaultPageLimit
+	}
+
+	// fetch one extra row to know whether there is a next page
+	var rows []T
+	if err := query.Limit(limit + 1).Find(&rows).Error; err != nil {
+		return nil, "", err
+	}
+	if len(rows) <= limit {
+		return rows, "", nil
+	}
+
+	rows = rows[:limit]
+	last := reflect.ValueOf(&rows[limit-1]).Elem()
+	sortValue, _ := sortField.ValueOf(ctx, last)
+	idValue, _ := idField.ValueOf(ctx, last)
+
+	rawValue, err := json.Marshal(sortValue)
+	if err != nil {
+		return nil, "", err
+	}
+	nextCursor, err := helpers.EncodeCursor(models.Cursor{
+		SortField: sortField.DBName,
+		Direction: direction,
+		Value:     rawValue,
+		ID:        fmt.Sprint(idValue),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return rows, nextCursor, nil
+}
 
-	result := db.WithContext(ctx).Create(model)
+func (r *CommonRepositoryImpl[T]) Count(ctx context.Context, param models.FindAllParam) (_ int64, err error) {
+	ctx, span := r.startSpan(ctx, "Count")
+	defer func() { tracing.End(span, err) }()
+
+	var total int64
+	query := conn(ctx, r.db).Model(new(T))
+	query = r.buildQueryConditions(param, query)
+
+	result := query.Count(&total)
 	if result.Error != nil {
-		return "", result.Error
+		return 0, result.Error
+	}
+	return total, nil
+}
+
+// Exists tells whether a record matches the search and filters of the provided parameters
+func (r *CommonRepositoryImpl[T]) Exists(ctx context.Context, param models.FindAllParam) (_ bool, err error) {
+	ctx, span := r.startSpan(ctx, "Exists")
+	defer func() { tracing.End(span, err) }()
+
+	var found []int
+	query := conn(ctx, r.db).Model(new(T))
+	query = r.buildQueryConditions(param, query)
+
+	result := query.Select("1").Limit(1).Scan(&found)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return len(found) > 0, nil
+}
+
+// Insert, Update and Delete record an audit entry in the same transaction as the change
+func (r *CommonRepositoryImpl[T]) Insert(ctx context.Context, model *T) (_ string, err error) {
+	ctx, span := r.startSpan(ctx, "Insert")
+	defer func() { tracing.End(span, err) }()
+
+	err = withAuditTransaction(ctx, r.db, func(db *gorm.DB) error {
+		if err := db.WithContext(ctx).Create(model).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, db, models.AuditActionCreate, nil, model)
+	})
+	if err != nil {
+		return "", err
 	}
 
 	idField := reflect.ValueOf(model).Elem().FieldByName("ID")
@@ -85,18 +307,176 @@ func (r *CommonRepositoryImpl[T]) Insert(ctx context.Context, tx *gorm.DB, model
 	return id, nil
 }
 
-func (r *CommonRepositoryImpl[T]) Update(ctx context.Context, tx *gorm.DB, model *T) error {
-	db := r.db
-	if tx != nil {
-		db = tx
+// BulkInsert inserts the records batchSize rows per statement, the records get their generated ID
+func (r *CommonRepositoryImpl[T]) BulkInsert(ctx context.Context, records []T, batchSize int) (err error) {
+	ctx, span := r.startSpan(ctx, "BulkInsert")
+	defer func() { tracing.End(span, err) }()
+
+	if len(records) == 0 {
+		return nil
+	}
+
+	return withAuditTransaction(ctx, r.db, func(db *gorm.DB) error {
+		if err := db.WithContext(ctx).CreateInBatches(&records, batchSize).Error; err != nil {
+			return err
+		}
+		return recordCreateAudits(ctx, db, records, batchSize)
+	})
+}
+
+func (r *CommonRepositoryImpl[T]) Update(ctx context.Context, model *T) (err error) {
+	ctx, span := r.startSpan(ctx, "Update")
+	defer func() { tracing.End(span, err) }()
+
+	return withAuditTransaction(ctx, r.db, func(db *gorm.DB) error {
+		before, err := r.findCurrent(ctx, db, model)
+		if err != nil {
+			return err
+		}
+
+		// Save inserts the row when it doesn't exist yet
+		if before == nil {
+			if err := db.WithContext(ctx).Save(model).Error; err != nil {
+				return err
+			}
+			return recordAudit(ctx, db, models.AuditActionCreate, nil, model)
+		}
+
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return err
+		}
+		if field := versionField(stmt.Schema); field != nil {
+			err = compareAndSwap(ctx, db.WithContext(ctx), stmt.Schema, field, model)
+		} else {
+			err = db.WithContext(ctx).Save(model).Error
+		}
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, db, models.AuditActionUpdate, before, model)
+	})
+}
+
+// UpdateFields updates only the given columns of a record, by column or Go field name, so the caller
+// doesn't need to read it first. A versioned record gets its version bumped.
+func (r *CommonRepositoryImpl[T]) UpdateFields(ctx context.Context, id string, fields map[string]interface{}) (err error) {
+	ctx, span := r.startSpan(ctx, "UpdateFields")
+	defer func() { tracing.End(span, err) }()
+
+	modelSchema, err := r.schema()
+	if err != nil {
+		return err
+	}
+	if modelSchema.PrioritizedPrimaryField == nil {
+		return fmt.Errorf("unable to update %s without primary key", modelSchema.Table)
+	}
+
+	columns := make(map[string]interface{}, len(fields)+1)
+	for name, value := range fields {
+		field, err := lookUpColumn(modelSchema, name)
+		if err != nil {
+			return err
+		}
+		if field.PrimaryKey || field.DBName == versionColumn {
+			return fmt.Errorf("column %q of %s can't be updated", name, modelSchema.Table)
+		}
+		columns[field.DBName] = value
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+	if versionField(modelSchema) != nil {
+		columns[versionColumn] = gorm.Expr(versionColumn + " + 1")
+	}
+
+	byID := fmt.Sprintf("%s.%s = ?", modelSchema.Table, modelSchema.PrioritizedPrimaryField.DBName)
+	return withAuditTransaction(ctx, r.db, func(db *gorm.DB) error {
+		var before, after T
+		if err := db.Session(&gorm.Session{NewDB: true}).WithContext(ctx).Where(byID, id).Take(&before).Error; err != nil {
+			return err
+		}
+		if err := db.WithContext(ctx).Model(new(T)).Where(byID, id).Updates(columns).Error; err != nil {
+			return err
+		}
+		if err := db.Session(&gorm.Session{NewDB: true}).WithContext(ctx).Where(byID, id).Take(&after).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, db, models.AuditActionUpdate, &before, &after)
+	})
+}
+
+func (r *CommonRepositoryImpl[T]) Delete(ctx context.Context, model *T) (err error) {
+	ctx, span := r.startSpan(ctx, "Delete")
+	defer func() { tracing.End(span, err) }()
+
+	return withAuditTransaction(ctx, r.db, func(db *gorm.DB) error {
+		before, err := r.findCurrent(ctx, db, model)
+		if err != nil {
+			return err
+		}
+
+		if err := db.WithContext(ctx).Delete(model).Error; err != nil {
+			return err
+		}
+
+		if before == nil {
+			return nil
+		}
+		return recordAudit(ctx, db, models.AuditActionDelete, before, nil)
+	})
+}
+
+// findCurrent reads the stored version of the model, nil when it isn't stored yet
+func (r *CommonRepositoryImpl[T]) findCurrent(ctx context.Context, db *gorm.DB, model *T) (*T, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+	primaryField := stmt.Schema.PrioritizedPrimaryField
+	if primaryField == nil {
+		return nil, nil
+	}
+
+	id, isZero := primaryField.ValueOf(ctx, reflect.ValueOf(model).Elem())
+	if isZero {
+		return nil, nil
+	}
+
+	var current T
+	err := db.Session(&gorm.Session{NewDB: true}).WithContext(ctx).Where(fmt.Sprintf("%s.%s = ?", stmt.Schema.Table, primaryField.DBName), id).Take(&current).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
 	}
-	result := db.WithContext(ctx).Save(model)
-	return result.Error
+	return &current, nil
 }
 
-func (r *CommonRepositoryImpl[T]) FindByID(ctx context.Context, id string, relations []string) (*T, error) {
+func (r *CommonRepositoryImpl[T]) FindByID(ctx context.Context, id string, relations []string) (_ *T, err error) {
+	ctx, span := r.startSpan(ctx, "FindByID")
+	defer func() { tracing.End(span, err) }()
+
+	return r.findByID(conn(ctx, r.db), id, relations)
+}
+
+// FindByIDForUpdate locks the row found with FOR UPDATE until the end of the ambient transaction,
+// the preloaded relations aren't locked
+func (r *CommonRepositoryImpl[T]) FindByIDForUpdate(ctx context.Context, id string, relations []string) (_ *T, err error) {
+	ctx, span := r.startSpan(ctx, "FindByIDForUpdate")
+	defer func() { tracing.End(span, err) }()
+
+	tx, err := lockingConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.findByID(tx.Clauses(clause.Locking{Strength: "UPDATE"}), id, relations)
+}
+
+func (r *CommonRepositoryImpl[T]) findByID(query *gorm.DB, id string, relations []string) (*T, error) {
 	var model T
-	query := r.db.WithContext(ctx).Where("id = ?", id)
+	query = query.Where("id = ?", id)
 	for _, relation := range relations {
 		query = query.Preload(relation)
 	}
@@ -108,6 +488,11 @@ func (r *CommonRepositoryImpl[T]) FindByID(ctx context.Context, id string, relat
 	return &model, nil
 }
 
-func (r *CommonRepositoryImpl[T]) WithTransaction(ctx context.Context, fn TransactionFunc) error {
-	return r.db.WithContext(ctx).Transaction(fn)
+// WithTransaction runs fn as a unit of work, the repository calls made with the context handed to fn
+// run in the transaction, whichever repository they belong to
+func (r *CommonRepositoryImpl[T]) WithTransaction(ctx context.Context, fn TransactionFunc) (err error) {
+	ctx, span := r.startSpan(ctx, "WithTransaction")
+	defer func() { tracing.End(span, err) }()
+
+	return runInTransaction(ctx, r.db, fn)
 }
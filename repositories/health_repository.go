@@ -0,0 +1,10 @@
+package repositories
+
+import (
+	"context"
+)
+
+type HealthRepository interface {
+	Ping(ctx context.Context) error
+	GetSchemaVersion(ctx context.Context) (int64, error)
+}
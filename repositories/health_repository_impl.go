@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// migrationVersionTable is the table where goose records the migrations it applied or rolled back
+const migrationVersionTable = "goose_db_version"
+
+type HealthRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewHealthRepository(db *gorm.DB) *HealthRepositoryImpl {
+	return &HealthRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (r *HealthRepositoryImpl) Ping(ctx context.Context) error {
+	sqlDB, err := r.DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// GetSchemaVersion returns the version of the last migration applied, read the way goose does but
+// without creating its table when the database was never migrated
+func (r *HealthRepositoryImpl) GetSchemaVersion(ctx context.Context) (int64, error) {
+	if !r.DB.WithContext(ctx).Migrator().HasTable(migrationVersionTable) {
+		return 0, nil
+	}
+
+	var records []struct {
+		VersionID int64
+		IsApplied bool
+	}
+	err := r.DB.WithContext(ctx).Table(migrationVersionTable).
+		Select("version_id", "is_applied").
+		Order("id DESC").
+		Find(&records).Error
+	if err != nil {
+		return 0, err
+	}
+
+	// the latest record of a version tells whether it is applied or rolled back
+	rolledBack := map[int64]bool{}
+	for _, record := range records {
+		if rolledBack[record.VersionID] {
+			continue
+		}
+		if record.IsApplied {
+			return record.VersionID, nil
+		}
+		rolledBack[record.VersionID] = true
+	}
+	return 0, nil
+}
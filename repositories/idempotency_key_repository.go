@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+)
+
+type IdempotencyKeyRepository interface {
+	CommonRepository[models.IdempotencyKey]
+
+	Reserve(ctx context.Context, idempotencyKey *models.IdempotencyKey, now time.Time) (bool, error)
+	FindByKey(ctx context.Context, scope string, key string) (*models.IdempotencyKey, error)
+	Complete(ctx context.Context, idempotencyKey *models.IdempotencyKey) error
+	Release(ctx context.Context, idempotencyKey *models.IdempotencyKey) error
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
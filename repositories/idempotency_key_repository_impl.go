@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/satryarangga/amartha-loan-engine/helpers"
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IdempotencyKeyRepositoryImpl writes the keys directly, they are a cache of responses and aren't audited
+type IdempotencyKeyRepositoryImpl struct {
+	DB *gorm.DB
+	CommonRepository[models.IdempotencyKey]
+}
+
+func NewIdempotencyKeyRepository(db *gorm.DB) *IdempotencyKeyRepositoryImpl {
+	return &IdempotencyKeyRepositoryImpl{
+		DB:               db,
+		CommonRepository: NewCommonRepository[models.IdempotencyKey](db),
+	}
+}
+
+// Reserve claims the key for a new request and returns false when it is already claimed. An
+// expired key, or a key whose request stopped without completing, is taken over.
+func (r *IdempotencyKeyRepositoryImpl) Reserve(ctx context.Context, idempotencyKey *models.IdempotencyKey, now time.Time) (bool, error) {
+	result := conn(ctx, r.DB).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "scope"}, {Name: "key"}},
+		Where: clause.Where{Exprs: []clause.Expression{clause.Expr{
+			SQL:  "idempotency_keys.expires_at <= ? OR (idempotency_keys.completed_at IS NULL AND idempotency_keys.locked_until <= ?)",
+			Vars: []interface{}{now, now},
+		}}},
+		DoUpdates: clause.AssignmentColumns([]string{"request_hash", "status_code", "content_type", "response_body", "locked_until", "completed_at", "expires_at", "created_at"}),
+	}).Create(idempotencyKey)
+	return result.RowsAffected > 0, result.Error
+}
+
+// FindByKey reads from the primary, the key is looked up right after another request claimed it
+func (r *IdempotencyKeyRepositoryImpl) FindByKey(ctx context.Context, scope string, key string) (*models.IdempotencyKey, error) {
+	var idempotencyKey models.IdempotencyKey
+	err := conn(helpers.WithPrimaryReads(ctx), r.DB).Where("scope = ? AND key = ?", scope, key).First(&idempotencyKey).Error
+	if err != nil {
+		return nil, err
+	}
+	return &idempotencyKey, nil
+}
+
+// Complete stores the response of the request holding the key
+func (r *IdempotencyKeyRepositoryImpl) Complete(ctx context.Context, idempotencyKey *models.IdempotencyKey) error {
+	return conn(ctx, r.DB).Model(&models.IdempotencyKey{}).
+		Where("id = ? AND request_hash = ?", idempotencyKey.ID, idempotencyKey.RequestHash).
+		UpdateColumns(map[string]interface{}{
+			"status_code":   idempotencyKey.StatusCode,
+			"content_type":  idempotencyKey.ContentType,
+			"response_body": idempotencyKey.ResponseBody,
+			"completed_at":  idempotencyKey.CompletedAt,
+		}).Error
+}
+
+// Release frees the key without a response, so the request can be retried
+func (r *IdempotencyKeyRepositoryImpl) Release(ctx context.Context, idempotencyKey *models.IdempotencyKey) error {
+	return conn(ctx, r.DB).Where("id = ? AND completed_at IS NULL", idempotencyKey.ID).Delete(&models.IdempotencyKey{}).Error
+}
+
+func (r *IdempotencyKeyRepositoryImpl) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := conn(ctx, r.DB).Where("expires_at <= ?", now).Delete(&models.IdempotencyKey{})
+	return result.RowsAffected, result.Error
+}
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"time"
 
 	"github.com/satryarangga/amartha-loan-engine/models"
 )
@@ -10,4 +11,8 @@ type LoanRepository interface {
 	CommonRepository[models.Loan]
 
 	FindOneByBorrowerID(ctx context.Context, borrowerID string) (models.Loan, error)
+
+	FindAllByFilter(ctx context.Context, filter models.LoanFilter, param models.FindAllParam) ([]models.Loan, string, error)
+
+	GetPortfolioStats(ctx context.Context, now time.Time) (models.PortfolioStats, error)
 }
@@ -2,7 +2,9 @@ package repositories
 
 import (
 	"context"
+	"time"
 
+	"github.com/satryarangga/amartha-loan-engine/helpers"
 	"github.com/satryarangga/amartha-loan-engine/models"
 	"gorm.io/gorm"
 )
@@ -21,9 +23,88 @@ func NewLoanRepository(db *gorm.DB) *LoanRepositoryImpl {
 
 func (r *LoanRepositoryImpl) FindOneByBorrowerID(ctx context.Context, borrowerID string) (models.Loan, error) {
 	var loan models.Loan
-	err := r.DB.WithContext(ctx).
+	err := conn(ctx, r.DB).
 		Where("borrower_id = ? and status = ?", borrowerID, models.LoanStatusActive).
 		Preload("LoanSchedules").
 		First(&loan).Error
 	return loan, err
 }
+
+const (
+	outstandingSubquery    = "(SELECT COALESCE(SUM(ls.total_payment), 0) FROM loan_schedules ls WHERE ls.loan_id = loans.id AND ls.status = ?)"
+	overdueScheduleExists  = "EXISTS (SELECT 1 FROM loan_schedules ls WHERE ls.loan_id = loans.id AND ls.status = ? AND ls.due_date <= ?)"
+	overdueScheduleMissing = "NOT " + overdueScheduleExists
+)
+
+// FindAllByFilter pages through the loans matching the filter using the cursor of the param
+func (r *LoanRepositoryImpl) FindAllByFilter(ctx context.Context, filter models.LoanFilter, param models.FindAllParam) ([]models.Loan, string, error) {
+	filters := map[string]interface{}{}
+	for column, value := range param.Filters {
+		filters[column] = value
+	}
+	if filter.Status != "" {
+		filters["loans.status"] = filter.Status
+	}
+	if filter.BorrowerID != "" {
+		filters["loans.borrower_id"] = filter.BorrowerID
+	}
+	if filter.ProductCode != "" {
+		filters["loans.product_code"] = filter.ProductCode
+	}
+	param.Filters = filters
+
+	conditions := append([]models.Condition{}, param.Conditions...)
+	if filter.DisbursedFrom != nil {
+		conditions = append(conditions, models.Condition{Query: "loans.disbursed_at >= ?", Args: []interface{}{*filter.DisbursedFrom}})
+	}
+	if filter.DisbursedTo != nil {
+		conditions = append(conditions, models.Condition{Query: "loans.disbursed_at < ?", Args: []interface{}{*filter.DisbursedTo}})
+	}
+
+	// DPD is counted from the oldest pending schedule, so a loan has at least N DPD when a pending
+	// schedule was due N days ago, and at most N DPD when no pending schedule was due N+1 days ago
+	now := time.Now()
+	if filter.MinDPD != nil && *filter.MinDPD > 0 {
+		conditions = append(conditions, models.Condition{
+			Query: overdueScheduleExists,
+			Args:  []interface{}{models.LoanScheduleStatusPending, now.AddDate(0, 0, -*filter.MinDPD)},
+		})
+	}
+	if filter.MaxDPD != nil {
+		conditions = append(conditions, models.Condition{
+			Query: overdueScheduleMissing,
+			Args:  []interface{}{models.LoanScheduleStatusPending, now.AddDate(0, 0, -(*filter.MaxDPD + 1))},
+		})
+	}
+
+	if filter.MinOutstanding != nil {
+		conditions = append(conditions, models.Condition{
+			Query: outstandingSubquery + " >= ?",
+			Args:  []interface{}{models.LoanScheduleStatusPending, *filter.MinOutstanding},
+		})
+	}
+	if filter.MaxOutstanding != nil {
+		conditions = append(conditions, models.Condition{
+			Query: outstandingSubquery + " <= ?",
+			Args:  []interface{}{models.LoanScheduleStatusPending, *filter.MaxOutstanding},
+		})
+	}
+	param.Conditions = conditions
+
+	return r.CommonRepository.FindAllByCursor(ctx, param)
+}
+
+// GetPortfolioStats sums up the active loans, a borrower is delinquent with as many overdue schedules
+// as helpers.IsBorrowerDelinquent requires
+func (r *LoanRepositoryImpl) GetPortfolioStats(ctx context.Context, now time.Time) (models.PortfolioStats, error) {
+	var stats models.PortfolioStats
+	err := conn(ctx, r.DB).Raw(`SELECT
+		(SELECT COALESCE(SUM(ls.total_payment), 0) FROM loan_schedules ls JOIN loans ON loans.id = ls.loan_id
+			WHERE loans.status = ? AND ls.status = ?) AS outstanding_amount,
+		(SELECT COUNT(DISTINCT loans.borrower_id) FROM loans
+			WHERE loans.status = ? AND (SELECT COUNT(*) FROM loan_schedules ls WHERE ls.loan_id = loans.id AND ls.status = ? AND ls.due_date < ?) >= ?) AS delinquent_borrowers`,
+		models.LoanStatusActive, models.LoanScheduleStatusPending,
+		models.LoanStatusActive, models.LoanScheduleStatusPending, now, helpers.ActiveLoanRules.DelinquencyOverdueSchedules,
+	).Scan(&stats).Error
+	return stats, err
+}
@@ -2,15 +2,18 @@ package repositories
 
 import (
 	"context"
+	"time"
 
 	"github.com/satryarangga/amartha-loan-engine/models"
-	"gorm.io/gorm"
 )
 
 type LoanScheduleRepository interface {
 	CommonRepository[models.LoanSchedule]
 
-	FindDueRepaymentSchedules(ctx context.Context, loanID string) ([]models.LoanSchedule, error)
+	FindDueRepaymentSchedules(ctx context.Context, loanID string, dueBefore time.Time) ([]models.LoanSchedule, error)
 
-	UpdateStatusByIDs(ctx context.Context, tx *gorm.DB, ids []string, status models.LoanScheduleStatus) error
+	UpdateStatusByIDs(ctx context.Context, ids []string, status models.LoanScheduleStatus) error
+
+	FindNewlyOverdueForUpdate(ctx context.Context, now time.Time, limit int) ([]models.LoanSchedule, error)
+	MarkOverdueByIDs(ctx context.Context, ids []string, overdueAt time.Time) error
 }
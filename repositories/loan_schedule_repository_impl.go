@@ -6,6 +6,7 @@ import (
 
 	"github.com/satryarangga/amartha-loan-engine/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type LoanScheduleRepositoryImpl struct {
@@ -20,16 +21,70 @@ func NewLoanScheduleRepository(db *gorm.DB) *LoanScheduleRepositoryImpl {
 	}
 }
 
-func (r *LoanScheduleRepositoryImpl) FindDueRepaymentSchedules(ctx context.Context, loanID string) ([]models.LoanSchedule, error) {
+func (r *LoanScheduleRepositoryImpl) FindDueRepaymentSchedules(ctx context.Context, loanID string, dueBefore time.Time) ([]models.LoanSchedule, error) {
 	var loanSchedules []models.LoanSchedule
-	err := r.DB.WithContext(ctx).Where("loan_id = ? and status = ? and due_date <= ?", loanID, models.LoanScheduleStatusPending, time.Now().AddDate(0, 0, 3)).Find(&loanSchedules).Error
+	err := conn(ctx, r.DB).Where("loan_id = ? and status = ? and due_date <= ?", loanID, models.LoanScheduleStatusPending, dueBefore).Find(&loanSchedules).Error
 	return loanSchedules, err
 }
 
-func (r *LoanScheduleRepositoryImpl) UpdateStatusByIDs(ctx context.Context, tx *gorm.DB, ids []string, status models.LoanScheduleStatus) error {
-	db := r.DB
-	if tx != nil {
-		db = tx
+func (r *LoanScheduleRepositoryImpl) UpdateStatusByIDs(ctx context.Context, ids []string, status models.LoanScheduleStatus) error {
+	return r.updateByIDs(ctx, ids, "status", status, func(loanSchedule *models.LoanSchedule) {
+		loanSchedule.Status = status
+	})
+}
+
+// FindNewlyOverdueForUpdate locks the pending schedules past their due date that weren't reported as overdue yet.
+// Their loan is preloaded for its borrower, without being locked.
+func (r *LoanScheduleRepositoryImpl) FindNewlyOverdueForUpdate(ctx context.Context, now time.Time, limit int) ([]models.LoanSchedule, error) {
+	tx, err := lockingConn(ctx)
+	if err != nil {
+		return nil, err
 	}
-	return db.WithContext(ctx).Model(&models.LoanSchedule{}).Where("id IN (?)", ids).Update("status", status).Error
+
+	var loanSchedules []models.LoanSchedule
+	err = tx.
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Preload("Loan", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "borrower_id")
+		}).
+		Where("status = ? AND overdue_at IS NULL AND due_date < ?", models.LoanScheduleStatusPending, now).
+		Order("due_date asc").
+		Limit(limit).
+		Find(&loanSchedules).Error
+	return loanSchedules, err
+}
+
+func (r *LoanScheduleRepositoryImpl) MarkOverdueByIDs(ctx context.Context, ids []string, overdueAt time.Time) error {
+	return r.updateByIDs(ctx, ids, "overdue_at", overdueAt, func(loanSchedule *models.LoanSchedule) {
+		loanSchedule.OverdueAt = &overdueAt
+	})
+}
+
+// updateByIDs sets one column on several schedules and audits every schedule changed
+func (r *LoanScheduleRepositoryImpl) updateByIDs(ctx context.Context, ids []string, column string, value interface{}, apply func(loanSchedule *models.LoanSchedule)) error {
+	return withAuditTransaction(ctx, r.DB, func(db *gorm.DB) error {
+		var loanSchedules []models.LoanSchedule
+		if err := db.WithContext(ctx).Where("id IN (?)", ids).Find(&loanSchedules).Error; err != nil {
+			return err
+		}
+
+		// the version is bumped too, so an update of a schedule read before fails instead of undoing this one
+		err := db.WithContext(ctx).Model(&models.LoanSchedule{}).Where("id IN (?)", ids).Updates(map[string]interface{}{
+			column:        value,
+			versionColumn: gorm.Expr(versionColumn + " + 1"),
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		for i := range loanSchedules {
+			updated := loanSchedules[i]
+			apply(&updated)
+			updated.Version++
+			if err := recordAudit(ctx, db, models.AuditActionUpdate, &loanSchedules[i], &updated); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
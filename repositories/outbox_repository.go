@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+)
+
+type OutboxRepository interface {
+	CommonRepository[models.OutboxEvent]
+
+	Append(ctx context.Context, events []models.OutboxEvent) error
+	FindPendingForUpdate(ctx context.Context, now time.Time, limit int) ([]models.OutboxEvent, error)
+	MarkPublished(ctx context.Context, id string, publishedAt time.Time) error
+	MarkFailed(ctx context.Context, id string, lastError string, availableAt time.Time) error
+}
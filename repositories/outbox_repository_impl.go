@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OutboxRepositoryImpl writes the outbox directly instead of through Insert and Update,
+// events are already a record of a change so they aren't audited
+type OutboxRepositoryImpl struct {
+	DB *gorm.DB
+	CommonRepository[models.OutboxEvent]
+}
+
+func NewOutboxRepository(db *gorm.DB) *OutboxRepositoryImpl {
+	return &OutboxRepositoryImpl{
+		DB:               db,
+		CommonRepository: NewCommonRepository[models.OutboxEvent](db),
+	}
+}
+
+func (r *OutboxRepositoryImpl) Append(ctx context.Context, events []models.OutboxEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return conn(ctx, r.DB).Create(&events).Error
+}
+
+// FindPendingForUpdate locks the next unpublished events, SKIP LOCKED lets several relays run side by side
+func (r *OutboxRepositoryImpl) FindPendingForUpdate(ctx context.Context, now time.Time, limit int) ([]models.OutboxEvent, error) {
+	tx, err := lockingConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []models.OutboxEvent
+	err = tx.
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("published_at IS NULL AND available_at <= ?", now).
+		Order("created_at asc").
+		Limit(limit).
+		Find(&events).Error
+	return events, err
+}
+
+func (r *OutboxRepositoryImpl) MarkPublished(ctx context.Context, id string, publishedAt time.Time) error {
+	return conn(ctx, r.DB).Model(&models.OutboxEvent{}).Where("id = ?", id).UpdateColumn("published_at", publishedAt).Error
+}
+
+func (r *OutboxRepositoryImpl) MarkFailed(ctx context.Context, id string, lastError string, availableAt time.Time) error {
+	return conn(ctx, r.DB).Model(&models.OutboxEvent{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+		"attempts":     gorm.Expr("attempts + 1"),
+		"last_error":   lastError,
+		"available_at": availableAt,
+	}).Error
+}
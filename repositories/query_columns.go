@@ -0,0 +1,162 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// queryTag marks the columns of a model the callers may sort and / or search on,
+// e.g. `query:"sort,search"`
+const queryTag = "query"
+
+const (
+	QueryErrorCodeInvalidSortField     = "invalid_sort_field"
+	QueryErrorCodeInvalidSortDirection = "invalid_sort_direction"
+	QueryErrorCodeInvalidSearchField   = "invalid_search_field"
+	QueryErrorCodeInvalidSearchMode    = "invalid_search_mode"
+)
+
+// QueryValidationError is returned when the sorting or the search of a query isn't allowed on the model,
+// the message is safe to show to the caller
+type QueryValidationError struct {
+	Code    string
+	Message string
+}
+
+func (e *QueryValidationError) Error() string {
+	return e.Message
+}
+
+// queryColumns are the columns of a model allowed in ORDER BY and in the search, by column name
+type queryColumns struct {
+	sortable   map[string]*schema.Field
+	searchable map[string]*schema.Field
+}
+
+var (
+	schemaCache       sync.Map
+	queryColumnsCache sync.Map
+)
+
+// columnsOf returns the query columns of the model, read once from its query tags. The primary key is
+// always sortable since it's the tiebreaker of the cursor pagination.
+func columnsOf(modelSchema *schema.Schema) *queryColumns {
+	if cached, ok := queryColumnsCache.Load(modelSchema.ModelType); ok {
+		return cached.(*queryColumns)
+	}
+
+	columns := &queryColumns{
+		sortable:   map[string]*schema.Field{},
+		searchable: map[string]*schema.Field{},
+	}
+	if primaryField := modelSchema.PrioritizedPrimaryField; primaryField != nil {
+		columns.sortable[primaryField.DBName] = primaryField
+	}
+	for _, field := range modelSchema.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		for _, usage := range strings.Split(field.Tag.Get(queryTag), ",") {
+			switch strings.TrimSpace(usage) {
+			case "sort":
+				columns.sortable[field.DBName] = field
+			case "search":
+				columns.searchable[field.DBName] = field
+			}
+		}
+	}
+
+	cached, _ := queryColumnsCache.LoadOrStore(modelSchema.ModelType, columns)
+	return cached.(*queryColumns)
+}
+
+// ValidateSort tells whether the records of the model can be sorted as requested, so a service can
+// reject a request before reaching the repository
+func ValidateSort[T any](sortBy models.SortBy) error {
+	modelSchema, err := schema.Parse(new(T), &schemaCache, schema.NamingStrategy{})
+	if err != nil {
+		return err
+	}
+	_, err = lookUpSortField(modelSchema, sortBy)
+	return err
+}
+
+// lookUpSortField returns the field to sort on, which has to be tagged as sortable
+func lookUpSortField(modelSchema *schema.Schema, sortBy models.SortBy) (*schema.Field, error) {
+	field, ok := columnsOf(modelSchema).sortable[sortBy.FieldName]
+	if !ok {
+		return nil, &QueryValidationError{
+			Code:    QueryErrorCodeInvalidSortField,
+			Message: fmt.Sprintf("unable to sort %s by %q", modelSchema.Table, sortBy.FieldName),
+		}
+	}
+
+	switch sortBy.Direction {
+	case "", models.SortDirectAscending, models.SortDirectDescending:
+	default:
+		return nil, &QueryValidationError{
+			Code:    QueryErrorCodeInvalidSortDirection,
+			Message: fmt.Sprintf("invalid sort direction %q", sortBy.Direction),
+		}
+	}
+	return field, nil
+}
+
+// orderByColumn sorts on the column qualified with the table of the model, ascending unless told otherwise
+func orderByColumn(modelSchema *schema.Schema, field *schema.Field, direction models.SortDirection) clause.OrderByColumn {
+	return clause.OrderByColumn{
+		Column: clause.Column{Table: modelSchema.Table, Name: field.DBName},
+		Desc:   direction == models.SortDirectDescending,
+	}
+}
+
+// searchExpression matches the keyword against the searchable fields. The contains mode is an ILIKE on
+// any of the fields, the full text mode matches the keyword as a web search query against the
+// tsvector of the fields concatenated in order, which an expression index over the same fields serves.
+func searchExpression(modelSchema *schema.Schema, fieldNames []string, mode models.SearchMode, keyword string) (clause.Expression, error) {
+	searchable := columnsOf(modelSchema).searchable
+	columns := make([]interface{}, 0, len(fieldNames))
+	for _, name := range fieldNames {
+		field, ok := searchable[name]
+		if !ok {
+			return nil, &QueryValidationError{
+				Code:    QueryErrorCodeInvalidSearchField,
+				Message: fmt.Sprintf("unable to search %s by %q", modelSchema.Table, name),
+			}
+		}
+		columns = append(columns, clause.Column{Table: modelSchema.Table, Name: field.DBName})
+	}
+
+	switch mode {
+	case "", models.SearchModeContains:
+		pattern := "%" + escapeLike(keyword) + "%"
+		expressions := make([]clause.Expression, 0, len(columns))
+		for _, column := range columns {
+			expressions = append(expressions, clause.Expr{SQL: "? ILIKE ?", Vars: []interface{}{column, pattern}})
+		}
+		return clause.Or(expressions...), nil
+	case models.SearchModeFullText:
+		document := strings.TrimSuffix(strings.Repeat("coalesce(?, '') || ' ' || ", len(columns)), " || ' ' || ")
+		return clause.Expr{
+			SQL:  "to_tsvector('simple', " + document + ") @@ websearch_to_tsquery('simple', ?)",
+			Vars: append(columns, keyword),
+		}, nil
+	default:
+		return nil, &QueryValidationError{
+			Code:    QueryErrorCodeInvalidSearchMode,
+			Message: fmt.Sprintf("invalid search mode %q", mode),
+		}
+	}
+}
+
+var likeReplacer = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike escapes the wildcards of LIKE so the keyword is matched literally
+func escapeLike(keyword string) string {
+	return likeReplacer.Replace(keyword)
+}
@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommonRepositoryImpl_BuildQueryFindAll_SortAndSearch(t *testing.T) {
+	tests := []struct {
+		name     string
+		param    models.FindAllParam
+		wantSQL  string
+		wantVars []interface{}
+	}{
+		{
+			name: "contains",
+			param: models.FindAllParam{
+				SearchKeyword:  "50%_off",
+				FieldsToSearch: []string{"first_name", "phone_number"},
+				SortBy:         models.SortBy{FieldName: "last_name", Direction: models.SortDirectDescending},
+			},
+			wantSQL:  `SELECT * FROM "borrowers" WHERE ("borrowers"."first_name" ILIKE $1 OR "borrowers"."phone_number" ILIKE $2) AND "borrowers"."deleted_at" IS NULL ORDER BY "borrowers"."last_name" DESC`,
+			wantVars: []interface{}{`%50\%\_off%`, `%50\%\_off%`},
+		},
+		{
+			name: "full text",
+			param: models.FindAllParam{
+				SearchKeyword:  "john doe",
+				FieldsToSearch: []string{"first_name", "last_name", "phone_number"},
+				SearchMode:     models.SearchModeFullText,
+				SortBy:         models.SortBy{FieldName: "created_at"},
+			},
+			wantSQL:  `SELECT * FROM "borrowers" WHERE to_tsvector('simple', coalesce("borrowers"."first_name", '') || ' ' || coalesce("borrowers"."last_name", '') || ' ' || coalesce("borrowers"."phone_number", '')) @@ websearch_to_tsquery('simple', $1) AND "borrowers"."deleted_at" IS NULL ORDER BY "borrowers"."created_at"`,
+			wantVars: []interface{}{"john doe"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			db := newDryRunDB(t)
+			repo := NewCommonRepository[models.Borrower](db)
+
+			// Act
+			var borrowers []models.Borrower
+			stmt := repo.buildQueryFindAll(tt.param, db.Model(&models.Borrower{})).Find(&borrowers).Statement
+
+			// Assert
+			assert.NoError(t, stmt.Error)
+			assert.Equal(t, tt.wantSQL, stmt.SQL.String())
+			assert.Equal(t, tt.wantVars, stmt.Vars)
+		})
+	}
+}
+
+func TestCommonRepositoryImpl_BuildQueryFindAll_InvalidSortAndSearch(t *testing.T) {
+	tests := []struct {
+		name     string
+		param    models.FindAllParam
+		wantCode string
+	}{
+		{
+			name:     "injected sort field",
+			param:    models.FindAllParam{SortBy: models.SortBy{FieldName: "id; DROP TABLE borrowers"}},
+			wantCode: QueryErrorCodeInvalidSortField,
+		},
+		{
+			name:     "column not tagged as sortable",
+			param:    models.FindAllParam{SortBy: models.SortBy{FieldName: "kyc_status"}},
+			wantCode: QueryErrorCodeInvalidSortField,
+		},
+		{
+			name:     "injected sort direction",
+			param:    models.FindAllParam{SortBy: models.SortBy{FieldName: "created_at", Direction: "asc, (SELECT 1)"}},
+			wantCode: QueryErrorCodeInvalidSortDirection,
+		},
+		{
+			name:     "column not tagged as searchable",
+			param:    models.FindAllParam{SearchKeyword: "john", FieldsToSearch: []string{"kyc_status"}},
+			wantCode: QueryErrorCodeInvalidSearchField,
+		},
+		{
+			name:     "unknown search mode",
+			param:    models.FindAllParam{SearchKeyword: "john", FieldsToSearch: []string{"first_name"}, SearchMode: "regex"},
+			wantCode: QueryErrorCodeInvalidSearchMode,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			db := newDryRunDB(t)
+			repo := NewCommonRepository[models.Borrower](db)
+
+			// Act
+			var borrowers []models.Borrower
+			err := repo.buildQueryFindAll(tt.param, db.Model(&models.Borrower{})).Find(&borrowers).Error
+
+			// Assert
+			var queryErr *QueryValidationError
+			assert.True(t, errors.As(err, &queryErr))
+			assert.Equal(t, tt.wantCode, queryErr.Code)
+		})
+	}
+}
+
+func TestValidateSort(t *testing.T) {
+	assert.NoError(t, ValidateSort[models.Loan](models.SortBy{FieldName: "disbursed_at", Direction: models.SortDirectAscending}))
+	assert.NoError(t, ValidateSort[models.Loan](models.SortBy{FieldName: "id"}))
+	assert.EqualError(t, ValidateSort[models.Loan](models.SortBy{FieldName: "interest_amount"}), `unable to sort loans by "interest_amount"`)
+	assert.EqualError(t, ValidateSort[models.Loan](models.SortBy{FieldName: "amount", Direction: "up"}), `invalid sort direction "up"`)
+}
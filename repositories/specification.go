@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// ErrUnknownColumn is returned for a column which isn't one of the model
+var ErrUnknownColumn = errors.New("unknown column")
+
+// lookUpColumn finds the field of a column given by its name or the name of its Go field,
+// optionally prefixed with the table of the model
+func lookUpColumn(modelSchema *schema.Schema, column string) (*schema.Field, error) {
+	name := column
+	if table, field, found := strings.Cut(column, "."); found {
+		if table != modelSchema.Table {
+			return nil, fmt.Errorf("%w %q of %s", ErrUnknownColumn, column, modelSchema.Table)
+		}
+		name = field
+	}
+
+	field := modelSchema.LookUpField(name)
+	if field == nil || field.DBName == "" {
+		return nil, fmt.Errorf("%w %q of %s", ErrUnknownColumn, column, modelSchema.Table)
+	}
+	return field, nil
+}
+
+// specExpressions turns the specs into where clauses, the columns are qualified with the table
+// of the model so they stay unambiguous with joins
+func specExpressions(modelSchema *schema.Schema, specs []models.Spec) ([]clause.Expression, error) {
+	expressions := make([]clause.Expression, 0, len(specs))
+	for _, spec := range specs {
+		field, err := lookUpColumn(modelSchema, spec.Column)
+		if err != nil {
+			return nil, err
+		}
+		column := clause.Column{Table: modelSchema.Table, Name: field.DBName}
+
+		switch spec.Operator {
+		case models.SpecOperatorEq, models.SpecOperatorNotEq:
+			if len(spec.Values) != 1 {
+				return nil, fmt.Errorf("%s on %q takes one value, got %d", spec.Operator, spec.Column, len(spec.Values))
+			}
+			// a nil value would be rendered as IS NULL, which IsNull is meant for
+			if spec.Values[0] == nil {
+				return nil, fmt.Errorf("%s on %q takes a non nil value", spec.Operator, spec.Column)
+			}
+			if spec.Operator == models.SpecOperatorEq {
+				expressions = append(expressions, clause.Eq{Column: column, Value: spec.Values[0]})
+			} else {
+				expressions = append(expressions, clause.Neq{Column: column, Value: spec.Values[0]})
+			}
+		case models.SpecOperatorIn:
+			if len(spec.Values) == 0 {
+				expressions = append(expressions, clause.Expr{SQL: "FALSE"})
+			} else {
+				expressions = append(expressions, clause.IN{Column: column, Values: spec.Values})
+			}
+		case models.SpecOperatorRange:
+			if len(spec.Values) != 2 {
+				return nil, fmt.Errorf("%s on %q takes two bounds, got %d", spec.Operator, spec.Column, len(spec.Values))
+			}
+			if spec.Values[0] != nil {
+				expressions = append(expressions, clause.Gte{Column: column, Value: spec.Values[0]})
+			}
+			if spec.Values[1] != nil {
+				expressions = append(expressions, clause.Lt{Column: column, Value: spec.Values[1]})
+			}
+		case models.SpecOperatorIsNull:
+			expressions = append(expressions, clause.Eq{Column: column, Value: nil})
+		case models.SpecOperatorIsNotNull:
+			expressions = append(expressions, clause.Neq{Column: column, Value: nil})
+		default:
+			return nil, fmt.Errorf("unknown operator %q on %q", spec.Operator, spec.Column)
+		}
+	}
+	return expressions, nil
+}
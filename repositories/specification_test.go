@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func newDryRunDB(t *testing.T) *gorm.DB {
+	// dry run, the statements are built but nothing is sent to a server
+	db, err := gorm.Open(postgres.Open("host=localhost"), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	assert.NoError(t, err)
+	return db
+}
+
+func TestCommonRepositoryImpl_BuildQueryConditions_Specs(t *testing.T) {
+	// Arrange
+	db := newDryRunDB(t)
+	repo := NewCommonRepository[models.Borrower](db)
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	param := models.FindAllParam{Specs: []models.Spec{
+		models.Eq("kyc_status", models.KYCStatusVerified),
+		models.In("ID", []string{"borrower-1", "borrower-2"}),
+		models.Range("borrowers.created_at", from, nil),
+		models.IsNotNull("phone_number"),
+	}}
+
+	// Act
+	var borrowers []models.Borrower
+	stmt := repo.buildQueryConditions(param, db.Model(&models.Borrower{})).Find(&borrowers).Statement
+
+	// Assert
+	assert.NoError(t, stmt.Error)
+	assert.Equal(t, `SELECT * FROM "borrowers" WHERE "borrowers"."kyc_status" = $1 AND "borrowers"."id" IN ($2,$3) AND "borrowers"."created_at" >= $4 AND "borrowers"."phone_number" IS NOT NULL AND "borrowers"."deleted_at" IS NULL`, stmt.SQL.String())
+	assert.Equal(t, []interface{}{models.KYCStatusVerified, "borrower-1", "borrower-2", from}, stmt.Vars)
+}
+
+func TestCommonRepositoryImpl_BuildQueryConditions_InvalidSpecs(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    models.Spec
+		wantErr string
+	}{
+		{name: "unknown column", spec: models.Eq("password; DROP TABLE borrowers", 1), wantErr: "unknown column"},
+		{name: "column of another table", spec: models.Eq("loans.id", 1), wantErr: "unknown column"},
+		{name: "nil value", spec: models.Eq("first_name", nil), wantErr: "non nil value"},
+		{name: "unknown operator", spec: models.Spec{Column: "first_name", Operator: "like"}, wantErr: "unknown operator"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			db := newDryRunDB(t)
+			repo := NewCommonRepository[models.Borrower](db)
+
+			// Act
+			var borrowers []models.Borrower
+			err := repo.buildQueryConditions(models.FindAllParam{Specs: []models.Spec{tt.spec}}, db.Model(&models.Borrower{})).Find(&borrowers).Error
+
+			// Assert
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
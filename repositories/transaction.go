@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrNoTransaction is returned by the locking reads called outside WithTransaction, the locks would be
+// released as soon as the statement is done
+var ErrNoTransaction = errors.New("locking read outside a transaction")
+
+type transactionContextKey struct{}
+
+// TransactionFunc runs the unit of work, every repository call made with ctx joins the transaction
+type TransactionFunc func(ctx context.Context) error
+
+func contextWithTransaction(ctx context.Context, tx *gorm.DB) context.Context {
+	return context.WithValue(ctx, transactionContextKey{}, tx)
+}
+
+func transactionFromContext(ctx context.Context) (*gorm.DB, bool) {
+	tx, ok := ctx.Value(transactionContextKey{}).(*gorm.DB)
+	return tx, ok && tx != nil
+}
+
+// conn returns the connection a repository call runs on, the ambient transaction of ctx when there is
+// one and db otherwise
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := transactionFromContext(ctx); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+// lockingConn returns the ambient transaction of ctx for a read taking row locks
+func lockingConn(ctx context.Context) (*gorm.DB, error) {
+	tx, ok := transactionFromContext(ctx)
+	if !ok {
+		return nil, ErrNoTransaction
+	}
+	return tx.WithContext(ctx), nil
+}
+
+// runInTransaction runs fn in the ambient transaction of ctx, or in a new transaction committed when fn
+// succeeds and rolled back otherwise. A nested unit of work joins the outer one, so it's only committed
+// along with it.
+func runInTransaction(ctx context.Context, db *gorm.DB, fn TransactionFunc) error {
+	if _, ok := transactionFromContext(ctx); ok {
+		return fn(ctx)
+	}
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(contextWithTransaction(ctx, tx))
+	})
+}
@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// versionColumn is bumped by every update of the models having it, Update only succeeds when the
+// stored version is still the one the model was read with
+const versionColumn = "version"
+
+// VersionConflictError is returned by Update when the record was changed since it was read, the
+// record has to be read again before retrying
+type VersionConflictError struct {
+	Table   string
+	ID      string
+	Version int64
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("%s %s was changed since version %d", e.Table, e.ID, e.Version)
+}
+
+// versionField returns the version field of the model, nil when the model isn't versioned
+func versionField(modelSchema *schema.Schema) *schema.Field {
+	field := modelSchema.LookUpField(versionColumn)
+	if field == nil || field.FieldType.Kind() != reflect.Int64 {
+		return nil
+	}
+	return field
+}
+
+// compareAndSwap updates every column of the stored model as long as its version is still the one of
+// the model, and bumps the version. The model keeps its version when the update fails.
+func compareAndSwap(ctx context.Context, db *gorm.DB, modelSchema *schema.Schema, field *schema.Field, model interface{}) error {
+	value := reflect.ValueOf(model).Elem()
+	current, _ := field.ValueOf(ctx, value)
+	version := current.(int64)
+
+	if err := field.Set(ctx, value, version+1); err != nil {
+		return err
+	}
+	result := db.Model(model).
+		Where(fmt.Sprintf("%s.%s = ?", modelSchema.Table, field.DBName), version).
+		Select("*").
+		Updates(model)
+	if result.Error == nil && result.RowsAffected == 0 {
+		id, _ := modelSchema.PrioritizedPrimaryField.ValueOf(ctx, value)
+		result.Error = &VersionConflictError{Table: modelSchema.Table, ID: fmt.Sprint(id), Version: version}
+	}
+	if result.Error != nil {
+		_ = field.Set(ctx, value, version)
+		return result.Error
+	}
+	return nil
+}
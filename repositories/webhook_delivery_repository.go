@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+)
+
+type WebhookDeliveryRepository interface {
+	CommonRepository[models.WebhookDelivery]
+
+	Enqueue(ctx context.Context, deliveries []models.WebhookDelivery) error
+	FindDueForUpdate(ctx context.Context, now time.Time, limit int) ([]models.WebhookDelivery, error)
+	Lease(ctx context.Context, ids []string, until time.Time) error
+	RecordAttempt(ctx context.Context, delivery *models.WebhookDelivery) error
+}
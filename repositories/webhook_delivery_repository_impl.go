@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WebhookDeliveryRepositoryImpl writes the deliveries directly, like the outbox they are a log of
+// what was sent and aren't audited
+type WebhookDeliveryRepositoryImpl struct {
+	DB *gorm.DB
+	CommonRepository[models.WebhookDelivery]
+}
+
+func NewWebhookDeliveryRepository(db *gorm.DB) *WebhookDeliveryRepositoryImpl {
+	return &WebhookDeliveryRepositoryImpl{
+		DB:               db,
+		CommonRepository: NewCommonRepository[models.WebhookDelivery](db),
+	}
+}
+
+// Enqueue skips the deliveries already enqueued for the same subscription and event, so an event
+// relayed twice is only delivered once
+func (r *WebhookDeliveryRepositoryImpl) Enqueue(ctx context.Context, deliveries []models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	return conn(ctx, r.DB).Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "subscription_id"}, {Name: "event_id"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "redelivery_of IS NULL"}}},
+		DoNothing:   true,
+	}).Create(&deliveries).Error
+}
+
+// FindDueForUpdate locks the pending deliveries due for an attempt, along with their subscription
+func (r *WebhookDeliveryRepositoryImpl) FindDueForUpdate(ctx context.Context, now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	tx, err := lockingConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var deliveries []models.WebhookDelivery
+	err = tx.
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Preload("Subscription").
+		Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryStatusPending, now).
+		Order("next_attempt_at asc").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// Lease postpones the next attempt of the deliveries, so the other dispatchers skip them while they are sent
+func (r *WebhookDeliveryRepositoryImpl) Lease(ctx context.Context, ids []string, until time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return conn(ctx, r.DB).Model(&models.WebhookDelivery{}).Where("id IN ?", ids).UpdateColumns(map[string]interface{}{
+		"next_attempt_at": until,
+		"updated_at":      time.Now(),
+	}).Error
+}
+
+// RecordAttempt stores the outcome of the last attempt of the delivery
+func (r *WebhookDeliveryRepositoryImpl) RecordAttempt(ctx context.Context, delivery *models.WebhookDelivery) error {
+	return conn(ctx, r.DB).Model(&models.WebhookDelivery{}).Where("id = ?", delivery.ID).UpdateColumns(map[string]interface{}{
+		"status":               delivery.Status,
+		"attempts":             delivery.Attempts,
+		"next_attempt_at":      delivery.NextAttemptAt,
+		"last_response_status": delivery.LastResponseStatus,
+		"last_response_body":   delivery.LastResponseBody,
+		"last_error":           delivery.LastError,
+		"delivered_at":         delivery.DeliveredAt,
+		"updated_at":           time.Now(),
+	}).Error
+}
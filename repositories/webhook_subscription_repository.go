@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+)
+
+type WebhookSubscriptionRepository interface {
+	CommonRepository[models.WebhookSubscription]
+
+	FindActiveByEventType(ctx context.Context, eventType models.EventType) ([]models.WebhookSubscription, error)
+	FindPartnerIDsByBorrowerID(ctx context.Context, borrowerID string) ([]string, error)
+	GrantBorrower(ctx context.Context, grant *models.PartnerBorrower) error
+	RevokeBorrower(ctx context.Context, partnerID string, borrowerID string) error
+}
@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WebhookSubscriptionRepositoryImpl struct {
+	DB *gorm.DB
+	CommonRepository[models.WebhookSubscription]
+}
+
+func NewWebhookSubscriptionRepository(db *gorm.DB) *WebhookSubscriptionRepositoryImpl {
+	return &WebhookSubscriptionRepositoryImpl{
+		DB:               db,
+		CommonRepository: NewCommonRepository[models.WebhookSubscription](db),
+	}
+}
+
+func (r *WebhookSubscriptionRepositoryImpl) FindActiveByEventType(ctx context.Context, eventType models.EventType) ([]models.WebhookSubscription, error) {
+	var subscriptions []models.WebhookSubscription
+	err := conn(ctx, r.DB).Where("is_active AND ? = ANY(event_types)", string(eventType)).Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+// FindPartnerIDsByBorrowerID returns the partners granted the events of the borrower
+func (r *WebhookSubscriptionRepositoryImpl) FindPartnerIDsByBorrowerID(ctx context.Context, borrowerID string) ([]string, error) {
+	var partnerIDs []string
+	err := conn(ctx, r.DB).Model(&models.PartnerBorrower{}).Where("borrower_id = ?", borrowerID).Pluck("partner_id", &partnerIDs).Error
+	return partnerIDs, err
+}
+
+// GrantBorrower grants the borrower to the partner, granting it again changes nothing. The grant is
+// audited like the changes going through CommonRepository.
+func (r *WebhookSubscriptionRepositoryImpl) GrantBorrower(ctx context.Context, grant *models.PartnerBorrower) error {
+	return withAuditTransaction(ctx, r.DB, func(db *gorm.DB) error {
+		result := db.WithContext(ctx).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "partner_id"}, {Name: "borrower_id"}},
+			DoNothing: true,
+		}).Create(grant)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return recordAudit(ctx, db, models.AuditActionCreate, nil, grant)
+	})
+}
+
+// RevokeBorrower removes the grant of the borrower to the partner, gorm.ErrRecordNotFound when there is none
+func (r *WebhookSubscriptionRepositoryImpl) RevokeBorrower(ctx context.Context, partnerID string, borrowerID string) error {
+	return withAuditTransaction(ctx, r.DB, func(db *gorm.DB) error {
+		var grant models.PartnerBorrower
+		if err := db.WithContext(ctx).Where("partner_id = ? AND borrower_id = ?", partnerID, borrowerID).Take(&grant).Error; err != nil {
+			return err
+		}
+		if err := db.WithContext(ctx).Delete(&grant).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, db, models.AuditActionDelete, &grant, nil)
+	})
+}
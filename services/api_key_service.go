@@ -0,0 +1,15 @@
+package services
+
+import (
+	"context"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+)
+
+type APIKeyService interface {
+	ListAPIKeys(ctx context.Context) ([]models.APIKey, error)
+	CreateAPIKey(ctx context.Context, request models.APIKeyRequest) (*models.APIKeySecretResponse, error)
+	RotateAPIKey(ctx context.Context, id string) (*models.APIKeySecretResponse, error)
+	RevokeAPIKey(ctx context.Context, id string) (*models.APIKey, error)
+	AuthenticateAPIKey(ctx context.Context, key string, clientIP string) (models.Principal, error)
+}
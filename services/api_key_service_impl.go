@@ -0,0 +1,218 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/netip"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/satryarangga/amartha-loan-engine/helpers"
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/satryarangga/amartha-loan-engine/repositories"
+	"gorm.io/gorm"
+)
+
+// last_used_at is only refreshed once per interval, so a busy client doesn't write on every request
+const apiKeyLastUsedInterval = time.Minute
+
+var ErrInvalidAPIKey = NewUnauthorizedError("invalid_api_key", "invalid API key")
+
+type APIKeyServiceImpl struct {
+	apiKeyRepo repositories.APIKeyRepository
+}
+
+func NewAPIKeyService(apiKeyRepo repositories.APIKeyRepository) *APIKeyServiceImpl {
+	return &APIKeyServiceImpl{
+		apiKeyRepo: apiKeyRepo,
+	}
+}
+
+func (s *APIKeyServiceImpl) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	return s.apiKeyRepo.FindAll(ctx, models.FindAllParam{
+		SortBy: models.SortBy{FieldName: "created_at", Direction: models.SortDirectDescending},
+	})
+}
+
+func (s *APIKeyServiceImpl) CreateAPIKey(ctx context.Context, request models.APIKeyRequest) (*models.APIKeySecretResponse, error) {
+	permissions := make(pq.StringArray, 0, len(request.Permissions))
+	for _, permission := range request.Permissions {
+		if !isGrantableAPIKeyPermission(permission) {
+			return nil, NewValidationError("invalid_permission", "unable to grant permission %q to an API key", permission)
+		}
+		permissions = append(permissions, string(permission))
+	}
+
+	allowedCIDRs := make(pq.StringArray, 0, len(request.AllowedCIDRs))
+	for _, cidr := range request.AllowedCIDRs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, NewValidationError("invalid_ip_range", "invalid IP range %q", cidr)
+		}
+		allowedCIDRs = append(allowedCIDRs, prefix.Masked().String())
+	}
+
+	key, prefix, err := helpers.GenerateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey := models.APIKey{
+		Name:         request.Name,
+		KeyPrefix:    prefix,
+		KeyHash:      helpers.HashAPIKey(key),
+		Permissions:  permissions,
+		AllowedCIDRs: allowedCIDRs,
+	}
+	if _, err := s.apiKeyRepo.Insert(ctx, &apiKey); err != nil {
+		return nil, err
+	}
+
+	return &models.APIKeySecretResponse{
+		APIKey: apiKey,
+		Key:    key,
+	}, nil
+}
+
+// RotateAPIKey replaces the key while keeping its permissions, the previous key stops working right away
+func (s *APIKeyServiceImpl) RotateAPIKey(ctx context.Context, id string) (*models.APIKeySecretResponse, error) {
+	key, prefix, err := helpers.GenerateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	// the key stays locked until it is saved, so a revoke made meanwhile is seen here instead of
+	// being overwritten with a stale read
+	var apiKey *models.APIKey
+	err = s.apiKeyRepo.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		apiKey, err = s.apiKeyRepo.FindByIDForUpdate(ctx, id, []string{})
+		if err != nil {
+			return err
+		}
+
+		if apiKey.RevokedAt != nil {
+			return NewStateTransitionError("api_key_revoked", "API key is revoked")
+		}
+
+		rotatedAt := time.Now()
+		apiKey.KeyPrefix = prefix
+		apiKey.KeyHash = helpers.HashAPIKey(key)
+		apiKey.RotatedAt = &rotatedAt
+		return s.apiKeyRepo.Update(ctx, apiKey)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.APIKeySecretResponse{
+		APIKey: *apiKey,
+		Key:    key,
+	}, nil
+}
+
+func (s *APIKeyServiceImpl) RevokeAPIKey(ctx context.Context, id string) (*models.APIKey, error) {
+	var apiKey *models.APIKey
+	err := s.apiKeyRepo.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		apiKey, err = s.apiKeyRepo.FindByIDForUpdate(ctx, id, []string{})
+		if err != nil {
+			return err
+		}
+
+		if apiKey.RevokedAt != nil {
+			return NewStateTransitionError("api_key_revoked", "API key is already revoked")
+		}
+
+		revokedAt := time.Now()
+		apiKey.RevokedAt = &revokedAt
+		return s.apiKeyRepo.Update(ctx, apiKey)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return apiKey, nil
+}
+
+func (s *APIKeyServiceImpl) AuthenticateAPIKey(ctx context.Context, key string, clientIP string) (models.Principal, error) {
+	prefix, ok := helpers.ParseAPIKeyPrefix(key)
+	if !ok {
+		return models.Principal{}, ErrInvalidAPIKey
+	}
+
+	apiKey, err := s.apiKeyRepo.FindOneByPrefix(ctx, prefix)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.Principal{}, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return models.Principal{}, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(helpers.HashAPIKey(key)), []byte(apiKey.KeyHash)) != 1 {
+		return models.Principal{}, ErrInvalidAPIKey
+	}
+
+	if apiKey.RevokedAt != nil {
+		return models.Principal{}, fmt.Errorf("%w: key is revoked", ErrInvalidAPIKey)
+	}
+
+	if !isAllowedAPIKeyClientIP(apiKey.AllowedCIDRs, clientIP) {
+		return models.Principal{}, fmt.Errorf("%w: key is not allowed from %s", ErrInvalidAPIKey, clientIP)
+	}
+
+	now := time.Now()
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= apiKeyLastUsedInterval {
+		if err := s.apiKeyRepo.UpdateLastUsedAt(ctx, apiKey.ID, now); err != nil {
+			return models.Principal{}, err
+		}
+	}
+
+	permissions := make([]models.Permission, 0, len(apiKey.Permissions))
+	for _, permission := range apiKey.Permissions {
+		permissions = append(permissions, models.Permission(permission))
+	}
+
+	return models.Principal{
+		Subject:     "api_key:" + apiKey.ID,
+		Role:        models.RoleService,
+		Permissions: permissions,
+	}, nil
+}
+
+// API keys can do anything an admin or the payment gateway can, except managing API keys
+func isGrantableAPIKeyPermission(permission models.Permission) bool {
+	if permission == models.PermissionAPIKeyManage {
+		return false
+	}
+	for _, role := range []models.Role{models.RoleAdmin, models.RolePaymentGateway} {
+		for _, granted := range models.RolePermissions[role] {
+			if permission == granted {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isAllowedAPIKeyClientIP(allowedCIDRs []string, clientIP string) bool {
+	if len(allowedCIDRs) == 0 {
+		return true
+	}
+
+	address, err := netip.ParseAddr(clientIP)
+	if err != nil {
+		return false
+	}
+	address = address.Unmap()
+
+	for _, cidr := range allowedCIDRs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err == nil && prefix.Contains(address) {
+			return true
+		}
+	}
+	return false
+}
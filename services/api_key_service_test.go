@@ -0,0 +1,236 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/satryarangga/amartha-loan-engine/helpers"
+	"github.com/satryarangga/amartha-loan-engine/mock"
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/satryarangga/amartha-loan-engine/repositories"
+	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestAPIKeyServiceImpl_CreateAPIKey_Success(t *testing.T) {
+	// Arrange
+	mockAPIKeyRepo := mock.NewAPIKeyRepository(t)
+	service := NewAPIKeyService(mockAPIKeyRepo)
+
+	ctx := context.Background()
+	request := models.APIKeyRequest{
+		Name:         "mobile-backend",
+		Permissions:  []models.Permission{models.PermissionLoanRead, models.PermissionPaymentLink},
+		AllowedCIDRs: []string{"10.1.2.3/8"},
+	}
+
+	mockAPIKeyRepo.On("Insert", ctx, testifymock.AnythingOfType("*models.APIKey")).Return("key-id", nil)
+
+	// Act
+	result, err := service.CreateAPIKey(ctx, request)
+
+	// Assert
+	assert.NoError(t, err)
+	prefix, ok := helpers.ParseAPIKeyPrefix(result.Key)
+	assert.True(t, ok)
+	assert.Equal(t, prefix, result.APIKey.KeyPrefix)
+	assert.Equal(t, helpers.HashAPIKey(result.Key), result.APIKey.KeyHash)
+	assert.Equal(t, pq.StringArray{"loans:read", "payments:link"}, result.APIKey.Permissions)
+	assert.Equal(t, pq.StringArray{"10.0.0.0/8"}, result.APIKey.AllowedCIDRs)
+}
+
+func TestAPIKeyServiceImpl_CreateAPIKey_InvalidRequest(t *testing.T) {
+	// Arrange
+	mockAPIKeyRepo := mock.NewAPIKeyRepository(t)
+	service := NewAPIKeyService(mockAPIKeyRepo)
+
+	ctx := context.Background()
+
+	// Act
+	_, manageErr := service.CreateAPIKey(ctx, models.APIKeyRequest{Name: "partner", Permissions: []models.Permission{models.PermissionAPIKeyManage}})
+	_, unknownErr := service.CreateAPIKey(ctx, models.APIKeyRequest{Name: "partner", Permissions: []models.Permission{"loans:delete"}})
+	_, cidrErr := service.CreateAPIKey(ctx, models.APIKeyRequest{Name: "partner", Permissions: []models.Permission{models.PermissionLoanRead}, AllowedCIDRs: []string{"10.0.0.1"}})
+
+	// Assert
+	assert.EqualError(t, manageErr, `unable to grant permission "api_keys:manage" to an API key`)
+	assert.EqualError(t, unknownErr, `unable to grant permission "loans:delete" to an API key`)
+	assert.EqualError(t, cidrErr, `invalid IP range "10.0.0.1"`)
+}
+
+func TestAPIKeyServiceImpl_CreateAPIKey_PaymentGatewayPermission(t *testing.T) {
+	// Arrange
+	mockAPIKeyRepo := mock.NewAPIKeyRepository(t)
+	service := NewAPIKeyService(mockAPIKeyRepo)
+
+	ctx := context.Background()
+	request := models.APIKeyRequest{Name: "payment-gateway", Permissions: []models.Permission{models.PermissionPaymentWebhook}}
+
+	mockAPIKeyRepo.On("Insert", ctx, testifymock.AnythingOfType("*models.APIKey")).Return("key-id", nil)
+
+	// Act
+	result, err := service.CreateAPIKey(ctx, request)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, pq.StringArray{"payments:webhook"}, result.APIKey.Permissions)
+}
+
+func TestAPIKeyServiceImpl_RotateAPIKey_Success(t *testing.T) {
+	// Arrange
+	mockAPIKeyRepo := mock.NewAPIKeyRepository(t)
+	service := NewAPIKeyService(mockAPIKeyRepo)
+
+	ctx := context.Background()
+	apiKey := &models.APIKey{ID: "key-id", KeyPrefix: "oldprefix", KeyHash: "old-hash"}
+
+	mockAPIKeyRepo.On("WithTransaction", ctx, testifymock.AnythingOfType("repositories.TransactionFunc")).
+		Return(func(ctx context.Context, fn repositories.TransactionFunc) error {
+			return fn(ctx)
+		})
+	mockAPIKeyRepo.On("FindByIDForUpdate", ctx, "key-id", []string{}).Return(apiKey, nil)
+	mockAPIKeyRepo.On("Update", ctx, apiKey).Return(nil)
+
+	// Act
+	result, err := service.RotateAPIKey(ctx, "key-id")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotEqual(t, "oldprefix", result.APIKey.KeyPrefix)
+	assert.Equal(t, helpers.HashAPIKey(result.Key), result.APIKey.KeyHash)
+	assert.NotNil(t, result.APIKey.RotatedAt)
+}
+
+func TestAPIKeyServiceImpl_RotateAPIKey_Revoked(t *testing.T) {
+	// Arrange
+	mockAPIKeyRepo := mock.NewAPIKeyRepository(t)
+	service := NewAPIKeyService(mockAPIKeyRepo)
+
+	ctx := context.Background()
+	revokedAt := time.Now()
+	mockAPIKeyRepo.On("WithTransaction", ctx, testifymock.AnythingOfType("repositories.TransactionFunc")).
+		Return(func(ctx context.Context, fn repositories.TransactionFunc) error {
+			return fn(ctx)
+		})
+	// the key is read locked on the primary, a revoke made just before isn't missed on a lagging replica
+	mockAPIKeyRepo.On("FindByIDForUpdate", ctx, "key-id", []string{}).Return(&models.APIKey{ID: "key-id", RevokedAt: &revokedAt}, nil)
+
+	// Act
+	result, err := service.RotateAPIKey(ctx, "key-id")
+
+	// Assert
+	assert.Nil(t, result)
+	assert.EqualError(t, err, "API key is revoked")
+	mockAPIKeyRepo.AssertNotCalled(t, "Update", testifymock.Anything, testifymock.Anything)
+}
+
+func TestAPIKeyServiceImpl_RevokeAPIKey_AlreadyRevoked(t *testing.T) {
+	// Arrange
+	mockAPIKeyRepo := mock.NewAPIKeyRepository(t)
+	service := NewAPIKeyService(mockAPIKeyRepo)
+
+	ctx := context.Background()
+	revokedAt := time.Now()
+	mockAPIKeyRepo.On("WithTransaction", ctx, testifymock.AnythingOfType("repositories.TransactionFunc")).
+		Return(func(ctx context.Context, fn repositories.TransactionFunc) error {
+			return fn(ctx)
+		})
+	mockAPIKeyRepo.On("FindByIDForUpdate", ctx, "key-id", []string{}).Return(&models.APIKey{ID: "key-id", RevokedAt: &revokedAt}, nil)
+
+	// Act
+	result, err := service.RevokeAPIKey(ctx, "key-id")
+
+	// Assert
+	assert.Nil(t, result)
+	assert.EqualError(t, err, "API key is already revoked")
+}
+
+func TestAPIKeyServiceImpl_AuthenticateAPIKey_Success(t *testing.T) {
+	// Arrange
+	mockAPIKeyRepo := mock.NewAPIKeyRepository(t)
+	service := NewAPIKeyService(mockAPIKeyRepo)
+
+	ctx := context.Background()
+	key, prefix, err := helpers.GenerateAPIKey()
+	assert.NoError(t, err)
+	apiKey := &models.APIKey{
+		ID:           "key-id",
+		KeyPrefix:    prefix,
+		KeyHash:      helpers.HashAPIKey(key),
+		Permissions:  pq.StringArray{"loans:read"},
+		AllowedCIDRs: pq.StringArray{"10.0.0.0/8"},
+	}
+
+	mockAPIKeyRepo.On("FindOneByPrefix", ctx, prefix).Return(apiKey, nil)
+	mockAPIKeyRepo.On("UpdateLastUsedAt", ctx, "key-id", testifymock.AnythingOfType("time.Time")).Return(nil)
+
+	// Act
+	principal, err := service.AuthenticateAPIKey(ctx, key, "10.20.30.40")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "api_key:key-id", principal.Subject)
+	assert.Equal(t, models.RoleService, principal.Role)
+	assert.True(t, principal.HasPermission(models.PermissionLoanRead))
+	assert.False(t, principal.IsBorrowerScoped())
+}
+
+func TestAPIKeyServiceImpl_AuthenticateAPIKey_RecentlyUsed(t *testing.T) {
+	// Arrange
+	mockAPIKeyRepo := mock.NewAPIKeyRepository(t)
+	service := NewAPIKeyService(mockAPIKeyRepo)
+
+	ctx := context.Background()
+	key, prefix, err := helpers.GenerateAPIKey()
+	assert.NoError(t, err)
+	lastUsedAt := time.Now().Add(-10 * time.Second)
+	mockAPIKeyRepo.On("FindOneByPrefix", ctx, prefix).Return(&models.APIKey{ID: "key-id", KeyHash: helpers.HashAPIKey(key), LastUsedAt: &lastUsedAt}, nil)
+
+	// Act
+	_, err = service.AuthenticateAPIKey(ctx, key, "10.20.30.40")
+
+	// Assert
+	assert.NoError(t, err)
+	mockAPIKeyRepo.AssertNotCalled(t, "UpdateLastUsedAt", testifymock.Anything, testifymock.Anything, testifymock.Anything)
+}
+
+func TestAPIKeyServiceImpl_AuthenticateAPIKey_Rejected(t *testing.T) {
+	key, prefix, err := helpers.GenerateAPIKey()
+	assert.NoError(t, err)
+	revokedAt := time.Now()
+
+	testCases := []struct {
+		name     string
+		key      string
+		clientIP string
+		apiKey   *models.APIKey
+		findErr  error
+	}{
+		{"malformed key", "not-an-api-key", "10.0.0.1", nil, nil},
+		{"unknown prefix", key, "10.0.0.1", nil, gorm.ErrRecordNotFound},
+		{"wrong secret", key, "10.0.0.1", &models.APIKey{ID: "key-id", KeyHash: helpers.HashAPIKey("ak_" + prefix + "_other")}, nil},
+		{"revoked", key, "10.0.0.1", &models.APIKey{ID: "key-id", KeyHash: helpers.HashAPIKey(key), RevokedAt: &revokedAt}, nil},
+		{"outside allowed IP ranges", key, "192.168.0.1", &models.APIKey{ID: "key-id", KeyHash: helpers.HashAPIKey(key), AllowedCIDRs: pq.StringArray{"10.0.0.0/8"}}, nil},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Arrange
+			mockAPIKeyRepo := mock.NewAPIKeyRepository(t)
+			service := NewAPIKeyService(mockAPIKeyRepo)
+			ctx := context.Background()
+			if testCase.apiKey != nil || testCase.findErr != nil {
+				mockAPIKeyRepo.On("FindOneByPrefix", ctx, prefix).Return(testCase.apiKey, testCase.findErr)
+			}
+
+			// Act
+			_, err := service.AuthenticateAPIKey(ctx, testCase.key, testCase.clientIP)
+
+			// Assert
+			assert.True(t, errors.Is(err, ErrInvalidAPIKey))
+		})
+	}
+}
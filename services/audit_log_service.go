@@ -0,0 +1,11 @@
+package services
+
+import (
+	"context"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+)
+
+type AuditLogService interface {
+	ListAuditLogs(ctx context.Context, request models.AuditLogListRequest) (*models.AuditLogListResponse, error)
+}
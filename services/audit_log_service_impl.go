@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/satryarangga/amartha-loan-engine/helpers"
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/satryarangga/amartha-loan-engine/repositories"
+)
+
+type AuditLogServiceImpl struct {
+	auditLogRepo repositories.AuditLogRepository
+}
+
+func NewAuditLogService(auditLogRepo repositories.AuditLogRepository) *AuditLogServiceImpl {
+	return &AuditLogServiceImpl{
+		auditLogRepo: auditLogRepo,
+	}
+}
+
+func (s *AuditLogServiceImpl) ListAuditLogs(ctx context.Context, request models.AuditLogListRequest) (*models.AuditLogListResponse, error) {
+	page, limit := helpers.NormalizePage(request.Page, request.Limit)
+
+	filters := map[string]interface{}{}
+	if request.EntityType != "" {
+		filters["entity_type"] = request.EntityType
+	}
+	if request.EntityID != "" {
+		filters["entity_id"] = request.EntityID
+	}
+	if request.Actor != "" {
+		filters["actor"] = request.Actor
+	}
+	if request.Action != "" {
+		filters["action"] = request.Action
+	}
+	if request.RequestID != "" {
+		filters["request_id"] = request.RequestID
+	}
+
+	var from, to interface{}
+	if request.From != "" {
+		fromDate, err := time.Parse(time.DateOnly, request.From)
+		if err != nil {
+			return nil, NewValidationError("invalid_date", "from must use YYYY-MM-DD format")
+		}
+		from = fromDate
+	}
+	if request.To != "" {
+		toDate, err := time.Parse(time.DateOnly, request.To)
+		if err != nil {
+			return nil, NewValidationError("invalid_date", "to must use YYYY-MM-DD format")
+		}
+		// the date is inclusive, so the period ends at the start of the next day
+		to = toDate.AddDate(0, 0, 1)
+	}
+	var specs []models.Spec
+	if from != nil || to != nil {
+		specs = append(specs, models.Range("created_at", from, to))
+	}
+
+	param := models.FindAllParam{
+		Limit:   limit,
+		Offset:  page,
+		Filters: filters,
+		Specs:   specs,
+		SortBy:  models.SortBy{FieldName: "created_at", Direction: models.SortDirectDescending},
+	}
+
+	total, err := s.auditLogRepo.Count(ctx, param)
+	if err != nil {
+		return nil, err
+	}
+
+	auditLogs, err := s.auditLogRepo.FindAll(ctx, param)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AuditLogListResponse{
+		Data:       auditLogs,
+		Pagination: helpers.NewPagination(page, limit, total),
+	}, nil
+}
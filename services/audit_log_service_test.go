@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/satryarangga/amartha-loan-engine/mock"
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditLogServiceImpl_ListAuditLogs_Success(t *testing.T) {
+	// Arrange
+	mockAuditLogRepo := mock.NewAuditLogRepository(t)
+	service := NewAuditLogService(mockAuditLogRepo)
+
+	ctx := context.Background()
+	request := models.AuditLogListRequest{
+		EntityType: "loans",
+		EntityID:   "loan-id",
+		Action:     models.AuditActionUpdate,
+		From:       "2025-01-01",
+		To:         "2025-01-31",
+		Page:       1,
+		Limit:      20,
+	}
+	expectedParam := models.FindAllParam{
+		Limit:  20,
+		Offset: 1,
+		Filters: map[string]interface{}{
+			"entity_type": "loans",
+			"entity_id":   "loan-id",
+			"action":      models.AuditActionUpdate,
+		},
+		Specs: []models.Spec{
+			models.Range("created_at", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)),
+		},
+		SortBy: models.SortBy{FieldName: "created_at", Direction: models.SortDirectDescending},
+	}
+	auditLogs := []models.AuditLog{
+		{ID: "audit-1", Actor: "user-1", Action: models.AuditActionUpdate, EntityType: "loans", EntityID: "loan-id"},
+	}
+
+	mockAuditLogRepo.On("Count", ctx, expectedParam).Return(int64(1), nil)
+	mockAuditLogRepo.On("FindAll", ctx, expectedParam).Return(auditLogs, nil)
+
+	// Act
+	result, err := service.ListAuditLogs(ctx, request)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, auditLogs, result.Data)
+	assert.Equal(t, models.Pagination{Page: 1, Limit: 20, TotalItems: 1, TotalPages: 1}, result.Pagination)
+}
+
+func TestAuditLogServiceImpl_ListAuditLogs_InvalidDate(t *testing.T) {
+	// Arrange
+	mockAuditLogRepo := mock.NewAuditLogRepository(t)
+	service := NewAuditLogService(mockAuditLogRepo)
+
+	// Act
+	result, err := service.ListAuditLogs(context.Background(), models.AuditLogListRequest{From: "yesterday"})
+
+	// Assert
+	assert.Nil(t, result)
+	assert.EqualError(t, err, "from must use YYYY-MM-DD format")
+}
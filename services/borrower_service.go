@@ -9,4 +9,7 @@ import (
 type BorrowerService interface {
 	GetBorrowerByID(ctx context.Context, id string) (*models.Borrower, error)
 	CreateBorrower(ctx context.Context, borrower *models.BorrowerRequest) (*models.Borrower, error)
+	ListBorrowers(ctx context.Context, request models.BorrowerListRequest) (*models.BorrowerListResponse, error)
+	UpdateBorrower(ctx context.Context, id string, request models.BorrowerUpdateRequest) (*models.Borrower, error)
+	DeleteBorrower(ctx context.Context, id string) error
 }
@@ -4,50 +4,241 @@ import (
 	"context"
 	"errors"
 
+	"github.com/satryarangga/amartha-loan-engine/cache"
+	"github.com/satryarangga/amartha-loan-engine/events"
 	"github.com/satryarangga/amartha-loan-engine/helpers"
 	"github.com/satryarangga/amartha-loan-engine/models"
 	"github.com/satryarangga/amartha-loan-engine/repositories"
+	"gorm.io/gorm"
 )
 
 type BorrowerServiceImpl struct {
 	borrowerRepo repositories.BorrowerRepository
 	loanRepo     repositories.LoanRepository
+	outboxRepo   repositories.OutboxRepository
+	responses    *cache.Responses
 }
 
 func NewBorrowerService(
 	borrowerRepo repositories.BorrowerRepository,
 	loanRepo repositories.LoanRepository,
+	outboxRepo repositories.OutboxRepository,
+	responses *cache.Responses,
 ) *BorrowerServiceImpl {
 	return &BorrowerServiceImpl{
 		borrowerRepo: borrowerRepo,
 		loanRepo:     loanRepo,
+		outboxRepo:   outboxRepo,
+		responses:    responses,
 	}
 }
 
 func (s *BorrowerServiceImpl) GetBorrowerByID(ctx context.Context, id string) (*models.BorrowerResponse, error) {
 	if id == "" {
-		return nil, errors.New("borrower ID is required")
+		return nil, NewValidationError("borrower_id_required", "borrower ID is required")
 	}
-	borrower, err := s.borrowerRepo.FindByID(ctx, id, []string{})
+	if !helpers.CanAccessBorrower(ctx, id) {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	// the mobile app polls the borrower, the response is cached until a change of the borrower or
+	// of its loan evicts it
+	return cache.GetOrLoad(ctx, s.responses, cache.BorrowerResponses, cache.BorrowerKey(id), func() (*models.BorrowerResponse, error) {
+		borrower, err := s.borrowerRepo.FindByID(ctx, id, []string{})
+		if err != nil {
+			return nil, err
+		}
+
+		// a borrower without an active loan simply has no schedules to be delinquent on
+		loan, err := s.loanRepo.FindOneByBorrowerID(ctx, id)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+
+		return &models.BorrowerResponse{
+			ID:           borrower.ID,
+			FirstName:    borrower.FirstName,
+			LastName:     borrower.LastName,
+			PhoneNumber:  borrower.PhoneNumber,
+			KYCStatus:    borrower.KYCStatus,
+			IsDelinquent: helpers.IsBorrowerDelinquent(loan.LoanSchedules),
+		}, nil
+	})
+}
+
+func (s *BorrowerServiceImpl) CreateBorrower(ctx context.Context, borrower *models.Borrower) error {
+	// every new borrower starts without KYC, it can only be changed through the KYC flow
+	borrower.KYCStatus = models.KYCStatusUnverified
+
+	return s.borrowerRepo.WithTransaction(ctx, func(ctx context.Context) error {
+		borrowerID, err := s.borrowerRepo.Insert(ctx, borrower)
+		if err != nil {
+			return err
+		}
+
+		event, err := events.NewOutboxEvent(models.EventTypeBorrowerCreated, "borrower", borrowerID, models.BorrowerCreatedEvent{
+			BorrowerID:  borrowerID,
+			FirstName:   borrower.FirstName,
+			LastName:    borrower.LastName,
+			PhoneNumber: borrower.PhoneNumber,
+		})
+		if err != nil {
+			return err
+		}
+		return s.outboxRepo.Append(ctx, []models.OutboxEvent{event})
+	})
+}
+
+func (s *BorrowerServiceImpl) ListBorrowers(ctx context.Context, request models.BorrowerListRequest) (*models.BorrowerListResponse, error) {
+	page, limit := helpers.NormalizePage(request.Page, request.Limit)
+
+	sortBy := models.SortBy{FieldName: "created_at", Direction: models.SortDirectDescending}
+	if request.SortBy != "" {
+		sortBy = models.SortBy{FieldName: request.SortBy, Direction: models.SortDirectAscending}
+	}
+	if request.SortDirection != "" {
+		sortBy.Direction = request.SortDirection
+	}
+	if err := repositories.ValidateSort[models.Borrower](sortBy); err != nil {
+		return nil, err
+	}
+
+	filters := map[string]interface{}{}
+	if request.KYCStatus != "" {
+		filters["kyc_status"] = request.KYCStatus
+	}
+
+	param := models.FindAllParam{
+		Limit:          limit,
+		Offset:         page,
+		SearchKeyword:  request.Search,
+		FieldsToSearch: []string{"first_name", "last_name", "phone_number"},
+		SearchMode:     request.SearchMode,
+		Filters:        filters,
+		SortBy:         sortBy,
+	}
+
+	total, err := s.borrowerRepo.Count(ctx, param)
 	if err != nil {
 		return nil, err
 	}
 
-	loan, err := s.loanRepo.FindOneByBorrowerID(ctx, id)
+	borrowers, err := s.borrowerRepo.FindAll(ctx, param)
 	if err != nil {
 		return nil, err
 	}
 
-	return &models.BorrowerResponse{
-		ID:           borrower.ID,
-		FirstName:    borrower.FirstName,
-		LastName:     borrower.LastName,
-		PhoneNumber:  borrower.PhoneNumber,
-		IsDelinquent: helpers.IsBorrowerDelinquent(loan.LoanSchedules),
+	// load the active loans of the whole page at once instead of one query per borrower
+	schedulesByBorrowerID := map[string][]models.LoanSchedule{}
+	if len(borrowers) > 0 {
+		borrowerIDs := make([]string, 0, len(borrowers))
+		for _, borrower := range borrowers {
+			borrowerIDs = append(borrowerIDs, borrower.ID)
+		}
+
+		loans, err := s.loanRepo.FindAll(ctx, models.FindAllParam{
+			Filters: map[string]interface{}{
+				"borrower_id": borrowerIDs,
+				"status":      models.LoanStatusActive,
+			},
+			PreloadTables: []string{"LoanSchedules"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, loan := range loans {
+			schedulesByBorrowerID[loan.BorrowerID] = loan.LoanSchedules
+		}
+	}
+
+	data := make([]models.BorrowerResponse, 0, len(borrowers))
+	for _, borrower := range borrowers {
+		data = append(data, models.BorrowerResponse{
+			ID:           borrower.ID,
+			FirstName:    borrower.FirstName,
+			LastName:     borrower.LastName,
+			PhoneNumber:  borrower.PhoneNumber,
+			KYCStatus:    borrower.KYCStatus,
+			IsDelinquent: helpers.IsBorrowerDelinquent(schedulesByBorrowerID[borrower.ID]),
+		})
+	}
+
+	return &models.BorrowerListResponse{
+		Data:       data,
+		Pagination: helpers.NewPagination(page, limit, total),
 	}, nil
 }
 
-func (s *BorrowerServiceImpl) CreateBorrower(ctx context.Context, borrower *models.Borrower) error {
-	_, err := s.borrowerRepo.Insert(ctx, nil, borrower)
-	return err
+func (s *BorrowerServiceImpl) UpdateBorrower(ctx context.Context, id string, request models.BorrowerUpdateRequest) (*models.Borrower, error) {
+	if id == "" {
+		return nil, NewValidationError("borrower_id_required", "borrower ID is required")
+	}
+
+	// the borrower stays locked until it is saved, so a KYC status changed meanwhile by the KYC flow
+	// isn't overwritten with the one read here
+	var borrower *models.Borrower
+	err := s.borrowerRepo.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		borrower, err = s.borrowerRepo.FindByIDForUpdate(ctx, id, []string{})
+		if err != nil {
+			return err
+		}
+
+		if request.FirstName != nil {
+			borrower.FirstName = *request.FirstName
+		}
+		if request.LastName != nil {
+			borrower.LastName = *request.LastName
+		}
+		if request.PhoneNumber != nil && *request.PhoneNumber != borrower.PhoneNumber {
+			// a phone number taken concurrently by another borrower is still caught by the unique index
+			existing, err := s.borrowerRepo.FindOneByPhoneNumber(ctx, *request.PhoneNumber)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			if err == nil && existing.ID != borrower.ID {
+				return NewConflictError("phone_number_registered", "phone number is already registered")
+			}
+			borrower.PhoneNumber = *request.PhoneNumber
+		}
+
+		return s.borrowerRepo.Update(ctx, borrower)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.responses.EvictBorrower(ctx, borrower.ID)
+
+	return borrower, nil
+}
+
+func (s *BorrowerServiceImpl) DeleteBorrower(ctx context.Context, id string) error {
+	if id == "" {
+		return NewValidationError("borrower_id_required", "borrower ID is required")
+	}
+
+	// the borrower stays locked until it is deleted, CreateLoan locks it too so no loan is created
+	// between the check and the delete
+	err := s.borrowerRepo.WithTransaction(ctx, func(ctx context.Context) error {
+		borrower, err := s.borrowerRepo.FindByIDForUpdate(ctx, id, []string{})
+		if err != nil {
+			return err
+		}
+
+		_, err = s.loanRepo.FindOneByBorrowerID(ctx, borrower.ID)
+		if err == nil {
+			return NewConflictError("borrower_has_active_loan", "borrower still has an active loan")
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		return s.borrowerRepo.Delete(ctx, borrower)
+	})
+	if err != nil {
+		return err
+	}
+	s.responses.EvictBorrower(ctx, id)
+	return nil
 }
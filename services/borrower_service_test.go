@@ -6,16 +6,32 @@ import (
 	"testing"
 	"time"
 
+	"github.com/satryarangga/amartha-loan-engine/cache"
+	"github.com/satryarangga/amartha-loan-engine/helpers"
 	"github.com/satryarangga/amartha-loan-engine/mock"
 	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/satryarangga/amartha-loan-engine/repositories"
 	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
 	"gorm.io/gorm"
 )
 
+// newTestResponses caches nothing, so every read reaches the mocked repositories
+func newTestResponses() *cache.Responses {
+	return cache.NewResponses(cache.NewNopCache(), time.Minute)
+}
+
+// newTestLRUResponses caches the responses for real, for the tests of the caching itself
+func newTestLRUResponses(t *testing.T) *cache.Responses {
+	lruCache, err := cache.NewLRUCache(100)
+	assert.NoError(t, err)
+	return cache.NewResponses(lruCache, time.Minute)
+}
+
 func TestNewBorrowerService(t *testing.T) {
 	mockRepo := mock.NewBorrowerRepository(t)
 	mockLoanRepo := mock.NewLoanRepository(t)
-	service := NewBorrowerService(mockRepo, mockLoanRepo)
+	service := NewBorrowerService(mockRepo, mockLoanRepo, mock.NewOutboxRepository(t), newTestResponses())
 
 	assert.NotNil(t, service)
 	assert.Equal(t, mockRepo, service.borrowerRepo)
@@ -26,7 +42,7 @@ func TestBorrowerServiceImpl_GetBorrowerByID_Success(t *testing.T) {
 	// Arrange
 	mockRepo := mock.NewBorrowerRepository(t)
 	mockLoanRepo := mock.NewLoanRepository(t)
-	service := NewBorrowerService(mockRepo, mockLoanRepo)
+	service := NewBorrowerService(mockRepo, mockLoanRepo, mock.NewOutboxRepository(t), newTestResponses())
 
 	ctx := context.Background()
 	borrowerID := "test-borrower-id"
@@ -83,7 +99,7 @@ func TestBorrowerServiceImpl_GetBorrowerByID_EmptyID(t *testing.T) {
 	// Arrange
 	mockRepo := mock.NewBorrowerRepository(t)
 	mockLoanRepo := mock.NewLoanRepository(t)
-	service := NewBorrowerService(mockRepo, mockLoanRepo)
+	service := NewBorrowerService(mockRepo, mockLoanRepo, mock.NewOutboxRepository(t), newTestResponses())
 
 	ctx := context.Background()
 
@@ -100,7 +116,7 @@ func TestBorrowerServiceImpl_GetBorrowerByID_RepositoryError(t *testing.T) {
 	// Arrange
 	mockRepo := mock.NewBorrowerRepository(t)
 	mockLoanRepo := mock.NewLoanRepository(t)
-	service := NewBorrowerService(mockRepo, mockLoanRepo)
+	service := NewBorrowerService(mockRepo, mockLoanRepo, mock.NewOutboxRepository(t), newTestResponses())
 
 	ctx := context.Background()
 	borrowerID := "test-borrower-id"
@@ -122,7 +138,7 @@ func TestBorrowerServiceImpl_GetBorrowerByID_LoanRepositoryError(t *testing.T) {
 	// Arrange
 	mockRepo := mock.NewBorrowerRepository(t)
 	mockLoanRepo := mock.NewLoanRepository(t)
-	service := NewBorrowerService(mockRepo, mockLoanRepo)
+	service := NewBorrowerService(mockRepo, mockLoanRepo, mock.NewOutboxRepository(t), newTestResponses())
 
 	ctx := context.Background()
 	borrowerID := "test-borrower-id"
@@ -154,7 +170,8 @@ func TestBorrowerServiceImpl_CreateBorrower_Success(t *testing.T) {
 	// Arrange
 	mockRepo := mock.NewBorrowerRepository(t)
 	mockLoanRepo := mock.NewLoanRepository(t)
-	service := NewBorrowerService(mockRepo, mockLoanRepo)
+	mockOutboxRepo := mock.NewOutboxRepository(t)
+	service := NewBorrowerService(mockRepo, mockLoanRepo, mockOutboxRepo, newTestResponses())
 
 	ctx := context.Background()
 	borrower := &models.Borrower{
@@ -163,14 +180,29 @@ func TestBorrowerServiceImpl_CreateBorrower_Success(t *testing.T) {
 		PhoneNumber: "081234567890",
 	}
 	expectedID := "new-borrower-id"
+	var appendedEvents []models.OutboxEvent
 
-	mockRepo.On("Insert", ctx, (*gorm.DB)(nil), borrower).Return(expectedID, nil)
+	mockRepo.On("WithTransaction", ctx, testifymock.AnythingOfType("repositories.TransactionFunc")).
+		Return(func(ctx context.Context, fn repositories.TransactionFunc) error {
+			return fn(ctx)
+		})
+	mockRepo.On("Insert", ctx, borrower).Return(expectedID, nil)
+	mockOutboxRepo.On("Append", ctx, testifymock.AnythingOfType("[]models.OutboxEvent")).
+		Run(func(args testifymock.Arguments) {
+			appendedEvents = args.Get(1).([]models.OutboxEvent)
+		}).
+		Return(nil)
 
 	// Act
 	err := service.CreateBorrower(ctx, borrower)
 
 	// Assert
 	assert.NoError(t, err)
+	assert.Equal(t, models.KYCStatusUnverified, borrower.KYCStatus)
+	assert.Len(t, appendedEvents, 1)
+	assert.Equal(t, models.EventTypeBorrowerCreated, appendedEvents[0].EventType)
+	assert.Equal(t, expectedID, appendedEvents[0].AggregateID)
+	assert.JSONEq(t, `{"borrower_id":"new-borrower-id","first_name":"John","last_name":"Doe","phone_number":"081234567890"}`, string(appendedEvents[0].Payload))
 	mockRepo.AssertExpectations(t)
 }
 
@@ -178,7 +210,7 @@ func TestBorrowerServiceImpl_CreateBorrower_RepositoryError(t *testing.T) {
 	// Arrange
 	mockRepo := mock.NewBorrowerRepository(t)
 	mockLoanRepo := mock.NewLoanRepository(t)
-	service := NewBorrowerService(mockRepo, mockLoanRepo)
+	service := NewBorrowerService(mockRepo, mockLoanRepo, mock.NewOutboxRepository(t), newTestResponses())
 
 	ctx := context.Background()
 	borrower := &models.Borrower{
@@ -188,7 +220,11 @@ func TestBorrowerServiceImpl_CreateBorrower_RepositoryError(t *testing.T) {
 	}
 	expectedError := errors.New("database error")
 
-	mockRepo.On("Insert", ctx, (*gorm.DB)(nil), borrower).Return("", expectedError)
+	mockRepo.On("WithTransaction", ctx, testifymock.AnythingOfType("repositories.TransactionFunc")).
+		Return(func(ctx context.Context, fn repositories.TransactionFunc) error {
+			return fn(ctx)
+		})
+	mockRepo.On("Insert", ctx, borrower).Return("", expectedError)
 
 	// Act
 	err := service.CreateBorrower(ctx, borrower)
@@ -198,3 +234,267 @@ func TestBorrowerServiceImpl_CreateBorrower_RepositoryError(t *testing.T) {
 	assert.Equal(t, expectedError, err)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestBorrowerServiceImpl_GetBorrowerByID_NoActiveLoan(t *testing.T) {
+	// Arrange
+	mockRepo := mock.NewBorrowerRepository(t)
+	mockLoanRepo := mock.NewLoanRepository(t)
+	service := NewBorrowerService(mockRepo, mockLoanRepo, mock.NewOutboxRepository(t), newTestResponses())
+
+	ctx := context.Background()
+	borrowerID := "test-borrower-id"
+	expectedBorrower := &models.Borrower{ID: borrowerID, FirstName: "John"}
+
+	mockRepo.On("FindByID", ctx, borrowerID, []string{}).Return(expectedBorrower, nil)
+	mockLoanRepo.On("FindOneByBorrowerID", ctx, borrowerID).Return(models.Loan{}, gorm.ErrRecordNotFound)
+
+	// Act
+	result, err := service.GetBorrowerByID(ctx, borrowerID)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, borrowerID, result.ID)
+	assert.False(t, result.IsDelinquent)
+}
+
+func TestBorrowerServiceImpl_ListBorrowers_Success(t *testing.T) {
+	// Arrange
+	mockRepo := mock.NewBorrowerRepository(t)
+	mockLoanRepo := mock.NewLoanRepository(t)
+	service := NewBorrowerService(mockRepo, mockLoanRepo, mock.NewOutboxRepository(t), newTestResponses())
+
+	ctx := context.Background()
+	request := models.BorrowerListRequest{
+		Search:        "john",
+		KYCStatus:     models.KYCStatusVerified,
+		SortBy:        "first_name",
+		SortDirection: models.SortDirectDescending,
+		Page:          2,
+		Limit:         2,
+	}
+	expectedParam := models.FindAllParam{
+		Limit:          2,
+		Offset:         2,
+		SearchKeyword:  "john",
+		FieldsToSearch: []string{"first_name", "last_name", "phone_number"},
+		Filters:        map[string]interface{}{"kyc_status": models.KYCStatusVerified},
+		SortBy:         models.SortBy{FieldName: "first_name", Direction: models.SortDirectDescending},
+	}
+	borrowers := []models.Borrower{
+		{ID: "borrower-1", FirstName: "John", KYCStatus: models.KYCStatusVerified},
+		{ID: "borrower-2", FirstName: "Johnny", KYCStatus: models.KYCStatusVerified},
+	}
+	loans := []models.Loan{
+		{
+			ID:         "loan-1",
+			BorrowerID: "borrower-1",
+			LoanSchedules: []models.LoanSchedule{
+				{Status: models.LoanScheduleStatusPending, DueDate: time.Now().AddDate(0, 0, -14)},
+				{Status: models.LoanScheduleStatusPending, DueDate: time.Now().AddDate(0, 0, -7)},
+			},
+		},
+	}
+
+	mockRepo.On("Count", ctx, expectedParam).Return(int64(5), nil)
+	mockRepo.On("FindAll", ctx, expectedParam).Return(borrowers, nil)
+	mockLoanRepo.On("FindAll", ctx, models.FindAllParam{
+		Filters: map[string]interface{}{
+			"borrower_id": []string{"borrower-1", "borrower-2"},
+			"status":      models.LoanStatusActive,
+		},
+		PreloadTables: []string{"LoanSchedules"},
+	}).Return(loans, nil)
+
+	// Act
+	result, err := service.ListBorrowers(ctx, request)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, result.Data, 2)
+	assert.True(t, result.Data[0].IsDelinquent)
+	assert.False(t, result.Data[1].IsDelinquent)
+	assert.Equal(t, models.Pagination{Page: 2, Limit: 2, TotalItems: 5, TotalPages: 3}, result.Pagination)
+}
+
+func TestBorrowerServiceImpl_ListBorrowers_InvalidSortField(t *testing.T) {
+	// Arrange
+	mockRepo := mock.NewBorrowerRepository(t)
+	mockLoanRepo := mock.NewLoanRepository(t)
+	service := NewBorrowerService(mockRepo, mockLoanRepo, mock.NewOutboxRepository(t), newTestResponses())
+
+	// Act
+	result, err := service.ListBorrowers(context.Background(), models.BorrowerListRequest{SortBy: "id; drop table borrowers"})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	mockRepo.AssertNotCalled(t, "FindAll")
+}
+
+func TestBorrowerServiceImpl_UpdateBorrower_Success(t *testing.T) {
+	// Arrange
+	mockRepo := mock.NewBorrowerRepository(t)
+	mockLoanRepo := mock.NewLoanRepository(t)
+	service := NewBorrowerService(mockRepo, mockLoanRepo, mock.NewOutboxRepository(t), newTestResponses())
+
+	ctx := context.Background()
+	borrower := &models.Borrower{ID: "borrower-id", FirstName: "John", LastName: "Doe", PhoneNumber: "081234567890"}
+	firstName := "Jonathan"
+	phoneNumber := "089876543210"
+
+	mockRepo.On("WithTransaction", ctx, testifymock.AnythingOfType("repositories.TransactionFunc")).
+		Return(func(ctx context.Context, fn repositories.TransactionFunc) error {
+			return fn(ctx)
+		})
+	mockRepo.On("FindByIDForUpdate", ctx, "borrower-id", []string{}).Return(borrower, nil)
+	mockRepo.On("FindOneByPhoneNumber", ctx, phoneNumber).Return(models.Borrower{}, gorm.ErrRecordNotFound)
+	mockRepo.On("Update", ctx, borrower).Return(nil)
+
+	// Act
+	result, err := service.UpdateBorrower(ctx, "borrower-id", models.BorrowerUpdateRequest{
+		FirstName:   &firstName,
+		PhoneNumber: &phoneNumber,
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "Jonathan", result.FirstName)
+	assert.Equal(t, "Doe", result.LastName)
+	assert.Equal(t, phoneNumber, result.PhoneNumber)
+}
+
+func TestBorrowerServiceImpl_GetBorrowerByID_Cached(t *testing.T) {
+	// Arrange
+	mockRepo := mock.NewBorrowerRepository(t)
+	mockLoanRepo := mock.NewLoanRepository(t)
+	service := NewBorrowerService(mockRepo, mockLoanRepo, mock.NewOutboxRepository(t), newTestLRUResponses(t))
+
+	ctx := context.Background()
+	borrower := &models.Borrower{ID: "borrower-id", FirstName: "John", LastName: "Doe", PhoneNumber: "081234567890"}
+	firstName := "Jonathan"
+
+	mockRepo.On("FindByID", ctx, "borrower-id", []string{}).Return(borrower, nil)
+	mockRepo.On("WithTransaction", ctx, testifymock.AnythingOfType("repositories.TransactionFunc")).
+		Return(func(ctx context.Context, fn repositories.TransactionFunc) error {
+			return fn(ctx)
+		})
+	mockRepo.On("FindByIDForUpdate", ctx, "borrower-id", []string{}).Return(borrower, nil)
+	mockRepo.On("Update", ctx, borrower).Return(nil).Once()
+	mockLoanRepo.On("FindOneByBorrowerID", ctx, "borrower-id").Return(models.Loan{}, gorm.ErrRecordNotFound)
+
+	// Act
+	first, firstErr := service.GetBorrowerByID(ctx, "borrower-id")
+	second, secondErr := service.GetBorrowerByID(ctx, "borrower-id")
+	_, updateErr := service.UpdateBorrower(ctx, "borrower-id", models.BorrowerUpdateRequest{FirstName: &firstName})
+	third, thirdErr := service.GetBorrowerByID(ctx, "borrower-id")
+
+	// Assert
+	assert.NoError(t, errors.Join(firstErr, secondErr, updateErr, thirdErr))
+	assert.Equal(t, first, second)
+	assert.Equal(t, "John", second.FirstName)
+	assert.Equal(t, "Jonathan", third.FirstName)
+	// read once for each cached response, the update locks the borrower instead
+	mockRepo.AssertNumberOfCalls(t, "FindByID", 2)
+	mockLoanRepo.AssertNumberOfCalls(t, "FindOneByBorrowerID", 2)
+}
+
+func TestBorrowerServiceImpl_UpdateBorrower_PhoneNumberTaken(t *testing.T) {
+	// Arrange
+	mockRepo := mock.NewBorrowerRepository(t)
+	mockLoanRepo := mock.NewLoanRepository(t)
+	service := NewBorrowerService(mockRepo, mockLoanRepo, mock.NewOutboxRepository(t), newTestResponses())
+
+	ctx := context.Background()
+	borrower := &models.Borrower{ID: "borrower-id", PhoneNumber: "081234567890"}
+	phoneNumber := "089876543210"
+
+	mockRepo.On("WithTransaction", ctx, testifymock.AnythingOfType("repositories.TransactionFunc")).
+		Return(func(ctx context.Context, fn repositories.TransactionFunc) error {
+			return fn(ctx)
+		})
+	mockRepo.On("FindByIDForUpdate", ctx, "borrower-id", []string{}).Return(borrower, nil)
+	mockRepo.On("FindOneByPhoneNumber", ctx, phoneNumber).Return(models.Borrower{ID: "other-borrower-id"}, nil)
+
+	// Act
+	result, err := service.UpdateBorrower(ctx, "borrower-id", models.BorrowerUpdateRequest{PhoneNumber: &phoneNumber})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, "phone number is already registered", err.Error())
+	var domainErr *Error
+	assert.True(t, errors.As(err, &domainErr))
+	assert.Equal(t, ErrorKindConflict, domainErr.Kind)
+	assert.Equal(t, "phone_number_registered", domainErr.Code)
+	mockRepo.AssertNotCalled(t, "Update")
+}
+
+func TestBorrowerServiceImpl_DeleteBorrower_Success(t *testing.T) {
+	// Arrange
+	mockRepo := mock.NewBorrowerRepository(t)
+	mockLoanRepo := mock.NewLoanRepository(t)
+	service := NewBorrowerService(mockRepo, mockLoanRepo, mock.NewOutboxRepository(t), newTestResponses())
+
+	ctx := context.Background()
+	borrower := &models.Borrower{ID: "borrower-id"}
+
+	mockRepo.On("WithTransaction", ctx, testifymock.AnythingOfType("repositories.TransactionFunc")).
+		Return(func(ctx context.Context, fn repositories.TransactionFunc) error {
+			return fn(ctx)
+		})
+	mockRepo.On("FindByIDForUpdate", ctx, "borrower-id", []string{}).Return(borrower, nil)
+	mockLoanRepo.On("FindOneByBorrowerID", ctx, "borrower-id").Return(models.Loan{}, gorm.ErrRecordNotFound)
+	mockRepo.On("Delete", ctx, borrower).Return(nil)
+
+	// Act
+	err := service.DeleteBorrower(ctx, "borrower-id")
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestBorrowerServiceImpl_DeleteBorrower_HasActiveLoan(t *testing.T) {
+	// Arrange
+	mockRepo := mock.NewBorrowerRepository(t)
+	mockLoanRepo := mock.NewLoanRepository(t)
+	service := NewBorrowerService(mockRepo, mockLoanRepo, mock.NewOutboxRepository(t), newTestResponses())
+
+	ctx := context.Background()
+	borrower := &models.Borrower{ID: "borrower-id"}
+
+	mockRepo.On("WithTransaction", ctx, testifymock.AnythingOfType("repositories.TransactionFunc")).
+		Return(func(ctx context.Context, fn repositories.TransactionFunc) error {
+			return fn(ctx)
+		})
+	mockRepo.On("FindByIDForUpdate", ctx, "borrower-id", []string{}).Return(borrower, nil)
+	mockLoanRepo.On("FindOneByBorrowerID", ctx, "borrower-id").Return(models.Loan{ID: "loan-id", Status: models.LoanStatusActive}, nil)
+
+	// Act
+	err := service.DeleteBorrower(ctx, "borrower-id")
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, "borrower still has an active loan", err.Error())
+	var domainErr *Error
+	assert.True(t, errors.As(err, &domainErr))
+	assert.Equal(t, ErrorKindConflict, domainErr.Kind)
+	assert.Equal(t, "borrower_has_active_loan", domainErr.Code)
+	mockRepo.AssertNotCalled(t, "Delete")
+}
+
+func TestBorrowerServiceImpl_GetBorrowerByID_OtherBorrower(t *testing.T) {
+	// Arrange
+	mockBorrowerRepo := mock.NewBorrowerRepository(t)
+	mockLoanRepo := mock.NewLoanRepository(t)
+	service := NewBorrowerService(mockBorrowerRepo, mockLoanRepo, mock.NewOutboxRepository(t), newTestResponses())
+
+	ctx := helpers.WithPrincipal(context.Background(), models.Principal{Role: models.RoleBorrower, BorrowerID: "borrower-id"})
+
+	// Act
+	result, err := service.GetBorrowerByID(ctx, "other-borrower-id")
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
@@ -0,0 +1,24 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/satryarangga/amartha-loan-engine/repositories"
+)
+
+// maxConflictAttempts bounds how many times a unit of work losing the race to a concurrent update is run
+const maxConflictAttempts = 3
+
+// retryOnConflict runs fn again when it fails on a version conflict. Only safe when fn reads again
+// everything it updates, so the next attempt starts from the latest versions.
+func retryOnConflict(fn func() error) error {
+	var err error
+	for attempt := 0; attempt < maxConflictAttempts; attempt++ {
+		err = fn()
+		var conflictErr *repositories.VersionConflictError
+		if !errors.As(err, &conflictErr) {
+			return err
+		}
+	}
+	return err
+}
@@ -0,0 +1,71 @@
+package services
+
+import "fmt"
+
+// ErrorKind tells what went wrong in a way the transport layer can map to a status code
+type ErrorKind string
+
+const (
+	ErrorKindNotFound        ErrorKind = "not_found"
+	ErrorKindValidation      ErrorKind = "validation"
+	ErrorKindConflict        ErrorKind = "conflict"
+	ErrorKindStateTransition ErrorKind = "state_transition"
+	ErrorKindUnauthorized    ErrorKind = "unauthorized"
+	ErrorKindForbidden       ErrorKind = "forbidden"
+	ErrorKindUpstream        ErrorKind = "upstream"
+)
+
+// Error is a domain error safe to show to the caller. Code is a stable machine-readable
+// identifier (e.g. borrower_has_active_loan), Message is meant for humans and Err keeps the
+// underlying cause for the logs, it is never rendered.
+type Error struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is makes errors.Is match domain errors by code, so a sentinel still matches once wrapped
+// with extra details
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == e.Kind && t.Code == e.Code
+}
+
+func NewNotFoundError(code string, format string, args ...interface{}) *Error {
+	return &Error{Kind: ErrorKindNotFound, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func NewValidationError(code string, format string, args ...interface{}) *Error {
+	return &Error{Kind: ErrorKindValidation, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func NewConflictError(code string, format string, args ...interface{}) *Error {
+	return &Error{Kind: ErrorKindConflict, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func NewStateTransitionError(code string, format string, args ...interface{}) *Error {
+	return &Error{Kind: ErrorKindStateTransition, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func NewUnauthorizedError(code string, format string, args ...interface{}) *Error {
+	return &Error{Kind: ErrorKindUnauthorized, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func NewForbiddenError(code string, format string, args ...interface{}) *Error {
+	return &Error{Kind: ErrorKindForbidden, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// NewUpstreamError reports a failing dependency outside of the database, e.g. the blob storage
+// or the payment gateway. The cause is kept for the logs only.
+func NewUpstreamError(code string, err error, format string, args ...interface{}) *Error {
+	return &Error{Kind: ErrorKindUpstream, Code: code, Message: fmt.Sprintf(format, args...), Err: err}
+}
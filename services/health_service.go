@@ -0,0 +1,12 @@
+package services
+
+import (
+	"context"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+)
+
+type HealthService interface {
+	CheckReadiness(ctx context.Context) models.HealthResponse
+	StartDraining()
+}
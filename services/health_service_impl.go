@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/satryarangga/amartha-loan-engine/config"
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/satryarangga/amartha-loan-engine/repositories"
+)
+
+const (
+	HealthCheckDatabase   = "database"
+	HealthCheckMigrations = "migrations"
+	HealthCheckServer     = "server"
+)
+
+type HealthServiceImpl struct {
+	healthRepo            repositories.HealthRepository
+	expectedSchemaVersion int64
+	logger                *config.AmarthaLogger
+	draining              atomic.Bool
+}
+
+// NewHealthService checks the database against the version of the last migration shipped with the build
+func NewHealthService(healthRepo repositories.HealthRepository, expectedSchemaVersion int64, logger *config.AmarthaLogger) *HealthServiceImpl {
+	return &HealthServiceImpl{
+		healthRepo:            healthRepo,
+		expectedSchemaVersion: expectedSchemaVersion,
+		logger:                logger,
+	}
+}
+
+// CheckReadiness tells whether the instance can take traffic, it can't when the database is
+// unreachable, when the migrations aren't all applied or once it is shutting down. The endpoint is
+// public, so the database errors are only logged and the checks carry a fixed message.
+func (s *HealthServiceImpl) CheckReadiness(ctx context.Context) models.HealthResponse {
+	if s.draining.Load() {
+		return models.HealthResponse{
+			Status: models.HealthStatusDown,
+			Checks: map[string]models.HealthCheckResponse{
+				HealthCheckServer: {Status: models.HealthStatusDown, Error: "shutting down"},
+			},
+		}
+	}
+
+	checks := map[string]models.HealthCheckResponse{
+		HealthCheckDatabase:   {Status: models.HealthStatusUp},
+		HealthCheckMigrations: {Status: models.HealthStatusUp},
+	}
+
+	if err := s.healthRepo.Ping(ctx); err != nil {
+		s.logger.Errorf(ctx, "Readiness check failed to reach the database. Error: %v", err)
+		checks[HealthCheckDatabase] = models.HealthCheckResponse{Status: models.HealthStatusDown, Error: "database unreachable"}
+		checks[HealthCheckMigrations] = models.HealthCheckResponse{Status: models.HealthStatusDown, Error: "database unreachable"}
+	} else if version, err := s.healthRepo.GetSchemaVersion(ctx); err != nil {
+		s.logger.Errorf(ctx, "Readiness check failed to read the schema version. Error: %v", err)
+		checks[HealthCheckMigrations] = models.HealthCheckResponse{Status: models.HealthStatusDown, Error: "schema version unavailable"}
+	} else if version < s.expectedSchemaVersion {
+		checks[HealthCheckMigrations] = models.HealthCheckResponse{
+			Status: models.HealthStatusDown,
+			Error:  fmt.Sprintf("schema version %d is behind %d, migrations are pending", version, s.expectedSchemaVersion),
+		}
+	}
+
+	status := models.HealthStatusUp
+	for _, check := range checks {
+		if check.Status == models.HealthStatusDown {
+			status = models.HealthStatusDown
+		}
+	}
+	return models.HealthResponse{Status: status, Checks: checks}
+}
+
+// StartDraining makes the readiness check fail, so the load balancer stops routing new requests to
+// the instance while the in-flight ones complete
+func (s *HealthServiceImpl) StartDraining() {
+	s.draining.Store(true)
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/satryarangga/amartha-loan-engine/config"
+	"github.com/satryarangga/amartha-loan-engine/mock"
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSchemaVersion = int64(20240101000014)
+
+func TestHealthServiceImpl_CheckReadiness_Ready(t *testing.T) {
+	// Arrange
+	mockHealthRepo := mock.NewHealthRepository(t)
+	service := NewHealthService(mockHealthRepo, testSchemaVersion, &config.AmarthaLogger{Logger: zerolog.Nop()})
+	ctx := context.Background()
+
+	mockHealthRepo.On("Ping", ctx).Return(nil)
+	mockHealthRepo.On("GetSchemaVersion", ctx).Return(testSchemaVersion, nil)
+
+	// Act
+	result := service.CheckReadiness(ctx)
+
+	// Assert
+	assert.Equal(t, models.HealthStatusUp, result.Status)
+	assert.Equal(t, models.HealthStatusUp, result.Checks[HealthCheckDatabase].Status)
+	assert.Equal(t, models.HealthStatusUp, result.Checks[HealthCheckMigrations].Status)
+}
+
+func TestHealthServiceImpl_CheckReadiness_DatabaseUnreachable(t *testing.T) {
+	// Arrange
+	var output bytes.Buffer
+	mockHealthRepo := mock.NewHealthRepository(t)
+	service := NewHealthService(mockHealthRepo, testSchemaVersion, &config.AmarthaLogger{Logger: zerolog.New(&output)})
+	ctx := context.Background()
+
+	mockHealthRepo.On("Ping", ctx).Return(errors.New(`failed to connect to host=db.internal user=amartha: connection refused`))
+
+	// Act
+	result := service.CheckReadiness(ctx)
+
+	// Assert
+	// the error is only logged, the host and user of the database aren't shown on the public endpoint
+	assert.Equal(t, models.HealthStatusDown, result.Status)
+	assert.Equal(t, models.HealthCheckResponse{Status: models.HealthStatusDown, Error: "database unreachable"}, result.Checks[HealthCheckDatabase])
+	assert.Contains(t, output.String(), "host=db.internal")
+	assert.Equal(t, models.HealthStatusDown, result.Checks[HealthCheckMigrations].Status)
+	mockHealthRepo.AssertNotCalled(t, "GetSchemaVersion", ctx)
+}
+
+func TestHealthServiceImpl_CheckReadiness_PendingMigrations(t *testing.T) {
+	// Arrange
+	mockHealthRepo := mock.NewHealthRepository(t)
+	service := NewHealthService(mockHealthRepo, testSchemaVersion, &config.AmarthaLogger{Logger: zerolog.Nop()})
+	ctx := context.Background()
+
+	mockHealthRepo.On("Ping", ctx).Return(nil)
+	mockHealthRepo.On("GetSchemaVersion", ctx).Return(int64(20240101000012), nil)
+
+	// Act
+	result := service.CheckReadiness(ctx)
+
+	// Assert
+	assert.Equal(t, models.HealthStatusDown, result.Status)
+	assert.Equal(t, models.HealthStatusUp, result.Checks[HealthCheckDatabase].Status)
+	assert.Equal(t, models.HealthStatusDown, result.Checks[HealthCheckMigrations].Status)
+	assert.Contains(t, result.Checks[HealthCheckMigrations].Error, "migrations are pending")
+}
+
+func TestHealthServiceImpl_CheckReadiness_SchemaVersionUnavailable(t *testing.T) {
+	// Arrange
+	var output bytes.Buffer
+	mockHealthRepo := mock.NewHealthRepository(t)
+	service := NewHealthService(mockHealthRepo, testSchemaVersion, &config.AmarthaLogger{Logger: zerolog.New(&output)})
+	ctx := context.Background()
+
+	mockHealthRepo.On("Ping", ctx).Return(nil)
+	mockHealthRepo.On("GetSchemaVersion", ctx).Return(int64(0), errors.New(`relation "goose_db_version" does not exist`))
+
+	// Act
+	result := service.CheckReadiness(ctx)
+
+	// Assert
+	assert.Equal(t, models.HealthStatusDown, result.Status)
+	assert.Equal(t, models.HealthCheckResponse{Status: models.HealthStatusDown, Error: "schema version unavailable"}, result.Checks[HealthCheckMigrations])
+	assert.Contains(t, output.String(), "goose_db_version")
+}
+
+func TestHealthServiceImpl_CheckReadiness_Draining(t *testing.T) {
+	// Arrange
+	mockHealthRepo := mock.NewHealthRepository(t)
+	service := NewHealthService(mockHealthRepo, testSchemaVersion, &config.AmarthaLogger{Logger: zerolog.Nop()})
+	service.StartDraining()
+
+	// Act
+	result := service.CheckReadiness(context.Background())
+
+	// Assert
+	// the dependencies aren't checked anymore once the instance is shutting down
+	assert.Equal(t, models.HealthStatusDown, result.Status)
+	assert.Equal(t, models.HealthStatusDown, result.Checks[HealthCheckServer].Status)
+}
@@ -0,0 +1,16 @@
+package services
+
+import (
+	"context"
+	"io"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+)
+
+type KYCService interface {
+	GetKYC(ctx context.Context, borrowerID string) (*models.KYCResponse, error)
+	SubmitKYCProfile(ctx context.Context, borrowerID string, request models.KYCProfileRequest) (*models.BorrowerKYCProfile, error)
+	UploadDocument(ctx context.Context, borrowerID string, documentType models.DocumentType, fileName string, size int64, content io.Reader) (*models.BorrowerDocument, error)
+	GetDocumentContent(ctx context.Context, borrowerID string, documentID string) (*models.BorrowerDocument, io.ReadCloser, error)
+	ReviewKYC(ctx context.Context, borrowerID string, request models.KYCReviewRequest) (*models.KYCResponse, error)
+}
@@ -0,0 +1,293 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/satryarangga/amartha-loan-engine/cache"
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/satryarangga/amartha-loan-engine/repositories"
+	"github.com/satryarangga/amartha-loan-engine/storage"
+	"gorm.io/gorm"
+)
+
+const maxKYCDocumentSizeBytes = 5 * 1024 * 1024
+
+type KYCServiceImpl struct {
+	borrowerRepo   repositories.BorrowerRepository
+	kycProfileRepo repositories.BorrowerKYCProfileRepository
+	documentRepo   repositories.BorrowerDocumentRepository
+	blobStorage    storage.BlobStorage
+	responses      *cache.Responses
+}
+
+func NewKYCService(
+	borrowerRepo repositories.BorrowerRepository,
+	kycProfileRepo repositories.BorrowerKYCProfileRepository,
+	documentRepo repositories.BorrowerDocumentRepository,
+	blobStorage storage.BlobStorage,
+	responses *cache.Responses,
+) *KYCServiceImpl {
+	return &KYCServiceImpl{
+		borrowerRepo:   borrowerRepo,
+		kycProfileRepo: kycProfileRepo,
+		documentRepo:   documentRepo,
+		blobStorage:    blobStorage,
+		responses:      responses,
+	}
+}
+
+func (s *KYCServiceImpl) GetKYC(ctx context.Context, borrowerID string) (*models.KYCResponse, error) {
+	borrower, err := s.borrowerRepo.FindByID(ctx, borrowerID, []string{})
+	if err != nil {
+		return nil, err
+	}
+	return s.kycOf(ctx, borrower)
+}
+
+// kycOf loads the KYC profile and documents of the borrower
+func (s *KYCServiceImpl) kycOf(ctx context.Context, borrower *models.Borrower) (*models.KYCResponse, error) {
+	response := &models.KYCResponse{
+		BorrowerID: borrower.ID,
+		Status:     borrower.KYCStatus,
+	}
+
+	profile, err := s.kycProfileRepo.FindOneByBorrowerID(ctx, borrower.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err == nil {
+		response.Profile = &profile
+	}
+
+	documents, err := s.documentRepo.FindByBorrowerID(ctx, borrower.ID)
+	if err != nil {
+		return nil, err
+	}
+	response.Documents = documents
+
+	return response, nil
+}
+
+func (s *KYCServiceImpl) SubmitKYCProfile(ctx context.Context, borrowerID string, request models.KYCProfileRequest) (*models.BorrowerKYCProfile, error) {
+	dateOfBirth, err := time.Parse(time.DateOnly, request.DateOfBirth)
+	if err != nil {
+		return nil, NewValidationError("invalid_date", "date of birth must use YYYY-MM-DD format")
+	}
+
+	// the borrower stays locked until the profile is saved, so a review can't run between the status
+	// check and the write, and the status and profile checked are the latest ones, read on the primary
+	var borrower *models.Borrower
+	var profile models.BorrowerKYCProfile
+	err = s.kycProfileRepo.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		borrower, err = s.borrowerRepo.FindByIDForUpdate(ctx, borrowerID, []string{})
+		if err != nil {
+			return err
+		}
+
+		if borrower.KYCStatus == models.KYCStatusVerified {
+			return NewStateTransitionError("kyc_already_verified", "borrower KYC is already verified")
+		}
+
+		profile, err = s.kycProfileRepo.FindOneByBorrowerID(ctx, borrower.ID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		isNewProfile := errors.Is(err, gorm.ErrRecordNotFound)
+
+		profile.BorrowerID = borrower.ID
+		profile.NIK = request.NIK
+		profile.DateOfBirth = dateOfBirth
+		profile.Address = request.Address
+		profile.BusinessType = request.BusinessType
+		profile.MonthlyIncome = request.MonthlyIncome
+		profile.RejectionReason = ""
+		profile.ReviewedAt = nil
+
+		// submitting (or re-submitting after a rejection) puts the borrower back in the review queue
+		borrower.KYCStatus = models.KYCStatusPending
+
+		if isNewProfile {
+			if _, err := s.kycProfileRepo.Insert(ctx, &profile); err != nil {
+				return err
+			}
+		} else {
+			if err := s.kycProfileRepo.Update(ctx, &profile); err != nil {
+				return err
+			}
+		}
+
+		return s.borrowerRepo.Update(ctx, borrower)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// the KYC status is part of the borrower response
+	s.responses.EvictBorrower(ctx, borrower.ID)
+
+	return &profile, nil
+}
+
+func (s *KYCServiceImpl) UploadDocument(ctx context.Context, borrowerID string, documentType models.DocumentType, fileName string, size int64, content io.Reader) (*models.BorrowerDocument, error) {
+	if !isKnownDocumentType(documentType) {
+		return nil, NewValidationError("invalid_document_type", "unknown document type %q", documentType)
+	}
+
+	if size <= 0 || size > maxKYCDocumentSizeBytes {
+		return nil, NewValidationError("invalid_document_size", "document size must be between 1 byte and %d bytes", maxKYCDocumentSizeBytes)
+	}
+
+	// http.DetectContentType looks at the first 512 bytes at most, they are put back in front of the content
+	head := make([]byte, 512)
+	n, err := io.ReadFull(content, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, NewValidationError("invalid_document_file", "Unable to read document file")
+	}
+	head = head[:n]
+	contentType := http.DetectContentType(head)
+	if !slices.Contains(models.KYCDocumentContentTypes, contentType) {
+		return nil, NewValidationError("unsupported_document_content_type", "document must be a JPEG, PNG or PDF file")
+	}
+	content = io.MultiReader(bytes.NewReader(head), content)
+
+	borrower, err := s.borrowerRepo.FindByID(ctx, borrowerID, []string{})
+	if err != nil {
+		return nil, err
+	}
+
+	if borrower.KYCStatus == models.KYCStatusVerified {
+		return nil, NewStateTransitionError("kyc_already_verified", "borrower KYC is already verified")
+	}
+
+	storageKey := fmt.Sprintf("borrowers/%s/%s/%d%s", borrower.ID, documentType, time.Now().UnixNano(), strings.ToLower(filepath.Ext(fileName)))
+	if err := s.blobStorage.Put(ctx, storageKey, content); err != nil {
+		return nil, NewUpstreamError("document_storage_unavailable", err, "unable to store the document")
+	}
+
+	document := models.BorrowerDocument{
+		BorrowerID:   borrower.ID,
+		DocumentType: documentType,
+		StorageKey:   storageKey,
+		FileName:     filepath.Base(fileName),
+		ContentType:  contentType,
+		SizeBytes:    size,
+	}
+	if _, err := s.documentRepo.Insert(ctx, &document); err != nil {
+		// the blob has no row pointing to it anymore, so it is safe to remove it
+		_ = s.blobStorage.Delete(ctx, storageKey)
+		return nil, err
+	}
+
+	return &document, nil
+}
+
+func (s *KYCServiceImpl) GetDocumentContent(ctx context.Context, borrowerID string, documentID string) (*models.BorrowerDocument, io.ReadCloser, error) {
+	document, err := s.documentRepo.FindByID(ctx, documentID, []string{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if document.BorrowerID != borrowerID {
+		return nil, nil, gorm.ErrRecordNotFound
+	}
+
+	content, err := s.blobStorage.Get(ctx, document.StorageKey)
+	if errors.Is(err, storage.ErrBlobNotFound) {
+		return nil, nil, NewNotFoundError("document_content_not_found", "document content not found")
+	}
+	if err != nil {
+		return nil, nil, NewUpstreamError("document_storage_unavailable", err, "unable to read the document")
+	}
+
+	return document, content, nil
+}
+
+func (s *KYCServiceImpl) ReviewKYC(ctx context.Context, borrowerID string, request models.KYCReviewRequest) (*models.KYCResponse, error) {
+	if request.Status != models.KYCStatusVerified && request.Status != models.KYCStatusRejected {
+		return nil, NewValidationError("invalid_review_status", "review status must be verified or rejected")
+	}
+
+	if request.Status == models.KYCStatusRejected && strings.TrimSpace(request.RejectionReason) == "" {
+		return nil, NewValidationError("rejection_reason_required", "rejection reason is required")
+	}
+
+	// the borrower stays locked until the review is saved, so a profile re-submitted meanwhile waits
+	// for it instead of being verified unseen, and a concurrent review finds it isn't pending anymore
+	var kyc *models.KYCResponse
+	err := s.kycProfileRepo.WithTransaction(ctx, func(ctx context.Context) error {
+		borrower, err := s.borrowerRepo.FindByIDForUpdate(ctx, borrowerID, []string{})
+		if err != nil {
+			return err
+		}
+
+		// read after the lock, they are the profile and documents the status was set for
+		kyc, err = s.kycOf(ctx, borrower)
+		if err != nil {
+			return err
+		}
+
+		if borrower.KYCStatus != models.KYCStatusPending || kyc.Profile == nil {
+			return NewStateTransitionError("kyc_not_pending_review", "borrower KYC is not pending review")
+		}
+
+		if request.Status == models.KYCStatusVerified {
+			if missing := missingDocumentTypes(kyc.Documents); len(missing) > 0 {
+				return NewStateTransitionError("kyc_documents_missing", "missing KYC documents: %v", missing)
+			}
+		}
+
+		reviewedAt := time.Now()
+		profile := kyc.Profile
+		profile.ReviewedAt = &reviewedAt
+		profile.RejectionReason = ""
+		if request.Status == models.KYCStatusRejected {
+			profile.RejectionReason = request.RejectionReason
+		}
+		borrower.KYCStatus = request.Status
+
+		if err := s.kycProfileRepo.Update(ctx, profile); err != nil {
+			return err
+		}
+		return s.borrowerRepo.Update(ctx, borrower)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.responses.EvictBorrower(ctx, borrowerID)
+
+	kyc.Status = request.Status
+	return kyc, nil
+}
+
+func isKnownDocumentType(documentType models.DocumentType) bool {
+	for _, known := range models.RequiredKYCDocumentTypes {
+		if documentType == known {
+			return true
+		}
+	}
+	return false
+}
+
+func missingDocumentTypes(documents []models.BorrowerDocument) []models.DocumentType {
+	uploaded := make(map[models.DocumentType]bool, len(documents))
+	for _, document := range documents {
+		uploaded[document.DocumentType] = true
+	}
+
+	missing := []models.DocumentType{}
+	for _, documentType := range models.RequiredKYCDocumentTypes {
+		if !uploaded[documentType] {
+			missing = append(missing, documentType)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,327 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/satryarangga/amartha-loan-engine/mock"
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/satryarangga/amartha-loan-engine/repositories"
+	"github.com/satryarangga/amartha-loan-engine/storage"
+	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func newTestKYCService(t *testing.T) (*KYCServiceImpl, *mock.BorrowerRepository, *mock.BorrowerKYCProfileRepository, *mock.BorrowerDocumentRepository, *storage.LocalBlobStorage) {
+	mockBorrowerRepo := mock.NewBorrowerRepository(t)
+	mockKYCProfileRepo := mock.NewBorrowerKYCProfileRepository(t)
+	mockDocumentRepo := mock.NewBorrowerDocumentRepository(t)
+	blobStorage, err := storage.NewLocalBlobStorage(t.TempDir())
+	assert.NoError(t, err)
+
+	service := NewKYCService(mockBorrowerRepo, mockKYCProfileRepo, mockDocumentRepo, blobStorage, newTestResponses())
+	return service, mockBorrowerRepo, mockKYCProfileRepo, mockDocumentRepo, blobStorage
+}
+
+func TestNewKYCService(t *testing.T) {
+	service, mockBorrowerRepo, mockKYCProfileRepo, mockDocumentRepo, blobStorage := newTestKYCService(t)
+
+	assert.NotNil(t, service)
+	assert.Equal(t, mockBorrowerRepo, service.borrowerRepo)
+	assert.Equal(t, mockKYCProfileRepo, service.kycProfileRepo)
+	assert.Equal(t, mockDocumentRepo, service.documentRepo)
+	assert.Equal(t, blobStorage, service.blobStorage)
+}
+
+func TestKYCServiceImpl_GetKYC_WithoutProfile(t *testing.T) {
+	// Arrange
+	service, mockBorrowerRepo, mockKYCProfileRepo, mockDocumentRepo, _ := newTestKYCService(t)
+	ctx := context.Background()
+	borrower := &models.Borrower{ID: "borrower-id", KYCStatus: models.KYCStatusUnverified}
+
+	mockBorrowerRepo.On("FindByID", ctx, "borrower-id", []string{}).Return(borrower, nil)
+	mockKYCProfileRepo.On("FindOneByBorrowerID", ctx, "borrower-id").Return(models.BorrowerKYCProfile{}, gorm.ErrRecordNotFound)
+	mockDocumentRepo.On("FindByBorrowerID", ctx, "borrower-id").Return([]models.BorrowerDocument{}, nil)
+
+	// Act
+	result, err := service.GetKYC(ctx, "borrower-id")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, models.KYCStatusUnverified, result.Status)
+	assert.Nil(t, result.Profile)
+	assert.Empty(t, result.Documents)
+}
+
+func TestKYCServiceImpl_SubmitKYCProfile_NewProfile(t *testing.T) {
+	// Arrange
+	service, mockBorrowerRepo, mockKYCProfileRepo, _, _ := newTestKYCService(t)
+	ctx := context.Background()
+	borrower := &models.Borrower{ID: "borrower-id", KYCStatus: models.KYCStatusUnverified}
+	request := models.KYCProfileRequest{
+		NIK:           "3201010101010001",
+		DateOfBirth:   "1990-05-17",
+		Address:       "Jl. Sudirman No. 1",
+		BusinessType:  "grocery",
+		MonthlyIncome: 5000000,
+	}
+
+	mockKYCProfileRepo.On("WithTransaction", ctx, testifymock.AnythingOfType("repositories.TransactionFunc")).
+		Return(func(ctx context.Context, fn repositories.TransactionFunc) error {
+			return fn(ctx)
+		})
+	mockBorrowerRepo.On("FindByIDForUpdate", ctx, "borrower-id", []string{}).Return(borrower, nil)
+	mockKYCProfileRepo.On("FindOneByBorrowerID", ctx, "borrower-id").Return(models.BorrowerKYCProfile{}, gorm.ErrRecordNotFound)
+	mockKYCProfileRepo.On("Insert", ctx, testifymock.AnythingOfType("*models.BorrowerKYCProfile")).Return("profile-id", nil)
+	mockBorrowerRepo.On("Update", ctx, borrower).Return(nil)
+
+	// Act
+	result, err := service.SubmitKYCProfile(ctx, "borrower-id", request)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "borrower-id", result.BorrowerID)
+	assert.Equal(t, request.NIK, result.NIK)
+	assert.Equal(t, "1990-05-17", result.DateOfBirth.Format("2006-01-02"))
+	assert.Equal(t, models.KYCStatusPending, borrower.KYCStatus)
+}
+
+func TestKYCServiceImpl_SubmitKYCProfile_InvalidDateOfBirth(t *testing.T) {
+	// Arrange
+	service, _, _, _, _ := newTestKYCService(t)
+
+	// Act
+	result, err := service.SubmitKYCProfile(context.Background(), "borrower-id", models.KYCProfileRequest{DateOfBirth: "17-05-1990"})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, "date of birth must use YYYY-MM-DD format", err.Error())
+}
+
+func TestKYCServiceImpl_SubmitKYCProfile_AlreadyVerified(t *testing.T) {
+	// Arrange
+	service, mockBorrowerRepo, mockKYCProfileRepo, _, _ := newTestKYCService(t)
+	ctx := context.Background()
+	borrower := &models.Borrower{ID: "borrower-id", KYCStatus: models.KYCStatusVerified}
+
+	mockKYCProfileRepo.On("WithTransaction", ctx, testifymock.AnythingOfType("repositories.TransactionFunc")).
+		Return(func(ctx context.Context, fn repositories.TransactionFunc) error {
+			return fn(ctx)
+		})
+	mockBorrowerRepo.On("FindByIDForUpdate", ctx, "borrower-id", []string{}).Return(borrower, nil)
+
+	// Act
+	result, err := service.SubmitKYCProfile(ctx, "borrower-id", models.KYCProfileRequest{DateOfBirth: "1990-05-17"})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, "borrower KYC is already verified", err.Error())
+}
+
+func TestKYCServiceImpl_UploadDocument_Success(t *testing.T) {
+	// Arrange
+	service, mockBorrowerRepo, _, mockDocumentRepo, blobStorage := newTestKYCService(t)
+	ctx := context.Background()
+	borrower := &models.Borrower{ID: "borrower-id", KYCStatus: models.KYCStatusPending}
+	content := "\xff\xd8\xff\xe0selfie-bytes"
+
+	mockBorrowerRepo.On("FindByID", ctx, "borrower-id", []string{}).Return(borrower, nil)
+	mockDocumentRepo.On("Insert", ctx, testifymock.AnythingOfType("*models.BorrowerDocument")).Return("document-id", nil)
+
+	// Act
+	result, err := service.UploadDocument(ctx, "borrower-id", models.DocumentTypeSelfie, "Selfie.JPG", int64(len(content)), strings.NewReader(content))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, models.DocumentTypeSelfie, result.DocumentType)
+	assert.Equal(t, "Selfie.JPG", result.FileName)
+	assert.Equal(t, "image/jpeg", result.ContentType)
+	assert.True(t, strings.HasPrefix(result.StorageKey, "borrowers/borrower-id/selfie/"))
+	assert.True(t, strings.HasSuffix(result.StorageKey, ".jpg"))
+
+	stored, err := blobStorage.Get(ctx, result.StorageKey)
+	assert.NoError(t, err)
+	storedContent, _ := io.ReadAll(stored)
+	stored.Close()
+	assert.Equal(t, content, string(storedContent))
+}
+
+func TestKYCServiceImpl_UploadDocument_InsertErrorRemovesBlob(t *testing.T) {
+	// Arrange
+	service, mockBorrowerRepo, _, mockDocumentRepo, blobStorage := newTestKYCService(t)
+	ctx := context.Background()
+	borrower := &models.Borrower{ID: "borrower-id", KYCStatus: models.KYCStatusPending}
+	expectedError := errors.New("database error")
+	var insertedDocument *models.BorrowerDocument
+
+	mockBorrowerRepo.On("FindByID", ctx, "borrower-id", []string{}).Return(borrower, nil)
+	mockDocumentRepo.On("Insert", ctx, testifymock.AnythingOfType("*models.BorrowerDocument")).
+		Run(func(args testifymock.Arguments) {
+			insertedDocument = args.Get(1).(*models.BorrowerDocument)
+		}).
+		Return("", expectedError)
+
+	// Act
+	content := "\x89PNG\r\n\x1a\nktp"
+	result, err := service.UploadDocument(ctx, "borrower-id", models.DocumentTypeIDPhoto, "ktp.png", int64(len(content)), strings.NewReader(content))
+
+	// Assert
+	assert.Equal(t, expectedError, err)
+	assert.Nil(t, result)
+	_, getErr := blobStorage.Get(ctx, insertedDocument.StorageKey)
+	assert.Equal(t, storage.ErrBlobNotFound, getErr)
+}
+
+func TestKYCServiceImpl_UploadDocument_UnknownType(t *testing.T) {
+	// Arrange
+	service, _, _, _, _ := newTestKYCService(t)
+
+	// Act
+	result, err := service.UploadDocument(context.Background(), "borrower-id", "passport", "passport.png", 3, strings.NewReader("abc"))
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, `unknown document type "passport"`, err.Error())
+}
+
+func TestKYCServiceImpl_UploadDocument_UnsupportedContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		content  string
+	}{
+		{name: "HTML named as an image", fileName: "selfie.png", content: "<html><script>alert(1)</script></html>"},
+		{name: "SVG with a script", fileName: "selfie.svg", content: `<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`},
+		{name: "plain text", fileName: "ktp.pdf", content: "not a pdf"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service, _, _, _, _ := newTestKYCService(t)
+
+			// Act
+			result, err := service.UploadDocument(context.Background(), "borrower-id", models.DocumentTypeSelfie, tt.fileName, int64(len(tt.content)), strings.NewReader(tt.content))
+
+			// Assert
+			assert.EqualError(t, err, "document must be a JPEG, PNG or PDF file")
+			assert.Nil(t, result)
+		})
+	}
+}
+
+func TestKYCServiceImpl_ReviewKYC_VerifyWithMissingDocuments(t *testing.T) {
+	// Arrange
+	service, mockBorrowerRepo, mockKYCProfileRepo, mockDocumentRepo, _ := newTestKYCService(t)
+	ctx := context.Background()
+	borrower := &models.Borrower{ID: "borrower-id", KYCStatus: models.KYCStatusPending}
+
+	mockKYCProfileRepo.On("WithTransaction", ctx, testifymock.AnythingOfType("repositories.TransactionFunc")).
+		Return(func(ctx context.Context, fn repositories.TransactionFunc) error {
+			return fn(ctx)
+		})
+	mockBorrowerRepo.On("FindByIDForUpdate", ctx, "borrower-id", []string{}).Return(borrower, nil)
+	mockKYCProfileRepo.On("FindOneByBorrowerID", ctx, "borrower-id").Return(models.BorrowerKYCProfile{ID: "profile-id", BorrowerID: "borrower-id"}, nil)
+	mockDocumentRepo.On("FindByBorrowerID", ctx, "borrower-id").Return([]models.BorrowerDocument{
+		{ID: "document-1", DocumentType: models.DocumentTypeIDPhoto},
+	}, nil)
+
+	// Act
+	result, err := service.ReviewKYC(ctx, "borrower-id", models.KYCReviewRequest{Status: models.KYCStatusVerified})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, "missing KYC documents: [selfie business_photo]", err.Error())
+	mockKYCProfileRepo.AssertNotCalled(t, "Update", testifymock.Anything, testifymock.Anything)
+	mockBorrowerRepo.AssertNotCalled(t, "Update", testifymock.Anything, testifymock.Anything)
+}
+
+func TestKYCServiceImpl_ReviewKYC_Verify(t *testing.T) {
+	// Arrange
+	service, mockBorrowerRepo, mockKYCProfileRepo, mockDocumentRepo, _ := newTestKYCService(t)
+	ctx := context.Background()
+	borrower := &models.Borrower{ID: "borrower-id", KYCStatus: models.KYCStatusPending}
+
+	mockKYCProfileRepo.On("WithTransaction", ctx, testifymock.AnythingOfType("repositories.TransactionFunc")).
+		Return(func(ctx context.Context, fn repositories.TransactionFunc) error {
+			return fn(ctx)
+		})
+	mockBorrowerRepo.On("FindByIDForUpdate", ctx, "borrower-id", []string{}).Return(borrower, nil)
+	mockKYCProfileRepo.On("FindOneByBorrowerID", ctx, "borrower-id").Return(models.BorrowerKYCProfile{ID: "profile-id", BorrowerID: "borrower-id"}, nil)
+	mockDocumentRepo.On("FindByBorrowerID", ctx, "borrower-id").Return([]models.BorrowerDocument{
+		{ID: "document-1", DocumentType: models.DocumentTypeIDPhoto},
+		{ID: "document-2", DocumentType: models.DocumentTypeSelfie},
+		{ID: "document-3", DocumentType: models.DocumentTypeBusinessPhoto},
+	}, nil)
+	mockKYCProfileRepo.On("Update", ctx, testifymock.AnythingOfType("*models.BorrowerKYCProfile")).Return(nil)
+	mockBorrowerRepo.On("Update", ctx, borrower).Return(nil)
+
+	// Act
+	result, err := service.ReviewKYC(ctx, "borrower-id", models.KYCReviewRequest{Status: models.KYCStatusVerified})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, models.KYCStatusVerified, result.Status)
+	assert.NotNil(t, result.Profile.ReviewedAt)
+	assert.Equal(t, models.KYCStatusVerified, borrower.KYCStatus)
+}
+
+func TestKYCServiceImpl_ReviewKYC_RejectWithoutReason(t *testing.T) {
+	// Arrange
+	service, _, _, _, _ := newTestKYCService(t)
+
+	// Act
+	result, err := service.ReviewKYC(context.Background(), "borrower-id", models.KYCReviewRequest{Status: models.KYCStatusRejected})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, "rejection reason is required", err.Error())
+}
+
+func TestKYCServiceImpl_ReviewKYC_NotPending(t *testing.T) {
+	testCases := []struct {
+		name       string
+		status     models.KYCStatus
+		profile    models.BorrowerKYCProfile
+		profileErr error
+	}{
+		{"never submitted", models.KYCStatusUnverified, models.BorrowerKYCProfile{}, gorm.ErrRecordNotFound},
+		// a concurrent review committed first, the lock makes this one read its outcome
+		{"already reviewed", models.KYCStatusVerified, models.BorrowerKYCProfile{ID: "profile-id", BorrowerID: "borrower-id"}, nil},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Arrange
+			service, mockBorrowerRepo, mockKYCProfileRepo, mockDocumentRepo, _ := newTestKYCService(t)
+			ctx := context.Background()
+			borrower := &models.Borrower{ID: "borrower-id", KYCStatus: testCase.status}
+
+			mockKYCProfileRepo.On("WithTransaction", ctx, testifymock.AnythingOfType("repositories.TransactionFunc")).
+				Return(func(ctx context.Context, fn repositories.TransactionFunc) error {
+					return fn(ctx)
+				})
+			mockBorrowerRepo.On("FindByIDForUpdate", ctx, "borrower-id", []string{}).Return(borrower, nil)
+			mockKYCProfileRepo.On("FindOneByBorrowerID", ctx, "borrower-id").Return(testCase.profile, testCase.profileErr)
+			mockDocumentRepo.On("FindByBorrowerID", ctx, "borrower-id").Return([]models.BorrowerDocument{}, nil)
+
+			// Act
+			result, err := service.ReviewKYC(ctx, "borrower-id", models.KYCReviewRequest{Status: models.KYCStatusRejected, RejectionReason: "blurry photo"})
+
+			// Assert
+			assert.Error(t, err)
+			assert.Nil(t, result)
+			assert.Equal(t, "borrower KYC is not pending review", err.Error())
+			mockBorrowerRepo.AssertNotCalled(t, "Update", testifymock.Anything, testifymock.Anything)
+		})
+	}
+}
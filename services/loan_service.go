@@ -2,11 +2,15 @@ package services
 
 import (
 	"context"
+	"time"
 
 	"github.com/satryarangga/amartha-loan-engine/models"
 )
 
 type LoanService interface {
-	GetLoanByID(ctx context.Context, id string) (*models.Loan, error)
+	GetLoanByID(ctx context.Context, id string, includes []models.LoanInclude) (*models.LoanResponse, error)
 	CreateLoan(ctx context.Context, loan *models.LoanRequest) error
+	ListLoans(ctx context.Context, request models.LoanListRequest) (*models.LoanListResponse, error)
+	MarkOverdueSchedules(ctx context.Context, now time.Time, limit int) (int, error)
+	GetPortfolioStats(ctx context.Context, now time.Time) (models.PortfolioStats, error)
 }
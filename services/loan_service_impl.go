@@ -3,75 +3,299 @@ package services
 import (
 	"context"
 	"errors"
+	"sort"
 	"time"
 
+	"github.com/satryarangga/amartha-loan-engine/cache"
+	"github.com/satryarangga/amartha-loan-engine/events"
 	"github.com/satryarangga/amartha-loan-engine/helpers"
+	"github.com/satryarangga/amartha-loan-engine/metrics"
 	"github.com/satryarangga/amartha-loan-engine/models"
 	"github.com/satryarangga/amartha-loan-engine/repositories"
 	"gorm.io/gorm"
 )
 
+const defaultLoanProductCode = "standard"
+
+// loanScheduleInsertBatchSize keeps the schedules of even a long loan in a few statements
+const loanScheduleInsertBatchSize = 100
+
 type LoanServiceImpl struct {
 	loanRepo         repositories.LoanRepository
 	loanScheduleRepo repositories.LoanScheduleRepository
 	borrowerRepo     repositories.BorrowerRepository
+	outboxRepo       repositories.OutboxRepository
+	responses        *cache.Responses
 }
 
-func NewLoanService(loanRepo repositories.LoanRepository, loanScheduleRepo repositories.LoanScheduleRepository, borrowerRepo repositories.BorrowerRepository) *LoanServiceImpl {
+func NewLoanService(loanRepo repositories.LoanRepository, loanScheduleRepo repositories.LoanScheduleRepository, borrowerRepo repositories.BorrowerRepository, outboxRepo repositories.OutboxRepository, responses *cache.Responses) *LoanServiceImpl {
 	return &LoanServiceImpl{
 		loanRepo:         loanRepo,
 		loanScheduleRepo: loanScheduleRepo,
 		borrowerRepo:     borrowerRepo,
+		outboxRepo:       outboxRepo,
+		responses:        responses,
 	}
 }
 
-func (s *LoanServiceImpl) GetLoanByID(ctx context.Context, id string) (*models.LoanResponse, error) {
+// GetLoanByID returns the loan aggregates, the includes expand the response with the
+// schedules, the payments and / or the borrower of the loan
+func (s *LoanServiceImpl) GetLoanByID(ctx context.Context, id string, includes []models.LoanInclude) (*models.LoanResponse, error) {
 	if id == "" {
-		return nil, errors.New("loan ID is required")
+		return nil, NewValidationError("loan_id_required", "loan ID is required")
+	}
+
+	relations := []string{"LoanSchedules"}
+	included := map[models.LoanInclude]bool{}
+	for _, include := range includes {
+		switch include {
+		case models.LoanIncludeSchedules:
+		case models.LoanIncludePayments:
+			relations = append(relations, "LoanPayments")
+		case models.LoanIncludeBorrower:
+			relations = append(relations, "Borrower")
+		default:
+			return nil, NewValidationError("invalid_include", "unable to include %q", include)
+		}
+		included[include] = true
+	}
+
+	load := func() (*models.LoanResponse, error) {
+		loan, err := s.loanRepo.FindByID(ctx, id, relations)
+		if err != nil {
+			return nil, err
+		}
+
+		now := time.Now()
+		loanResponse := newLoanResponse(loan, now)
+
+		if included[models.LoanIncludeSchedules] {
+			loanResponse.Schedules = newLoanScheduleResponses(loan.LoanSchedules, now)
+		}
+
+		if included[models.LoanIncludePayments] {
+			loanResponse.Payments = newLoanPaymentResponses(loan.LoanPayments)
+		}
+
+		if included[models.LoanIncludeBorrower] {
+			loanSchedules := loan.LoanSchedules
+			if loan.Status != models.LoanStatusActive {
+				loanSchedules = nil
+			}
+			loanResponse.Borrower = &models.BorrowerResponse{
+				ID:           loan.Borrower.ID,
+				FirstName:    loan.Borrower.FirstName,
+				LastName:     loan.Borrower.LastName,
+				PhoneNumber:  loan.Borrower.PhoneNumber,
+				KYCStatus:    loan.Borrower.KYCStatus,
+				IsDelinquent: helpers.IsBorrowerDelinquent(loanSchedules),
+			}
+		}
+
+		return &loanResponse, nil
+	}
+
+	var loanResponse *models.LoanResponse
+	var err error
+	if key, cacheable := cache.LoanKey(id, includes); cacheable {
+		loanResponse, err = cache.GetOrLoad(ctx, s.responses, cache.LoanResponses, key, load)
+	} else {
+		loanResponse, err = load()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// checked on the cached response too, it's shared by every caller
+	if !helpers.CanAccessBorrower(ctx, loanResponse.BorrowerID) {
+		return nil, gorm.ErrRecordNotFound
 	}
-	loan, err := s.loanRepo.FindByID(ctx, id, []string{"LoanSchedules"})
+
+	return loanResponse, nil
+}
+
+func (s *LoanServiceImpl) ListLoans(ctx context.Context, request models.LoanListRequest) (*models.LoanListResponse, error) {
+	filter := models.LoanFilter{
+		Status:         request.Status,
+		BorrowerID:     request.BorrowerID,
+		ProductCode:    request.ProductCode,
+		MinOutstanding: request.MinOutstanding,
+		MaxOutstanding: request.MaxOutstanding,
+	}
+
+	if borrowerID, ok := helpers.ScopedBorrowerID(ctx); ok {
+		if filter.BorrowerID != "" && filter.BorrowerID != borrowerID {
+			return nil, NewForbiddenError("borrower_scope_violation", "unable to list loans of another borrower")
+		}
+		filter.BorrowerID = borrowerID
+	}
+
+	if request.DisbursedFrom != "" {
+		disbursedFrom, err := time.Parse(time.DateOnly, request.DisbursedFrom)
+		if err != nil {
+			return nil, NewValidationError("invalid_date", "disbursed_from must use YYYY-MM-DD format")
+		}
+		filter.DisbursedFrom = &disbursedFrom
+	}
+	if request.DisbursedTo != "" {
+		disbursedTo, err := time.Parse(time.DateOnly, request.DisbursedTo)
+		if err != nil {
+			return nil, NewValidationError("invalid_date", "disbursed_to must use YYYY-MM-DD format")
+		}
+		// the date is inclusive, so the filter ends at the start of the next day
+		disbursedTo = disbursedTo.AddDate(0, 0, 1)
+		filter.DisbursedTo = &disbursedTo
+	}
+
+	if request.DPDBucket != "" {
+		bucket, ok := helpers.GetDPDBucket(request.DPDBucket)
+		if !ok {
+			return nil, NewValidationError("invalid_dpd_bucket", "unknown DPD bucket %q", request.DPDBucket)
+		}
+		filter.MinDPD = &bucket.MinDays
+		if bucket.MaxDays >= 0 {
+			filter.MaxDPD = &bucket.MaxDays
+		}
+	}
+
+	sortBy := models.SortBy{FieldName: "created_at", Direction: models.SortDirectDescending}
+	if request.SortBy != "" {
+		sortBy.FieldName = request.SortBy
+	}
+	if request.SortDirection != "" {
+		sortBy.Direction = request.SortDirection
+	}
+	if err := repositories.ValidateSort[models.Loan](sortBy); err != nil {
+		return nil, err
+	}
+
+	_, limit := helpers.NormalizePage(1, request.Limit)
+	loans, nextCursor, err := s.loanRepo.FindAllByFilter(ctx, filter, models.FindAllParam{
+		Limit:         limit,
+		Cursor:        request.Cursor,
+		SortBy:        sortBy,
+		PreloadTables: []string{"LoanSchedules"},
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	loanResponse := models.LoanResponse{
+	now := time.Now()
+	data := make([]models.LoanResponse, 0, len(loans))
+	for i := range loans {
+		data = append(data, newLoanResponse(&loans[i], now))
+	}
+
+	return &models.LoanListResponse{
+		Data:       data,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+func newLoanResponse(loan *models.Loan, now time.Time) models.LoanResponse {
+	nextDueDate, nextDueAmount := helpers.GetNextDue(loan.LoanSchedules, now)
+	return models.LoanResponse{
 		ID:                   loan.ID,
+		BorrowerID:           loan.BorrowerID,
+		ProductCode:          loan.ProductCode,
 		Amount:               loan.Amount,
 		RepaymentCadenceDays: loan.RepaymentCadenceDays,
 		RepaymentRepetition:  loan.RepaymentRepetition,
 		InterestPercentage:   loan.InterestPercentage,
 		InterestAmount:       loan.InterestAmount,
 		Status:               string(loan.Status),
+		DisbursedAt:          loan.DisbursedAt,
 		TotalOutstanding:     helpers.CalculateTotalOutstanding(loan),
+		DPD:                  helpers.CalculateDPD(loan.LoanSchedules, now),
+		NextDueDate:          nextDueDate,
+		NextDueAmount:        nextDueAmount,
 	}
-
-	return &loanResponse, nil
 }
 
-func (s *LoanServiceImpl) CreateLoan(ctx context.Context, req *models.LoanRequest) error {
-	borrower, err := s.borrowerRepo.FindByID(ctx, req.BorrowerID, []string{})
-	if err != nil {
-		return err
+func newLoanScheduleResponses(loanSchedules []models.LoanSchedule, now time.Time) []models.LoanScheduleResponse {
+	sorted := append([]models.LoanSchedule{}, loanSchedules...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].DueDate.Before(sorted[j].DueDate)
+	})
+
+	responses := make([]models.LoanScheduleResponse, 0, len(sorted))
+	for _, schedule := range sorted {
+		var paidAmount float64
+		if schedule.Status == models.LoanScheduleStatusPaid {
+			paidAmount = schedule.TotalPayment
+		}
+		responses = append(responses, models.LoanScheduleResponse{
+			ID:             schedule.ID,
+			DueDate:        schedule.DueDate,
+			BasicAmount:    schedule.BasicAmount,
+			InterestAmount: schedule.InterestAmount,
+			TotalPayment:   schedule.TotalPayment,
+			PaidAmount:     paidAmount,
+			Status:         schedule.Status,
+			IsOverdue:      schedule.Status == models.LoanScheduleStatusPending && schedule.DueDate.Before(now),
+		})
 	}
+	return responses
+}
+
+func newLoanPaymentResponses(loanPayments []models.LoanPayment) []models.LoanPaymentResponse {
+	sorted := append([]models.LoanPayment{}, loanPayments...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+	})
 
-	if borrower == nil {
-		return errors.New("borrower not found")
+	responses := make([]models.LoanPaymentResponse, 0, len(sorted))
+	for _, payment := range sorted {
+		responses = append(responses, models.LoanPaymentResponse{
+			ID:              payment.ID,
+			LoanScheduleIDs: payment.LoanScheduleIDs,
+			TotalPayment:    payment.TotalPayment,
+			PaymentMethod:   payment.PaymentMethod,
+			Status:          payment.Status,
+			PaidAt:          payment.PaidAt,
+			CreatedAt:       payment.CreatedAt,
+			UpdatedAt:       payment.UpdatedAt,
+		})
 	}
+	return responses
+}
 
+func (s *LoanServiceImpl) CreateLoan(ctx context.Context, req *models.LoanRequest) error {
 	interestAmount := req.Amount * req.InterestPercentage / 100
 
+	productCode := req.ProductCode
+	if productCode == "" {
+		productCode = defaultLoanProductCode
+	}
+
 	loan := models.Loan{
-		BorrowerID:           borrower.ID,
+		BorrowerID:           req.BorrowerID,
 		Amount:               req.Amount,
 		RepaymentCadenceDays: req.RepaymentCadenceDays,
 		RepaymentRepetition:  req.RepaymentRepetition,
 		InterestPercentage:   req.InterestPercentage,
 		InterestAmount:       interestAmount,
 		Status:               "active",
+		ProductCode:          productCode,
+		DisbursedAt:          time.Now(),
 	}
 
-	err = s.loanRepo.WithTransaction(ctx, func(tx *gorm.DB) error {
-		loanID, err := s.loanRepo.Insert(ctx, tx, &loan)
+	var createdEvents []models.OutboxEvent
+	err := s.loanRepo.WithTransaction(ctx, func(ctx context.Context) error {
+		// locked like DeleteBorrower does, so the borrower can't be deleted while the loan is created
+		borrower, err := s.borrowerRepo.FindByIDForUpdate(ctx, req.BorrowerID, []string{})
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return NewNotFoundError("borrower_not_found", "borrower not found")
+		}
+		if err != nil {
+			return err
+		}
+
+		if borrower.KYCStatus != models.KYCStatusVerified {
+			return NewStateTransitionError("kyc_not_verified", "borrower KYC is not verified")
+		}
+
+		loanID, err := s.loanRepo.Insert(ctx, &loan)
 		if err != nil {
 			return err
 		}
@@ -91,15 +315,88 @@ func (s *LoanServiceImpl) CreateLoan(ctx context.Context, req *models.LoanReques
 			}
 		}
 
+		if err := s.loanScheduleRepo.BulkInsert(ctx, loanSchedules, loanScheduleInsertBatchSize); err != nil {
+			return err
+		}
+
+		event, err := events.NewOutboxEvent(models.EventTypeLoanCreated, "loan", loanID, models.LoanCreatedEvent{
+			LoanID:               loanID,
+			BorrowerID:           loan.BorrowerID,
+			ProductCode:          loan.ProductCode,
+			Amount:               loan.Amount,
+			InterestAmount:       loan.InterestAmount,
+			RepaymentCadenceDays: loan.RepaymentCadenceDays,
+			RepaymentRepetition:  loan.RepaymentRepetition,
+			DisbursedAt:          loan.DisbursedAt,
+		})
+		if err != nil {
+			return err
+		}
+		createdEvents = []models.OutboxEvent{event}
+		return s.outboxRepo.Append(ctx, createdEvents)
+	})
+	if err != nil {
+		return err
+	}
+
+	metrics.RecordLoanCreated(loan.ProductCode, loan.Amount)
+	// the borrower response now sees the schedules of the new loan
+	s.responses.Invalidate(ctx, createdEvents)
+	return nil
+}
+
+// GetPortfolioStats sums up the outstanding amount and the delinquent borrowers of the active loans
+func (s *LoanServiceImpl) GetPortfolioStats(ctx context.Context, now time.Time) (models.PortfolioStats, error) {
+	return s.loanRepo.GetPortfolioStats(ctx, now)
+}
+
+// MarkOverdueSchedules flags the pending schedules that just passed their due date and emits a
+// loan_schedule.overdue event for each of them, so every schedule is reported once. It returns
+// the number of schedules marked, a full batch means there may be more to mark.
+func (s *LoanServiceImpl) MarkOverdueSchedules(ctx context.Context, now time.Time, limit int) (int, error) {
+	var marked int
+	var overdueEvents []models.OutboxEvent
+	err := s.loanScheduleRepo.WithTransaction(ctx, func(ctx context.Context) error {
+		loanSchedules, err := s.loanScheduleRepo.FindNewlyOverdueForUpdate(ctx, now, limit)
+		if err != nil {
+			return err
+		}
+		if len(loanSchedules) == 0 {
+			return nil
+		}
+
+		ids := make([]string, 0, len(loanSchedules))
+		outboxEvents := make([]models.OutboxEvent, 0, len(loanSchedules))
 		for _, loanSchedule := range loanSchedules {
-			_, err := s.loanScheduleRepo.Insert(ctx, tx, &loanSchedule)
+			ids = append(ids, loanSchedule.ID)
+			event, err := events.NewOutboxEvent(models.EventTypeLoanScheduleOverdue, "loan_schedule", loanSchedule.ID, models.LoanScheduleOverdueEvent{
+				LoanScheduleID: loanSchedule.ID,
+				LoanID:         loanSchedule.LoanID,
+				BorrowerID:     loanSchedule.Loan.BorrowerID,
+				DueDate:        loanSchedule.DueDate,
+				TotalPayment:   loanSchedule.TotalPayment,
+			})
 			if err != nil {
 				return err
 			}
+			outboxEvents = append(outboxEvents, event)
+		}
+
+		if err := s.loanScheduleRepo.MarkOverdueByIDs(ctx, ids, now); err != nil {
+			return err
+		}
+		if err := s.outboxRepo.Append(ctx, outboxEvents); err != nil {
+			return err
 		}
 
+		marked = len(loanSchedules)
+		overdueEvents = outboxEvents
 		return nil
 	})
+	if err != nil {
+		return 0, err
+	}
 
-	return err
+	s.responses.Invalidate(ctx, overdueEvents)
+	return marked, nil
 }
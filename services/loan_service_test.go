@@ -2,13 +2,20 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/satryarangga/amartha-loan-engine/helpers"
+	"github.com/satryarangga/amartha-loan-engine/metrics"
 	"github.com/satryarangga/amartha-loan-engine/mock"
 	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/satryarangga/amartha-loan-engine/repositories"
 	"github.com/stretchr/testify/assert"
 	testifymock "github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 func TestNewLoanService(t *testing.T) {
@@ -16,7 +23,7 @@ func TestNewLoanService(t *testing.T) {
 	mockLoanScheduleRepo := mock.NewLoanScheduleRepository(t)
 	mockBorrowerRepo := mock.NewBorrowerRepository(t)
 
-	service := NewLoanService(mockLoanRepo, mockLoanScheduleRepo, mockBorrowerRepo)
+	service := NewLoanService(mockLoanRepo, mockLoanScheduleRepo, mockBorrowerRepo, mock.NewOutboxRepository(t), newTestResponses())
 
 	assert.NotNil(t, service)
 	assert.Equal(t, mockLoanRepo, service.loanRepo)
@@ -29,7 +36,7 @@ func TestLoanServiceImpl_GetLoanByID_Success(t *testing.T) {
 	mockLoanRepo := mock.NewLoanRepository(t)
 	mockLoanScheduleRepo := mock.NewLoanScheduleRepository(t)
 	mockBorrowerRepo := mock.NewBorrowerRepository(t)
-	service := NewLoanService(mockLoanRepo, mockLoanScheduleRepo, mockBorrowerRepo)
+	service := NewLoanService(mockLoanRepo, mockLoanScheduleRepo, mockBorrowerRepo, mock.NewOutboxRepository(t), newTestResponses())
 
 	ctx := context.Background()
 	loanID := "test-loan-id"
@@ -61,7 +68,7 @@ func TestLoanServiceImpl_GetLoanByID_Success(t *testing.T) {
 	mockLoanRepo.On("FindByID", ctx, loanID, []string{"LoanSchedules"}).Return(expectedLoan, nil)
 
 	// Act
-	result, err := service.GetLoanByID(ctx, loanID)
+	result, err := service.GetLoanByID(ctx, loanID, nil)
 
 	// Assert
 	assert.NoError(t, err)
@@ -77,17 +84,86 @@ func TestLoanServiceImpl_GetLoanByID_Success(t *testing.T) {
 	mockLoanRepo.AssertExpectations(t)
 }
 
+func TestLoanServiceImpl_GetLoanByID_WithIncludes(t *testing.T) {
+	// Arrange
+	mockLoanRepo := mock.NewLoanRepository(t)
+	mockLoanScheduleRepo := mock.NewLoanScheduleRepository(t)
+	mockBorrowerRepo := mock.NewBorrowerRepository(t)
+	service := NewLoanService(mockLoanRepo, mockLoanScheduleRepo, mockBorrowerRepo, mock.NewOutboxRepository(t), newTestResponses())
+
+	ctx := context.Background()
+	loanID := "test-loan-id"
+	now := time.Now()
+	expectedLoan := &models.Loan{
+		ID:         loanID,
+		BorrowerID: "borrower-id",
+		Status:     models.LoanStatusActive,
+		Borrower: models.Borrower{
+			ID:        "borrower-id",
+			FirstName: "John",
+			KYCStatus: models.KYCStatusVerified,
+		},
+		LoanSchedules: []models.LoanSchedule{
+			{ID: "schedule-3", TotalPayment: 110000, Status: models.LoanScheduleStatusPending, DueDate: now.AddDate(0, 0, 7)},
+			{ID: "schedule-1", TotalPayment: 110000, Status: models.LoanScheduleStatusPaid, DueDate: now.AddDate(0, 0, -7)},
+			{ID: "schedule-2", TotalPayment: 110000, Status: models.LoanScheduleStatusPending, DueDate: now.AddDate(0, 0, -1)},
+		},
+		LoanPayments: []models.LoanPayment{
+			{ID: "payment-1", LoanScheduleIDs: []string{"schedule-1"}, TotalPayment: 110000, PaymentMethod: "bank_transfer", Status: models.LoanPaymentStatusPaid},
+		},
+	}
+
+	mockLoanRepo.On("FindByID", ctx, loanID, []string{"LoanSchedules", "LoanPayments", "Borrower"}).Return(expectedLoan, nil)
+
+	// Act
+	result, err := service.GetLoanByID(ctx, loanID, []models.LoanInclude{
+		models.LoanIncludeSchedules,
+		models.LoanIncludePayments,
+		models.LoanIncludeBorrower,
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, result.Schedules, 3)
+	assert.Equal(t, "schedule-1", result.Schedules[0].ID)
+	assert.Equal(t, 110000.0, result.Schedules[0].PaidAmount)
+	assert.True(t, result.Schedules[1].IsOverdue)
+	assert.Equal(t, 0.0, result.Schedules[2].PaidAmount)
+	assert.Len(t, result.Payments, 1)
+	assert.Equal(t, "bank_transfer", result.Payments[0].PaymentMethod)
+	assert.Equal(t, "borrower-id", result.Borrower.ID)
+	assert.Equal(t, 1, result.DPD)
+	assert.Equal(t, now.AddDate(0, 0, 7), *result.NextDueDate)
+	assert.Equal(t, 220000.0, result.NextDueAmount)
+}
+
+func TestLoanServiceImpl_GetLoanByID_UnknownInclude(t *testing.T) {
+	// Arrange
+	mockLoanRepo := mock.NewLoanRepository(t)
+	mockLoanScheduleRepo := mock.NewLoanScheduleRepository(t)
+	mockBorrowerRepo := mock.NewBorrowerRepository(t)
+	service := NewLoanService(mockLoanRepo, mockLoanScheduleRepo, mockBorrowerRepo, mock.NewOutboxRepository(t), newTestResponses())
+
+	// Act
+	result, err := service.GetLoanByID(context.Background(), "test-loan-id", []models.LoanInclude{"lender"})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, `unable to include "lender"`, err.Error())
+}
+
 func TestLoanServiceImpl_GetLoanByID_EmptyID(t *testing.T) {
 	// Arrange
 	mockLoanRepo := mock.NewLoanRepository(t)
 	mockLoanScheduleRepo := mock.NewLoanScheduleRepository(t)
 	mockBorrowerRepo := mock.NewBorrowerRepository(t)
-	service := NewLoanService(mockLoanRepo, mockLoanScheduleRepo, mockBorrowerRepo)
+	service := NewLoanService(mockLoanRepo, mockLoanScheduleRepo, mockBorrowerRepo, mock.NewOutboxRepository(t), newTestResponses())
 
 	ctx := context.Background()
 
 	// Act
-	result, err := service.GetLoanByID(ctx, "")
+	result, err := service.GetLoanByID(ctx, "", nil)
 
 	// Assert
 	assert.Error(t, err)
@@ -100,7 +176,7 @@ func TestLoanServiceImpl_GetLoanByID_RepositoryError(t *testing.T) {
 	mockLoanRepo := mock.NewLoanRepository(t)
 	mockLoanScheduleRepo := mock.NewLoanScheduleRepository(t)
 	mockBorrowerRepo := mock.NewBorrowerRepository(t)
-	service := NewLoanService(mockLoanRepo, mockLoanScheduleRepo, mockBorrowerRepo)
+	service := NewLoanService(mockLoanRepo, mockLoanScheduleRepo, mockBorrowerRepo, mock.NewOutboxRepository(t), newTestResponses())
 
 	ctx := context.Background()
 	loanID := "test-loan-id"
@@ -109,7 +185,7 @@ func TestLoanServiceImpl_GetLoanByID_RepositoryError(t *testing.T) {
 	mockLoanRepo.On("FindByID", ctx, loanID, []string{"LoanSchedules"}).Return(nil, expectedError)
 
 	// Act
-	result, err := service.GetLoanByID(ctx, loanID)
+	result, err := service.GetLoanByID(ctx, loanID, nil)
 
 	// Assert
 	assert.Error(t, err)
@@ -123,7 +199,8 @@ func TestLoanServiceImpl_CreateLoan_Success(t *testing.T) {
 	mockLoanRepo := mock.NewLoanRepository(t)
 	mockLoanScheduleRepo := mock.NewLoanScheduleRepository(t)
 	mockBorrowerRepo := mock.NewBorrowerRepository(t)
-	service := NewLoanService(mockLoanRepo, mockLoanScheduleRepo, mockBorrowerRepo)
+	mockOutboxRepo := mock.NewOutboxRepository(t)
+	service := NewLoanService(mockLoanRepo, mockLoanScheduleRepo, mockBorrowerRepo, mockOutboxRepo, newTestResponses())
 
 	ctx := context.Background()
 	request := &models.LoanRequest{
@@ -139,10 +216,17 @@ func TestLoanServiceImpl_CreateLoan_Success(t *testing.T) {
 		FirstName:   "John",
 		LastName:    "Doe",
 		PhoneNumber: "081234567890",
+		KYCStatus:   models.KYCStatusVerified,
 	}
 
-	mockBorrowerRepo.On("FindByID", ctx, "borrower-id", []string{}).Return(borrower, nil)
-	mockLoanRepo.On("WithTransaction", ctx, testifymock.AnythingOfType("repositories.TransactionFunc")).Return(nil)
+	mockLoanRepo.On("WithTransaction", ctx, testifymock.AnythingOfType("repositories.TransactionFunc")).
+		Return(func(ctx context.Context, fn repositories.TransactionFunc) error {
+			return fn(ctx)
+		})
+	mockBorrowerRepo.On("FindByIDForUpdate", ctx, "borrower-id", []string{}).Return(borrower, nil)
+	mockLoanRepo.On("Insert", ctx, testifymock.AnythingOfType("*models.Loan")).Return("loan-id", nil)
+	mockLoanScheduleRepo.On("BulkInsert", ctx, testifymock.AnythingOfType("[]models.LoanSchedule"), loanScheduleInsertBatchSize).Return(nil)
+	mockOutboxRepo.On("Append", ctx, testifymock.AnythingOfType("[]models.OutboxEvent")).Return(nil)
 
 	// Act
 	err := service.CreateLoan(ctx, request)
@@ -158,7 +242,7 @@ func TestLoanServiceImpl_CreateLoan_BorrowerNotFound(t *testing.T) {
 	mockLoanRepo := mock.NewLoanRepository(t)
 	mockLoanScheduleRepo := mock.NewLoanScheduleRepository(t)
 	mockBorrowerRepo := mock.NewBorrowerRepository(t)
-	service := NewLoanService(mockLoanRepo, mockLoanScheduleRepo, mockBorrowerRepo)
+	service := NewLoanService(mockLoanRepo, mockLoanScheduleRepo, mockBorrowerRepo, mock.NewOutboxRepository(t), newTestResponses())
 
 	ctx := context.Background()
 	request := &models.LoanRequest{
@@ -169,7 +253,11 @@ func TestLoanServiceImpl_CreateLoan_BorrowerNotFound(t *testing.T) {
 		InterestPercentage:   10,
 	}
 
-	mockBorrowerRepo.On("FindByID", ctx, "borrower-id", []string{}).Return(nil, nil)
+	mockLoanRepo.On("WithTransaction", ctx, testifymock.AnythingOfType("repositories.TransactionFunc")).
+		Return(func(ctx context.Context, fn repositories.TransactionFunc) error {
+			return fn(ctx)
+		})
+	mockBorrowerRepo.On("FindByIDForUpdate", ctx, "borrower-id", []string{}).Return(nil, gorm.ErrRecordNotFound)
 
 	// Act
 	err := service.CreateLoan(ctx, request)
@@ -177,7 +265,52 @@ func TestLoanServiceImpl_CreateLoan_BorrowerNotFound(t *testing.T) {
 	// Assert
 	assert.Error(t, err)
 	assert.Equal(t, "borrower not found", err.Error())
+	var domainErr *Error
+	assert.True(t, errors.As(err, &domainErr))
+	assert.Equal(t, ErrorKindNotFound, domainErr.Kind)
+	assert.Equal(t, "borrower_not_found", domainErr.Code)
+	mockBorrowerRepo.AssertExpectations(t)
+}
+
+func TestLoanServiceImpl_CreateLoan_BorrowerKYCNotVerified(t *testing.T) {
+	// Arrange
+	mockLoanRepo := mock.NewLoanRepository(t)
+	mockLoanScheduleRepo := mock.NewLoanScheduleRepository(t)
+	mockBorrowerRepo := mock.NewBorrowerRepository(t)
+	service := NewLoanService(mockLoanRepo, mockLoanScheduleRepo, mockBorrowerRepo, mock.NewOutboxRepository(t), newTestResponses())
+
+	ctx := context.Background()
+	request := &models.LoanRequest{
+		BorrowerID:           "borrower-id",
+		Amount:               1000000,
+		RepaymentCadenceDays: 7,
+		RepaymentRepetition:  12,
+		InterestPercentage:   10,
+	}
+
+	borrower := &models.Borrower{
+		ID:        "borrower-id",
+		KYCStatus: models.KYCStatusPending,
+	}
+
+	mockLoanRepo.On("WithTransaction", ctx, testifymock.AnythingOfType("repositories.TransactionFunc")).
+		Return(func(ctx context.Context, fn repositories.TransactionFunc) error {
+			return fn(ctx)
+		})
+	mockBorrowerRepo.On("FindByIDForUpdate", ctx, "borrower-id", []string{}).Return(borrower, nil)
+
+	// Act
+	err := service.CreateLoan(ctx, request)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, "borrower KYC is not verified", err.Error())
+	var domainErr *Error
+	assert.True(t, errors.As(err, &domainErr))
+	assert.Equal(t, ErrorKindStateTransition, domainErr.Kind)
+	assert.Equal(t, "kyc_not_verified", domainErr.Code)
 	mockBorrowerRepo.AssertExpectations(t)
+	mockLoanRepo.AssertNotCalled(t, "Insert")
 }
 
 func TestLoanServiceImpl_CreateLoan_BorrowerRepositoryError(t *testing.T) {
@@ -185,7 +318,7 @@ func TestLoanServiceImpl_CreateLoan_BorrowerRepositoryError(t *testing.T) {
 	mockLoanRepo := mock.NewLoanRepository(t)
 	mockLoanScheduleRepo := mock.NewLoanScheduleRepository(t)
 	mockBorrowerRepo := mock.NewBorrowerRepository(t)
-	service := NewLoanService(mockLoanRepo, mockLoanScheduleRepo, mockBorrowerRepo)
+	service := NewLoanService(mockLoanRepo, mockLoanScheduleRepo, mockBorrowerRepo, mock.NewOutboxRepository(t), newTestResponses())
 
 	ctx := context.Background()
 	request := &models.LoanRequest{
@@ -197,7 +330,11 @@ func TestLoanServiceImpl_CreateLoan_BorrowerRepositoryError(t *testing.T) {
 	}
 
 	expectedError := errors.New("database error")
-	mockBorrowerRepo.On("FindByID", ctx, "borrower-id", []string{}).Return(nil, expectedError)
+	mockLoanRepo.On("WithTransaction", ctx, testifymock.AnythingOfType("repositories.TransactionFunc")).
+		Return(func(ctx context.Context, fn repositories.TransactionFunc) error {
+			return fn(ctx)
+		})
+	mockBorrowerRepo.On("FindByIDForUpdate", ctx, "borrower-id", []string{}).Return(nil, expectedError)
 
 	// Act
 	err := service.CreateLoan(ctx, request)
@@ -207,3 +344,318 @@ func TestLoanServiceImpl_CreateLoan_BorrowerRepositoryError(t *testing.T) {
 	assert.Equal(t, expectedError, err)
 	mockBorrowerRepo.AssertExpectations(t)
 }
+
+func TestLoanServiceImpl_ListLoans_Success(t *testing.T) {
+	// Arrange
+	mockLoanRepo := mock.NewLoanRepository(t)
+	mockLoanScheduleRepo := mock.NewLoanScheduleRepository(t)
+	mockBorrowerRepo := mock.NewBorrowerRepository(t)
+	service := NewLoanService(mockLoanRepo, mockLoanScheduleRepo, mockBorrowerRepo, mock.NewOutboxRepository(t), newTestResponses())
+
+	ctx := context.Background()
+	minOutstanding := 100000.0
+	request := models.LoanListRequest{
+		Status:         models.LoanStatusActive,
+		ProductCode:    "standard",
+		DisbursedFrom:  "2025-01-01",
+		DisbursedTo:    "2025-01-31",
+		DPDBucket:      "1-30",
+		MinOutstanding: &minOutstanding,
+		SortBy:         "amount",
+		SortDirection:  models.SortDirectAscending,
+		Cursor:         "cursor-token",
+		Limit:          20,
+	}
+
+	disbursedFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	disbursedTo := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	minDPD, maxDPD := 1, 30
+	expectedFilter := models.LoanFilter{
+		Status:         models.LoanStatusActive,
+		ProductCode:    "standard",
+		DisbursedFrom:  &disbursedFrom,
+		DisbursedTo:    &disbursedTo,
+		MinDPD:         &minDPD,
+		MaxDPD:         &maxDPD,
+		MinOutstanding: &minOutstanding,
+	}
+	expectedParam := models.FindAllParam{
+		Limit:         20,
+		Cursor:        "cursor-token",
+		SortBy:        models.SortBy{FieldName: "amount", Direction: models.SortDirectAscending},
+		PreloadTables: []string{"LoanSchedules"},
+	}
+	loans := []models.Loan{
+		{
+			ID:         "loan-id",
+			BorrowerID: "borrower-id",
+			Amount:     1000000,
+			Status:     models.LoanStatusActive,
+			LoanSchedules: []models.LoanSchedule{
+				{TotalPayment: 110000, Status: models.LoanScheduleStatusPending, DueDate: time.Now().AddDate(0, 0, -10)},
+				{TotalPayment: 110000, Status: models.LoanScheduleStatusPending, DueDate: time.Now().AddDate(0, 0, 4)},
+			},
+		},
+	}
+
+	mockLoanRepo.On("FindAllByFilter", ctx, expectedFilter, expectedParam).Return(loans, "next-cursor-token", nil)
+
+	// Act
+	result, err := service.ListLoans(ctx, request)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "next-cursor-token", result.NextCursor)
+	assert.Len(t, result.Data, 1)
+	assert.Equal(t, "borrower-id", result.Data[0].BorrowerID)
+	assert.Equal(t, 220000.0, result.Data[0].TotalOutstanding)
+	assert.Equal(t, 10, result.Data[0].DPD)
+}
+
+func TestLoanServiceImpl_ListLoans_OpenEndedDPDBucket(t *testing.T) {
+	// Arrange
+	mockLoanRepo := mock.NewLoanRepository(t)
+	mockLoanScheduleRepo := mock.NewLoanScheduleRepository(t)
+	mockBorrowerRepo := mock.NewBorrowerRepository(t)
+	service := NewLoanService(mockLoanRepo, mockLoanScheduleRepo, mockBorrowerRepo, mock.NewOutboxRepository(t), newTestResponses())
+
+	ctx := context.Background()
+	minDPD := 91
+	expectedParam := models.FindAllParam{
+		Limit:         10,
+		SortBy:        models.SortBy{FieldName: "created_at", Direction: models.SortDirectDescending},
+		PreloadTables: []string{"LoanSchedules"},
+	}
+
+	mockLoanRepo.On("FindAllByFilter", ctx, models.LoanFilter{MinDPD: &minDPD}, expectedParam).Return([]models.Loan{}, "", nil)
+
+	// Act
+	result, err := service.ListLoans(ctx, models.LoanListRequest{DPDBucket: "90+"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, result.Data)
+	assert.Empty(t, result.NextCursor)
+}
+
+func TestLoanServiceImpl_ListLoans_InvalidRequest(t *testing.T) {
+	// Arrange
+	mockLoanRepo := mock.NewLoanRepository(t)
+	mockLoanScheduleRepo := mock.NewLoanScheduleRepository(t)
+	mockBorrowerRepo := mock.NewBorrowerRepository(t)
+	service := NewLoanService(mockLoanRepo, mockLoanScheduleRepo, mockBorrowerRepo, mock.NewOutboxRepository(t), newTestResponses())
+
+	ctx := context.Background()
+
+	// Act
+	_, dateErr := service.ListLoans(ctx, models.LoanListRequest{DisbursedFrom: "01/01/2025"})
+	_, sortErr := service.ListLoans(ctx, models.LoanListRequest{SortBy: "interest_amount"})
+
+	// Assert
+	assert.Equal(t, "disbursed_from must use YYYY-MM-DD format", dateErr.Error())
+	assert.Equal(t, `unable to sort loans by "interest_amount"`, sortErr.Error())
+	mockLoanRepo.AssertNotCalled(t, "FindAllByFilter")
+}
+
+func TestLoanServiceImpl_GetLoanByID_OtherBorrower(t *testing.T) {
+	// Arrange
+	mockLoanRepo := mock.NewLoanRepository(t)
+	mockLoanScheduleRepo := mock.NewLoanScheduleRepository(t)
+	mockBorrowerRepo := mock.NewBorrowerRepository(t)
+	service := NewLoanService(mockLoanRepo, mockLoanScheduleRepo, mockBorrowerRepo, mock.NewOutboxRepository(t), newTestResponses())
+
+	ctx := helpers.WithPrincipal(context.Background(), models.Principal{Role: models.RoleBorrower, BorrowerID: "borrower-id"})
+	mockLoanRepo.On("FindByID", ctx, "loan-id", []string{"LoanSchedules"}).Return(&models.Loan{ID: "loan-id", BorrowerID: "other-borrower-id"}, nil)
+
+	// Act
+	result, err := service.GetLoanByID(ctx, "loan-id", nil)
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestLoanServiceImpl_GetLoanByID_Cached(t *testing.T) {
+	// Arrange
+	mockLoanRepo := mock.NewLoanRepository(t)
+	mockLoanScheduleRepo := mock.NewLoanScheduleRepository(t)
+	mockBorrowerRepo := mock.NewBorrowerRepository(t)
+	service := NewLoanService(mockLoanRepo, mockLoanScheduleRepo, mockBorrowerRepo, mock.NewOutboxRepository(t), newTestLRUResponses(t))
+
+	ctx := context.Background()
+	otherCtx := helpers.WithPrincipal(ctx, models.Principal{Role: models.RoleBorrower, BorrowerID: "other-borrower-id"})
+	mockLoanRepo.On("FindByID", ctx, "loan-id", []string{"LoanSchedules"}).Return(&models.Loan{ID: "loan-id", BorrowerID: "borrower-id", Status: models.LoanStatusActive}, nil).Once()
+
+	// Act
+	first, firstErr := service.GetLoanByID(ctx, "loan-id", nil)
+	second, secondErr := service.GetLoanByID(ctx, "loan-id", []models.LoanInclude{})
+	other, otherErr := service.GetLoanByID(otherCtx, "loan-id", nil)
+
+	// Assert
+	assert.NoError(t, errors.Join(firstErr, secondErr))
+	assert.Equal(t, first, second)
+	assert.Nil(t, other)
+	assert.ErrorIs(t, otherErr, gorm.ErrRecordNotFound)
+}
+
+func TestLoanServiceImpl_GetLoanByID_BorrowerIncludeNotCached(t *testing.T) {
+	// Arrange
+	mockLoanRepo := mock.NewLoanRepository(t)
+	mockLoanScheduleRepo := mock.NewLoanScheduleRepository(t)
+	mockBorrowerRepo := mock.NewBorrowerRepository(t)
+	service := NewLoanService(mockLoanRepo, mockLoanScheduleRepo, mockBorrowerRepo, mock.NewOutboxRepository(t), newTestLRUResponses(t))
+
+	ctx := context.Background()
+	includes := []models.LoanInclude{models.LoanIncludeBorrower}
+	loan := &models.Loan{ID: "loan-id", BorrowerID: "borrower-id", Borrower: models.Borrower{ID: "borrower-id"}}
+	mockLoanRepo.On("FindByID", ctx, "loan-id", []string{"LoanSchedules", "Borrower"}).Return(loan, nil).Twice()
+
+	// Act
+	_, firstErr := service.GetLoanByID(ctx, "loan-id", includes)
+	_, secondErr := service.GetLoanByID(ctx, "loan-id", includes)
+
+	// Assert
+	assert.NoError(t, errors.Join(firstErr, secondErr))
+}
+
+func TestLoanServiceImpl_ListLoans_BorrowerScoped(t *testing.T) {
+	// Arrange
+	mockLoanRepo := mock.NewLoanRepository(t)
+	mockLoanScheduleRepo := mock.NewLoanScheduleRepository(t)
+	mockBorrowerRepo := mock.NewBorrowerRepository(t)
+	service := NewLoanService(mockLoanRepo, mockLoanScheduleRepo, mockBorrowerRepo, mock.NewOutboxRepository(t), newTestResponses())
+
+	ctx := helpers.WithPrincipal(context.Background(), models.Principal{Role: models.RoleBorrower, BorrowerID: "borrower-id"})
+	mockLoanRepo.On("FindAllByFilter", ctx, models.LoanFilter{BorrowerID: "borrower-id"}, testifymock.Anything).Return([]models.Loan{}, "", nil)
+
+	// Act
+	result, err := service.ListLoans(ctx, models.LoanListRequest{})
+	_, otherErr := service.ListLoans(ctx, models.LoanListRequest{BorrowerID: "other-borrower-id"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, result.Data)
+	assert.EqualError(t, otherErr, "unable to list loans of another borrower")
+}
+
+func TestLoanServiceImpl_CreateLoan_AppendsLoanCreatedEvent(t *testing.T) {
+	// Arrange
+	mockLoanRepo := mock.NewLoanRepository(t)
+	mockLoanScheduleRepo := mock.NewLoanScheduleRepository(t)
+	mockBorrowerRepo := mock.NewBorrowerRepository(t)
+	mockOutboxRepo := mock.NewOutboxRepository(t)
+	service := NewLoanService(mockLoanRepo, mockLoanScheduleRepo, mockBorrowerRepo, mockOutboxRepo, newTestResponses())
+
+	ctx := context.Background()
+	request := &models.LoanRequest{
+		BorrowerID:           "borrower-id",
+		Amount:               1000000,
+		RepaymentCadenceDays: 7,
+		RepaymentRepetition:  2,
+		InterestPercentage:   10,
+	}
+	var appendedEvents []models.OutboxEvent
+
+	mockBorrowerRepo.On("FindByIDForUpdate", ctx, "borrower-id", []string{}).Return(&models.Borrower{ID: "borrower-id", KYCStatus: models.KYCStatusVerified}, nil)
+	mockLoanRepo.On("WithTransaction", ctx, testifymock.AnythingOfType("repositories.TransactionFunc")).
+		Return(func(ctx context.Context, fn repositories.TransactionFunc) error {
+			return fn(ctx)
+		})
+	mockLoanRepo.On("Insert", ctx, testifymock.AnythingOfType("*models.Loan")).Return("loan-id", nil)
+	var insertedSchedules []models.LoanSchedule
+	mockLoanScheduleRepo.On("BulkInsert", ctx, testifymock.AnythingOfType("[]models.LoanSchedule"), loanScheduleInsertBatchSize).
+		Run(func(args testifymock.Arguments) {
+			insertedSchedules = args.Get(1).([]models.LoanSchedule)
+		}).
+		Return(nil)
+	mockOutboxRepo.On("Append", ctx, testifymock.AnythingOfType("[]models.OutboxEvent")).
+		Run(func(args testifymock.Arguments) {
+			appendedEvents = args.Get(1).([]models.OutboxEvent)
+		}).
+		Return(nil)
+	loansCreated := testutil.ToFloat64(metrics.LoansCreated.WithLabelValues("standard"))
+	amountDisbursed := testutil.ToFloat64(metrics.AmountDisbursed.WithLabelValues("standard"))
+
+	// Act
+	err := service.CreateLoan(ctx, request)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, loansCreated+1, testutil.ToFloat64(metrics.LoansCreated.WithLabelValues("standard")))
+	assert.Equal(t, amountDisbursed+1000000, testutil.ToFloat64(metrics.AmountDisbursed.WithLabelValues("standard")))
+	assert.Len(t, insertedSchedules, 2)
+	assert.Equal(t, "loan-id", insertedSchedules[0].LoanID)
+	assert.Equal(t, 550000.0, insertedSchedules[1].TotalPayment)
+	assert.Len(t, appendedEvents, 1)
+	assert.Equal(t, models.EventTypeLoanCreated, appendedEvents[0].EventType)
+	assert.Equal(t, "loan", appendedEvents[0].AggregateType)
+	assert.Equal(t, "loan-id", appendedEvents[0].AggregateID)
+	assert.Contains(t, string(appendedEvents[0].Payload), `"borrower_id":"borrower-id"`)
+}
+
+func TestLoanServiceImpl_MarkOverdueSchedules_Success(t *testing.T) {
+	// Arrange
+	mockLoanRepo := mock.NewLoanRepository(t)
+	mockLoanScheduleRepo := mock.NewLoanScheduleRepository(t)
+	mockBorrowerRepo := mock.NewBorrowerRepository(t)
+	mockOutboxRepo := mock.NewOutboxRepository(t)
+	service := NewLoanService(mockLoanRepo, mockLoanScheduleRepo, mockBorrowerRepo, mockOutboxRepo, newTestResponses())
+
+	ctx := context.Background()
+	now := time.Now()
+	loanSchedules := []models.LoanSchedule{
+		{ID: "schedule-1", LoanID: "loan-id", DueDate: now.AddDate(0, 0, -2), TotalPayment: 100, Loan: models.Loan{ID: "loan-id", BorrowerID: "borrower-id"}},
+		{ID: "schedule-2", LoanID: "loan-id", DueDate: now.AddDate(0, 0, -1), TotalPayment: 100, Loan: models.Loan{ID: "loan-id", BorrowerID: "borrower-id"}},
+	}
+	var appendedEvents []models.OutboxEvent
+
+	mockLoanScheduleRepo.On("WithTransaction", ctx, testifymock.AnythingOfType("repositories.TransactionFunc")).
+		Return(func(ctx context.Context, fn repositories.TransactionFunc) error {
+			return fn(ctx)
+		})
+	mockLoanScheduleRepo.On("FindNewlyOverdueForUpdate", ctx, now, 100).Return(loanSchedules, nil)
+	mockLoanScheduleRepo.On("MarkOverdueByIDs", ctx, []string{"schedule-1", "schedule-2"}, now).Return(nil)
+	mockOutboxRepo.On("Append", ctx, testifymock.AnythingOfType("[]models.OutboxEvent")).
+		Run(func(args testifymock.Arguments) {
+			appendedEvents = args.Get(1).([]models.OutboxEvent)
+		}).
+		Return(nil)
+
+	// Act
+	marked, err := service.MarkOverdueSchedules(ctx, now, 100)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2, marked)
+	assert.Len(t, appendedEvents, 2)
+	assert.Equal(t, models.EventTypeLoanScheduleOverdue, appendedEvents[0].EventType)
+	assert.Equal(t, "schedule-1", appendedEvents[0].AggregateID)
+	assert.Equal(t, "schedule-2", appendedEvents[1].AggregateID)
+	var payload models.LoanScheduleOverdueEvent
+	assert.NoError(t, json.Unmarshal(appendedEvents[0].Payload, &payload))
+	assert.Equal(t, "borrower-id", payload.BorrowerID)
+}
+
+func TestLoanServiceImpl_MarkOverdueSchedules_NothingOverdue(t *testing.T) {
+	// Arrange
+	mockLoanRepo := mock.NewLoanRepository(t)
+	mockLoanScheduleRepo := mock.NewLoanScheduleRepository(t)
+	mockBorrowerRepo := mock.NewBorrowerRepository(t)
+	service := NewLoanService(mockLoanRepo, mockLoanScheduleRepo, mockBorrowerRepo, mock.NewOutboxRepository(t), newTestResponses())
+
+	ctx := context.Background()
+	now := time.Now()
+
+	mockLoanScheduleRepo.On("WithTransaction", ctx, testifymock.AnythingOfType("repositories.TransactionFunc")).
+		Return(func(ctx context.Context, fn repositories.TransactionFunc) error {
+			return fn(ctx)
+		})
+	mockLoanScheduleRepo.On("FindNewlyOverdueForUpdate", ctx, now, 100).Return([]models.LoanSchedule{}, nil)
+
+	// Act
+	marked, err := service.MarkOverdueSchedules(ctx, now, 100)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 0, marked)
+}
@@ -2,10 +2,14 @@ package services
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"net/url"
+	"time"
 
+	"github.com/satryarangga/amartha-loan-engine/cache"
+	"github.com/satryarangga/amartha-loan-engine/events"
 	"github.com/satryarangga/amartha-loan-engine/helpers"
+	"github.com/satryarangga/amartha-loan-engine/metrics"
 	"github.com/satryarangga/amartha-loan-engine/models"
 	"github.com/satryarangga/amartha-loan-engine/repositories"
 	"gorm.io/gorm"
@@ -16,6 +20,10 @@ type PaymentServiceImpl struct {
 	loanPaymentRepo  repositories.LoanPaymentRepository
 	loanScheduleRepo repositories.LoanScheduleRepository
 	borrowerRepo     repositories.BorrowerRepository
+	outboxRepo       repositories.OutboxRepository
+	responses        *cache.Responses
+	// paymentLinkBaseURL is the checkout page of the payment gateway
+	paymentLinkBaseURL string
 }
 
 func NewPaymentService(
@@ -23,16 +31,27 @@ func NewPaymentService(
 	loanPaymentRepo repositories.LoanPaymentRepository,
 	loanScheduleRepo repositories.LoanScheduleRepository,
 	borrowerRepo repositories.BorrowerRepository,
+	outboxRepo repositories.OutboxRepository,
+	responses *cache.Responses,
+	paymentLinkBaseURL string,
 ) *PaymentServiceImpl {
 	return &PaymentServiceImpl{
 		loanRepo:         loanRepo,
 		loanPaymentRepo:  loanPaymentRepo,
 		loanScheduleRepo: loanScheduleRepo,
 		borrowerRepo:     borrowerRepo,
+		outboxRepo:       outboxRepo,
+		responses:        responses,
+
+		paymentLinkBaseURL: paymentLinkBaseURL,
 	}
 }
 
 func (s *PaymentServiceImpl) GeneratePaymentLink(ctx context.Context, request models.PaymentLinkRequest) (*models.PaymentLinkResponse, error) {
+	if !helpers.CanAccessBorrower(ctx, request.BorrowerID) {
+		return nil, gorm.ErrRecordNotFound
+	}
+
 	// 1. Find by borrower ID
 	borrower, err := s.borrowerRepo.FindByID(ctx, request.BorrowerID, []string{})
 	if err != nil {
@@ -45,14 +64,14 @@ func (s *PaymentServiceImpl) GeneratePaymentLink(ctx context.Context, request mo
 		return nil, err
 	}
 
-	//3 Get all loan schedules that are pending and due date is less than 3 days from now
-	loanSchedules, err := s.loanScheduleRepo.FindDueRepaymentSchedules(ctx, loan.ID)
+	//3 Get all loan schedules that are pending and due within the repayment window
+	loanSchedules, err := s.loanScheduleRepo.FindDueRepaymentSchedules(ctx, loan.ID, helpers.ActiveLoanRules.RepaymentDueBefore(time.Now()))
 	if err != nil {
 		return nil, err
 	}
 
 	if len(loanSchedules) == 0 {
-		return nil, errors.New("no loan schedules found")
+		return nil, NewStateTransitionError("no_due_loan_schedules", "no loan schedules found")
 	}
 
 	// 3. Show total outstanding that needs to be paid
@@ -70,13 +89,14 @@ func (s *PaymentServiceImpl) GeneratePaymentLink(ctx context.Context, request mo
 		TotalPayment:    totalRepaymentAmount,
 		PaymentMethod:   request.PaymentMethod,
 	}
-	loanPaymentID, err := s.loanPaymentRepo.Insert(ctx, nil, &loanPayment)
+	loanPaymentID, err := s.loanPaymentRepo.Insert(ctx, &loanPayment)
 	if err != nil {
 		return nil, err
 	}
+	metrics.RecordPaymentProcessed(loanPayment.PaymentMethod, models.LoanPaymentStatusPending)
 
 	// 5. Generate payment link (Assume hitting Payment Gateway API and the Payment Gateway API returns payment link)
-	paymentLink := fmt.Sprintf("https://example.com/payment-link?external_id=%s", loanPaymentID)
+	paymentLink := fmt.Sprintf("%s?external_id=%s", s.paymentLinkBaseURL, url.QueryEscape(loanPaymentID))
 
 	return &models.PaymentLinkResponse{
 		ID:                   loanPaymentID,
@@ -87,54 +107,101 @@ func (s *PaymentServiceImpl) GeneratePaymentLink(ctx context.Context, request mo
 
 func (s *PaymentServiceImpl) HandlePaymentWebhook(ctx context.Context, request models.PaymentWebhookRequest) error {
 	if request.PaymentStatus != "paid" {
-		return errors.New("payment status from PG is not paid")
+		return NewValidationError("payment_not_paid", "payment status from PG is not paid")
 	}
 
-	err := s.loanRepo.WithTransaction(ctx, func(tx *gorm.DB) error {
-		// 1.Find loan payment with ID
-		loanPayment, err := s.loanPaymentRepo.FindByID(ctx, request.ExternalID, []string{})
-		if err != nil {
-			return err
-		}
-
-		// 2. Update Status on Loan Payment
-		loanPayment.Status = models.LoanPaymentStatusPaid
-		err = s.loanPaymentRepo.Update(ctx, nil, loanPayment)
-		if err != nil {
-			return err
-		}
-
-		// 3. Find Loan Detail
-		loan, err := s.loanRepo.FindByID(ctx, loanPayment.LoanID, []string{"LoanSchedules"})
-		if err != nil {
-			return err
-		}
-
-		// 4. Calculate total paid repayment amount
-		var totalPaidRepaymentAmount float64
-		for _, loanSchedule := range loan.LoanSchedules {
-			if loanSchedule.Status == models.LoanScheduleStatusPaid {
-				totalPaidRepaymentAmount += loanSchedule.TotalPayment
+	// the transaction reads the payment and the loan again, so it can be retried when a concurrent
+	// update, e.g. an admin edit, changed them in the meantime
+	var paidPayment *models.LoanPayment
+	var paidEvents []models.OutboxEvent
+	err := retryOnConflict(func() error {
+		paidPayment = nil
+		paidEvents = nil
+		return s.loanRepo.WithTransaction(ctx, func(ctx context.Context) error {
+			// 1.Find loan payment with ID, locked so a webhook delivered twice at once is processed once
+			loanPayment, err := s.loanPaymentRepo.FindByIDForUpdate(ctx, request.ExternalID, []string{})
+			if err != nil {
+				return err
+			}
+			if loanPayment.Status == models.LoanPaymentStatusPaid {
+				return nil
 			}
-		}
-
-		// 5. Update Status of Loan Schedules
-		err = s.loanScheduleRepo.UpdateStatusByIDs(ctx, tx, loanPayment.LoanScheduleIDs, models.LoanScheduleStatusPaid)
-		if err != nil {
-			return err
-		}
-
-		// 6. Update Status of Loan if no more outstanding repayment amount
-		if totalPaidRepaymentAmount+loanPayment.TotalPayment >= helpers.GetTotalRepaymentAmount(loan) {
-			loan.Status = models.LoanStatusPaid
-			err = s.loanRepo.Update(ctx, tx, loan)
+			paidPayment = loanPayment
+
+			// 2. Update Status on Loan Payment
+			paidAt := time.Now()
+			loanPayment.Status = models.LoanPaymentStatusPaid
+			loanPayment.PaidAt = &paidAt
+			err = s.loanPaymentRepo.Update(ctx, loanPayment)
 			if err != nil {
 				return err
 			}
-		}
 
-		return nil
+			// 3. Find Loan Detail, locked so the payments of the same loan are counted one after the other
+			loan, err := s.loanRepo.FindByIDForUpdate(ctx, loanPayment.LoanID, []string{"LoanSchedules"})
+			if err != nil {
+				return err
+			}
+
+			// 4. Calculate total paid repayment amount
+			var totalPaidRepaymentAmount float64
+			for _, loanSchedule := range loan.LoanSchedules {
+				if loanSchedule.Status == models.LoanScheduleStatusPaid {
+					totalPaidRepaymentAmount += loanSchedule.TotalPayment
+				}
+			}
+
+			// 5. Update Status of Loan Schedules
+			err = s.loanScheduleRepo.UpdateStatusByIDs(ctx, loanPayment.LoanScheduleIDs, models.LoanScheduleStatusPaid)
+			if err != nil {
+				return err
+			}
+
+			paymentEvent, err := events.NewOutboxEvent(models.EventTypeLoanPaymentPaid, "loan", loan.ID, models.LoanPaymentPaidEvent{
+				LoanPaymentID:   loanPayment.ID,
+				LoanID:          loan.ID,
+				BorrowerID:      loan.BorrowerID,
+				LoanScheduleIDs: loanPayment.LoanScheduleIDs,
+				TotalPayment:    loanPayment.TotalPayment,
+				PaymentMethod:   loanPayment.PaymentMethod,
+				PaidAt:          paidAt,
+			})
+			if err != nil {
+				return err
+			}
+			outboxEvents := []models.OutboxEvent{paymentEvent}
+
+			// 6. Update Status of Loan if no more outstanding repayment amount
+			if totalPaidRepaymentAmount+loanPayment.TotalPayment >= helpers.GetTotalRepaymentAmount(loan) {
+				loan.Status = models.LoanStatusPaid
+				err = s.loanRepo.Update(ctx, loan)
+				if err != nil {
+					return err
+				}
+
+				fullyPaidEvent, err := events.NewOutboxEvent(models.EventTypeLoanFullyPaid, "loan", loan.ID, models.LoanFullyPaidEvent{
+					LoanID:     loan.ID,
+					BorrowerID: loan.BorrowerID,
+					PaidAt:     paidAt,
+				})
+				if err != nil {
+					return err
+				}
+				outboxEvents = append(outboxEvents, fullyPaidEvent)
+			}
+
+			// 7. Store the events with the payment, the outbox relay publishes them once committed
+			paidEvents = outboxEvents
+			return s.outboxRepo.Append(ctx, outboxEvents)
+		})
 	})
+	if err != nil {
+		return err
+	}
 
-	return err
+	if paidPayment != nil {
+		metrics.RecordPaymentProcessed(paidPayment.PaymentMethod, models.LoanPaymentStatusPaid)
+		s.responses.Invalidate(ctx, paidEvents)
+	}
+	return nil
 }
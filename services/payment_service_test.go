@@ -2,23 +2,32 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/satryarangga/amartha-loan-engine/cache"
+	"github.com/satryarangga/amartha-loan-engine/helpers"
+	"github.com/satryarangga/amartha-loan-engine/metrics"
 	"github.com/satryarangga/amartha-loan-engine/mock"
 	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/satryarangga/amartha-loan-engine/repositories"
 	"github.com/stretchr/testify/assert"
 	testifymock "github.com/stretchr/testify/mock"
 	"gorm.io/gorm"
 )
 
+const testPaymentLinkBaseURL = "https://pay.example.com/checkout"
+
 func TestNewPaymentService(t *testing.T) {
 	mockLoanRepo := mock.NewLoanRepository(t)
 	mockLoanPaymentRepo := mock.NewLoanPaymentRepository(t)
 	mockLoanScheduleRepo := mock.NewLoanScheduleRepository(t)
 	mockBorrowerRepo := mock.NewBorrowerRepository(t)
 
-	service := NewPaymentService(mockLoanRepo, mockLoanPaymentRepo, mockLoanScheduleRepo, mockBorrowerRepo)
+	service := NewPaymentService(mockLoanRepo, mockLoanPaymentRepo, mockLoanScheduleRepo, mockBorrowerRepo, mock.NewOutboxRepository(t), newTestResponses(), testPaymentLinkBaseURL)
 
 	assert.NotNil(t, service)
 	assert.Equal(t, mockLoanRepo, service.loanRepo)
@@ -33,7 +42,7 @@ func TestPaymentServiceImpl_GeneratePaymentLink_Success(t *testing.T) {
 	mockLoanPaymentRepo := mock.NewLoanPaymentRepository(t)
 	mockLoanScheduleRepo := mock.NewLoanScheduleRepository(t)
 	mockBorrowerRepo := mock.NewBorrowerRepository(t)
-	service := NewPaymentService(mockLoanRepo, mockLoanPaymentRepo, mockLoanScheduleRepo, mockBorrowerRepo)
+	service := NewPaymentService(mockLoanRepo, mockLoanPaymentRepo, mockLoanScheduleRepo, mockBorrowerRepo, mock.NewOutboxRepository(t), newTestResponses(), testPaymentLinkBaseURL)
 
 	ctx := context.Background()
 	request := models.PaymentLinkRequest{
@@ -72,8 +81,8 @@ func TestPaymentServiceImpl_GeneratePaymentLink_Success(t *testing.T) {
 
 	mockBorrowerRepo.On("FindByID", ctx, "borrower-id", []string{}).Return(borrower, nil)
 	mockLoanRepo.On("FindOneByBorrowerID", ctx, "borrower-id").Return(loan, nil)
-	mockLoanScheduleRepo.On("FindDueRepaymentSchedules", ctx, "loan-id").Return(loanSchedules, nil)
-	mockLoanPaymentRepo.On("Insert", ctx, (*gorm.DB)(nil), testifymock.AnythingOfType("*models.LoanPayment")).Return(paymentID, nil)
+	mockLoanScheduleRepo.On("FindDueRepaymentSchedules", ctx, "loan-id", testifymock.AnythingOfType("time.Time")).Return(loanSchedules, nil)
+	mockLoanPaymentRepo.On("Insert", ctx, testifymock.AnythingOfType("*models.LoanPayment")).Return(paymentID, nil)
 
 	// Act
 	result, err := service.GeneratePaymentLink(ctx, request)
@@ -83,7 +92,7 @@ func TestPaymentServiceImpl_GeneratePaymentLink_Success(t *testing.T) {
 	assert.NotNil(t, result)
 	assert.Equal(t, paymentID, result.ID)
 	assert.Equal(t, 220000.0, result.TotalRepaymentAmount) // 110000 * 2
-	assert.Contains(t, result.PaymentLink, paymentID)
+	assert.Equal(t, testPaymentLinkBaseURL+"?external_id="+paymentID, result.PaymentLink)
 	mockBorrowerRepo.AssertExpectations(t)
 	mockLoanRepo.AssertExpectations(t)
 	mockLoanScheduleRepo.AssertExpectations(t)
@@ -96,7 +105,7 @@ func TestPaymentServiceImpl_GeneratePaymentLink_BorrowerNotFound(t *testing.T) {
 	mockLoanPaymentRepo := mock.NewLoanPaymentRepository(t)
 	mockLoanScheduleRepo := mock.NewLoanScheduleRepository(t)
 	mockBorrowerRepo := mock.NewBorrowerRepository(t)
-	service := NewPaymentService(mockLoanRepo, mockLoanPaymentRepo, mockLoanScheduleRepo, mockBorrowerRepo)
+	service := NewPaymentService(mockLoanRepo, mockLoanPaymentRepo, mockLoanScheduleRepo, mockBorrowerRepo, mock.NewOutboxRepository(t), newTestResponses(), testPaymentLinkBaseURL)
 
 	ctx := context.Background()
 	request := models.PaymentLinkRequest{
@@ -123,7 +132,7 @@ func TestPaymentServiceImpl_GeneratePaymentLink_LoanNotFound(t *testing.T) {
 	mockLoanPaymentRepo := mock.NewLoanPaymentRepository(t)
 	mockLoanScheduleRepo := mock.NewLoanScheduleRepository(t)
 	mockBorrowerRepo := mock.NewBorrowerRepository(t)
-	service := NewPaymentService(mockLoanRepo, mockLoanPaymentRepo, mockLoanScheduleRepo, mockBorrowerRepo)
+	service := NewPaymentService(mockLoanRepo, mockLoanPaymentRepo, mockLoanScheduleRepo, mockBorrowerRepo, mock.NewOutboxRepository(t), newTestResponses(), testPaymentLinkBaseURL)
 
 	ctx := context.Background()
 	request := models.PaymentLinkRequest{
@@ -159,7 +168,7 @@ func TestPaymentServiceImpl_GeneratePaymentLink_NoSchedules(t *testing.T) {
 	mockLoanPaymentRepo := mock.NewLoanPaymentRepository(t)
 	mockLoanScheduleRepo := mock.NewLoanScheduleRepository(t)
 	mockBorrowerRepo := mock.NewBorrowerRepository(t)
-	service := NewPaymentService(mockLoanRepo, mockLoanPaymentRepo, mockLoanScheduleRepo, mockBorrowerRepo)
+	service := NewPaymentService(mockLoanRepo, mockLoanPaymentRepo, mockLoanScheduleRepo, mockBorrowerRepo, mock.NewOutboxRepository(t), newTestResponses(), testPaymentLinkBaseURL)
 
 	ctx := context.Background()
 	request := models.PaymentLinkRequest{
@@ -181,7 +190,7 @@ func TestPaymentServiceImpl_GeneratePaymentLink_NoSchedules(t *testing.T) {
 
 	mockBorrowerRepo.On("FindByID", ctx, "borrower-id", []string{}).Return(borrower, nil)
 	mockLoanRepo.On("FindOneByBorrowerID", ctx, "borrower-id").Return(loan, nil)
-	mockLoanScheduleRepo.On("FindDueRepaymentSchedules", ctx, "loan-id").Return([]models.LoanSchedule{}, nil)
+	mockLoanScheduleRepo.On("FindDueRepaymentSchedules", ctx, "loan-id", testifymock.AnythingOfType("time.Time")).Return([]models.LoanSchedule{}, nil)
 
 	// Act
 	result, err := service.GeneratePaymentLink(ctx, request)
@@ -201,7 +210,7 @@ func TestPaymentServiceImpl_HandlePaymentWebhook_Success(t *testing.T) {
 	mockLoanPaymentRepo := mock.NewLoanPaymentRepository(t)
 	mockLoanScheduleRepo := mock.NewLoanScheduleRepository(t)
 	mockBorrowerRepo := mock.NewBorrowerRepository(t)
-	service := NewPaymentService(mockLoanRepo, mockLoanPaymentRepo, mockLoanScheduleRepo, mockBorrowerRepo)
+	service := NewPaymentService(mockLoanRepo, mockLoanPaymentRepo, mockLoanScheduleRepo, mockBorrowerRepo, mock.NewOutboxRepository(t), newTestResponses(), testPaymentLinkBaseURL)
 
 	ctx := context.Background()
 	request := models.PaymentWebhookRequest{
@@ -225,7 +234,7 @@ func TestPaymentServiceImpl_HandlePaymentWebhook_NotPaidStatus(t *testing.T) {
 	mockLoanPaymentRepo := mock.NewLoanPaymentRepository(t)
 	mockLoanScheduleRepo := mock.NewLoanScheduleRepository(t)
 	mockBorrowerRepo := mock.NewBorrowerRepository(t)
-	service := NewPaymentService(mockLoanRepo, mockLoanPaymentRepo, mockLoanScheduleRepo, mockBorrowerRepo)
+	service := NewPaymentService(mockLoanRepo, mockLoanPaymentRepo, mockLoanScheduleRepo, mockBorrowerRepo, mock.NewOutboxRepository(t), newTestResponses(), testPaymentLinkBaseURL)
 
 	ctx := context.Background()
 	request := models.PaymentWebhookRequest{
@@ -247,7 +256,7 @@ func TestPaymentServiceImpl_HandlePaymentWebhook_PaymentNotFound(t *testing.T) {
 	mockLoanPaymentRepo := mock.NewLoanPaymentRepository(t)
 	mockLoanScheduleRepo := mock.NewLoanScheduleRepository(t)
 	mockBorrowerRepo := mock.NewBorrowerRepository(t)
-	service := NewPaymentService(mockLoanRepo, mockLoanPaymentRepo, mockLoanScheduleRepo, mockBorrowerRepo)
+	service := NewPaymentService(mockLoanRepo, mockLoanPaymentRepo, mockLoanScheduleRepo, mockBorrowerRepo, mock.NewOutboxRepository(t), newTestResponses(), testPaymentLinkBaseURL)
 
 	ctx := context.Background()
 	request := models.PaymentWebhookRequest{
@@ -266,3 +275,179 @@ func TestPaymentServiceImpl_HandlePaymentWebhook_PaymentNotFound(t *testing.T) {
 	assert.Equal(t, expectedError, err)
 	mockLoanRepo.AssertExpectations(t)
 }
+
+func TestPaymentServiceImpl_HandlePaymentWebhook_AlreadyPaid(t *testing.T) {
+	// Arrange
+	mockLoanRepo := mock.NewLoanRepository(t)
+	mockLoanPaymentRepo := mock.NewLoanPaymentRepository(t)
+	mockLoanScheduleRepo := mock.NewLoanScheduleRepository(t)
+	mockBorrowerRepo := mock.NewBorrowerRepository(t)
+	service := NewPaymentService(mockLoanRepo, mockLoanPaymentRepo, mockLoanScheduleRepo, mockBorrowerRepo, mock.NewOutboxRepository(t), newTestResponses(), testPaymentLinkBaseURL)
+
+	ctx := context.Background()
+	request := models.PaymentWebhookRequest{
+		ExternalID:    "payment-id",
+		PaymentStatus: "paid",
+	}
+	loanPayment := &models.LoanPayment{ID: "payment-id", LoanID: "loan-id", Status: models.LoanPaymentStatusPaid}
+
+	mockLoanRepo.On("WithTransaction", ctx, testifymock.AnythingOfType("repositories.TransactionFunc")).
+		Return(func(ctx context.Context, fn repositories.TransactionFunc) error {
+			return fn(ctx)
+		})
+	mockLoanPaymentRepo.On("FindByIDForUpdate", ctx, "payment-id", []string{}).Return(loanPayment, nil)
+
+	// Act
+	err := service.HandlePaymentWebhook(ctx, request)
+
+	// Assert
+	// a webhook delivered again leaves the payment and its loan untouched
+	assert.NoError(t, err)
+	mockLoanPaymentRepo.AssertNotCalled(t, "Update", testifymock.Anything, testifymock.Anything)
+	mockLoanRepo.AssertNotCalled(t, "FindByIDForUpdate", testifymock.Anything, testifymock.Anything, testifymock.Anything)
+}
+
+func TestPaymentServiceImpl_HandlePaymentWebhook_RetriesVersionConflict(t *testing.T) {
+	// Arrange
+	mockLoanRepo := mock.NewLoanRepository(t)
+	mockLoanPaymentRepo := mock.NewLoanPaymentRepository(t)
+	mockLoanScheduleRepo := mock.NewLoanScheduleRepository(t)
+	mockBorrowerRepo := mock.NewBorrowerRepository(t)
+	service := NewPaymentService(mockLoanRepo, mockLoanPaymentRepo, mockLoanScheduleRepo, mockBorrowerRepo, mock.NewOutboxRepository(t), newTestResponses(), testPaymentLinkBaseURL)
+
+	ctx := context.Background()
+	request := models.PaymentWebhookRequest{
+		ExternalID:    "payment-id",
+		PaymentStatus: "paid",
+	}
+	conflictErr := &repositories.VersionConflictError{Table: "loans", ID: "loan-id", Version: 3}
+
+	mockLoanRepo.On("WithTransaction", ctx, testifymock.AnythingOfType("repositories.TransactionFunc")).Return(conflictErr).Once()
+	mockLoanRepo.On("WithTransaction", ctx, testifymock.AnythingOfType("repositories.TransactionFunc")).Return(nil).Once()
+
+	// Act
+	err := service.HandlePaymentWebhook(ctx, request)
+
+	// Assert
+	assert.NoError(t, err)
+	mockLoanRepo.AssertNumberOfCalls(t, "WithTransaction", 2)
+}
+
+func TestPaymentServiceImpl_HandlePaymentWebhook_VersionConflictPersists(t *testing.T) {
+	// Arrange
+	mockLoanRepo := mock.NewLoanRepository(t)
+	mockLoanPaymentRepo := mock.NewLoanPaymentRepository(t)
+	mockLoanScheduleRepo := mock.NewLoanScheduleRepository(t)
+	mockBorrowerRepo := mock.NewBorrowerRepository(t)
+	service := NewPaymentService(mockLoanRepo, mockLoanPaymentRepo, mockLoanScheduleRepo, mockBorrowerRepo, mock.NewOutboxRepository(t), newTestResponses(), testPaymentLinkBaseURL)
+
+	ctx := context.Background()
+	request := models.PaymentWebhookRequest{
+		ExternalID:    "payment-id",
+		PaymentStatus: "paid",
+	}
+	conflictErr := &repositories.VersionConflictError{Table: "loans", ID: "loan-id", Version: 3}
+
+	mockLoanRepo.On("WithTransaction", ctx, testifymock.AnythingOfType("repositories.TransactionFunc")).Return(conflictErr)
+
+	// Act
+	err := service.HandlePaymentWebhook(ctx, request)
+
+	// Assert
+	assert.ErrorIs(t, err, conflictErr)
+	mockLoanRepo.AssertNumberOfCalls(t, "WithTransaction", maxConflictAttempts)
+}
+
+func TestPaymentServiceImpl_GeneratePaymentLink_OtherBorrower(t *testing.T) {
+	// Arrange
+	mockLoanRepo := mock.NewLoanRepository(t)
+	mockLoanPaymentRepo := mock.NewLoanPaymentRepository(t)
+	mockLoanScheduleRepo := mock.NewLoanScheduleRepository(t)
+	mockBorrowerRepo := mock.NewBorrowerRepository(t)
+	service := NewPaymentService(mockLoanRepo, mockLoanPaymentRepo, mockLoanScheduleRepo, mockBorrowerRepo, mock.NewOutboxRepository(t), newTestResponses(), testPaymentLinkBaseURL)
+
+	ctx := helpers.WithPrincipal(context.Background(), models.Principal{Role: models.RoleBorrower, BorrowerID: "borrower-id"})
+
+	// Act
+	result, err := service.GeneratePaymentLink(ctx, models.PaymentLinkRequest{BorrowerID: "other-borrower-id", PaymentMethod: "bank_transfer"})
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestPaymentServiceImpl_HandlePaymentWebhook_AppendsPaymentEvents(t *testing.T) {
+	// Arrange
+	mockLoanRepo := mock.NewLoanRepository(t)
+	mockLoanPaymentRepo := mock.NewLoanPaymentRepository(t)
+	mockLoanScheduleRepo := mock.NewLoanScheduleRepository(t)
+	mockBorrowerRepo := mock.NewBorrowerRepository(t)
+	mockOutboxRepo := mock.NewOutboxRepository(t)
+	lruCache, err := cache.NewLRUCache(10)
+	assert.NoError(t, err)
+	service := NewPaymentService(mockLoanRepo, mockLoanPaymentRepo, mockLoanScheduleRepo, mockBorrowerRepo, mockOutboxRepo, cache.NewResponses(lruCache, time.Minute), testPaymentLinkBaseURL)
+
+	ctx := context.Background()
+	loanKey, _ := cache.LoanKey("loan-id", []models.LoanInclude{models.LoanIncludeSchedules})
+	_ = lruCache.Set(ctx, loanKey, []byte(`{"id":"loan-id"}`), time.Minute)
+	_ = lruCache.Set(ctx, cache.BorrowerKey("borrower-id"), []byte(`{"id":"borrower-id"}`), time.Minute)
+	request := models.PaymentWebhookRequest{
+		ExternalID:    "payment-id",
+		PaymentStatus: "paid",
+	}
+	loanPayment := &models.LoanPayment{
+		ID:              "payment-id",
+		LoanID:          "loan-id",
+		LoanScheduleIDs: []string{"schedule-2"},
+		TotalPayment:    550,
+		PaymentMethod:   "bank_transfer",
+	}
+	loan := &models.Loan{
+		ID:             "loan-id",
+		BorrowerID:     "borrower-id",
+		Amount:         1000,
+		InterestAmount: 100,
+		Status:         models.LoanStatusActive,
+		LoanSchedules: []models.LoanSchedule{
+			{ID: "schedule-1", TotalPayment: 550, Status: models.LoanScheduleStatusPaid},
+			{ID: "schedule-2", TotalPayment: 550, Status: models.LoanScheduleStatusPending},
+		},
+	}
+	var appendedEvents []models.OutboxEvent
+
+	mockLoanRepo.On("WithTransaction", ctx, testifymock.AnythingOfType("repositories.TransactionFunc")).
+		Return(func(ctx context.Context, fn repositories.TransactionFunc) error {
+			return fn(ctx)
+		})
+	mockLoanPaymentRepo.On("FindByIDForUpdate", ctx, "payment-id", []string{}).Return(loanPayment, nil)
+	mockLoanPaymentRepo.On("Update", ctx, loanPayment).Return(nil)
+	mockLoanRepo.On("FindByIDForUpdate", ctx, "loan-id", []string{"LoanSchedules"}).Return(loan, nil)
+	mockLoanScheduleRepo.On("UpdateStatusByIDs", ctx, []string{"schedule-2"}, models.LoanScheduleStatusPaid).Return(nil)
+	mockLoanRepo.On("Update", ctx, loan).Return(nil)
+	mockOutboxRepo.On("Append", ctx, testifymock.AnythingOfType("[]models.OutboxEvent")).
+		Run(func(args testifymock.Arguments) {
+			appendedEvents = args.Get(1).([]models.OutboxEvent)
+		}).
+		Return(nil)
+	paymentsPaid := testutil.ToFloat64(metrics.PaymentsProcessed.WithLabelValues("bank_transfer", "paid"))
+
+	// Act
+	err = service.HandlePaymentWebhook(ctx, request)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, paymentsPaid+1, testutil.ToFloat64(metrics.PaymentsProcessed.WithLabelValues("bank_transfer", "paid")))
+	assert.Equal(t, models.LoanPaymentStatusPaid, loanPayment.Status)
+	assert.NotNil(t, loanPayment.PaidAt)
+	assert.Equal(t, models.LoanStatusPaid, loan.Status)
+	assert.Len(t, appendedEvents, 2)
+	var paidEvent models.LoanPaymentPaidEvent
+	assert.NoError(t, json.Unmarshal(appendedEvents[0].Payload, &paidEvent))
+	assert.True(t, paidEvent.PaidAt.Equal(*loanPayment.PaidAt))
+	assert.Equal(t, models.EventTypeLoanPaymentPaid, appendedEvents[0].EventType)
+	assert.Contains(t, string(appendedEvents[0].Payload), `"loan_payment_id":"payment-id"`)
+	assert.Equal(t, models.EventTypeLoanFullyPaid, appendedEvents[1].EventType)
+	assert.Equal(t, "loan-id", appendedEvents[1].AggregateID)
+	// the cached responses of the loan and of its borrower are evicted once committed
+	assert.Equal(t, 0, lruCache.Len())
+}
@@ -0,0 +1,11 @@
+package services
+
+import (
+	"context"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+)
+
+type StatementService interface {
+	GetBorrowerStatement(ctx context.Context, borrowerID string, request models.StatementRequest) (*models.StatementResponse, error)
+}
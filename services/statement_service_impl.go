@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/satryarangga/amartha-loan-engine/helpers"
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/satryarangga/amartha-loan-engine/repositories"
+	"gorm.io/gorm"
+)
+
+type StatementServiceImpl struct {
+	borrowerRepo repositories.BorrowerRepository
+	loanRepo     repositories.LoanRepository
+}
+
+func NewStatementService(borrowerRepo repositories.BorrowerRepository, loanRepo repositories.LoanRepository) *StatementServiceImpl {
+	return &StatementServiceImpl{
+		borrowerRepo: borrowerRepo,
+		loanRepo:     loanRepo,
+	}
+}
+
+func (s *StatementServiceImpl) GetBorrowerStatement(ctx context.Context, borrowerID string, request models.StatementRequest) (*models.StatementResponse, error) {
+	now := time.Now()
+
+	var from *time.Time
+	if request.From != "" {
+		parsedFrom, err := time.ParseInLocation(time.DateOnly, request.From, now.Location())
+		if err != nil {
+			return nil, NewValidationError("invalid_date", "from must use YYYY-MM-DD format")
+		}
+		from = &parsedFrom
+	}
+
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if request.To != "" {
+		parsedTo, err := time.ParseInLocation(time.DateOnly, request.To, now.Location())
+		if err != nil {
+			return nil, NewValidationError("invalid_date", "to must use YYYY-MM-DD format")
+		}
+		to = parsedTo
+	}
+
+	if from != nil && from.After(to) {
+		return nil, NewValidationError("invalid_period", "from must not be after to")
+	}
+
+	if !helpers.CanAccessBorrower(ctx, borrowerID) {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	borrower, err := s.borrowerRepo.FindByID(ctx, borrowerID, []string{})
+	if err != nil {
+		return nil, err
+	}
+
+	loans, err := s.loanRepo.FindAll(ctx, models.FindAllParam{
+		Filters:       map[string]interface{}{"borrower_id": borrower.ID},
+		PreloadTables: []string{"LoanSchedules", "LoanPayments"},
+		SortBy:        models.SortBy{FieldName: "disbursed_at", Direction: models.SortDirectAscending},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.StatementResponse{
+		Borrower: models.BorrowerResponse{
+			ID:          borrower.ID,
+			FirstName:   borrower.FirstName,
+			LastName:    borrower.LastName,
+			PhoneNumber: borrower.PhoneNumber,
+			KYCStatus:   borrower.KYCStatus,
+		},
+		From:        from,
+		To:          to,
+		GeneratedAt: now,
+		Loans:       []models.LoanStatementResponse{},
+	}
+
+	// the period includes the whole "to" day
+	periodEnd := to.AddDate(0, 0, 1)
+	for _, loan := range loans {
+		if loan.Status == models.LoanStatusActive {
+			response.Borrower.IsDelinquent = helpers.IsBorrowerDelinquent(loan.LoanSchedules)
+		}
+
+		loanStatement, hasActivity := helpers.BuildLoanStatement(loan, from, periodEnd)
+		if !hasActivity {
+			continue
+		}
+		response.Loans = append(response.Loans, loanStatement)
+	}
+
+	return response, nil
+}
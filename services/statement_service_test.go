@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/satryarangga/amartha-loan-engine/mock"
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatementServiceImpl_GetBorrowerStatement_Success(t *testing.T) {
+	// Arrange
+	mockBorrowerRepo := mock.NewBorrowerRepository(t)
+	mockLoanRepo := mock.NewLoanRepository(t)
+	service := NewStatementService(mockBorrowerRepo, mockLoanRepo)
+
+	ctx := context.Background()
+	borrower := &models.Borrower{ID: "borrower-id", FirstName: "John", LastName: "Doe", KYCStatus: models.KYCStatusVerified}
+	loans := []models.Loan{
+		{
+			ID:          "old-loan-id",
+			Amount:      100000,
+			Status:      models.LoanStatusPaid,
+			DisbursedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local),
+			LoanPayments: []models.LoanPayment{
+				{ID: "payment-1", TotalPayment: 100000, Status: models.LoanPaymentStatusPaid, UpdatedAt: time.Date(2024, 2, 1, 0, 0, 0, 0, time.Local)},
+			},
+		},
+		{
+			ID:             "loan-id",
+			Amount:         200000,
+			InterestAmount: 20000,
+			Status:         models.LoanStatusActive,
+			DisbursedAt:    time.Date(2025, 1, 10, 9, 0, 0, 0, time.Local),
+		},
+	}
+
+	mockBorrowerRepo.On("FindByID", ctx, "borrower-id", []string{}).Return(borrower, nil)
+	mockLoanRepo.On("FindAll", ctx, models.FindAllParam{
+		Filters:       map[string]interface{}{"borrower_id": "borrower-id"},
+		PreloadTables: []string{"LoanSchedules", "LoanPayments"},
+		SortBy:        models.SortBy{FieldName: "disbursed_at", Direction: models.SortDirectAscending},
+	}).Return(loans, nil)
+
+	// Act
+	result, err := service.GetBorrowerStatement(ctx, "borrower-id", models.StatementRequest{From: "2025-01-01", To: "2025-01-31"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "borrower-id", result.Borrower.ID)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local), *result.From)
+	assert.Equal(t, time.Date(2025, 1, 31, 0, 0, 0, 0, time.Local), result.To)
+	assert.Len(t, result.Loans, 1) // the old loan was repaid before the period
+	assert.Equal(t, "loan-id", result.Loans[0].LoanID)
+	assert.Equal(t, 220000.0, result.Loans[0].ClosingBalance)
+}
+
+func TestStatementServiceImpl_GetBorrowerStatement_InvalidPeriod(t *testing.T) {
+	// Arrange
+	mockBorrowerRepo := mock.NewBorrowerRepository(t)
+	mockLoanRepo := mock.NewLoanRepository(t)
+	service := NewStatementService(mockBorrowerRepo, mockLoanRepo)
+
+	ctx := context.Background()
+
+	// Act
+	_, invalidFromErr := service.GetBorrowerStatement(ctx, "borrower-id", models.StatementRequest{From: "01-01-2025"})
+	_, reversedErr := service.GetBorrowerStatement(ctx, "borrower-id", models.StatementRequest{From: "2025-02-01", To: "2025-01-01"})
+
+	// Assert
+	assert.EqualError(t, invalidFromErr, "from must use YYYY-MM-DD format")
+	assert.EqualError(t, reversedErr, "from must not be after to")
+}
+
+func TestStatementServiceImpl_GetBorrowerStatement_BorrowerNotFound(t *testing.T) {
+	// Arrange
+	mockBorrowerRepo := mock.NewBorrowerRepository(t)
+	mockLoanRepo := mock.NewLoanRepository(t)
+	service := NewStatementService(mockBorrowerRepo, mockLoanRepo)
+
+	ctx := context.Background()
+	expectedError := errors.New("borrower not found")
+	mockBorrowerRepo.On("FindByID", ctx, "borrower-id", []string{}).Return(nil, expectedError)
+
+	// Act
+	result, err := service.GetBorrowerStatement(ctx, "borrower-id", models.StatementRequest{})
+
+	// Assert
+	assert.Nil(t, result)
+	assert.Equal(t, expectedError, err)
+}
@@ -0,0 +1,19 @@
+package services
+
+import (
+	"context"
+
+	"github.com/satryarangga/amartha-loan-engine/models"
+)
+
+type WebhookService interface {
+	ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error)
+	CreateSubscription(ctx context.Context, request models.WebhookSubscriptionRequest) (*models.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id string) error
+	GrantBorrower(ctx context.Context, partnerID string, borrowerID string) (*models.PartnerBorrower, error)
+	RevokeBorrower(ctx context.Context, partnerID string, borrowerID string) error
+	ListDeliveries(ctx context.Context, subscriptionID string, request models.WebhookDeliveryListRequest) (*models.WebhookDeliveryListResponse, error)
+	RedeliverDelivery(ctx context.Context, id string) (*models.WebhookDelivery, error)
+	EnqueueDeliveries(ctx context.Context, event models.OutboxEvent) error
+	DeliverPending(ctx context.Context, limit int) (int, error)
+}
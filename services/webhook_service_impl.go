@@ -0,0 +1,363 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/satryarangga/amartha-loan-engine/helpers"
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/satryarangga/amartha-loan-engine/repositories"
+)
+
+const (
+	webhookRetryBaseDelay = 30 * time.Second
+	webhookRetryMaxDelay  = 6 * time.Hour
+	// added to the time the claimed deliveries may take to send, before another dispatcher takes them over
+	webhookLeaseMargin = time.Minute
+	// only the beginning of the partner response is kept in the delivery log
+	webhookResponseBodyLimit = 1024
+)
+
+type WebhookServiceImpl struct {
+	subscriptionRepo repositories.WebhookSubscriptionRepository
+	deliveryRepo     repositories.WebhookDeliveryRepository
+	borrowerRepo     repositories.BorrowerRepository
+	httpClient       *http.Client
+	maxAttempts      int
+	// lookupIP resolves the host of the subscriptions, replaced in the tests
+	lookupIP func(ctx context.Context, network string, host string) ([]net.IP, error)
+}
+
+func NewWebhookService(
+	subscriptionRepo repositories.WebhookSubscriptionRepository,
+	deliveryRepo repositories.WebhookDeliveryRepository,
+	borrowerRepo repositories.BorrowerRepository,
+	httpClient *http.Client,
+	maxAttempts int,
+) *WebhookServiceImpl {
+	return &WebhookServiceImpl{
+		subscriptionRepo: subscriptionRepo,
+		deliveryRepo:     deliveryRepo,
+		borrowerRepo:     borrowerRepo,
+		httpClient:       httpClient,
+		maxAttempts:      maxAttempts,
+		lookupIP:         net.DefaultResolver.LookupIP,
+	}
+}
+
+func (s *WebhookServiceImpl) ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	return s.subscriptionRepo.FindAll(ctx, models.FindAllParam{
+		SortBy: models.SortBy{FieldName: "created_at", Direction: models.SortDirectDescending},
+	})
+}
+
+func (s *WebhookServiceImpl) CreateSubscription(ctx context.Context, request models.WebhookSubscriptionRequest) (*models.WebhookSubscription, error) {
+	endpoint, err := url.Parse(request.URL)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, NewValidationError("invalid_webhook_url", "invalid webhook URL %q", request.URL)
+	}
+	if err := s.checkPublicHost(ctx, endpoint.Hostname()); err != nil {
+		return nil, err
+	}
+
+	eventTypes := make(pq.StringArray, 0, len(request.EventTypes))
+	for _, eventType := range request.EventTypes {
+		if !slices.Contains(models.EventTypes, eventType) {
+			return nil, NewValidationError("invalid_event_type", "unknown event type %q", eventType)
+		}
+		eventTypes = append(eventTypes, string(eventType))
+	}
+
+	subscription := models.WebhookSubscription{
+		PartnerID:  request.PartnerID,
+		Name:       request.Name,
+		URL:        endpoint.String(),
+		Secret:     request.Secret,
+		EventTypes: eventTypes,
+		IsActive:   true,
+	}
+	if _, err := s.subscriptionRepo.Insert(ctx, &subscription); err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// checkPublicHost refuses a host resolving to an internal address, the webhooks would let the caller
+// reach the internal network. The HTTP client checks the address again when sending.
+func (s *WebhookServiceImpl) checkPublicHost(ctx context.Context, host string) error {
+	ips, err := s.lookupIP(ctx, "ip", host)
+	if err != nil || len(ips) == 0 {
+		return NewValidationError("invalid_webhook_url", "cannot resolve the webhook host %q", host)
+	}
+	for _, ip := range ips {
+		if !helpers.IsPublicIP(ip) {
+			return NewValidationError("invalid_webhook_url", "webhook host %q resolves to the non public address %s", host, ip)
+		}
+	}
+	return nil
+}
+
+// DeleteSubscription soft deletes the subscription, its pending deliveries are failed on their next attempt
+func (s *WebhookServiceImpl) DeleteSubscription(ctx context.Context, id string) error {
+	subscription, err := s.subscriptionRepo.FindByID(ctx, id, []string{})
+	if err != nil {
+		return err
+	}
+	return s.subscriptionRepo.Delete(ctx, subscription)
+}
+
+// GrantBorrower lets the subscriptions of the partner receive the events of the borrower
+func (s *WebhookServiceImpl) GrantBorrower(ctx context.Context, partnerID string, borrowerID string) (*models.PartnerBorrower, error) {
+	if _, err := s.borrowerRepo.FindByID(ctx, borrowerID, []string{}); err != nil {
+		return nil, err
+	}
+
+	grant := models.PartnerBorrower{PartnerID: partnerID, BorrowerID: borrowerID}
+	if err := s.subscriptionRepo.GrantBorrower(ctx, &grant); err != nil {
+		return nil, err
+	}
+	return &grant, nil
+}
+
+// RevokeBorrower stops the events of the borrower to the partner, the deliveries already enqueued are still sent
+func (s *WebhookServiceImpl) RevokeBorrower(ctx context.Context, partnerID string, borrowerID string) error {
+	return s.subscriptionRepo.RevokeBorrower(ctx, partnerID, borrowerID)
+}
+
+func (s *WebhookServiceImpl) ListDeliveries(ctx context.Context, subscriptionID string, request models.WebhookDeliveryListRequest) (*models.WebhookDeliveryListResponse, error) {
+	if _, err := s.subscriptionRepo.FindByID(ctx, subscriptionID, []string{}); err != nil {
+		return nil, err
+	}
+
+	page, limit := helpers.NormalizePage(request.Page, request.Limit)
+	filters := map[string]interface{}{"subscription_id": subscriptionID}
+	if request.Status != "" {
+		filters["status"] = request.Status
+	}
+
+	param := models.FindAllParam{
+		Limit:   limit,
+		Offset:  page,
+		Filters: filters,
+		SortBy:  models.SortBy{FieldName: "created_at", Direction: models.SortDirectDescending},
+	}
+
+	total, err := s.deliveryRepo.Count(ctx, param)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.deliveryRepo.FindAll(ctx, param)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.WebhookDeliveryListResponse{
+		Data:       deliveries,
+		Pagination: helpers.NewPagination(page, limit, total),
+	}, nil
+}
+
+// RedeliverDelivery sends the payload of a delivery again as a new delivery, the original one is left untouched
+func (s *WebhookServiceImpl) RedeliverDelivery(ctx context.Context, id string) (*models.WebhookDelivery, error) {
+	delivery, err := s.deliveryRepo.FindByID(ctx, id, []string{"Subscription"})
+	if err != nil {
+		return nil, err
+	}
+
+	if delivery.Subscription.ID == "" || !delivery.Subscription.IsActive {
+		return nil, NewStateTransitionError("webhook_subscription_inactive", "webhook subscription is deleted or inactive")
+	}
+
+	now := time.Now()
+	redeliveries := []models.WebhookDelivery{{
+		SubscriptionID: delivery.SubscriptionID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Payload:        delivery.Payload,
+		Status:         models.WebhookDeliveryStatusPending,
+		NextAttemptAt:  &now,
+		RedeliveryOf:   &delivery.ID,
+	}}
+	if err := s.deliveryRepo.Enqueue(ctx, redeliveries); err != nil {
+		return nil, err
+	}
+	return &redeliveries[0], nil
+}
+
+// EnqueueDeliveries creates a delivery for every active subscription of the event type whose partner
+// is granted the borrower of the event, an event without borrower isn't delivered to anyone. It is
+// called by the outbox relay, so it has to be idempotent as an event may be relayed more than once.
+func (s *WebhookServiceImpl) EnqueueDeliveries(ctx context.Context, event models.OutboxEvent) error {
+	subscriptions, err := s.subscriptionRepo.FindActiveByEventType(ctx, event.EventType)
+	if err != nil {
+		return err
+	}
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	var scope struct {
+		BorrowerID string `json:"borrower_id"`
+	}
+	if err := json.Unmarshal(event.Payload, &scope); err != nil {
+		return err
+	}
+	if scope.BorrowerID == "" {
+		return nil
+	}
+	partnerIDs, err := s.subscriptionRepo.FindPartnerIDsByBorrowerID(ctx, scope.BorrowerID)
+	if err != nil {
+		return err
+	}
+	subscriptions = slices.DeleteFunc(subscriptions, func(subscription models.WebhookSubscription) bool {
+		return !slices.Contains(partnerIDs, subscription.PartnerID)
+	})
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(models.WebhookEvent{
+		ID:            event.ID,
+		Type:          event.EventType,
+		AggregateType: event.AggregateType,
+		AggregateID:   event.AggregateID,
+		CreatedAt:     event.CreatedAt,
+		Data:          event.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	deliveries := make([]models.WebhookDelivery, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		deliveries = append(deliveries, models.WebhookDelivery{
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      event.EventType,
+			Payload:        payload,
+			Status:         models.WebhookDeliveryStatusPending,
+			NextAttemptAt:  &now,
+		})
+	}
+	return s.deliveryRepo.Enqueue(ctx, deliveries)
+}
+
+// DeliverPending attempts the deliveries that are due and returns how many were attempted. A failed
+// delivery is retried with an exponential backoff until it reaches the maximum number of attempts.
+// The deliveries are claimed in a short transaction leasing them to this dispatcher, then sent without
+// holding any lock, and each attempt is recorded on its own so a failure doesn't lose the others. A
+// dispatcher stopping before recording an attempt leaves the delivery to be retried once the lease ends.
+func (s *WebhookServiceImpl) DeliverPending(ctx context.Context, limit int) (int, error) {
+	deliveries, err := s.claimDue(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		s.attemptDelivery(ctx, delivery)
+		if err := s.deliveryRepo.RecordAttempt(ctx, delivery); err != nil {
+			errs = append(errs, fmt.Errorf("cannot record the attempt of webhook delivery %s: %w", delivery.ID, err))
+		}
+	}
+	return len(deliveries), errors.Join(errs...)
+}
+
+// claimDue locks the due deliveries and leases them long enough to send all of them one after the other
+func (s *WebhookServiceImpl) claimDue(ctx context.Context, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := s.deliveryRepo.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		deliveries, err = s.deliveryRepo.FindDueForUpdate(ctx, time.Now(), limit)
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]string, 0, len(deliveries))
+		for _, delivery := range deliveries {
+			ids = append(ids, delivery.ID)
+		}
+		lease := time.Duration(len(deliveries))*s.httpClient.Timeout + webhookLeaseMargin
+		return s.deliveryRepo.Lease(ctx, ids, time.Now().Add(lease))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (s *WebhookServiceImpl) attemptDelivery(ctx context.Context, delivery *models.WebhookDelivery) {
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastResponseStatus = nil
+	delivery.LastResponseBody = ""
+	delivery.LastError = ""
+
+	// a deleted subscription isn't preloaded
+	subscription := delivery.Subscription
+	if subscription.ID == "" || !subscription.IsActive {
+		delivery.Status = models.WebhookDeliveryStatusFailed
+		delivery.NextAttemptAt = nil
+		delivery.LastError = "webhook subscription is deleted or inactive"
+		return
+	}
+
+	statusCode, responseBody, err := s.send(ctx, subscription, delivery, now)
+	if err == nil {
+		delivery.LastResponseStatus = &statusCode
+		delivery.LastResponseBody = responseBody
+		if statusCode >= http.StatusOK && statusCode < http.StatusMultipleChoices {
+			delivery.Status = models.WebhookDeliveryStatusSucceeded
+			delivery.NextAttemptAt = nil
+			delivery.DeliveredAt = &now
+			return
+		}
+		err = fmt.Errorf("unexpected response status %d", statusCode)
+	}
+	delivery.LastError = err.Error()
+
+	if delivery.Attempts >= s.maxAttempts {
+		delivery.Status = models.WebhookDeliveryStatusFailed
+		delivery.NextAttemptAt = nil
+		return
+	}
+	nextAttemptAt := now.Add(helpers.ExponentialBackoff(webhookRetryBaseDelay, webhookRetryMaxDelay, delivery.Attempts))
+	delivery.NextAttemptAt = &nextAttemptAt
+}
+
+func (s *WebhookServiceImpl) send(ctx context.Context, subscription models.WebhookSubscription, delivery *models.WebhookDelivery, now time.Time) (int, string, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, "", err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "amartha-loan-engine-webhooks")
+	request.Header.Set("X-Webhook-ID", delivery.ID)
+	request.Header.Set("X-Webhook-Event", string(delivery.EventType))
+	request.Header.Set(helpers.WebhookSignatureHeader, helpers.SignWebhookPayload(subscription.Secret, now, delivery.Payload))
+
+	response, err := s.httpClient.Do(request)
+	if err != nil {
+		return 0, "", err
+	}
+	defer response.Body.Close()
+
+	responseBody, err := io.ReadAll(io.LimitReader(response.Body, webhookResponseBodyLimit))
+	if err != nil {
+		return 0, "", err
+	}
+	return response.StatusCode, string(responseBody), nil
+}
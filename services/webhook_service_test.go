@@ -0,0 +1,543 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/satryarangga/amartha-loan-engine/helpers"
+	"github.com/satryarangga/amartha-loan-engine/mock"
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/satryarangga/amartha-loan-engine/repositories"
+	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+const testWebhookSecret = "partner-secret-0123456789"
+
+func newTestWebhookService(t *testing.T) (*WebhookServiceImpl, *mock.WebhookSubscriptionRepository, *mock.WebhookDeliveryRepository) {
+	mockSubscriptionRepo := mock.NewWebhookSubscriptionRepository(t)
+	mockDeliveryRepo := mock.NewWebhookDeliveryRepository(t)
+	service := NewWebhookService(mockSubscriptionRepo, mockDeliveryRepo, mock.NewBorrowerRepository(t), &http.Client{Timeout: time.Second}, 3)
+	service.lookupIP = fakeLookupIP
+	return service, mockSubscriptionRepo, mockDeliveryRepo
+}
+
+// fakeLookupIP resolves the hosts of the tests without DNS, the IP literals resolve to themselves
+func fakeLookupIP(_ context.Context, _ string, host string) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}, nil
+	}
+	switch host {
+	case "partner.example.com":
+		return []net.IP{net.ParseIP("93.184.216.34")}, nil
+	case "internal.partner.example.com":
+		return []net.IP{net.ParseIP("93.184.216.34"), net.ParseIP("10.0.0.5")}, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+// deliverPendingWith runs DeliverPending on the given deliveries and returns them as recorded after the attempt
+func deliverPendingWith(t *testing.T, service *WebhookServiceImpl, mockDeliveryRepo *mock.WebhookDeliveryRepository, deliveries []models.WebhookDelivery) (int, []models.WebhookDelivery) {
+	ctx := context.Background()
+	var recorded []models.WebhookDelivery
+
+	mockDeliveryRepo.On("WithTransaction", ctx, testifymock.AnythingOfType("repositories.TransactionFunc")).
+		Return(func(ctx context.Context, fn repositories.TransactionFunc) error {
+			return fn(ctx)
+		})
+	mockDeliveryRepo.On("FindDueForUpdate", ctx, testifymock.AnythingOfType("time.Time"), 10).Return(deliveries, nil)
+	mockDeliveryRepo.On("Lease", ctx, testifymock.AnythingOfType("[]string"), testifymock.AnythingOfType("time.Time")).Return(nil)
+	mockDeliveryRepo.On("RecordAttempt", ctx, testifymock.AnythingOfType("*models.WebhookDelivery")).
+		Run(func(args testifymock.Arguments) {
+			recorded = append(recorded, *args.Get(1).(*models.WebhookDelivery))
+		}).
+		Return(nil)
+
+	attempted, err := service.DeliverPending(ctx, 10)
+	assert.NoError(t, err)
+	return attempted, recorded
+}
+
+func TestWebhookServiceImpl_CreateSubscription_Success(t *testing.T) {
+	// Arrange
+	service, mockSubscriptionRepo, _ := newTestWebhookService(t)
+	ctx := context.Background()
+	request := models.WebhookSubscriptionRequest{
+		PartnerID:  "partner-a",
+		Name:       "Partner",
+		URL:        "https://partner.example.com/webhooks",
+		Secret:     testWebhookSecret,
+		EventTypes: []models.EventType{models.EventTypeLoanCreated, models.EventTypeLoanFullyPaid},
+	}
+
+	mockSubscriptionRepo.On("Insert", ctx, testifymock.AnythingOfType("*models.WebhookSubscription")).Return("subscription-id", nil)
+
+	// Act
+	subscription, err := service.CreateSubscription(ctx, request)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "partner-a", subscription.PartnerID)
+	assert.Equal(t, "https://partner.example.com/webhooks", subscription.URL)
+	assert.Equal(t, testWebhookSecret, subscription.Secret)
+	assert.Equal(t, []string{"loan.created", "loan.fully_paid"}, []string(subscription.EventTypes))
+	assert.True(t, subscription.IsActive)
+}
+
+func TestWebhookServiceImpl_CreateSubscription_InvalidRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		request models.WebhookSubscriptionRequest
+		message string
+	}{
+		{
+			name:    "relative URL",
+			request: models.WebhookSubscriptionRequest{URL: "/webhooks", EventTypes: []models.EventType{models.EventTypeLoanCreated}},
+			message: `invalid webhook URL "/webhooks"`,
+		},
+		{
+			name:    "unsupported scheme",
+			request: models.WebhookSubscriptionRequest{URL: "ftp://partner.example.com", EventTypes: []models.EventType{models.EventTypeLoanCreated}},
+			message: `invalid webhook URL "ftp://partner.example.com"`,
+		},
+		{
+			name:    "loopback address",
+			request: models.WebhookSubscriptionRequest{URL: "http://127.0.0.1:8080/webhooks", EventTypes: []models.EventType{models.EventTypeLoanCreated}},
+			message: `webhook host "127.0.0.1" resolves to the non public address 127.0.0.1`,
+		},
+		{
+			name:    "link-local metadata address",
+			request: models.WebhookSubscriptionRequest{URL: "http://169.254.169.254/latest/meta-data", EventTypes: []models.EventType{models.EventTypeLoanCreated}},
+			message: `webhook host "169.254.169.254" resolves to the non public address 169.254.169.254`,
+		},
+		{
+			name:    "IPv6 loopback address",
+			request: models.WebhookSubscriptionRequest{URL: "http://[::1]/webhooks", EventTypes: []models.EventType{models.EventTypeLoanCreated}},
+			message: `webhook host "::1" resolves to the non public address ::1`,
+		},
+		{
+			name:    "host resolving to a private address",
+			request: models.WebhookSubscriptionRequest{URL: "https://internal.partner.example.com", EventTypes: []models.EventType{models.EventTypeLoanCreated}},
+			message: `webhook host "internal.partner.example.com" resolves to the non public address 10.0.0.5`,
+		},
+		{
+			name:    "unresolvable host",
+			request: models.WebhookSubscriptionRequest{URL: "https://unknown.example.com", EventTypes: []models.EventType{models.EventTypeLoanCreated}},
+			message: `cannot resolve the webhook host "unknown.example.com"`,
+		},
+		{
+			name:    "unknown event type",
+			request: models.WebhookSubscriptionRequest{URL: "https://partner.example.com", EventTypes: []models.EventType{"loan.deleted"}},
+			message: `unknown event type "loan.deleted"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service, _, _ := newTestWebhookService(t)
+
+			// Act
+			subscription, err := service.CreateSubscription(context.Background(), tt.request)
+
+			// Assert
+			assert.EqualError(t, err, tt.message)
+			assert.Nil(t, subscription)
+		})
+	}
+}
+
+func TestWebhookServiceImpl_EnqueueDeliveries(t *testing.T) {
+	// Arrange
+	service, mockSubscriptionRepo, mockDeliveryRepo := newTestWebhookService(t)
+	ctx := context.Background()
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	event := models.OutboxEvent{
+		ID:            "event-id",
+		EventType:     models.EventTypeLoanFullyPaid,
+		AggregateType: "loan",
+		AggregateID:   "loan-id",
+		Payload:       json.RawMessage(`{"loan_id":"loan-id","borrower_id":"borrower-id"}`),
+		CreatedAt:     createdAt,
+	}
+	var enqueued []models.WebhookDelivery
+
+	mockSubscriptionRepo.On("FindActiveByEventType", ctx, models.EventTypeLoanFullyPaid).
+		Return([]models.WebhookSubscription{{ID: "subscription-1", PartnerID: "partner-a"}, {ID: "subscription-2", PartnerID: "partner-a"}}, nil)
+	mockSubscriptionRepo.On("FindPartnerIDsByBorrowerID", ctx, "borrower-id").Return([]string{"partner-a"}, nil)
+	mockDeliveryRepo.On("Enqueue", ctx, testifymock.AnythingOfType("[]models.WebhookDelivery")).
+		Run(func(args testifymock.Arguments) {
+			enqueued = args.Get(1).([]models.WebhookDelivery)
+		}).
+		Return(nil)
+
+	// Act
+	err := service.EnqueueDeliveries(ctx, event)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, enqueued, 2)
+	assert.Equal(t, "subscription-1", enqueued[0].SubscriptionID)
+	assert.Equal(t, "subscription-2", enqueued[1].SubscriptionID)
+	assert.Equal(t, "event-id", enqueued[0].EventID)
+	assert.Equal(t, models.WebhookDeliveryStatusPending, enqueued[0].Status)
+	assert.NotNil(t, enqueued[0].NextAttemptAt)
+	assert.JSONEq(t, `{"id":"event-id","type":"loan.fully_paid","aggregate_type":"loan","aggregate_id":"loan-id","created_at":"2024-01-01T00:00:00Z","data":{"loan_id":"loan-id","borrower_id":"borrower-id"}}`, string(enqueued[0].Payload))
+}
+
+func TestWebhookServiceImpl_EnqueueDeliveries_OtherPartnerBorrower(t *testing.T) {
+	// Arrange
+	service, mockSubscriptionRepo, mockDeliveryRepo := newTestWebhookService(t)
+	ctx := context.Background()
+	event := models.OutboxEvent{
+		ID:        "event-id",
+		EventType: models.EventTypeLoanCreated,
+		Payload:   json.RawMessage(`{"loan_id":"loan-id","borrower_id":"borrower-of-a"}`),
+	}
+	var enqueued []models.WebhookDelivery
+
+	mockSubscriptionRepo.On("FindActiveByEventType", ctx, models.EventTypeLoanCreated).
+		Return([]models.WebhookSubscription{{ID: "subscription-a", PartnerID: "partner-a"}, {ID: "subscription-b", PartnerID: "partner-b"}}, nil)
+	mockSubscriptionRepo.On("FindPartnerIDsByBorrowerID", ctx, "borrower-of-a").Return([]string{"partner-a"}, nil)
+	mockDeliveryRepo.On("Enqueue", ctx, testifymock.AnythingOfType("[]models.WebhookDelivery")).
+		Run(func(args testifymock.Arguments) {
+			enqueued = args.Get(1).([]models.WebhookDelivery)
+		}).
+		Return(nil)
+
+	// Act
+	err := service.EnqueueDeliveries(ctx, event)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, enqueued, 1)
+	assert.Equal(t, "subscription-a", enqueued[0].SubscriptionID)
+}
+
+func TestWebhookServiceImpl_EnqueueDeliveries_BorrowerNotGranted(t *testing.T) {
+	// Arrange
+	service, mockSubscriptionRepo, _ := newTestWebhookService(t)
+	ctx := context.Background()
+	event := models.OutboxEvent{
+		ID:        "event-id",
+		EventType: models.EventTypeBorrowerCreated,
+		Payload:   json.RawMessage(`{"borrower_id":"borrower-id"}`),
+	}
+
+	mockSubscriptionRepo.On("FindActiveByEventType", ctx, models.EventTypeBorrowerCreated).
+		Return([]models.WebhookSubscription{{ID: "subscription-b", PartnerID: "partner-b"}}, nil)
+	mockSubscriptionRepo.On("FindPartnerIDsByBorrowerID", ctx, "borrower-id").Return([]string{}, nil)
+
+	// Act
+	err := service.EnqueueDeliveries(ctx, event)
+
+	// Assert, Enqueue isn't expected on the delivery mock
+	assert.NoError(t, err)
+}
+
+func TestWebhookServiceImpl_EnqueueDeliveries_WithoutBorrower(t *testing.T) {
+	// Arrange
+	service, mockSubscriptionRepo, _ := newTestWebhookService(t)
+	ctx := context.Background()
+
+	mockSubscriptionRepo.On("FindActiveByEventType", ctx, models.EventTypeLoanCreated).
+		Return([]models.WebhookSubscription{{ID: "subscription-a", PartnerID: "partner-a"}}, nil)
+
+	// Act
+	err := service.EnqueueDeliveries(ctx, models.OutboxEvent{ID: "event-id", EventType: models.EventTypeLoanCreated, Payload: json.RawMessage(`{}`)})
+
+	// Assert, the partners aren't even looked up
+	assert.NoError(t, err)
+}
+
+func TestWebhookServiceImpl_EnqueueDeliveries_NoSubscription(t *testing.T) {
+	// Arrange
+	service, mockSubscriptionRepo, _ := newTestWebhookService(t)
+	ctx := context.Background()
+
+	mockSubscriptionRepo.On("FindActiveByEventType", ctx, models.EventTypeLoanCreated).Return([]models.WebhookSubscription{}, nil)
+
+	// Act
+	err := service.EnqueueDeliveries(ctx, models.OutboxEvent{ID: "event-id", EventType: models.EventTypeLoanCreated})
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestWebhookServiceImpl_DeliverPending_Success(t *testing.T) {
+	// Arrange
+	payload := []byte(`{"id":"event-id","type":"loan.created"}`)
+	var receivedHeader http.Header
+	var receivedBody []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedHeader = r.Header
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	service, _, mockDeliveryRepo := newTestWebhookService(t)
+	deliveries := []models.WebhookDelivery{{
+		ID:        "delivery-id",
+		EventType: models.EventTypeLoanCreated,
+		Payload:   payload,
+		Status:    models.WebhookDeliveryStatusPending,
+		Subscription: models.WebhookSubscription{
+			ID:       "subscription-id",
+			URL:      receiver.URL,
+			Secret:   testWebhookSecret,
+			IsActive: true,
+		},
+	}}
+
+	// Act
+	attempted, recorded := deliverPendingWith(t, service, mockDeliveryRepo, deliveries)
+
+	// Assert
+	assert.Equal(t, 1, attempted)
+	assert.Equal(t, payload, receivedBody)
+	assert.Equal(t, "application/json", receivedHeader.Get("Content-Type"))
+	assert.Equal(t, "delivery-id", receivedHeader.Get("X-Webhook-ID"))
+	assert.Equal(t, "loan.created", receivedHeader.Get("X-Webhook-Event"))
+
+	signature := receivedHeader.Get(helpers.WebhookSignatureHeader)
+	var timestamp int64
+	_, err := fmt.Sscanf(signature, "t=%d,", &timestamp)
+	assert.NoError(t, err)
+	assert.Equal(t, helpers.SignWebhookPayload(testWebhookSecret, time.Unix(timestamp, 0), payload), signature)
+
+	assert.Len(t, recorded, 1)
+	assert.Equal(t, models.WebhookDeliveryStatusSucceeded, recorded[0].Status)
+	assert.Equal(t, 1, recorded[0].Attempts)
+	assert.Equal(t, http.StatusNoContent, *recorded[0].LastResponseStatus)
+	assert.NotNil(t, recorded[0].DeliveredAt)
+	assert.Nil(t, recorded[0].NextAttemptAt)
+}
+
+func TestWebhookServiceImpl_DeliverPending_FailedIsRetried(t *testing.T) {
+	// Arrange
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte("partner is down"))
+	}))
+	defer receiver.Close()
+
+	service, _, mockDeliveryRepo := newTestWebhookService(t)
+	deliveries := []models.WebhookDelivery{{
+		ID:           "delivery-id",
+		Payload:      []byte(`{}`),
+		Status:       models.WebhookDeliveryStatusPending,
+		Attempts:     1,
+		Subscription: models.WebhookSubscription{ID: "subscription-id", URL: receiver.URL, Secret: testWebhookSecret, IsActive: true},
+	}}
+	before := time.Now()
+
+	// Act
+	_, recorded := deliverPendingWith(t, service, mockDeliveryRepo, deliveries)
+
+	// Assert
+	assert.Equal(t, models.WebhookDeliveryStatusPending, recorded[0].Status)
+	assert.Equal(t, 2, recorded[0].Attempts)
+	assert.Equal(t, http.StatusInternalServerError, *recorded[0].LastResponseStatus)
+	assert.Equal(t, "partner is down", recorded[0].LastResponseBody)
+	assert.Equal(t, "unexpected response status 500", recorded[0].LastError)
+	assert.WithinDuration(t, before.Add(time.Minute), *recorded[0].NextAttemptAt, 5*time.Second)
+}
+
+func TestWebhookServiceImpl_DeliverPending_FailsAfterMaxAttempts(t *testing.T) {
+	// Arrange
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer receiver.Close()
+
+	service, _, mockDeliveryRepo := newTestWebhookService(t)
+	deliveries := []models.WebhookDelivery{{
+		ID:           "delivery-id",
+		Payload:      []byte(`{}`),
+		Status:       models.WebhookDeliveryStatusPending,
+		Attempts:     2,
+		Subscription: models.WebhookSubscription{ID: "subscription-id", URL: receiver.URL, Secret: testWebhookSecret, IsActive: true},
+	}}
+
+	// Act
+	_, recorded := deliverPendingWith(t, service, mockDeliveryRepo, deliveries)
+
+	// Assert
+	assert.Equal(t, models.WebhookDeliveryStatusFailed, recorded[0].Status)
+	assert.Equal(t, 3, recorded[0].Attempts)
+	assert.Nil(t, recorded[0].NextAttemptAt)
+}
+
+func TestWebhookServiceImpl_DeliverPending_DeletedSubscription(t *testing.T) {
+	// Arrange
+	service, _, mockDeliveryRepo := newTestWebhookService(t)
+	deliveries := []models.WebhookDelivery{{ID: "delivery-id", Payload: []byte(`{}`), Status: models.WebhookDeliveryStatusPending}}
+
+	// Act
+	_, recorded := deliverPendingWith(t, service, mockDeliveryRepo, deliveries)
+
+	// Assert
+	assert.Equal(t, models.WebhookDeliveryStatusFailed, recorded[0].Status)
+	assert.Equal(t, "webhook subscription is deleted or inactive", recorded[0].LastError)
+}
+
+func TestWebhookServiceImpl_DeliverPending_SendsOutsideTransaction(t *testing.T) {
+	// Arrange
+	var inTransaction, sentInTransaction bool
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sentInTransaction = inTransaction
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	service, _, mockDeliveryRepo := newTestWebhookService(t)
+	ctx := context.Background()
+	deliveries := []models.WebhookDelivery{
+		{ID: "delivery-1", Payload: []byte(`{}`), Status: models.WebhookDeliveryStatusPending, Subscription: models.WebhookSubscription{ID: "subscription-id", URL: receiver.URL, Secret: testWebhookSecret, IsActive: true}},
+		{ID: "delivery-2", Payload: []byte(`{}`), Status: models.WebhookDeliveryStatusPending, Subscription: models.WebhookSubscription{ID: "subscription-id", URL: receiver.URL, Secret: testWebhookSecret, IsActive: true}},
+	}
+	var leasedIDs []string
+	var leasedUntil time.Time
+	var recordedInTransaction []bool
+
+	mockDeliveryRepo.On("WithTransaction", ctx, testifymock.AnythingOfType("repositories.TransactionFunc")).
+		Return(func(ctx context.Context, fn repositories.TransactionFunc) error {
+			inTransaction = true
+			defer func() { inTransaction = false }()
+			return fn(ctx)
+		}).Once()
+	mockDeliveryRepo.On("FindDueForUpdate", ctx, testifymock.AnythingOfType("time.Time"), 10).Return(deliveries, nil)
+	mockDeliveryRepo.On("Lease", ctx, testifymock.AnythingOfType("[]string"), testifymock.AnythingOfType("time.Time")).
+		Run(func(args testifymock.Arguments) {
+			leasedIDs = args.Get(1).([]string)
+			leasedUntil = args.Get(2).(time.Time)
+		}).
+		Return(nil)
+	mockDeliveryRepo.On("RecordAttempt", ctx, testifymock.AnythingOfType("*models.WebhookDelivery")).
+		Run(func(args testifymock.Arguments) {
+			recordedInTransaction = append(recordedInTransaction, inTransaction)
+		}).
+		Return(errors.New("connection reset")).Once()
+	mockDeliveryRepo.On("RecordAttempt", ctx, testifymock.AnythingOfType("*models.WebhookDelivery")).
+		Run(func(args testifymock.Arguments) {
+			recordedInTransaction = append(recordedInTransaction, inTransaction)
+		}).
+		Return(nil).Once()
+	before := time.Now()
+
+	// Act
+	attempted, err := service.DeliverPending(ctx, 10)
+
+	// Assert, the lease covers the timeout of both sends and a failed record doesn't skip the next one
+	assert.EqualError(t, err, "cannot record the attempt of webhook delivery delivery-1: connection reset")
+	assert.Equal(t, 2, attempted)
+	assert.Equal(t, []string{"delivery-1", "delivery-2"}, leasedIDs)
+	assert.WithinDuration(t, before.Add(2*time.Second+webhookLeaseMargin), leasedUntil, 5*time.Second)
+	assert.False(t, sentInTransaction)
+	assert.Equal(t, []bool{false, false}, recordedInTransaction)
+}
+
+func TestWebhookServiceImpl_RedeliverDelivery_Success(t *testing.T) {
+	// Arrange
+	service, _, mockDeliveryRepo := newTestWebhookService(t)
+	ctx := context.Background()
+	delivery := &models.WebhookDelivery{
+		ID:             "delivery-id",
+		SubscriptionID: "subscription-id",
+		EventID:        "event-id",
+		EventType:      models.EventTypeLoanCreated,
+		Payload:        []byte(`{"id":"event-id"}`),
+		Status:         models.WebhookDeliveryStatusFailed,
+		Attempts:       8,
+		Subscription:   models.WebhookSubscription{ID: "subscription-id", IsActive: true},
+	}
+
+	mockDeliveryRepo.On("FindByID", ctx, "delivery-id", []string{"Subscription"}).Return(delivery, nil)
+	mockDeliveryRepo.On("Enqueue", ctx, testifymock.AnythingOfType("[]models.WebhookDelivery")).Return(nil)
+
+	// Act
+	redelivery, err := service.RedeliverDelivery(ctx, "delivery-id")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "delivery-id", *redelivery.RedeliveryOf)
+	assert.Equal(t, "event-id", redelivery.EventID)
+	assert.Equal(t, models.WebhookDeliveryStatusPending, redelivery.Status)
+	assert.Equal(t, 0, redelivery.Attempts)
+	assert.Equal(t, delivery.Payload, redelivery.Payload)
+}
+
+func TestWebhookServiceImpl_RedeliverDelivery_InactiveSubscription(t *testing.T) {
+	// Arrange
+	service, _, mockDeliveryRepo := newTestWebhookService(t)
+	ctx := context.Background()
+
+	mockDeliveryRepo.On("FindByID", ctx, "delivery-id", []string{"Subscription"}).Return(&models.WebhookDelivery{ID: "delivery-id"}, nil)
+
+	// Act
+	redelivery, err := service.RedeliverDelivery(ctx, "delivery-id")
+
+	// Assert
+	assert.EqualError(t, err, "webhook subscription is deleted or inactive")
+	assert.Nil(t, redelivery)
+}
+
+func TestWebhookServiceImpl_ListDeliveries_UnknownSubscription(t *testing.T) {
+	// Arrange
+	service, mockSubscriptionRepo, _ := newTestWebhookService(t)
+	ctx := context.Background()
+
+	mockSubscriptionRepo.On("FindByID", ctx, "subscription-id", []string{}).Return(nil, gorm.ErrRecordNotFound)
+
+	// Act
+	deliveries, err := service.ListDeliveries(ctx, "subscription-id", models.WebhookDeliveryListRequest{})
+
+	// Assert
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+	assert.Nil(t, deliveries)
+}
+
+func TestWebhookServiceImpl_GrantBorrower(t *testing.T) {
+	// Arrange
+	mockSubscriptionRepo := mock.NewWebhookSubscriptionRepository(t)
+	mockBorrowerRepo := mock.NewBorrowerRepository(t)
+	service := NewWebhookService(mockSubscriptionRepo, mock.NewWebhookDeliveryRepository(t), mockBorrowerRepo, &http.Client{}, 3)
+	ctx := context.Background()
+
+	mockBorrowerRepo.On("FindByID", ctx, "borrower-id", []string{}).Return(&models.Borrower{ID: "borrower-id"}, nil)
+	mockSubscriptionRepo.On("GrantBorrower", ctx, &models.PartnerBorrower{PartnerID: "partner-a", BorrowerID: "borrower-id"}).Return(nil)
+
+	// Act
+	grant, err := service.GrantBorrower(ctx, "partner-a", "borrower-id")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "partner-a", grant.PartnerID)
+	assert.Equal(t, "borrower-id", grant.BorrowerID)
+}
+
+func TestWebhookServiceImpl_GrantBorrower_UnknownBorrower(t *testing.T) {
+	// Arrange
+	mockBorrowerRepo := mock.NewBorrowerRepository(t)
+	service := NewWebhookService(mock.NewWebhookSubscriptionRepository(t), mock.NewWebhookDeliveryRepository(t), mockBorrowerRepo, &http.Client{}, 3)
+	ctx := context.Background()
+
+	mockBorrowerRepo.On("FindByID", ctx, "borrower-id", []string{}).Return(nil, gorm.ErrRecordNotFound)
+
+	// Act
+	grant, err := service.GrantBorrower(ctx, "partner-a", "borrower-id")
+
+	// Assert
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+	assert.Nil(t, grant)
+}
@@ -0,0 +1,22 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStorage stores binary objects (e.g. KYC documents) under a string key
+type BlobStorage interface {
+
+	// Put writes the content of the reader under the given key, replacing any existing blob
+	Put(ctx context.Context, key string, content io.Reader) error
+
+	// Get opens the blob stored under the given key, the caller must close the returned reader
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Delete removes the blob stored under the given key
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLocalBlobStorage_EmptyBaseDir(t *testing.T) {
+	// Act
+	storage, err := NewLocalBlobStorage("")
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, storage)
+}
+
+func TestLocalBlobStorage_PutGetDelete(t *testing.T) {
+	// Arrange
+	storage, err := NewLocalBlobStorage(t.TempDir())
+	assert.NoError(t, err)
+	ctx := context.Background()
+	key := "borrowers/borrower-id/selfie/1.jpg"
+
+	// Act
+	err = storage.Put(ctx, key, strings.NewReader("image-content"))
+	assert.NoError(t, err)
+
+	reader, err := storage.Get(ctx, key)
+	assert.NoError(t, err)
+	content, _ := io.ReadAll(reader)
+	reader.Close()
+
+	deleteErr := storage.Delete(ctx, key)
+	_, getAfterDeleteErr := storage.Get(ctx, key)
+
+	// Assert
+	assert.Equal(t, "image-content", string(content))
+	assert.NoError(t, deleteErr)
+	assert.Equal(t, ErrBlobNotFound, getAfterDeleteErr)
+}
+
+func TestLocalBlobStorage_Get_NotFound(t *testing.T) {
+	// Arrange
+	storage, _ := NewLocalBlobStorage(t.TempDir())
+
+	// Act
+	reader, err := storage.Get(context.Background(), "missing.jpg")
+
+	// Assert
+	assert.Nil(t, reader)
+	assert.Equal(t, ErrBlobNotFound, err)
+}
+
+func TestLocalBlobStorage_Put_KeyIsConfinedToBaseDir(t *testing.T) {
+	// Arrange
+	baseDir := t.TempDir()
+	storage, _ := NewLocalBlobStorage(baseDir + "/blobs")
+	ctx := context.Background()
+
+	// Act
+	err := storage.Put(ctx, "../../outside.txt", strings.NewReader("content"))
+	reader, getErr := storage.Get(ctx, "outside.txt")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, getErr)
+	reader.Close()
+}
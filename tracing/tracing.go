@@ -0,0 +1,41 @@
+// Package tracing holds the OpenTelemetry helpers of the service, spans are exported by the tracer
+// provider set up in config.NewTracerProvider and are no-ops until it is
+package tracing
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const instrumentationName = "github.com/satryarangga/amartha-loan-engine"
+
+// Tracer returns the tracer of the service, it is looked up on every call so spans follow the
+// global provider even when it is set after the package is loaded
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Start starts a span as a child of the span in ctx, the returned context carries the new span
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, opts...)
+}
+
+// End records err on the span before ending it
+func End(span trace.Span, err error) {
+	RecordError(span, err)
+	span.End()
+}
+
+// RecordError marks the span as failed, a missing row is an expected outcome so it isn't
+func RecordError(span trace.Span, err error) {
+	if err == nil || errors.Is(err, gorm.ErrRecordNotFound) {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
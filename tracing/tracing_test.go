@@ -0,0 +1,44 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/gorm"
+)
+
+func TestEnd(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus codes.Code
+		expectedEvents int
+	}{
+		{name: "Success", err: nil, expectedStatus: codes.Unset, expectedEvents: 0},
+		{name: "Record not found", err: gorm.ErrRecordNotFound, expectedStatus: codes.Unset, expectedEvents: 0},
+		{name: "Failure", err: errors.New("connection refused"), expectedStatus: codes.Error, expectedEvents: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			recorder := tracetest.NewSpanRecorder()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			_, span := provider.Tracer("test").Start(context.Background(), "LoanRepository.FindByID")
+
+			// Act
+			End(span, tt.err)
+
+			// Assert
+			spans := recorder.Ended()
+			assert.Len(t, spans, 1)
+			assert.Equal(t, tt.expectedStatus, spans[0].Status().Code)
+			assert.Len(t, spans[0].Events(), tt.expectedEvents)
+		})
+	}
+}
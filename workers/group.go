@@ -0,0 +1,45 @@
+package workers
+
+import (
+	"context"
+	"sync"
+)
+
+// Worker runs until its context is cancelled
+type Worker interface {
+	Run(ctx context.Context)
+}
+
+// Group runs the background workers and waits for them to stop on shutdown
+type Group struct {
+	running sync.WaitGroup
+}
+
+func NewGroup() *Group {
+	return &Group{}
+}
+
+// Start runs the worker in its own goroutine until ctx is cancelled
+func (g *Group) Start(ctx context.Context, worker Worker) {
+	g.running.Add(1)
+	go func() {
+		defer g.running.Done()
+		worker.Run(ctx)
+	}()
+}
+
+// Wait waits for every worker to return, or gives up when ctx is done first
+func (g *Group) Wait(ctx context.Context) error {
+	stopped := make(chan struct{})
+	go func() {
+		g.running.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
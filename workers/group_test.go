@@ -0,0 +1,65 @@
+package workers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeWorker struct {
+	stopDelay time.Duration
+	stopped   chan struct{}
+}
+
+func (w *fakeWorker) Run(ctx context.Context) {
+	<-ctx.Done()
+	time.Sleep(w.stopDelay)
+	close(w.stopped)
+}
+
+func TestGroup_Wait_WorkersStopped(t *testing.T) {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	group := NewGroup()
+	workers := []*fakeWorker{{stopped: make(chan struct{})}, {stopDelay: 10 * time.Millisecond, stopped: make(chan struct{})}}
+	for _, worker := range workers {
+		group.Start(ctx, worker)
+	}
+
+	// Act
+	cancel()
+	err := group.Wait(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	for _, worker := range workers {
+		assert.True(t, isClosed(worker.stopped))
+	}
+}
+
+func TestGroup_Wait_Timeout(t *testing.T) {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	group := NewGroup()
+	group.Start(ctx, &fakeWorker{stopDelay: time.Second, stopped: make(chan struct{})})
+	waitCtx, cancelWait := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelWait()
+
+	// Act
+	cancel()
+	err := group.Wait(waitCtx)
+
+	// Assert
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func isClosed(stopped chan struct{}) bool {
+	select {
+	case <-stopped:
+		return true
+	default:
+		return false
+	}
+}
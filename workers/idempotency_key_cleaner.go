@@ -0,0 +1,43 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/satryarangga/amartha-loan-engine/config"
+	"github.com/satryarangga/amartha-loan-engine/repositories"
+)
+
+// IdempotencyKeyCleaner periodically deletes the expired idempotency keys. Expired keys are already
+// ignored when a request reuses them, this only keeps the table small.
+type IdempotencyKeyCleaner struct {
+	idempotencyKeyRepo repositories.IdempotencyKeyRepository
+	interval           time.Duration
+	logger             *config.AmarthaLogger
+}
+
+func NewIdempotencyKeyCleaner(idempotencyKeyRepo repositories.IdempotencyKeyRepository, interval time.Duration, logger *config.AmarthaLogger) *IdempotencyKeyCleaner {
+	return &IdempotencyKeyCleaner{
+		idempotencyKeyRepo: idempotencyKeyRepo,
+		interval:           interval,
+		logger:             logger,
+	}
+}
+
+// Run cleans every interval until the context is cancelled
+func (c *IdempotencyKeyCleaner) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := c.idempotencyKeyRepo.DeleteExpired(ctx, time.Now()); err != nil {
+			c.logger.Errorf(ctx, "Unable to delete expired idempotency keys. Error: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/satryarangga/amartha-loan-engine/config"
+	"github.com/satryarangga/amartha-loan-engine/events"
+	"github.com/satryarangga/amartha-loan-engine/helpers"
+	"github.com/satryarangga/amartha-loan-engine/repositories"
+)
+
+const maxOutboxRetryDelay = 10 * time.Minute
+
+// OutboxRelay publishes the events stored in the outbox. The pending events are locked with
+// SKIP LOCKED, so several instances of the API can run the relay at the same time.
+type OutboxRelay struct {
+	outboxRepo repositories.OutboxRepository
+	publisher  events.Publisher
+	batchSize  int
+	interval   time.Duration
+	logger     *config.AmarthaLogger
+	now        func() time.Time
+}
+
+func NewOutboxRelay(
+	outboxRepo repositories.OutboxRepository,
+	publisher events.Publisher,
+	batchSize int,
+	interval time.Duration,
+	logger *config.AmarthaLogger,
+) *OutboxRelay {
+	return &OutboxRelay{
+		outboxRepo: outboxRepo,
+		publisher:  publisher,
+		batchSize:  batchSize,
+		interval:   interval,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// Run relays the outbox every interval until the context is cancelled
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		// a full batch means the outbox has a backlog, so keep going without waiting for the ticker
+		published, err := r.RelayBatch(ctx)
+		if err != nil {
+			r.logger.Errorf(ctx, "Unable to relay outbox events. Error: %v", err)
+		}
+		if err == nil && published == r.batchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayBatch publishes one batch of pending events and returns how many were handled. An event
+// failing to publish is retried later with an exponential backoff, it doesn't block the batch.
+func (r *OutboxRelay) RelayBatch(ctx context.Context) (int, error) {
+	var handled int
+	err := r.outboxRepo.WithTransaction(ctx, func(ctx context.Context) error {
+		now := r.now()
+		outboxEvents, err := r.outboxRepo.FindPendingForUpdate(ctx, now, r.batchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range outboxEvents {
+			if publishErr := r.publisher.Publish(ctx, event); publishErr != nil {
+				r.logger.Warnf(ctx, "Unable to publish outbox event %s (%s). Error: %v", event.ID, event.EventType, publishErr)
+				availableAt := now.Add(helpers.ExponentialBackoff(time.Second, maxOutboxRetryDelay, event.Attempts+1))
+				if err := r.outboxRepo.MarkFailed(ctx, event.ID, publishErr.Error(), availableAt); err != nil {
+					return err
+				}
+			} else if err := r.outboxRepo.MarkPublished(ctx, event.ID, now); err != nil {
+				return err
+			}
+			handled++
+		}
+		return nil
+	})
+	return handled, err
+}
@@ -0,0 +1,95 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/satryarangga/amartha-loan-engine/config"
+	"github.com/satryarangga/amartha-loan-engine/events"
+	"github.com/satryarangga/amartha-loan-engine/mock"
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/satryarangga/amartha-loan-engine/repositories"
+	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
+)
+
+type failingPublisher struct {
+	err error
+}
+
+func (p failingPublisher) Publish(ctx context.Context, event models.OutboxEvent) error {
+	return p.err
+}
+
+func newTestOutboxRelay(t *testing.T, publisher events.Publisher, now time.Time) (*OutboxRelay, *mock.OutboxRepository) {
+	mockOutboxRepo := mock.NewOutboxRepository(t)
+	logger := config.NewLogger()
+	relay := NewOutboxRelay(mockOutboxRepo, publisher, 10, time.Second, &logger)
+	relay.now = func() time.Time { return now }
+
+	mockOutboxRepo.On("WithTransaction", testifymock.Anything, testifymock.AnythingOfType("repositories.TransactionFunc")).
+		Return(func(ctx context.Context, fn repositories.TransactionFunc) error {
+			return fn(ctx)
+		})
+	return relay, mockOutboxRepo
+}
+
+func TestOutboxRelay_RelayBatch_Published(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	now := time.Now()
+	publisher := events.NewMemoryPublisher()
+	relay, mockOutboxRepo := newTestOutboxRelay(t, publisher, now)
+	pending := []models.OutboxEvent{
+		{ID: "event-1", EventType: models.EventTypeLoanCreated},
+		{ID: "event-2", EventType: models.EventTypeLoanFullyPaid},
+	}
+
+	mockOutboxRepo.On("FindPendingForUpdate", ctx, now, 10).Return(pending, nil)
+	mockOutboxRepo.On("MarkPublished", ctx, "event-1", now).Return(nil)
+	mockOutboxRepo.On("MarkPublished", ctx, "event-2", now).Return(nil)
+
+	// Act
+	handled, err := relay.RelayBatch(ctx)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2, handled)
+	assert.Equal(t, pending, publisher.Events())
+}
+
+func TestOutboxRelay_RelayBatch_PublishFailedIsRetriedLater(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	now := time.Now()
+	relay, mockOutboxRepo := newTestOutboxRelay(t, failingPublisher{err: errors.New("broker unavailable")}, now)
+
+	mockOutboxRepo.On("FindPendingForUpdate", ctx, now, 10).Return([]models.OutboxEvent{{ID: "event-1", Attempts: 2}}, nil)
+	mockOutboxRepo.On("MarkFailed", ctx, "event-1", "broker unavailable", now.Add(4*time.Second)).Return(nil)
+
+	// Act
+	handled, err := relay.RelayBatch(ctx)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, handled)
+}
+
+func TestOutboxRelay_RelayBatch_RepositoryError(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	now := time.Now()
+	relay, mockOutboxRepo := newTestOutboxRelay(t, events.NewMemoryPublisher(), now)
+	expectedError := errors.New("database error")
+
+	mockOutboxRepo.On("FindPendingForUpdate", ctx, now, 10).Return(nil, expectedError)
+
+	// Act
+	handled, err := relay.RelayBatch(ctx)
+
+	// Assert
+	assert.Equal(t, expectedError, err)
+	assert.Equal(t, 0, handled)
+}
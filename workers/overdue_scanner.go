@@ -0,0 +1,60 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/satryarangga/amartha-loan-engine/config"
+	"github.com/satryarangga/amartha-loan-engine/services"
+)
+
+// OverdueScanner periodically marks the schedules that passed their due date, which emits the
+// loan_schedule.overdue events
+type OverdueScanner struct {
+	loanService services.LoanService
+	batchSize   int
+	interval    time.Duration
+	logger      *config.AmarthaLogger
+	now         func() time.Time
+}
+
+func NewOverdueScanner(loanService services.LoanService, batchSize int, interval time.Duration, logger *config.AmarthaLogger) *OverdueScanner {
+	return &OverdueScanner{
+		loanService: loanService,
+		batchSize:   batchSize,
+		interval:    interval,
+		logger:      logger,
+		now:         time.Now,
+	}
+}
+
+// Run scans every interval until the context is cancelled
+func (s *OverdueScanner) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Scan(ctx); err != nil {
+			s.logger.Errorf(ctx, "Unable to mark overdue loan schedules. Error: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Scan marks the overdue schedules batch by batch until none is left
+func (s *OverdueScanner) Scan(ctx context.Context) error {
+	for {
+		marked, err := s.loanService.MarkOverdueSchedules(ctx, s.now(), s.batchSize)
+		if err != nil {
+			return err
+		}
+		if marked < s.batchSize {
+			return nil
+		}
+	}
+}
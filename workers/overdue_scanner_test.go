@@ -0,0 +1,56 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/satryarangga/amartha-loan-engine/config"
+	"github.com/satryarangga/amartha-loan-engine/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeLoanService returns the marked counts one call after the other
+type fakeLoanService struct {
+	services.LoanService
+	marked []int
+	err    error
+	calls  int
+}
+
+func (s *fakeLoanService) MarkOverdueSchedules(ctx context.Context, now time.Time, limit int) (int, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	marked := s.marked[s.calls]
+	s.calls++
+	return marked, nil
+}
+
+func TestOverdueScanner_Scan_UntilBacklogIsEmpty(t *testing.T) {
+	// Arrange
+	loanService := &fakeLoanService{marked: []int{10, 10, 3}}
+	logger := config.NewLogger()
+	scanner := NewOverdueScanner(loanService, 10, time.Minute, &logger)
+
+	// Act
+	err := scanner.Scan(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 3, loanService.calls)
+}
+
+func TestOverdueScanner_Scan_Error(t *testing.T) {
+	// Arrange
+	expectedError := errors.New("database error")
+	logger := config.NewLogger()
+	scanner := NewOverdueScanner(&fakeLoanService{err: expectedError}, 10, time.Minute, &logger)
+
+	// Act
+	err := scanner.Scan(context.Background())
+
+	// Assert
+	assert.Equal(t, expectedError, err)
+}
@@ -0,0 +1,57 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/satryarangga/amartha-loan-engine/config"
+	"github.com/satryarangga/amartha-loan-engine/metrics"
+	"github.com/satryarangga/amartha-loan-engine/services"
+)
+
+// PortfolioMetricsRefresher periodically recomputes the portfolio gauges from the database, they
+// can't be counted in-process like the loans created or the payments processed
+type PortfolioMetricsRefresher struct {
+	loanService services.LoanService
+	interval    time.Duration
+	logger      *config.AmarthaLogger
+	now         func() time.Time
+}
+
+func NewPortfolioMetricsRefresher(loanService services.LoanService, interval time.Duration, logger *config.AmarthaLogger) *PortfolioMetricsRefresher {
+	return &PortfolioMetricsRefresher{
+		loanService: loanService,
+		interval:    interval,
+		logger:      logger,
+		now:         time.Now,
+	}
+}
+
+// Run refreshes every interval until the context is cancelled
+func (r *PortfolioMetricsRefresher) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.Refresh(ctx); err != nil {
+			r.logger.Errorf(ctx, "Unable to refresh the portfolio metrics. Error: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh sets the portfolio gauges, they keep their previous value when the stats can't be computed
+func (r *PortfolioMetricsRefresher) Refresh(ctx context.Context) error {
+	stats, err := r.loanService.GetPortfolioStats(ctx, r.now())
+	if err != nil {
+		return err
+	}
+
+	metrics.SetPortfolioStats(stats)
+	return nil
+}
@@ -0,0 +1,56 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/satryarangga/amartha-loan-engine/config"
+	"github.com/satryarangga/amartha-loan-engine/metrics"
+	"github.com/satryarangga/amartha-loan-engine/models"
+	"github.com/satryarangga/amartha-loan-engine/services"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakePortfolioLoanService struct {
+	services.LoanService
+	stats models.PortfolioStats
+	err   error
+}
+
+func (s *fakePortfolioLoanService) GetPortfolioStats(ctx context.Context, now time.Time) (models.PortfolioStats, error) {
+	return s.stats, s.err
+}
+
+func TestPortfolioMetricsRefresher_Refresh(t *testing.T) {
+	// Arrange
+	logger := config.NewLogger()
+	loanService := &fakePortfolioLoanService{stats: models.PortfolioStats{OutstandingAmount: 1500000, DelinquentBorrowers: 3}}
+	refresher := NewPortfolioMetricsRefresher(loanService, time.Minute, &logger)
+
+	// Act
+	err := refresher.Refresh(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1500000), testutil.ToFloat64(metrics.OutstandingPortfolio))
+	assert.Equal(t, float64(3), testutil.ToFloat64(metrics.DelinquentBorrowers))
+}
+
+func TestPortfolioMetricsRefresher_Refresh_KeepsGaugesOnError(t *testing.T) {
+	// Arrange
+	logger := config.NewLogger()
+	metrics.SetPortfolioStats(models.PortfolioStats{OutstandingAmount: 500, DelinquentBorrowers: 1})
+	expectedError := errors.New("database error")
+	refresher := NewPortfolioMetricsRefresher(&fakePortfolioLoanService{err: expectedError}, time.Minute, &logger)
+
+	// Act
+	err := refresher.Refresh(context.Background())
+
+	// Assert
+	assert.Equal(t, expectedError, err)
+	assert.Equal(t, float64(500), testutil.ToFloat64(metrics.OutstandingPortfolio))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.DelinquentBorrowers))
+}
@@ -0,0 +1,57 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/satryarangga/amartha-loan-engine/config"
+	"github.com/satryarangga/amartha-loan-engine/services"
+)
+
+// WebhookDispatcher periodically sends the webhook deliveries that are due
+type WebhookDispatcher struct {
+	webhookService services.WebhookService
+	batchSize      int
+	interval       time.Duration
+	logger         *config.AmarthaLogger
+}
+
+func NewWebhookDispatcher(webhookService services.WebhookService, batchSize int, interval time.Duration, logger *config.AmarthaLogger) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		webhookService: webhookService,
+		batchSize:      batchSize,
+		interval:       interval,
+		logger:         logger,
+	}
+}
+
+// Run dispatches every interval until the context is cancelled
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.Dispatch(ctx); err != nil {
+			d.logger.Errorf(ctx, "Unable to dispatch webhook deliveries. Error: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Dispatch sends the due deliveries batch by batch until none is left
+func (d *WebhookDispatcher) Dispatch(ctx context.Context) error {
+	for {
+		attempted, err := d.webhookService.DeliverPending(ctx, d.batchSize)
+		if err != nil {
+			return err
+		}
+		if attempted < d.batchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/satryarangga/amartha-loan-engine/config"
+	"github.com/satryarangga/amartha-loan-engine/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeWebhookService returns the attempted counts one call after the other
+type fakeWebhookService struct {
+	services.WebhookService
+	attempted []int
+	err       error
+	calls     int
+}
+
+func (s *fakeWebhookService) DeliverPending(ctx context.Context, limit int) (int, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	attempted := s.attempted[s.calls]
+	s.calls++
+	return attempted, nil
+}
+
+func TestWebhookDispatcher_Dispatch_UntilBacklogIsEmpty(t *testing.T) {
+	// Arrange
+	webhookService := &fakeWebhookService{attempted: []int{10, 0}}
+	logger := config.NewLogger()
+	dispatcher := NewWebhookDispatcher(webhookService, 10, time.Minute, &logger)
+
+	// Act
+	err := dispatcher.Dispatch(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2, webhookService.calls)
+}
+
+func TestWebhookDispatcher_Dispatch_Error(t *testing.T) {
+	// Arrange
+	expectedError := errors.New("database error")
+	logger := config.NewLogger()
+	dispatcher := NewWebhookDispatcher(&fakeWebhookService{err: expectedError}, 10, time.Minute, &logger)
+
+	// Act
+	err := dispatcher.Dispatch(context.Background())
+
+	// Assert
+	assert.Equal(t, expectedError, err)
+}